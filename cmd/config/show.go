@@ -0,0 +1,50 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var ConfigShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective Konflux config and where each value comes from.",
+	Long: `Resolves the Konflux config from the layers below, later layers override earlier ones:
+ - built-in defaults
+ - "cluster-config" config map in "konflux-info" namespace
+ - optional "build-pipeline-config" config map in the pipeline namespace, only for keys tenants
+   may override, the allow-cache-proxy and allow-package-registry-proxy-<package-manager> switches
+   may only be set to false
+ - INI or YAML file set by PLATFORM_CONFIG_FILE environment variable
+ - KBC_CONFIG_<KEY> environment variables, e.g. KBC_CONFIG_HTTP_PROXY overrides http-proxy,
+   in the pipeline namespace with the same restrictions as the "build-pipeline-config" config map
+
+The cluster config map is optional if PLATFORM_CONFIG_FILE is set.
+
+Prints every config key with its effective value and source, followed by unknown keys
+and keys with invalid values.`,
+	Example: `
+  # Show the effective config
+  konflux-build-cli config show
+
+  # Show the effective config as JSON, with a local override
+  KBC_CONFIG_ALLOW_CACHE_PROXY=false konflux-build-cli config show --output-format json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting config show")
+		configShow, err := commands.NewConfigShow(cmd)
+		if err != nil {
+			l.Logger.Fatal(err)
+		}
+		if err := configShow.Run(); err != nil {
+			l.Logger.Fatal(err)
+		}
+		l.Logger.Debug("Finished config show")
+	},
+}
+
+func init() {
+	common.RegisterParameters(ConfigShowCmd, commands.ConfigShowParamsConfig)
+}
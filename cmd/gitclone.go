@@ -12,7 +12,11 @@ var gitCloneCmd = &cobra.Command{
 	Use:   "git-clone",
 	Short: "Clone a git repository",
 	Long: `Clone a git repository with support for submodules, sparse checkout,
-authentication, and optional merge with a target branch.`,
+authentication, and optional merge with a target branch.
+
+With --verify-signatures, the checked-out commit and the target branch head are
+verified to be signed by a trusted key. The signers directory may contain an SSH
+'allowed_signers' file and GPG public keys (*.asc, *.gpg, *.pgp).`,
 	Example: `  # Clone a repository
   kbc git-clone --url https://github.com/user/repo.git
 
@@ -22,9 +26,43 @@ authentication, and optional merge with a target branch.`,
   # Clone with sparse checkout (only specific directories)
   kbc git-clone --url https://github.com/user/repo.git --sparse-checkout-directories "src,docs"
 
+  # Blobless partial clone of a large monorepo, fetching only the blobs of the checked out directories
+  kbc git-clone --url https://github.com/user/monorepo.git --clone-filter blob:none --sparse-checkout-directories "services/api"
+
+  # Clone using a bare mirror on a persistent volume to download only new objects
+  kbc git-clone --url https://github.com/user/repo.git --reference-dir /var/cache/git-mirrors
+
+  # Export a clone into a bundle file, and clone from it later without network access
+  kbc git-clone --url https://github.com/user/repo.git --revision main --depth 0 --create-bundle /workspace/repo.bundle
+  kbc git-clone --bundle /workspace/repo.bundle --url https://github.com/user/repo.git --revision main
+
+  # Clone and write a reproducible source archive, with its digest for provenance
+  kbc git-clone --url https://github.com/user/repo.git --revision main \
+    --source-archive-output /workspace/source.tar.gz --source-archive-digest-output /tekton/results/SOURCE_ARCHIVE_DIGEST
+
+  # Clone with Git LFS objects of the checked out directories
+  kbc git-clone --url https://github.com/user/repo.git --lfs --sparse-checkout-directories "assets"
+
+  # Clone a signed tag, failing unless it and its commit are signed by a trusted key
+  kbc git-clone --url https://github.com/user/repo.git --revision v1.0.0 \
+    --verify-signatures --verify-tag-signature --signers-directory /workspace/signers
+
+  # Clone with nested submodules, only from the organization on GitHub and at most two levels deep
+  kbc git-clone --url https://github.com/user/repo.git \
+    --submodule-allowed-urls 'https://github.com/user/*' --submodule-max-depth 2
+
   # Clone and merge target branch (for PR testing)
   kbc git-clone --url https://github.com/user/repo.git --revision feature-branch --merge-target-branch --target-branch main
 
+  # Clone a PR revision and report whether it changes the files of a component
+  kbc git-clone --url https://github.com/user/monorepo.git --revision feature-branch \
+    --changed-files-base main --changed-files-patterns 'services/api/*,go.mod' \
+    --changed-files-output /tekton/results/CHANGED_FILES --component-affected-output /tekton/results/COMPONENT_AFFECTED
+
+  # Simulate a merge queue: merge the PR into the target branch, then other queued PRs, one from a fork
+  kbc git-clone --url https://github.com/user/repo.git --revision main --depth 0 \
+    --merge-refs 'refs/pull/12/head,refs/pull/15/head,https://github.com/fork/repo.git#fix-build'
+
   # Clone while ignoring known test symlinks outside the tree
   kbc git-clone --url https://github.com/helm/helm.git --revision release-3.20 \
     --symlink-check-ignore-pattern 'internal/*/testdata/*,pkg/*/testdata/*'`,
@@ -15,5 +15,7 @@ func init() {
 	imageCmd.AddCommand(image.ApplyTagsCmd)
 	imageCmd.AddCommand(image.BuildCmd)
 	imageCmd.AddCommand(image.BuildImageIndexCmd)
+	imageCmd.AddCommand(image.BuildSourceCmd)
+	imageCmd.AddCommand(image.FetchContainerfileCmd)
 	imageCmd.AddCommand(image.PushContainerfileCmd)
 }
@@ -19,7 +19,41 @@ Tags can be defined in two ways:
  - via tags parameter
  - via image label in the base image (see --tags-from-image-label parameter)
 Both ways can be used together.
+
+The tags are always applied to the given digest, which can be an image index, e.g. the output of build-image-index.
+For an image index, the label with tags is read from every platform image in the index (attestations are skipped).
+If the platform images have different label values, the command fails, or, with --label-conflict merge,
+the tags of all platforms are applied.
+
+Tags given via tags parameter can be Go templates rendered with the image config data:
+ - .Labels  - image labels, e.g. {{ .Labels.version }} or {{ index .Labels "org.opencontainers.image.version" }}
+ - .Env     - image environment variables, e.g. {{ .Env.GIT_COMMIT }}
+ - .Created - image creation time, e.g. {{ .Created.Format "20060102" }}
+ - .Digest  - image digest without the algorithm prefix, e.g. {{ trunc 12 .Digest }}
+Available functions: trunc, lower, replace.
+The rendered tags must be valid image tags.
+
+With --expand-semver, semantic version tags are expanded, e.g. '1.2.3' also creates '1.2' and '1' tags.
+
+Tags matching one of --protected-tags patterns are never moved: if such a tag already points to a different
+digest, the command fails before any tag is created. Protected tags cannot be removed either.
+
+Tags given via --remove-tags are removed after the new tags are created. Only the tags are deleted, the manifests
+they point to and their other tags, e.g. sha256-<digest>.sig, are kept. The registry must support deleting tags.
+A tag pointing to the image being tagged cannot be removed.
+
+The digest each created or removed tag pointed to before is reported in the results.
 `,
+	Example: `  # Apply static tags
+  konflux-build-cli image apply-tags --image-url quay.io/org/app --digest sha256:abc... --tags latest v1
+
+  # Apply version tags from image label and short commit tag from image env
+  konflux-build-cli image apply-tags --image-url quay.io/org/app --digest sha256:abc... \
+    --tags '{{ .Labels.version }}' '{{ trunc 7 .Env.GIT_COMMIT }}' --expand-semver --expand-semver-latest
+
+  # Move 'latest' tag, but never overwrite release tags, and remove a pull request tag
+  konflux-build-cli image apply-tags --image-url quay.io/org/app --digest sha256:abc... \
+    --tags latest v1.2.3 --protected-tags 'v[0-9]+\.[0-9]+\.[0-9]+' --remove-tags pr-42`,
 	Run: func(cmd *cobra.Command, args []string) {
 		l.Logger.Debug("Starting apply-tags")
 		applyTags, err := commands.NewApplyTags(cmd)
@@ -15,6 +15,10 @@ var BuildImageIndexCmd = &cobra.Command{
 
 This command combines multiple container images into a single image index, enabling
 multi-platform container image support.
+
+Input images that are image indexes themselves (e.g. the per-arch index produced by
+'image build --compression-format=dual') are flattened: every child manifest is added
+to the resulting index directly, keeping its platform and annotations.
 `,
 	Example: `  # Build an image index from multiple platform images
   konflux-build-cli image build-image-index \
@@ -27,6 +31,12 @@ multi-platform container image support.
     --images quay.io/myorg/myapp@sha256:amd64digest... quay.io/myorg/myapp@sha256:arm64digest... \
     --additional-tags taskrun-xyz-12345 commit-abc123
 
+  # Fail unless the index contains exactly the amd64 and arm64 platforms
+  konflux-build-cli image build-image-index \
+    --image quay.io/myorg/myapp:latest \
+    --images quay.io/myorg/myapp@sha256:amd64digest... quay.io/myorg/myapp@sha256:arm64digest... \
+    --required-platforms linux/amd64 linux/arm64
+
   # Write results to files (useful for Tekton tasks)
   konflux-build-cli image build-image-index \
     --image quay.io/myorg/myapp:latest \
@@ -0,0 +1,66 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var BuildSourceCmd = &cobra.Command{
+	Use:   "build-source",
+	Short: "Build the source image of a built image and push it to registry.",
+	Long: `Builds an OCI source image and pushes it to the repository of the built image.
+
+The source image has a layer per source component:
+ - the source tree given by --source-dir, without .git, under src/
+ - each package manager directory of the prefetch-dependencies output given by
+   --prefetch-output-dir, under deps/<package manager>/
+ - the layers of the source images of the base images listed in the file given by
+   --resolved-base-images-file. The source image of a base image is looked up as
+   <repository>:sha256-<digest hex>.src, then as <repository>:<version>-<release>-source
+   from the labels of the base image. Base images without a source image are skipped.
+   Base images and source images which are image indexes are resolved to the
+   platform of the built image --output-ref, which is read from the registry.
+   The layers are streamed from the registry of the source image.
+
+The platform of the source image is the one of the built image. If --output-ref
+is an image index, the platform must be selected with --platform. Each layer is
+annotated with the component type and name. The source tree and the prefetched
+dependencies are archived reproducibly, so the same inputs give the same image.
+
+The source image is pushed as <output-ref tag>-source, or with the tag given by
+--source-image-tag, using the credential selected from the default authentication
+file (~/.docker/config.json).`,
+	Example: `
+  # Push the sources of quay.io/org/app:v1 as quay.io/org/app:v1-source
+  konflux-build-cli image build-source --output-ref quay.io/org/app:v1 --source-dir source
+
+  # Include prefetched dependencies and the sources of the base images
+  konflux-build-cli image build-source --output-ref quay.io/org/app:v1 --source-dir source \
+    --prefetch-output-dir prefetch-output --resolved-base-images-file /path/to/resolved-base-images
+
+  # Push the sources of the linux/arm64 image of the image index quay.io/org/app:v1
+  konflux-build-cli image build-source --output-ref quay.io/org/app:v1 --source-dir source --platform linux/arm64
+
+  # Push with a custom tag and write the pushed image reference into a file
+  konflux-build-cli image build-source --output-ref quay.io/org/app:v1 --source-dir source \
+    --source-image-tag sha256-1234567.src --result-path-image-ref /tmp/source-image-ref
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting build-source")
+		buildSource, err := commands.NewBuildSource(cmd)
+		if err != nil {
+			l.Logger.Fatal(err)
+		}
+		if err := buildSource.Run(); err != nil {
+			l.Logger.Fatal(err)
+		}
+		l.Logger.Debug("Finished build-source")
+	},
+}
+
+func init() {
+	common.RegisterParameters(BuildSourceCmd, commands.BuildSourceParamsConfig)
+}
@@ -0,0 +1,48 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var FetchContainerfileCmd = &cobra.Command{
+	Use:   "fetch-containerfile",
+	Short: "Fetch the Containerfile pushed by push-containerfile for the given image digest.",
+	Long: `Fetches Containerfile of a built image from image registry.
+
+This is the reverse of push-containerfile. The Containerfile artifact tag is
+constructed from the image digest and the tag suffix the same way as
+push-containerfile does, e.g. quay.io/org/app:sha256-1234567.containerfile.
+If the Containerfile was pushed with --alternative-filename, pass the same
+file name to select the right layer of the artifact.
+
+Registry authentication is selected from the default authentication file
+~/.docker/config.json. If it is not configured, the artifact is fetched anonymously.`,
+	Example: `
+  # Fetch Containerfile of quay.io/org/app@sha256:1234567 and write it to ./Containerfile
+  konflux-build-cli image fetch-containerfile --image-url quay.io/org/app --image-digest sha256:1234567 \
+    --output ./Containerfile
+
+  # Fetch Containerfile pushed with a custom tag suffix and an alternative file name
+  konflux-build-cli image fetch-containerfile --image-url quay.io/org/app --image-digest sha256:1234567 \
+    --tag-suffix .dockerfile --alternative-filename Dockerfile --output /tmp/Dockerfile
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting fetch-containerfile")
+		fetchContainerfile, err := commands.NewFetchContainerfile(cmd)
+		if err != nil {
+			l.Logger.Fatal(err)
+		}
+		if err := fetchContainerfile.Run(); err != nil {
+			l.Logger.Fatal(err)
+		}
+		l.Logger.Debug("Finished fetch-containerfile")
+	},
+}
+
+func init() {
+	common.RegisterParameters(FetchContainerfileCmd, commands.FetchContainerfileParamsConfig)
+}
@@ -17,7 +17,20 @@ Containerfile is auto-detected from the source by default. It is searched
 firstly from build context, then the source directory. Dockerfile is supported
 as a fallback. If neither is found command exits as normal without pushing
 anyting. The search is highly customizable with arguments --source, --context
-and --containerfile.`,
+and --containerfile.
+
+Build metadata is pushed along with the Containerfile, each file as a separate
+layer of the artifact with its own media type and title annotation:
+ - the .containerignore/.dockerignore of the Containerfile or the build context, if any
+ - the build arguments file given by --build-args-file
+ - the resolved base images file given by --resolved-base-images-file
+ - the Buildprobe result given by --buildprobe-file
+
+The artifact is pushed directly to the registry using the credential selected
+for --image-url from the default authentication file (~/.docker/config.json).
+Registry settings of registries.conf and certificates of certs.d apply the same
+way as for skopeo and buildah, --tls-verify=false allows a registry without TLS.
+Use --push-backend oras to push with the oras CLI instead.`,
 	Example: `
   # Push source/Containerfile as artifact quay.io/org/app:sha256-1234567.containerfile
   konflux-build-cli image push-containerfile --image-url quay.io/org/app --image-digest sha256:1234567 --source source
@@ -46,6 +59,11 @@ and --containerfile.`,
   konflux-build-cli image push-containerfile --image-url quay.io/org/app --image-digest sha256:1234567 \
     --source /path/to/source --context db --containerfile containerfiles/db \
     --alternative-filename Dockerfile
+
+  # Push source/Containerfile together with build metadata written by the build command
+  konflux-build-cli image push-containerfile --image-url quay.io/org/app --image-digest sha256:1234567 \
+    --source source --build-args-file /path/to/build-args \
+    --resolved-base-images-file /path/to/resolved-base-images --buildprobe-file /path/to/buildprobe.yaml
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		l.Logger.Debug("Starting push-containerfile")
@@ -53,4 +53,5 @@ func init() {
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(internalCmdGroup)
 	rootCmd.AddCommand(gitCloneCmd)
+	rootCmd.AddCommand(sbomCmd)
 }
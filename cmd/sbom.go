@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/cmd/sbom"
+)
+
+var sbomCmd = &cobra.Command{
+	Use:   "sbom",
+	Short: "A sub command group to work with SBOMs",
+}
+
+func init() {
+	sbomCmd.AddCommand(sbom.ConvertCmd)
+	sbomCmd.AddCommand(sbom.MergeCmd)
+}
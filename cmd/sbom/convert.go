@@ -0,0 +1,48 @@
+package sbom
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var ConvertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert an SBOM between SPDX and CycloneDX.",
+	Long: `Converts an SPDX 2.3 JSON SBOM to CycloneDX 1.5/1.6 JSON, or a CycloneDX SBOM to SPDX.
+
+The format of the given SBOM is detected, --sbom-format is the format to convert to.
+
+Purls, checksums, licenses, external references and relationships are kept:
+ - the package described by the SPDX document is the CycloneDX metadata component
+ - packages and files are components, files nested in the components of their package
+ - SPDXIDs are used as bom-refs, and bom-refs that are valid SPDXIDs are kept
+ - DEPENDS_ON relationships are dependencies, DESCENDANT_OF relationships pedigree ancestors
+ - external references without a CycloneDX counterpart are kept as spdx:externalRef:<category>:<type> properties
+
+Fields that could not be mapped are logged as warnings and listed in the results.`,
+	Example: `
+  # Convert the SPDX image SBOM to CycloneDX 1.6
+  konflux-build-cli sbom convert --sbom sbom.spdx.json --sbom-format cyclonedx --output sbom.cdx.json
+
+  # Convert a CycloneDX SBOM to SPDX
+  konflux-build-cli sbom convert --sbom sbom.cdx.json --sbom-format spdx --output sbom.spdx.json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting sbom convert")
+		convert, err := commands.NewSBOMConvert(cmd)
+		if err != nil {
+			l.Logger.Fatal(err)
+		}
+		if err := convert.Run(); err != nil {
+			l.Logger.Fatal(err)
+		}
+		l.Logger.Debug("Finished sbom convert")
+	},
+}
+
+func init() {
+	common.RegisterParameters(ConvertCmd, commands.SBOMConvertParamsConfig)
+}
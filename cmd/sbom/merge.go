@@ -0,0 +1,62 @@
+package sbom
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var MergeCmd = &cobra.Command{
+	Use:   "merge",
+	Short: "Merge SBOMs of an image into a single SBOM.",
+	Long: `Merges SBOMs of the same format, SPDX 2.3 or CycloneDX 1.5/1.6 JSON, into a single document.
+
+The first SBOM given by --sbom describes the image, usually the syft image SBOM.
+The other SBOMs, e.g. the syft source SBOM and the prefetch SBOM, are added as its content:
+ - SPDX: the packages described by the other SBOMs are contained by the image package
+ - CycloneDX: the metadata components of the other SBOMs are components the image depends on
+
+Packages with the same purl are merged into one, keeping the checksums and references of
+all of them. Relationships and dependencies are kept, identifiers colliding between the SBOMs
+are renamed.
+
+Only the base image of the final build stage, given by --base-image, is an ancestor of the image:
+an SPDX package the image is DESCENDANT_OF, or a CycloneDX pedigree ancestor.
+Without --base-image, it is the first image of the file given by --resolved-base-images-file,
+where the build writes the images of the final stage first. Set --base-image if the final stage
+is built from scratch or from another stage.
+The other digested images from the file given by --resolved-base-images-file, i.e. the base images
+of builder stages and the images of COPY --from and RUN --mount=from, were only used to build the image:
+SPDX packages that are BUILD_TOOL_OF the image, or components of a CycloneDX formula.`,
+	Example: `
+  # Merge SPDX SBOMs of the image, the source and the prefetched dependencies
+  konflux-build-cli sbom merge --sbom image.spdx.json source.spdx.json prefetch-output/bom.json \
+    --output sbom.spdx.json
+
+  # Merge CycloneDX SBOMs and add the base image and the build images
+  konflux-build-cli sbom merge --sbom-format cyclonedx --sbom image.cdx.json prefetch-output/bom.json \
+    --resolved-base-images-file /path/to/resolved-base-images --output sbom.cdx.json
+
+  # Set the base image if the final stage is built from scratch or from another stage
+  konflux-build-cli sbom merge --sbom image.spdx.json --output sbom.spdx.json \
+    --base-image registry.access.redhat.com/ubi9:latest@sha256:<digest> \
+    --resolved-base-images-file /path/to/resolved-base-images
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting sbom merge")
+		merge, err := commands.NewSBOMMerge(cmd)
+		if err != nil {
+			l.Logger.Fatal(err)
+		}
+		if err := merge.Run(); err != nil {
+			l.Logger.Fatal(err)
+		}
+		l.Logger.Debug("Finished sbom merge")
+	},
+}
+
+func init() {
+	common.RegisterParameters(MergeCmd, commands.SBOMMergeParamsConfig)
+}
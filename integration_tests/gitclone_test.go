@@ -1,8 +1,14 @@
 package integration_tests
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -164,7 +170,7 @@ func TestGitClone(t *testing.T) {
 				return map[string]string{"expectedCommit": headCommit}
 			},
 			url:  "file:///workspace/main-bare.git",
-			args: []string{"--depth", "0", "--submodules=true"},
+			args: []string{"--depth", "0", "--submodules=true", "--submodule-allow-local-urls=true"},
 			check: func(t *testing.T, workspaceDir, stdout, stderr string, setupData map[string]string) {
 				content, err := os.ReadFile(filepath.Join(workspaceDir, "out", "my-submodule", "sub-file.txt"))
 				Expect(err).ToNot(HaveOccurred())
@@ -172,6 +178,72 @@ func TestGitClone(t *testing.T) {
 
 				head := runGit(t, filepath.Join(workspaceDir, "out"), "rev-parse", "HEAD")
 				Expect(head).To(Equal(setupData["expectedCommit"]))
+
+				result, err := parseGitCloneResult(stdout)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.Submodules).To(HaveLen(1))
+				Expect(result.Submodules[0].Path).To(Equal("my-submodule"))
+				Expect(result.Submodules[0].URL).To(Equal("file:///workspace/sub-bare.git"))
+				Expect(result.Submodules[0].Commit).ToNot(BeEmpty())
+			},
+		},
+		{
+			name: "create reproducible source archive with submodules",
+			setup: func(t *testing.T, workspaceDir string) map[string]string {
+				prepareBareRepoWithSubmodule(t, workspaceDir)
+				// Allow the container user to write the archive
+				Expect(os.Chmod(workspaceDir, 0777)).To(Succeed())
+				return nil
+			},
+			url: "file:///workspace/main-bare.git",
+			args: []string{"--depth", "0", "--submodules=true", "--submodule-allow-local-urls=true",
+				"--source-archive-output", "/workspace/source.tar.gz", "--source-archive-digest-output", "/workspace/source-digest"},
+			check: func(t *testing.T, workspaceDir, stdout, stderr string, _ map[string]string) {
+				archive, err := os.ReadFile(filepath.Join(workspaceDir, "source.tar.gz"))
+				Expect(err).ToNot(HaveOccurred())
+				sum := sha256.Sum256(archive)
+				expectedDigest := "sha256:" + hex.EncodeToString(sum[:])
+
+				digest, err := os.ReadFile(filepath.Join(workspaceDir, "source-digest"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(digest)).To(Equal(expectedDigest))
+
+				result, err := parseGitCloneResult(stdout)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.SourceArchive).ToNot(BeNil())
+				Expect(result.SourceArchive.Digest).To(Equal(expectedDigest))
+
+				gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+				Expect(err).ToNot(HaveOccurred())
+				tarReader := tar.NewReader(gzipReader)
+				var names []string
+				for {
+					header, err := tarReader.Next()
+					if err == io.EOF {
+						break
+					}
+					Expect(err).ToNot(HaveOccurred())
+					names = append(names, header.Name)
+				}
+				Expect(names).To(ContainElement("my-submodule/sub-file.txt"))
+				Expect(names).ToNot(ContainElement(HavePrefix(".git/")))
+				Expect(names).ToNot(ContainElement("my-submodule/.git"))
+				Expect(slices.IsSorted(names)).To(BeTrue())
+			},
+		},
+		{
+			name: "reject local submodule URLs by default",
+			setup: func(t *testing.T, workspaceDir string) map[string]string {
+				prepareBareRepoWithSubmodule(t, workspaceDir)
+				return nil
+			},
+			url:     "file:///workspace/main-bare.git",
+			args:    []string{"--depth", "0", "--submodules=true"},
+			wantErr: true,
+			check: func(t *testing.T, workspaceDir, stdout, stderr string, _ map[string]string) {
+				Expect(stderr).To(ContainSubstring("local paths and file:// URLs are not allowed"))
+				_, err := os.Stat(filepath.Join(workspaceDir, "out", "my-submodule", "sub-file.txt"))
+				Expect(err).To(MatchError(os.ErrNotExist))
 			},
 		},
 		{
@@ -191,6 +263,143 @@ func TestGitClone(t *testing.T) {
 				Expect(result.MergedSha).ToNot(BeEmpty())
 			},
 		},
+		{
+			name: "clone from bundle and export it again",
+			setup: func(t *testing.T, workspaceDir string) map[string]string {
+				repo := createLocalTestRepo(t)
+				runGit(t, repo.Path, "bundle", "create", filepath.Join(workspaceDir, "repo.bundle"), "HEAD", "main")
+				// Allow the container user to write the exported bundle
+				Expect(os.Chmod(workspaceDir, 0777)).To(Succeed())
+				return map[string]string{"commit": runGit(t, repo.Path, "rev-parse", "HEAD")}
+			},
+			url: "",
+			args: []string{"--bundle", "/workspace/repo.bundle", "--revision", "main", "--submodules=false",
+				"--create-bundle", "/workspace/export.bundle"},
+			check: func(t *testing.T, workspaceDir, stdout, stderr string, setupData map[string]string) {
+				Expect(filepath.Join(workspaceDir, "out", "README.md")).To(BeAnExistingFile())
+
+				result, err := parseGitCloneResult(stdout)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.Commit).To(Equal(setupData["commit"]))
+				Expect(result.URL).To(BeEmpty())
+
+				heads := runGit(t, workspaceDir, "bundle", "list-heads", filepath.Join(workspaceDir, "export.bundle"))
+				Expect(heads).To(ContainSubstring(setupData["commit"] + " refs/heads/main"))
+			},
+		},
+		{
+			name: "changed files against base branch deepen shallow clone",
+			setup: func(t *testing.T, workspaceDir string) map[string]string {
+				prepareBareRepoWithFeatureBranch(t, workspaceDir)
+				// Allow the container user to write the result files
+				Expect(os.Chmod(workspaceDir, 0777)).To(Succeed())
+				return nil
+			},
+			url: "file:///workspace/merge-bare.git",
+			args: []string{"--depth", "1", "--revision", "feature", "--submodules=false",
+				"--changed-files-base", "main", "--changed-files-patterns", "feature-*",
+				"--changed-files-output", "/workspace/changed-files", "--component-affected-output", "/workspace/component-affected"},
+			check: func(t *testing.T, workspaceDir, stdout, stderr string, _ map[string]string) {
+				changedFiles, err := os.ReadFile(filepath.Join(workspaceDir, "changed-files"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(changedFiles)).To(Equal("feature-only.txt\n"))
+				affected, err := os.ReadFile(filepath.Join(workspaceDir, "component-affected"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(affected)).To(Equal("true"))
+
+				result, err := parseGitCloneResult(stdout)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.ChangedFiles).ToNot(BeNil())
+				Expect(result.ChangedFiles.Count).To(Equal(1))
+			},
+		},
+		{
+			name: "merge multiple refs in order",
+			setup: func(t *testing.T, workspaceDir string) map[string]string {
+				prepareBareRepoWithFeatureBranch(t, workspaceDir)
+				return nil
+			},
+			url:  "file:///workspace/merge-bare.git",
+			args: []string{"--depth", "0", "--revision", "main", "--merge-refs", "feature,file:///workspace/merge-bare.git#feature", "--submodules=false"},
+			check: func(t *testing.T, workspaceDir, stdout, stderr string, _ map[string]string) {
+				Expect(filepath.Join(workspaceDir, "out", "feature-only.txt")).To(BeAnExistingFile())
+				Expect(filepath.Join(workspaceDir, "out", "main-only.txt")).To(BeAnExistingFile())
+
+				result, err := parseGitCloneResult(stdout)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.MergedRefs).To(HaveLen(2))
+				Expect(result.MergedRefs[0].Ref).To(Equal("feature"))
+				Expect(result.MergedSha).To(Equal(result.MergedRefs[1].MergeCommit))
+				Expect(result.MergedSha).To(Equal(runGit(t, filepath.Join(workspaceDir, "out"), "rev-parse", "HEAD")))
+			},
+		},
+		{
+			name: "blobless partial clone with sparse checkout and tags",
+			setup: func(t *testing.T, workspaceDir string) map[string]string {
+				repo := createLocalTestRepo(t)
+				bareCloneToPath(t, repo.Path, filepath.Join(workspaceDir, "repo.git"))
+				runGit(t, filepath.Join(workspaceDir, "repo.git"), "config", "uploadpack.allowFilter", "true")
+				return map[string]string{"tagCommit": repo.TagCommit}
+			},
+			url:  "file:///workspace/repo.git",
+			args: []string{"--depth", "0", "--clone-filter", "blob:none", "--sparse-checkout-directories", "src", "--fetch-tags=true", "--submodules=false"},
+			check: func(t *testing.T, workspaceDir, stdout, stderr string, setupData map[string]string) {
+				outDir := filepath.Join(workspaceDir, "out")
+				Expect(filepath.Join(outDir, "src", "file.txt")).To(BeAnExistingFile())
+
+				Expect(runGit(t, outDir, "config", "remote.origin.promisor")).To(Equal("true"))
+				Expect(runGit(t, outDir, "config", "remote.origin.partialclonefilter")).To(Equal("blob:none"))
+				// Blobs outside the sparse checkout are not fetched
+				missing := runGit(t, outDir, "rev-list", "--objects", "--missing=print", "HEAD")
+				Expect(missing).To(ContainSubstring("?"))
+
+				Expect(runGit(t, outDir, "rev-parse", "v1.0.0^{commit}")).To(Equal(setupData["tagCommit"]))
+			},
+		},
+		{
+			name: "merge target branch into blobless partial clone",
+			setup: func(t *testing.T, workspaceDir string) map[string]string {
+				prepareBareRepoWithFeatureBranch(t, workspaceDir)
+				runGit(t, filepath.Join(workspaceDir, "merge-bare.git"), "config", "uploadpack.allowFilter", "true")
+				return nil
+			},
+			url:  "file:///workspace/merge-bare.git",
+			args: []string{"--depth", "0", "--clone-filter", "blob:none", "--revision", "feature", "--merge-target-branch", "--target-branch", "main", "--submodules=false"},
+			check: func(t *testing.T, workspaceDir, stdout, stderr string, _ map[string]string) {
+				Expect(filepath.Join(workspaceDir, "out", "feature-only.txt")).To(BeAnExistingFile())
+				Expect(filepath.Join(workspaceDir, "out", "main-only.txt")).To(BeAnExistingFile())
+
+				result, err := parseGitCloneResult(stdout)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.MergedSha).ToNot(BeEmpty())
+			},
+		},
+		{
+			name: "clone with reference mirror dissociates checkout",
+			setup: func(t *testing.T, workspaceDir string) map[string]string {
+				repo := createLocalTestRepo(t)
+				bareCloneToPath(t, repo.Path, filepath.Join(workspaceDir, "repo.git"))
+				cacheDir := filepath.Join(workspaceDir, "cache")
+				Expect(os.MkdirAll(cacheDir, 0755)).To(Succeed())
+				// Allow the container user to create the mirror
+				Expect(os.Chmod(cacheDir, 0777)).To(Succeed())
+				return nil
+			},
+			url:  "file:///workspace/repo.git",
+			args: []string{"--depth", "0", "--submodules=false", "--reference-dir", "/workspace/cache"},
+			check: func(t *testing.T, workspaceDir, stdout, stderr string, _ map[string]string) {
+				outDir := filepath.Join(workspaceDir, "out")
+				Expect(filepath.Join(outDir, "README.md")).To(BeAnExistingFile())
+				Expect(filepath.Join(outDir, ".git", "objects", "info", "alternates")).ToNot(BeAnExistingFile())
+				runGit(t, outDir, "fsck", "--connectivity-only")
+
+				mirrors, err := filepath.Glob(filepath.Join(workspaceDir, "cache", "*.git"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mirrors).To(HaveLen(1))
+				Expect(runGit(t, mirrors[0], "rev-parse", "refs/heads/main")).To(Equal(runGit(t, outDir, "rev-parse", "HEAD")))
+				Expect(runGit(t, mirrors[0], "tag", "-l")).To(Equal("v1.0.0"))
+			},
+		},
 		{
 			name: "delete existing output before clone",
 			setup: func(t *testing.T, workspaceDir string) map[string]string {
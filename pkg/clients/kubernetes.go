@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 	"k8s.io/client-go/kubernetes"
@@ -13,6 +14,17 @@ import (
 
 var kubeLog = l.Logger.WithField("logger", "KubeClient")
 
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// CurrentNamespace returns the namespace of the pod the CLI runs in, or empty string if not running in a cluster.
+func CurrentNamespace() string {
+	data, err := os.ReadFile(serviceAccountNamespaceFile)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
 // NewKubeClientSet creates a new Kubernetes clientset
 func NewKubeClientSet() (*kubernetes.Clientset, error) {
 	var config *rest.Config
@@ -0,0 +1,635 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/manifest"
+	"github.com/containers/image/v5/pkg/blobinfocache/memory"
+	"github.com/containers/image/v5/types"
+	"github.com/docker/distribution/registry/api/errcode"
+	v2 "github.com/docker/distribution/registry/api/v2"
+	go_digest "github.com/opencontainers/go-digest"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var registryLog = l.Logger.WithField("logger", "OCIRegistryClient")
+
+const (
+	// emptyConfigContent is the content of the empty config descriptor used by artifacts,
+	// refer to https://github.com/opencontainers/image-spec/blob/main/manifest.md#guidance-for-an-empty-descriptor
+	emptyConfigContent = "{}"
+
+	// metadataTimeout limits an attempt of a registry operation which transfers no layer content,
+	// e.g. getting a manifest. Layer uploads are not limited, the transport of containers/image
+	// times out on dial and TLS handshake, so that uploads of large layers are not cut off.
+	metadataTimeout = 2 * time.Minute
+
+	// Exit codes reported to cliwrappers.Retryer, which stops retrying on permanent errors
+	retryableErrCode = 1
+	permanentErrCode = 2
+)
+
+// ErrManifestNotFound is returned by GetManifest if the registry has no manifest for the reference.
+var ErrManifestNotFound = errors.New("manifest not found")
+
+// ErrPlatformNotSelected is returned by GetManifest if the image is an image index and no platform is given.
+var ErrPlatformNotSelected = errors.New("image is an image index and no platform is selected")
+
+// OCIRegistryClientInterface pushes and pulls OCI artifacts to/from an image registry
+// without any external CLI tool.
+type OCIRegistryClientInterface interface {
+	PushArtifact(args *PushArtifactArgs) (string, error)
+	PushImage(args *PushImageArgs) (string, error)
+	GetManifest(args *GetManifestArgs) (*ociv1.Manifest, string, error)
+	GetBlob(args *GetBlobArgs) ([]byte, error)
+}
+
+var _ OCIRegistryClientInterface = &OCIRegistryClient{}
+
+// OCIRegistryClient talks to image registries with the docker transport of containers/image,
+// the library of skopeo and buildah. registries.conf (mirrors, insecure registries), certs.d
+// and proxy settings apply the same way as for these tools.
+// Registry operations are retried with the image registry preset of cliwrappers.Retryer.
+type OCIRegistryClient struct {
+	// TLSVerify set to false skips TLS verification and allows plain HTTP for all registries.
+	// Otherwise it is configured per registry by registries.conf.
+	TLSVerify bool
+}
+
+func NewOCIRegistryClient() *OCIRegistryClient {
+	return &OCIRegistryClient{TLSVerify: true}
+}
+
+// ArtifactLayer is a file pushed as a layer of an artifact.
+type ArtifactLayer struct {
+	Content   []byte
+	MediaType string
+	// Title is set as org.opencontainers.image.title annotation of the layer
+	Title string
+	// Annotations are additional annotations of the layer descriptor
+	Annotations map[string]string
+}
+
+type PushArtifactArgs struct {
+	// DestinationImage is the image reference with tag, e.g. quay.io/org/app:tag
+	DestinationImage string
+	ArtifactType     string
+	Layers           []ArtifactLayer
+	// Auth is the registry credential selected by common.SelectRegistryAuth.
+	// Anonymous access is used if not set.
+	Auth *common.RegistryAuth
+}
+
+// PushArtifact pushes the layers as an OCI artifact with an empty config and tags it.
+// Returns the digested reference of the pushed artifact, e.g. quay.io/org/app@sha256:...
+func (c *OCIRegistryClient) PushArtifact(args *PushArtifactArgs) (string, error) {
+	if args.DestinationImage == "" {
+		return "", fmt.Errorf("destination image arg is empty")
+	}
+	if len(args.Layers) == 0 {
+		return "", fmt.Errorf("no layers to push")
+	}
+
+	ref, err := parseDestinationImage(args.DestinationImage)
+	if err != nil {
+		return "", err
+	}
+	dest, err := c.newImageDestination(ref, args.Auth)
+	if err != nil {
+		return "", err
+	}
+	defer closeImage(dest)
+	cache := memory.New()
+
+	imageManifest := ociv1.Manifest{
+		MediaType:    ociv1.MediaTypeImageManifest,
+		ArtifactType: args.ArtifactType,
+		Config: ociv1.Descriptor{
+			MediaType: ociv1.MediaTypeEmptyJSON,
+			Digest:    go_digest.FromString(emptyConfigContent),
+			Size:      int64(len(emptyConfigContent)),
+		},
+	}
+	imageManifest.SchemaVersion = 2
+	if err := putBlob(dest, cache, imageManifest.Config, bytesContent([]byte(emptyConfigContent)), true); err != nil {
+		return "", fmt.Errorf("failed to push artifact config: %w", err)
+	}
+
+	for _, layer := range args.Layers {
+		descriptor := ociv1.Descriptor{
+			MediaType: layer.MediaType,
+			Digest:    go_digest.FromBytes(layer.Content),
+			Size:      int64(len(layer.Content)),
+		}
+		if layer.Title != "" || len(layer.Annotations) > 0 {
+			descriptor.Annotations = map[string]string{}
+			for key, value := range layer.Annotations {
+				descriptor.Annotations[key] = value
+			}
+			if layer.Title != "" {
+				descriptor.Annotations[ociv1.AnnotationTitle] = layer.Title
+			}
+		}
+		if err := putBlob(dest, cache, descriptor, bytesContent(layer.Content), false); err != nil {
+			return "", fmt.Errorf("failed to push layer %s: %w", layer.Title, err)
+		}
+		imageManifest.Layers = append(imageManifest.Layers, descriptor)
+	}
+
+	manifestDigest, err := putManifest(dest, imageManifest)
+	if err != nil {
+		return "", err
+	}
+
+	registryLog.Debugf("Artifact pushed to %s with digest %s", args.DestinationImage, manifestDigest)
+
+	return ref.Name() + "@" + manifestDigest.String(), nil
+}
+
+// ImageLayer is a layer of an image, uploaded from a file or copied from another image.
+// The content is streamed, it is never held in memory.
+type ImageLayer struct {
+	// Descriptor of the layer in the image manifest, with the digest and the size of the content
+	Descriptor ociv1.Descriptor
+	// Path is the file with the layer content
+	Path string
+	// SourceImage is the image the layer is streamed from if Path is not set, with tag or digest,
+	// e.g. quay.io/org/base@sha256:...
+	SourceImage string
+	// SourceAuth is the credential for SourceImage, anonymous access is used if not set
+	SourceAuth *common.RegistryAuth
+}
+
+type PushImageArgs struct {
+	// DestinationImage is the image reference with tag, e.g. quay.io/org/app:tag
+	DestinationImage string
+	// Config is the content of the image config, e.g. a marshalled ociv1.Image
+	Config []byte
+	Layers []ImageLayer
+	// Annotations are set on the image manifest
+	Annotations map[string]string
+	// Auth is the registry credential selected by common.SelectRegistryAuth.
+	// Anonymous access is used if not set.
+	Auth *common.RegistryAuth
+}
+
+// PushImage pushes the config and layers as an OCI image and tags it.
+// Returns the digested reference of the pushed image, e.g. quay.io/org/app@sha256:...
+func (c *OCIRegistryClient) PushImage(args *PushImageArgs) (string, error) {
+	if args.DestinationImage == "" {
+		return "", fmt.Errorf("destination image arg is empty")
+	}
+	if len(args.Config) == 0 {
+		return "", fmt.Errorf("image config is empty")
+	}
+
+	ref, err := parseDestinationImage(args.DestinationImage)
+	if err != nil {
+		return "", err
+	}
+	dest, err := c.newImageDestination(ref, args.Auth)
+	if err != nil {
+		return "", err
+	}
+	defer closeImage(dest)
+	cache := memory.New()
+	sources := &layerSources{client: c, cache: cache, sources: map[string]types.ImageSource{}}
+	defer sources.close()
+
+	imageManifest := ociv1.Manifest{
+		MediaType: ociv1.MediaTypeImageManifest,
+		Config: ociv1.Descriptor{
+			MediaType: ociv1.MediaTypeImageConfig,
+			Digest:    go_digest.FromBytes(args.Config),
+			Size:      int64(len(args.Config)),
+		},
+		Annotations: args.Annotations,
+	}
+	imageManifest.SchemaVersion = 2
+	if err := putBlob(dest, cache, imageManifest.Config, bytesContent(args.Config), true); err != nil {
+		return "", fmt.Errorf("failed to push image config: %w", err)
+	}
+
+	for _, layer := range args.Layers {
+		if err := pushImageLayer(dest, cache, sources, layer); err != nil {
+			return "", fmt.Errorf("failed to push layer %s: %w", layer.Descriptor.Digest, err)
+		}
+		imageManifest.Layers = append(imageManifest.Layers, layer.Descriptor)
+	}
+
+	manifestDigest, err := putManifest(dest, imageManifest)
+	if err != nil {
+		return "", err
+	}
+
+	registryLog.Debugf("Image pushed to %s with digest %s", args.DestinationImage, manifestDigest)
+
+	return ref.Name() + "@" + manifestDigest.String(), nil
+}
+
+// pushImageLayer uploads the layer file, or streams the layer blob from its source image.
+// Nothing is uploaded if the destination repository already has the blob.
+func pushImageLayer(dest types.ImageDestination, cache types.BlobInfoCache, sources *layerSources, layer ImageLayer) error {
+	if err := layer.Descriptor.Digest.Validate(); err != nil {
+		return fmt.Errorf("invalid layer digest: %w", err)
+	}
+	if layer.Path != "" {
+		return putBlob(dest, cache, layer.Descriptor, fileContent(layer.Path), false)
+	}
+	if layer.SourceImage == "" {
+		return fmt.Errorf("layer has neither path nor source image")
+	}
+	return putBlob(dest, cache, layer.Descriptor, sources.blobContent(layer.SourceImage, layer.SourceAuth, layer.Descriptor.Digest), false)
+}
+
+type GetManifestArgs struct {
+	// ImageRef is the image reference with tag or digest, e.g. quay.io/org/app:tag
+	ImageRef string
+	// Platform selects the manifest of an image index
+	Platform *ociv1.Platform
+	// Auth is the registry credential, anonymous access is used if not set
+	Auth *common.RegistryAuth
+}
+
+// GetManifest fetches the image manifest of the given image reference, an OCI or a Docker v2s2 manifest.
+// Image indexes and Docker manifest lists are resolved to the manifest of the requested platform.
+// Returns the manifest and the digested reference of the image manifest, e.g. quay.io/org/app@sha256:...
+// Returns an error wrapping ErrManifestNotFound if the image does not exist,
+// or ErrPlatformNotSelected if the image is an image index and no platform is given.
+func (c *OCIRegistryClient) GetManifest(args *GetManifestArgs) (*ociv1.Manifest, string, error) {
+	ref, err := parseImageReference(args.ImageRef)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var content []byte
+	var mediaType string
+	var digest go_digest.Digest
+	err = retry(metadataTimeout, func(ctx context.Context) error {
+		source, err := c.newImageSource(ctx, ref, args.Auth)
+		if err != nil {
+			return err
+		}
+		defer closeImage(source)
+		content, mediaType, err = source.GetManifest(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if digest, err = manifest.Digest(content); err != nil {
+			return err
+		}
+		if digested, ok := ref.(reference.Digested); ok && digested.Digest() != digest {
+			return fmt.Errorf("manifest digest %s does not match %s", digest, args.ImageRef)
+		}
+
+		if manifest.MIMETypeIsMultiImage(mediaType) {
+			if args.Platform == nil {
+				return ErrPlatformNotSelected
+			}
+			if digest, err = selectPlatformManifest(content, args.Platform); err != nil {
+				return err
+			}
+			if content, mediaType, err = source.GetManifest(ctx, &digest); err != nil {
+				return err
+			}
+			if go_digest.FromBytes(content) != digest {
+				return fmt.Errorf("manifest digest %s does not match the image index %s", digest, args.ImageRef)
+			}
+		}
+		return nil
+	})
+	if isManifestUnknownError(err) {
+		return nil, "", fmt.Errorf("failed to get manifest %s: %w: %s", args.ImageRef, ErrManifestNotFound, err.Error())
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get manifest %s: %w", args.ImageRef, err)
+	}
+
+	if mediaType != ociv1.MediaTypeImageManifest && mediaType != manifest.DockerV2Schema2MediaType {
+		return nil, "", fmt.Errorf("unsupported manifest media type '%s' of %s", mediaType, args.ImageRef)
+	}
+	imageManifest := &ociv1.Manifest{}
+	if err := json.Unmarshal(content, imageManifest); err != nil {
+		return nil, "", fmt.Errorf("failed to parse manifest of %s: %w", args.ImageRef, err)
+	}
+	imageManifest.MediaType = mediaType
+
+	return imageManifest, reference.TrimNamed(ref).Name() + "@" + digest.String(), nil
+}
+
+// selectPlatformManifest returns the digest of the manifest of the platform in the image index.
+// The variant is compared only if requested, e.g. v8 of arm64.
+func selectPlatformManifest(content []byte, platform *ociv1.Platform) (go_digest.Digest, error) {
+	index := ociv1.Index{}
+	if err := json.Unmarshal(content, &index); err != nil {
+		return "", fmt.Errorf("failed to parse image index: %w", err)
+	}
+	for _, descriptor := range index.Manifests {
+		if descriptor.Platform == nil {
+			continue
+		}
+		if descriptor.Platform.OS == platform.OS && descriptor.Platform.Architecture == platform.Architecture &&
+			(platform.Variant == "" || descriptor.Platform.Variant == platform.Variant) {
+			return descriptor.Digest, nil
+		}
+	}
+	name := platform.OS + "/" + platform.Architecture
+	if platform.Variant != "" {
+		name += "/" + platform.Variant
+	}
+	return "", fmt.Errorf("no manifest for platform %s", name)
+}
+
+type GetBlobArgs struct {
+	// ImageRef is the image the blob belongs to, with tag or digest,
+	// e.g. the digested reference returned by GetManifest
+	ImageRef string
+	Digest   go_digest.Digest
+	// Auth is the registry credential, anonymous access is used if not set
+	Auth *common.RegistryAuth
+}
+
+// GetBlob fetches the blob content and verifies its digest.
+func (c *OCIRegistryClient) GetBlob(args *GetBlobArgs) ([]byte, error) {
+	ref, err := parseImageReference(args.ImageRef)
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Digest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid blob digest '%s': %w", args.Digest, err)
+	}
+
+	var content []byte
+	err = retry(metadataTimeout, func(ctx context.Context) error {
+		source, err := c.newImageSource(ctx, ref, args.Auth)
+		if err != nil {
+			return err
+		}
+		defer closeImage(source)
+		body, _, err := source.GetBlob(ctx, types.BlobInfo{Digest: args.Digest, Size: -1}, memory.New())
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		content, err = io.ReadAll(body)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob %s: %w", args.Digest, err)
+	}
+	if go_digest.FromBytes(content) != args.Digest {
+		return nil, fmt.Errorf("content of blob %s does not match its digest", args.Digest)
+	}
+	return content, nil
+}
+
+// parseDestinationImage parses the reference of an image to push, which must have a tag.
+func parseDestinationImage(imageRef string) (reference.NamedTagged, error) {
+	ref, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination image '%s': %w", imageRef, err)
+	}
+	tagged, ok := ref.(reference.Tagged)
+	if !ok {
+		return nil, fmt.Errorf("destination image '%s' has no tag", imageRef)
+	}
+	return reference.WithTag(reference.TrimNamed(ref), tagged.Tag())
+}
+
+// parseImageReference parses the reference of an image to read, which must have a tag or a digest.
+// The tag of a reference with both is dropped, the docker transport does not accept such references.
+func parseImageReference(imageRef string) (reference.Named, error) {
+	ref, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image '%s': %w", imageRef, err)
+	}
+	if digested, ok := ref.(reference.Digested); ok {
+		return reference.WithDigest(reference.TrimNamed(ref), digested.Digest())
+	}
+	if _, ok := ref.(reference.Tagged); !ok {
+		return nil, fmt.Errorf("image '%s' has neither tag nor digest", imageRef)
+	}
+	return ref, nil
+}
+
+// systemContext returns the configuration of the docker transport with the credential.
+// Without a credential the registry is accessed anonymously, auth files are not searched.
+func (c *OCIRegistryClient) systemContext(auth *common.RegistryAuth) (*types.SystemContext, error) {
+	sys := &types.SystemContext{DockerAuthConfig: &types.DockerAuthConfig{}}
+	if !c.TLSVerify {
+		sys.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+	}
+	if auth != nil {
+		credential, err := base64.StdEncoding.DecodeString(auth.Token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry credential for %s: %w", auth.Registry, err)
+		}
+		username, password, found := strings.Cut(string(credential), ":")
+		if !found {
+			return nil, fmt.Errorf("invalid registry credential for %s: expected username:password", auth.Registry)
+		}
+		sys.DockerAuthConfig = &types.DockerAuthConfig{Username: username, Password: password}
+	}
+	return sys, nil
+}
+
+func (c *OCIRegistryClient) newImageDestination(ref reference.Named, auth *common.RegistryAuth) (types.ImageDestination, error) {
+	sys, err := c.systemContext(auth)
+	if err != nil {
+		return nil, err
+	}
+	imageRef, err := docker.NewReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination image '%s': %w", ref.String(), err)
+	}
+	dest, err := imageRef.NewImageDestination(context.Background(), sys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open destination image %s: %w", ref.String(), err)
+	}
+	return dest, nil
+}
+
+// newImageSource opens the image, the manifest is read from the registry or its mirror.
+func (c *OCIRegistryClient) newImageSource(ctx context.Context, ref reference.Named, auth *common.RegistryAuth) (types.ImageSource, error) {
+	sys, err := c.systemContext(auth)
+	if err != nil {
+		return nil, err
+	}
+	imageRef, err := docker.NewReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image '%s': %w", ref.String(), err)
+	}
+	return imageRef.NewImageSource(ctx, sys)
+}
+
+// blobContent opens the content of a blob to upload, it is opened again on every attempt.
+type blobContent func(ctx context.Context) (io.ReadCloser, error)
+
+func bytesContent(content []byte) blobContent {
+	return func(context.Context) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
+}
+
+func fileContent(path string) blobContent {
+	return func(context.Context) (io.ReadCloser, error) {
+		return os.Open(path)
+	}
+}
+
+// layerSources keeps the images layers are streamed from open for the whole push.
+type layerSources struct {
+	client  *OCIRegistryClient
+	cache   types.BlobInfoCache
+	sources map[string]types.ImageSource
+}
+
+// blobContent streams the blob from the image. The registry the blob is uploaded to verifies its digest.
+func (s *layerSources) blobContent(imageRef string, auth *common.RegistryAuth, digest go_digest.Digest) blobContent {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		source, found := s.sources[imageRef]
+		if !found {
+			ref, err := parseImageReference(imageRef)
+			if err != nil {
+				return nil, err
+			}
+			if source, err = s.client.newImageSource(ctx, ref, auth); err != nil {
+				return nil, err
+			}
+			s.sources[imageRef] = source
+		}
+		body, _, err := source.GetBlob(ctx, types.BlobInfo{Digest: digest, Size: -1}, s.cache)
+		return body, err
+	}
+}
+
+func (s *layerSources) close() {
+	for _, source := range s.sources {
+		closeImage(source)
+	}
+}
+
+// putBlob uploads the blob unless the registry already has it.
+// The upload of a config has the metadata timeout, layer uploads are not limited.
+func putBlob(dest types.ImageDestination, cache types.BlobInfoCache, descriptor ociv1.Descriptor, content blobContent, isConfig bool) error {
+	timeout := time.Duration(0)
+	if isConfig {
+		timeout = metadataTimeout
+	}
+	return retry(timeout, func(ctx context.Context) error {
+		body, err := content(ctx)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		_, err = dest.PutBlob(ctx, body, blobInfo(descriptor), cache, isConfig)
+		return err
+	})
+}
+
+// putManifest uploads the OCI image manifest with the tag of the destination. Returns the manifest digest.
+func putManifest(dest types.ImageDestination, imageManifest ociv1.Manifest) (go_digest.Digest, error) {
+	content, err := json.Marshal(imageManifest)
+	if err != nil {
+		return "", fmt.Errorf("failed to create manifest: %w", err)
+	}
+	err = retry(metadataTimeout, func(ctx context.Context) error {
+		if err := dest.PutManifest(ctx, content, nil); err != nil {
+			return err
+		}
+		return dest.Commit(ctx, nil)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to push manifest: %w", err)
+	}
+	return manifest.Digest(content)
+}
+
+func blobInfo(descriptor ociv1.Descriptor) types.BlobInfo {
+	return types.BlobInfo{
+		Digest:    descriptor.Digest,
+		Size:      descriptor.Size,
+		MediaType: descriptor.MediaType,
+	}
+}
+
+// retry runs the registry operation until it succeeds or fails on an error which repeating cannot fix,
+// e.g. a rejected credential, with the image registry preset of cliwrappers.Retryer.
+// Every attempt is given the timeout if it is positive.
+func retry(timeout time.Duration, operation func(ctx context.Context) error) error {
+	retryer := cliwrappers.NewRetryer(func() (string, string, int, error) {
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		err := operation(ctx)
+		if err == nil {
+			return "", "", 0, nil
+		}
+		if !isRetryableError(err) {
+			return "", err.Error(), permanentErrCode, err
+		}
+		return "", err.Error(), retryableErrCode, err
+	}).WithImageRegistryPreset().StopOnExitCode(permanentErrCode)
+
+	_, _, _, err := retryer.Run()
+	return err
+}
+
+// isRetryableError reports whether the registry operation may succeed if repeated,
+// i.e. it failed on a network error, or the registry is overloaded or responded with a server error.
+func isRetryableError(err error) bool {
+	if errors.Is(err, docker.ErrTooManyRequests) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var statusErr docker.UnexpectedHTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	var errorCoder errcode.ErrorCoder
+	if errors.As(err, &errorCoder) {
+		return errorCoder.ErrorCode() == errcode.ErrorCodeTooManyRequests || errorCoder.ErrorCode() == errcode.ErrorCodeUnavailable
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// isManifestUnknownError reports whether the registry has no such manifest or repository.
+func isManifestUnknownError(err error) bool {
+	var errorCoder errcode.ErrorCoder
+	if errors.As(err, &errorCoder) &&
+		(errorCoder.ErrorCode() == v2.ErrorCodeManifestUnknown || errorCoder.ErrorCode() == v2.ErrorCodeNameUnknown) {
+		return true
+	}
+	// Some registries report a missing manifest as an unknown error, e.g. registry.redhat.io
+	var registryErr errcode.Error
+	return errors.As(err, &registryErr) && registryErr.ErrorCode() == errcode.ErrorCodeUnknown &&
+		strings.Contains(strings.ToLower(registryErr.Message), "not found")
+}
+
+// closeImage closes an image source or destination.
+func closeImage(image io.Closer) {
+	if err := image.Close(); err != nil {
+		registryLog.Debugf("failed to close image: %s", err.Error())
+	}
+}
@@ -0,0 +1,542 @@
+package clients
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/containers/image/v5/manifest"
+	. "github.com/onsi/gomega"
+	go_digest "github.com/opencontainers/go-digest"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+)
+
+// fakeRegistry is a minimal OCI distribution registry with token authentication.
+type fakeRegistry struct {
+	mu        sync.Mutex
+	server    *httptest.Server
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	// requests records method and path of the registry requests, except the ping of /v2/
+	requests []string
+	// basicToken is the credential expected by the token endpoint
+	basicToken string
+	// bearerAuth switches between bearer token and basic authentication
+	bearerAuth bool
+	// scopes records the scopes of the token requests
+	scopes [][]string
+	// upload is the content of the upload session received so far
+	upload []byte
+	// failures are the numbers of requests, by method and path, answered with a server error
+	failures map[string]int
+}
+
+const fakeBearerToken = "registry-token"
+
+func newFakeRegistry(t *testing.T, bearerAuth bool) *fakeRegistry {
+	r := &fakeRegistry{
+		blobs:      map[string][]byte{},
+		manifests:  map[string][]byte{},
+		basicToken: "dXNlcjpwYXNz",
+		bearerAuth: bearerAuth,
+		failures:   map[string]int{},
+	}
+	r.server = httptest.NewTLSServer(http.HandlerFunc(r.handle))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *fakeRegistry) host() string {
+	return strings.TrimPrefix(r.server.URL, "https://")
+}
+
+// client returns a client trusting the self-signed certificate of the registry.
+func (r *fakeRegistry) client() *OCIRegistryClient {
+	return &OCIRegistryClient{TLSVerify: false}
+}
+
+func (r *fakeRegistry) handle(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Path == "/token" {
+		if req.Header.Get("Authorization") != "Basic "+r.basicToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		scopes := req.URL.Query()["scope"]
+		r.scopes = append(r.scopes, scopes)
+		for _, scope := range scopes {
+			if !slices.Contains([]string{"repository:org/app:pull,push", "repository:org/app:pull"}, scope) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		if req.URL.Query().Get("service") != "fake" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token":"` + fakeBearerToken + `"}`))
+		return
+	}
+
+	if req.URL.Path != "/v2/" {
+		r.requests = append(r.requests, req.Method+" "+req.URL.Path)
+	}
+
+	expectedAuthorization := "Basic " + r.basicToken
+	if r.bearerAuth {
+		expectedAuthorization = "Bearer " + fakeBearerToken
+	}
+	if req.Header.Get("Authorization") != expectedAuthorization {
+		if r.bearerAuth {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+r.server.URL+`/token",service="fake"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", `Basic realm="fake"`)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if req.URL.Path == "/v2/" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.failures[req.Method+" "+req.URL.Path] > 0 {
+		r.failures[req.Method+" "+req.URL.Path]--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	path, found := strings.CutPrefix(req.URL.Path, "/v2/org/app/")
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	uploadLocation := "/v2/org/app/blobs/uploads/session-1?state=abc"
+
+	switch {
+	case req.Method == http.MethodHead && strings.HasPrefix(path, "blobs/"):
+		if content, found := r.blobs[strings.TrimPrefix(path, "blobs/")]; found {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case req.Method == http.MethodPost && path == "blobs/uploads/":
+		r.upload = nil
+		w.Header().Set("Location", uploadLocation)
+		w.WriteHeader(http.StatusAccepted)
+	case req.Method == http.MethodPatch && path == "blobs/uploads/session-1":
+		content, _ := io.ReadAll(req.Body)
+		r.upload = append(r.upload, content...)
+		w.Header().Set("Location", uploadLocation)
+		w.WriteHeader(http.StatusAccepted)
+	case req.Method == http.MethodPut && path == "blobs/uploads/session-1":
+		if req.URL.Query().Get("state") != "abc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(req.Body)
+		content = append(r.upload, content...)
+		digest := req.URL.Query().Get("digest")
+		if go_digest.FromBytes(content).String() != digest {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.blobs[digest] = content
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == http.MethodGet && strings.HasPrefix(path, "blobs/"):
+		content, found := r.blobs[strings.TrimPrefix(path, "blobs/")]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	case req.Method == http.MethodGet && strings.HasPrefix(path, "manifests/"):
+		reference := strings.TrimPrefix(path, "manifests/")
+		content, found := r.manifests[reference]
+		if !found {
+			for _, manifest := range r.manifests {
+				if go_digest.FromBytes(manifest).String() == reference {
+					content, found = manifest, true
+				}
+			}
+		}
+		if !found || !strings.Contains(req.Header.Get("Accept"), ociv1.MediaTypeImageManifest) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`))
+			return
+		}
+		var fields struct {
+			MediaType string `json:"mediaType"`
+		}
+		_ = json.Unmarshal(content, &fields)
+		w.Header().Set("Content-Type", fields.MediaType)
+		_, _ = w.Write(content)
+	case req.Method == http.MethodPut && strings.HasPrefix(path, "manifests/"):
+		content, _ := io.ReadAll(req.Body)
+		r.manifests[strings.TrimPrefix(path, "manifests/")] = content
+		w.Header().Set("Docker-Content-Digest", go_digest.FromBytes(content).String())
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// countRequests returns how many requests had the method and path.
+func (r *fakeRegistry) countRequests(request string) int {
+	count := 0
+	for _, recorded := range r.requests {
+		if recorded == request {
+			count++
+		}
+	}
+	return count
+}
+
+func TestOCIRegistryClient_PushArtifact(t *testing.T) {
+	g := NewWithT(t)
+
+	layers := []ArtifactLayer{
+		{Content: []byte("FROM fedora"), MediaType: "application/vnd.oci.image.layer.v1.tar", Title: "Containerfile"},
+		{Content: []byte("VERSION=1"), MediaType: "application/vnd.konflux.build-args", Title: "build-args"},
+	}
+
+	for _, bearerAuth := range []bool{true, false} {
+		name := "basic"
+		if bearerAuth {
+			name = "bearer"
+		}
+		t.Run("should push artifact with "+name+" authentication", func(t *testing.T) {
+			registry := newFakeRegistry(t, bearerAuth)
+
+			imageRef, err := registry.client().PushArtifact(&PushArtifactArgs{
+				DestinationImage: registry.host() + "/org/app:sha256-1234.containerfile",
+				ArtifactType:     "application/vnd.konflux.containerfile",
+				Layers:           layers,
+				Auth:             &common.RegistryAuth{Registry: registry.host(), Token: registry.basicToken},
+			})
+			g.Expect(err).ToNot(HaveOccurred())
+
+			manifestContent, found := registry.manifests["sha256-1234.containerfile"]
+			g.Expect(found).To(BeTrue())
+			g.Expect(imageRef).To(Equal(registry.host() + "/org/app@" + go_digest.FromBytes(manifestContent).String()))
+
+			var manifest ociv1.Manifest
+			g.Expect(json.Unmarshal(manifestContent, &manifest)).To(Succeed())
+			g.Expect(manifest.SchemaVersion).To(Equal(2))
+			g.Expect(manifest.MediaType).To(Equal(ociv1.MediaTypeImageManifest))
+			g.Expect(manifest.ArtifactType).To(Equal("application/vnd.konflux.containerfile"))
+			g.Expect(manifest.Config.MediaType).To(Equal(ociv1.MediaTypeEmptyJSON))
+			g.Expect(registry.blobs[manifest.Config.Digest.String()]).To(Equal([]byte("{}")))
+			g.Expect(manifest.Layers).To(HaveLen(2))
+			for i, layer := range layers {
+				g.Expect(manifest.Layers[i].MediaType).To(Equal(layer.MediaType))
+				g.Expect(manifest.Layers[i].Annotations).To(Equal(map[string]string{ociv1.AnnotationTitle: layer.Title}))
+				g.Expect(manifest.Layers[i].Size).To(Equal(int64(len(layer.Content))))
+				g.Expect(registry.blobs[manifest.Layers[i].Digest.String()]).To(Equal(layer.Content))
+			}
+		})
+	}
+
+	t.Run("should not upload existing blobs", func(t *testing.T) {
+		registry := newFakeRegistry(t, true)
+		registry.blobs[go_digest.FromString("{}").String()] = []byte("{}")
+
+		_, err := registry.client().PushArtifact(&PushArtifactArgs{
+			DestinationImage: registry.host() + "/org/app:tag",
+			Layers:           layers[:1],
+			Auth:             &common.RegistryAuth{Registry: registry.host(), Token: registry.basicToken},
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		// Only the layer is uploaded
+		g.Expect(registry.countRequests("POST /v2/org/app/blobs/uploads/")).To(Equal(1))
+		g.Expect(registry.blobs[go_digest.FromBytes(layers[0].Content).String()]).To(Equal(layers[0].Content))
+	})
+
+	t.Run("should retry on server errors", func(t *testing.T) {
+		registry := newFakeRegistry(t, true)
+		registry.failures["PUT /v2/org/app/manifests/tag"] = 1
+
+		_, err := registry.client().PushArtifact(&PushArtifactArgs{
+			DestinationImage: registry.host() + "/org/app:tag",
+			Layers:           layers,
+			Auth:             &common.RegistryAuth{Registry: registry.host(), Token: registry.basicToken},
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registry.countRequests("PUT /v2/org/app/manifests/tag")).To(Equal(2))
+		g.Expect(registry.manifests).To(HaveKey("tag"))
+	})
+
+	t.Run("should verify TLS of the registry", func(t *testing.T) {
+		registry := newFakeRegistry(t, true)
+
+		_, err := NewOCIRegistryClient().PushArtifact(&PushArtifactArgs{
+			DestinationImage: registry.host() + "/org/app:tag",
+			Layers:           layers,
+			Auth:             &common.RegistryAuth{Registry: registry.host(), Token: registry.basicToken},
+		})
+		g.Expect(err).To(MatchError(ContainSubstring("certificate")))
+		g.Expect(registry.requests).To(BeEmpty())
+	})
+
+	t.Run("should fail if credential is rejected", func(t *testing.T) {
+		registry := newFakeRegistry(t, true)
+
+		_, err := registry.client().PushArtifact(&PushArtifactArgs{
+			DestinationImage: registry.host() + "/org/app:tag",
+			Layers:           layers,
+			Auth:             &common.RegistryAuth{Registry: registry.host(), Token: "d3Jvbmc6Y3JlZHM="},
+		})
+		g.Expect(err).To(MatchError(ContainSubstring("invalid username/password")))
+		g.Expect(registry.manifests).To(BeEmpty())
+	})
+
+	t.Run("should fail if basic authentication is required but no credential is given", func(t *testing.T) {
+		registry := newFakeRegistry(t, false)
+
+		_, err := registry.client().PushArtifact(&PushArtifactArgs{
+			DestinationImage: registry.host() + "/org/app:tag",
+			Layers:           layers,
+		})
+		g.Expect(err).To(MatchError(ContainSubstring("authentication required")))
+	})
+
+	t.Run("should fail on invalid arguments", func(t *testing.T) {
+		client := NewOCIRegistryClient()
+
+		_, err := client.PushArtifact(&PushArtifactArgs{Layers: layers})
+		g.Expect(err).To(MatchError(ContainSubstring("destination image arg is empty")))
+
+		_, err = client.PushArtifact(&PushArtifactArgs{DestinationImage: "quay.io/org/app:tag"})
+		g.Expect(err).To(MatchError(ContainSubstring("no layers to push")))
+
+		_, err = client.PushArtifact(&PushArtifactArgs{DestinationImage: "quay.io/org/app", Layers: layers})
+		g.Expect(err).To(MatchError(ContainSubstring("has no tag")))
+	})
+}
+
+func TestOCIRegistryClient_PushImage(t *testing.T) {
+	g := NewWithT(t)
+
+	config := []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`)
+	layerDir := t.TempDir()
+	fileLayer := func(name, content string, annotations map[string]string) ImageLayer {
+		path := filepath.Join(layerDir, name)
+		g.Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return ImageLayer{
+			Descriptor: ociv1.Descriptor{
+				MediaType:   ociv1.MediaTypeImageLayerGzip,
+				Digest:      go_digest.FromString(content),
+				Size:        int64(len(content)),
+				Annotations: annotations,
+			},
+			Path: path,
+		}
+	}
+	layers := []ImageLayer{
+		fileLayer("src", "source tarball", map[string]string{
+			ociv1.AnnotationTitle:        "src",
+			"dev.konflux-ci.source.type": "application",
+		}),
+		fileLayer("deps", "deps tarball", nil),
+	}
+
+	t.Run("should push image with config, layer and manifest annotations", func(t *testing.T) {
+		registry := newFakeRegistry(t, true)
+
+		imageRef, err := registry.client().PushImage(&PushImageArgs{
+			DestinationImage: registry.host() + "/org/app:v1-source",
+			Config:           config,
+			Layers:           layers,
+			Annotations:      map[string]string{ociv1.AnnotationRevision: "abc123"},
+			Auth:             &common.RegistryAuth{Registry: registry.host(), Token: registry.basicToken},
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		manifestContent, found := registry.manifests["v1-source"]
+		g.Expect(found).To(BeTrue())
+		g.Expect(imageRef).To(Equal(registry.host() + "/org/app@" + go_digest.FromBytes(manifestContent).String()))
+
+		var manifest ociv1.Manifest
+		g.Expect(json.Unmarshal(manifestContent, &manifest)).To(Succeed())
+		g.Expect(manifest.MediaType).To(Equal(ociv1.MediaTypeImageManifest))
+		g.Expect(manifest.ArtifactType).To(BeEmpty())
+		g.Expect(manifest.Annotations).To(Equal(map[string]string{ociv1.AnnotationRevision: "abc123"}))
+		g.Expect(manifest.Config.MediaType).To(Equal(ociv1.MediaTypeImageConfig))
+		g.Expect(registry.blobs[manifest.Config.Digest.String()]).To(Equal(config))
+		g.Expect(manifest.Layers).To(Equal([]ociv1.Descriptor{layers[0].Descriptor, layers[1].Descriptor}))
+		g.Expect(registry.blobs[manifest.Layers[0].Digest.String()]).To(Equal([]byte("source tarball")))
+		g.Expect(registry.blobs[manifest.Layers[1].Digest.String()]).To(Equal([]byte("deps tarball")))
+	})
+
+	t.Run("should stream layers of a source image in another registry", func(t *testing.T) {
+		registry := newFakeRegistry(t, true)
+		sourceRegistry := newFakeRegistry(t, false)
+		content := []byte("rpm sources")
+		digest := go_digest.FromBytes(content)
+		sourceRegistry.blobs[digest.String()] = content
+		sourceRegistry.manifests["v1"] = []byte(`{"schemaVersion":2,"mediaType":"` + ociv1.MediaTypeImageManifest + `"}`)
+
+		_, err := registry.client().PushImage(&PushImageArgs{
+			DestinationImage: registry.host() + "/org/app:v1-source",
+			Config:           config,
+			Layers: []ImageLayer{{
+				Descriptor:  ociv1.Descriptor{MediaType: ociv1.MediaTypeImageLayerGzip, Digest: digest, Size: int64(len(content))},
+				SourceImage: sourceRegistry.host() + "/org/app:v1",
+				SourceAuth:  &common.RegistryAuth{Registry: sourceRegistry.host(), Token: sourceRegistry.basicToken},
+			}},
+			Auth: &common.RegistryAuth{Registry: registry.host(), Token: registry.basicToken},
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registry.blobs[digest.String()]).To(Equal(content))
+		g.Expect(sourceRegistry.requests).To(ContainElement("GET /v2/org/app/blobs/" + digest.String()))
+		for _, scopes := range registry.scopes {
+			g.Expect(scopes).To(Equal([]string{"repository:org/app:pull,push"}))
+		}
+	})
+
+	t.Run("should fail if the layer file does not exist", func(t *testing.T) {
+		registry := newFakeRegistry(t, true)
+		missing := layers[1]
+		missing.Path = filepath.Join(layerDir, "missing")
+
+		_, err := registry.client().PushImage(&PushImageArgs{
+			DestinationImage: registry.host() + "/org/app:v1-source",
+			Config:           config,
+			Layers:           []ImageLayer{missing},
+			Auth:             &common.RegistryAuth{Registry: registry.host(), Token: registry.basicToken},
+		})
+		g.Expect(err).To(MatchError(ContainSubstring("failed to push layer " + missing.Descriptor.Digest.String())))
+		g.Expect(registry.manifests).To(BeEmpty())
+	})
+
+	t.Run("should fail on invalid arguments", func(t *testing.T) {
+		client := NewOCIRegistryClient()
+
+		_, err := client.PushImage(&PushImageArgs{Config: config})
+		g.Expect(err).To(MatchError(ContainSubstring("destination image arg is empty")))
+
+		_, err = client.PushImage(&PushImageArgs{DestinationImage: "quay.io/org/app:tag"})
+		g.Expect(err).To(MatchError(ContainSubstring("image config is empty")))
+
+		_, err = client.PushImage(&PushImageArgs{DestinationImage: "quay.io/org/app", Config: config})
+		g.Expect(err).To(MatchError(ContainSubstring("has no tag")))
+	})
+}
+
+func TestOCIRegistryClient_GetManifestAndBlob(t *testing.T) {
+	g := NewWithT(t)
+
+	registry := newFakeRegistry(t, true)
+	auth := &common.RegistryAuth{Registry: registry.host(), Token: registry.basicToken}
+	client := registry.client()
+
+	layerContent := []byte("FROM fedora")
+	pushedRef, err := client.PushArtifact(&PushArtifactArgs{
+		DestinationImage: registry.host() + "/org/app:tag",
+		Layers:           []ArtifactLayer{{Content: layerContent, MediaType: "text/plain", Title: "Containerfile"}},
+		Auth:             auth,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	t.Run("should get manifest by tag", func(t *testing.T) {
+		imageManifest, imageRef, err := client.GetManifest(&GetManifestArgs{ImageRef: registry.host() + "/org/app:tag", Auth: auth})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(imageRef).To(Equal(pushedRef))
+		g.Expect(imageManifest.Layers).To(HaveLen(1))
+		g.Expect(imageManifest.Layers[0].Annotations[ociv1.AnnotationTitle]).To(Equal("Containerfile"))
+	})
+
+	t.Run("should get manifest by digest", func(t *testing.T) {
+		_, imageRef, err := client.GetManifest(&GetManifestArgs{ImageRef: pushedRef, Auth: auth})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(imageRef).To(Equal(pushedRef))
+	})
+
+	t.Run("should fail if manifest does not exist", func(t *testing.T) {
+		_, _, err := client.GetManifest(&GetManifestArgs{ImageRef: registry.host() + "/org/app:missing", Auth: auth})
+		g.Expect(err).To(MatchError(ErrManifestNotFound))
+		g.Expect(err).To(MatchError(ContainSubstring("failed to get manifest " + registry.host() + "/org/app:missing")))
+	})
+
+	dockerManifest := []byte(`{"schemaVersion":2,"mediaType":"` + manifest.DockerV2Schema2MediaType + `",` +
+		`"config":{"mediaType":"application/vnd.docker.container.image.v1+json","digest":"` + go_digest.FromString("{}").String() + `","size":2},` +
+		`"layers":[{"mediaType":"application/vnd.docker.image.rootfs.diff.tar.gzip","digest":"` + go_digest.FromBytes(layerContent).String() + `","size":11}]}`)
+	dockerManifestRef := registry.host() + "/org/app@" + go_digest.FromBytes(dockerManifest).String()
+	registry.manifests["docker"] = dockerManifest
+	registry.manifests["list"] = []byte(`{"schemaVersion":2,"mediaType":"` + manifest.DockerV2ListMediaType + `","manifests":[` +
+		`{"mediaType":"` + ociv1.MediaTypeImageManifest + `","digest":"` + strings.TrimPrefix(pushedRef, registry.host()+"/org/app@") + `","size":1,"platform":{"architecture":"amd64","os":"linux"}},` +
+		`{"mediaType":"` + manifest.DockerV2Schema2MediaType + `","digest":"` + go_digest.FromBytes(dockerManifest).String() + `","size":1,"platform":{"architecture":"arm64","os":"linux","variant":"v8"}}]}`)
+
+	t.Run("should get Docker v2s2 manifest", func(t *testing.T) {
+		imageManifest, imageRef, err := client.GetManifest(&GetManifestArgs{ImageRef: registry.host() + "/org/app:docker", Auth: auth})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(imageRef).To(Equal(dockerManifestRef))
+		g.Expect(imageManifest.MediaType).To(Equal(manifest.DockerV2Schema2MediaType))
+		g.Expect(imageManifest.Layers).To(HaveLen(1))
+		g.Expect(imageManifest.Layers[0].Digest).To(Equal(go_digest.FromBytes(layerContent)))
+	})
+
+	t.Run("should resolve manifest list to the manifest of the platform", func(t *testing.T) {
+		imageManifest, imageRef, err := client.GetManifest(&GetManifestArgs{
+			ImageRef: registry.host() + "/org/app:list",
+			Platform: &ociv1.Platform{OS: "linux", Architecture: "arm64"},
+			Auth:     auth,
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(imageRef).To(Equal(dockerManifestRef))
+		g.Expect(imageManifest.MediaType).To(Equal(manifest.DockerV2Schema2MediaType))
+
+		_, imageRef, err = client.GetManifest(&GetManifestArgs{
+			ImageRef: registry.host() + "/org/app:list",
+			Platform: &ociv1.Platform{OS: "linux", Architecture: "amd64"},
+			Auth:     auth,
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(imageRef).To(Equal(pushedRef))
+	})
+
+	t.Run("should fail if manifest list is resolved without platform", func(t *testing.T) {
+		_, _, err := client.GetManifest(&GetManifestArgs{ImageRef: registry.host() + "/org/app:list", Auth: auth})
+		g.Expect(err).To(MatchError(ErrPlatformNotSelected))
+	})
+
+	t.Run("should fail if manifest list has no manifest of the platform", func(t *testing.T) {
+		_, _, err := client.GetManifest(&GetManifestArgs{
+			ImageRef: registry.host() + "/org/app:list",
+			Platform: &ociv1.Platform{OS: "linux", Architecture: "arm64", Variant: "v7"},
+			Auth:     auth,
+		})
+		g.Expect(err).To(MatchError(ContainSubstring("no manifest for platform linux/arm64/v7")))
+	})
+
+	t.Run("should get blob", func(t *testing.T) {
+		content, err := client.GetBlob(&GetBlobArgs{
+			ImageRef: registry.host() + "/org/app:tag",
+			Digest:   go_digest.FromBytes(layerContent),
+			Auth:     auth,
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(content).To(Equal(layerContent))
+	})
+
+	t.Run("should fail if blob content does not match digest", func(t *testing.T) {
+		digest := go_digest.FromString("other content")
+		registry.blobs[digest.String()] = []byte("tampered content")
+
+		_, err := client.GetBlob(&GetBlobArgs{ImageRef: registry.host() + "/org/app:tag", Digest: digest, Auth: auth})
+		g.Expect(err).To(MatchError(ContainSubstring("does not match its digest")))
+	})
+}
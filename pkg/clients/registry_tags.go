@@ -0,0 +1,101 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var tagsLog = l.Logger.WithField("logger", "RegistryTagsClient")
+
+// Exit codes reported to cliwrappers.Retryer by DeleteTag
+const (
+	deleteTagRetryableErrCode = 1
+	deleteTagPermanentErrCode = 2
+)
+
+// ErrTagNotFound is returned by DeleteTag if the repository has no such tag.
+var ErrTagNotFound = errors.New("tag not found")
+
+// RegistryTagsClientInterface manages tags of image repositories.
+type RegistryTagsClientInterface interface {
+	DeleteTag(args *DeleteTagArgs) error
+}
+
+var _ RegistryTagsClientInterface = &RegistryTagsClient{}
+
+// RegistryTagsClient manages tags with the OCI distribution API, using go-containerregistry.
+type RegistryTagsClient struct{}
+
+func NewRegistryTagsClient() *RegistryTagsClient {
+	return &RegistryTagsClient{}
+}
+
+type DeleteTagArgs struct {
+	// ImageRef is the image reference with the tag to delete, e.g. quay.io/org/app:tag
+	ImageRef string
+	// Auth is the registry credential selected by common.SelectRegistryAuth.
+	// Anonymous access is used if not set.
+	Auth *common.RegistryAuth
+}
+
+// DeleteTag deletes the tag by DELETE /v2/<name>/manifests/<tag>. Unlike deleting the manifest,
+// the manifest the tag points to and its other tags are kept. The registry must support deleting tags.
+// Failed requests are retried unless the registry rejected the request.
+// Returns an error wrapping ErrTagNotFound if the tag does not exist.
+func (c *RegistryTagsClient) DeleteTag(args *DeleteTagArgs) error {
+	tag, err := name.NewTag(args.ImageRef)
+	if err != nil {
+		return fmt.Errorf("invalid image '%s': %w", args.ImageRef, err)
+	}
+	authenticator := authn.Anonymous
+	if args.Auth != nil {
+		authenticator = authn.FromConfig(authn.AuthConfig{Auth: args.Auth.Token})
+	}
+
+	retryer := cliwrappers.NewRetryer(func() (string, string, int, error) {
+		// Retries are done by the Retryer
+		err := remote.Delete(tag, remote.WithAuth(authenticator), remote.WithRetryPredicate(func(error) bool { return false }))
+		if err == nil {
+			return "", "", 0, nil
+		}
+		var transportErr *transport.Error
+		if errors.As(err, &transportErr) && !transportErr.Temporary() {
+			return "", err.Error(), deleteTagPermanentErrCode, err
+		}
+		return "", err.Error(), deleteTagRetryableErrCode, err
+	}).StopOnExitCode(deleteTagPermanentErrCode)
+
+	if _, _, _, err := retryer.Run(); err != nil {
+		if isTagUnknownError(err) {
+			return fmt.Errorf("failed to delete tag %s: %w: %s", args.ImageRef, ErrTagNotFound, err.Error())
+		}
+		return fmt.Errorf("failed to delete tag %s: %w", args.ImageRef, err)
+	}
+
+	tagsLog.Debugf("Tag %s deleted", args.ImageRef)
+	return nil
+}
+
+// isTagUnknownError reports whether the registry has no such tag.
+func isTagUnknownError(err error) bool {
+	var transportErr *transport.Error
+	if !errors.As(err, &transportErr) {
+		return false
+	}
+	for _, diagnostic := range transportErr.Errors {
+		if diagnostic.Code == transport.ManifestUnknownErrorCode || diagnostic.Code == transport.NameUnknownErrorCode {
+			return true
+		}
+	}
+	return len(transportErr.Errors) == 0 && transportErr.StatusCode == http.StatusNotFound
+}
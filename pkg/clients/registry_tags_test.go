@@ -0,0 +1,114 @@
+package clients
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+)
+
+// fakeTagsRegistry is a registry with basic authentication which deletes tags.
+type fakeTagsRegistry struct {
+	mu     sync.Mutex
+	server *httptest.Server
+	tags   map[string]bool
+	// deletes records the paths of the delete requests
+	deletes []string
+	// failures is the number of delete requests answered with a server error
+	failures int
+}
+
+func newFakeTagsRegistry(t *testing.T, tags ...string) *fakeTagsRegistry {
+	r := &fakeTagsRegistry{tags: map[string]bool{}}
+	for _, tag := range tags {
+		r.tags[tag] = true
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.handle))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *fakeTagsRegistry) host() string {
+	return strings.TrimPrefix(r.server.URL, "http://")
+}
+
+func (r *fakeTagsRegistry) handle(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, password, ok := req.BasicAuth(); !ok || user != "user" || password != "pass" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="fake"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if req.URL.Path == "/v2/" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	tag, found := strings.CutPrefix(req.URL.Path, "/v2/org/app/manifests/")
+	if req.Method != http.MethodDelete || !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	r.deletes = append(r.deletes, req.URL.Path)
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if !r.tags[tag] {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`))
+		return
+	}
+	delete(r.tags, tag)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func TestRegistryTagsClient_DeleteTag(t *testing.T) {
+	g := NewWithT(t)
+
+	auth := &common.RegistryAuth{Token: "dXNlcjpwYXNz"}
+
+	t.Run("should delete the tag", func(t *testing.T) {
+		registry := newFakeTagsRegistry(t, "pr-1", "pr-1-rebuild")
+
+		err := NewRegistryTagsClient().DeleteTag(&DeleteTagArgs{ImageRef: registry.host() + "/org/app:pr-1", Auth: auth})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registry.deletes).To(Equal([]string{"/v2/org/app/manifests/pr-1"}))
+		g.Expect(registry.tags).To(Equal(map[string]bool{"pr-1-rebuild": true}))
+	})
+
+	t.Run("should retry on server errors", func(t *testing.T) {
+		registry := newFakeTagsRegistry(t, "pr-1")
+		registry.failures = 1
+
+		err := NewRegistryTagsClient().DeleteTag(&DeleteTagArgs{ImageRef: registry.host() + "/org/app:pr-1", Auth: auth})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registry.deletes).To(HaveLen(2))
+		g.Expect(registry.tags).To(BeEmpty())
+	})
+
+	t.Run("should return ErrTagNotFound if the tag does not exist", func(t *testing.T) {
+		registry := newFakeTagsRegistry(t)
+
+		err := NewRegistryTagsClient().DeleteTag(&DeleteTagArgs{ImageRef: registry.host() + "/org/app:pr-1", Auth: auth})
+		g.Expect(err).To(MatchError(ErrTagNotFound))
+		g.Expect(registry.deletes).To(HaveLen(1))
+	})
+
+	t.Run("should not retry if the credential is rejected", func(t *testing.T) {
+		registry := newFakeTagsRegistry(t, "pr-1")
+
+		err := NewRegistryTagsClient().DeleteTag(&DeleteTagArgs{ImageRef: registry.host() + "/org/app:pr-1"})
+		g.Expect(err).To(MatchError(ContainSubstring("failed to delete tag " + registry.host() + "/org/app:pr-1")))
+		g.Expect(registry.deletes).To(BeEmpty())
+		g.Expect(registry.tags).To(HaveKey("pr-1"))
+	})
+}
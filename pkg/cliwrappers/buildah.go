@@ -712,6 +712,11 @@ type BuildahManifestAddArgs struct {
 	ManifestName string
 	ImageRef     string
 	All          bool
+	// Optional platform overrides for the list entry. When unset, buildah
+	// derives the platform from the image config.
+	Os      string
+	Arch    string
+	Variant string
 }
 
 // ManifestAdd adds an image to a manifest list
@@ -728,6 +733,15 @@ func (b *BuildahCli) ManifestAdd(args *BuildahManifestAddArgs) error {
 	if args.All {
 		buildahArgs = append(buildahArgs, "--all")
 	}
+	if args.Os != "" {
+		buildahArgs = append(buildahArgs, "--os", args.Os)
+	}
+	if args.Arch != "" {
+		buildahArgs = append(buildahArgs, "--arch", args.Arch)
+	}
+	if args.Variant != "" {
+		buildahArgs = append(buildahArgs, "--variant", args.Variant)
+	}
 
 	buildahLog.Debugf("Running command:\nbuildah %s", strings.Join(buildahArgs, " "))
 
@@ -1360,6 +1360,31 @@ func TestBuildahCli_ManifestAdd(t *testing.T) {
 		g.Expect(capturedArgs).To(Equal([]string{"manifest", "add", manifestName, imageRef}))
 	})
 
+	t.Run("should pass platform overrides", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		args := &cliwrappers.BuildahManifestAddArgs{
+			ManifestName: manifestName,
+			ImageRef:     imageRef,
+			Os:           "linux",
+			Arch:         "arm64",
+			Variant:      "v8",
+		}
+
+		err := buildahCli.ManifestAdd(args)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{
+			"manifest", "add", manifestName, imageRef,
+			"--os", "linux", "--arch", "arm64", "--variant", "v8",
+		}))
+	})
+
 	t.Run("should error if manifest name is empty", func(t *testing.T) {
 		buildahCli, _ := setupBuildahCli()
 		args := &cliwrappers.BuildahManifestAddArgs{
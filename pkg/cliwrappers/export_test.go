@@ -2,4 +2,5 @@ package cliwrappers
 
 var ExportParseGitVersion = parseGitVersion
 var ExportIsVersionAtLeast = isVersionAtLeast
+var ExportParseSignatureOutput = parseSignatureOutput
 var ExportGetUID = &getUID
@@ -1,8 +1,10 @@
 package cliwrappers
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"slices"
@@ -37,14 +39,48 @@ type GitCliInterface interface {
 	Merge(ref, message string) (string, error)
 	// SetSparseCheckout configures sparse checkout directories. Runs: git sparse-checkout set <dirs...>
 	SetSparseCheckout(directories []string) error
-	// SubmoduleUpdate initializes and updates submodules. Runs: git submodule update --recursive [--init] --force [--depth=N] [-- paths...]
-	SubmoduleUpdate(init bool, depth int, paths []string) error
+	// SubmoduleInit registers the submodules and resolves their URLs. Runs: git [-C <dir>] submodule init [-- paths...]
+	SubmoduleInit(dir string, paths []string) error
+	// SubmoduleList returns the initialized submodules with their resolved URLs and recorded commits.
+	// Runs: git [-C <dir>] config --local --get-regexp, git [-C <dir>] config -f .gitmodules --get-regexp, git [-C <dir>] ls-tree HEAD
+	SubmoduleList(dir string) ([]GitSubmodule, error)
+	// SubmoduleUpdate updates submodules. Runs: git [-C <dir>] submodule update [--recursive] [--init] --force [--depth=N] [--filter=<filter>] [-- paths...]
+	SubmoduleUpdate(opts GitSubmoduleUpdateOptions) error
 	// SubmoduleFetchTags fetches tags from all submodules. Runs: git submodule foreach --recursive git fetch --force origin refs/tags/*:refs/tags/*
 	SubmoduleFetchTags() error
 	// FetchTags fetches tags from the remote. Runs: git fetch --force origin refs/tags/*:refs/tags/* && git tag -l
 	FetchTags() ([]string, error)
 	// Log returns formatted git log output. Runs: git log [--pretty=<format>] [-N]
 	Log(format string, count int) (string, error)
+	// MergeBase returns the best common ancestor of two commits. Runs: git merge-base <a> <b>
+	MergeBase(a, b string) (string, error)
+	// DiffNames returns the paths changed between two commits. Runs: git diff --name-only --no-renames -z <from> <to>
+	DiffNames(from, to string) ([]string, error)
+	// UnmergedFiles returns the paths with unresolved merge conflicts. Runs: git diff --name-only --diff-filter=U -z
+	UnmergedFiles() ([]string, error)
+	// InitBare initializes a new bare repository in the given directory. Runs: git init --bare <dir>
+	InitBare(dir string) error
+	// FetchMirror fetches all branches and tags from a URL into a bare mirror repository with retry.
+	// Runs: git --git-dir=<dir> -c gc.auto=0 fetch --prune --force --atomic <url> +refs/heads/*:refs/heads/* +refs/tags/*:refs/tags/*
+	FetchMirror(opts GitFetchMirrorOptions) error
+	// RepackAll packs all reachable objects, including those borrowed from alternates. Runs: git repack -a -d
+	RepackAll() error
+	// UpdateRef creates or updates a ref to point to the given object. Runs: git update-ref <ref> <sha>
+	UpdateRef(ref, sha string) error
+	// BundleVerify checks that a bundle file is valid and applies to the repository. Runs: git bundle verify <path>
+	BundleVerify(path string) error
+	// BundleCreate writes the history of the given refs into a bundle file. Runs: git bundle create <path> <refs...>
+	BundleCreate(path string, refs []string) error
+	// VerifyCommit verifies the signature of a commit. Runs: git verify-commit --raw <ref>
+	VerifyCommit(ref string) (*GitSignature, error)
+	// VerifyTag verifies the signature of an annotated tag. Runs: git verify-tag --raw <ref>
+	VerifyTag(ref string) (*GitSignature, error)
+	// LfsInstall configures the git LFS filters for the repository. Runs: git lfs install --local
+	LfsInstall() error
+	// LfsPull fetches and checks out LFS objects of HEAD with retry. Runs: git lfs pull [--include=<paths>] <remote>
+	LfsPull(opts GitLfsPullOptions) error
+	// LfsListFiles lists LFS files of HEAD. Runs: git lfs ls-files --json [--include=<paths>]
+	LfsListFiles(submodules bool, include []string) ([]GitLfsFile, error)
 }
 
 // GitFetchOptions contains the options for FetchWithRefspec.
@@ -55,12 +91,86 @@ type GitFetchOptions struct {
 	// (e.g. "sha1:refs/remotes/origin/branch refs/tags/*:refs/tags/*").
 	// When multiple refspecs are provided, they are split on whitespace and
 	// passed as separate arguments to git fetch.
-	Refspec     string
-	Depth       int
+	Refspec string
+	Depth   int
+	// Deepen extends the history of a shallow repository by the given number
+	// of commits from the current shallow boundary. Ignored when Depth is set.
+	Deepen int
+	// Unshallow fetches the complete history of a shallow repository.
+	Unshallow bool
+	// Filter is the partial clone filter spec, e.g. "blob:none" or "tree:0".
+	// Fetching with a filter makes the remote a promisor remote, the filtered
+	// objects are fetched from it lazily when needed, e.g. on checkout or merge.
+	Filter      string
 	Submodules  bool
 	MaxAttempts int
 }
 
+// GitSubmoduleUpdateOptions contains the options for SubmoduleUpdate.
+type GitSubmoduleUpdateOptions struct {
+	// Dir is the repository to update the submodules of, relative to the working directory.
+	// Empty for the repository in the working directory.
+	Dir       string
+	Init      bool
+	Recursive bool
+	Depth     int
+	// Filter makes the submodules partial clones, it requires git 2.36 or newer.
+	Filter string
+	Paths  []string
+}
+
+// GitSubmodule describes an initialized submodule.
+type GitSubmodule struct {
+	Name string
+	// Path is relative to the repository containing the submodule.
+	Path string
+	// URL is the resolved URL, relative URLs of .gitmodules are resolved against the remote of the repository.
+	URL string
+	// Commit is the commit recorded for the submodule in HEAD of the repository.
+	Commit string
+}
+
+// GitLfsPullOptions contains the options for LfsPull.
+type GitLfsPullOptions struct {
+	Remote string
+	// Include restricts the pulled LFS objects to the given paths.
+	Include []string
+	// Submodules runs the pull in all initialized submodules, recursively,
+	// instead of the repository itself.
+	Submodules  bool
+	MaxAttempts int
+}
+
+// GitLfsFile describes a file tracked by git LFS, as reported by git lfs ls-files --json.
+type GitLfsFile struct {
+	Name       string `json:"name"`
+	Size       int64  `json:"size"`
+	Oid        string `json:"oid"`
+	Checkout   bool   `json:"checkout"`
+	Downloaded bool   `json:"downloaded"`
+}
+
+// GitFetchMirrorOptions contains the options for FetchMirror.
+type GitFetchMirrorOptions struct {
+	// GitDir is the path of the bare mirror repository.
+	GitDir string
+	// URL is fetched directly, without configuring a remote,
+	// so that credentials in the URL are not stored in the mirror.
+	URL         string
+	MaxAttempts int
+}
+
+// GitSignature describes a good signature of a commit or tag.
+type GitSignature struct {
+	// Format is the signature format, "gpg" or "ssh".
+	Format string
+	// Signer is the identity of the signer, the user ID of a GPG key
+	// or the principal of an SSH key from the allowed signers file.
+	Signer string
+	// Key is the fingerprint of the signing key.
+	Key string
+}
+
 var _ GitCliInterface = &GitCli{}
 
 // GitCli provides methods for executing git commands via a CLI executor.
@@ -248,6 +358,118 @@ func (g *GitCli) Merge(ref, message string) (string, error) {
 	return strings.TrimSpace(stdout), nil
 }
 
+// InitBare initializes a new bare repository in the given directory.
+// Runs: git init --bare <dir>
+func (g *GitCli) InitBare(dir string) error {
+	if dir == "" {
+		return errors.New("directory must not be empty")
+	}
+
+	gitArgs := []string{"init", "--bare", dir}
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	cmd := g.buildCmd(gitArgs)
+	// The working directory may not exist yet
+	cmd.Dir = ""
+	_, stderr, _, err := g.Executor.Execute(cmd)
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return nil
+}
+
+// RepackAll packs all objects reachable from the repository into a single pack,
+// copying the objects borrowed from alternates. Afterwards, the alternates can be removed.
+// Runs: git repack -a -d
+func (g *GitCli) RepackAll() error {
+	gitArgs := []string{"repack", "-a", "-d"}
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	_, stderr, _, err := g.Executor.Execute(g.buildCmd(gitArgs))
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return nil
+}
+
+// UpdateRef creates or updates the ref to point to the given object.
+// Runs: git update-ref <ref> <sha>
+func (g *GitCli) UpdateRef(ref, sha string) error {
+	if ref == "" || sha == "" {
+		return errors.New("ref and sha must not be empty")
+	}
+
+	gitArgs := []string{"update-ref", ref, sha}
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	_, stderr, _, err := g.Executor.Execute(g.buildCmd(gitArgs))
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return nil
+}
+
+// --- Bundle operations ---
+
+// BundleVerify checks that the bundle file is valid and that the repository
+// contains the prerequisite commits of the bundle, if any.
+// Runs: git bundle verify <path>
+func (g *GitCli) BundleVerify(path string) error {
+	if path == "" {
+		return errors.New("bundle path must not be empty")
+	}
+
+	gitArgs := []string{"bundle", "verify", path}
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	_, stderr, _, err := g.Executor.Execute(g.buildCmd(gitArgs))
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return nil
+}
+
+// BundleCreate writes the refs and all objects reachable from them into a bundle file.
+// Git writes the bundle through a lock file, so an existing file is replaced only on success.
+// Runs: git bundle create <path> <refs...>
+func (g *GitCli) BundleCreate(path string, refs []string) error {
+	if path == "" {
+		return errors.New("bundle path must not be empty")
+	}
+	if len(refs) == 0 {
+		return errors.New("refs must not be empty")
+	}
+
+	gitArgs := append([]string{"bundle", "create", path}, refs...)
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	_, stderr, _, err := g.Executor.Execute(g.buildCmd(gitArgs))
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return nil
+}
+
 // --- Remote operations ---
 
 // RemoteAdd adds a new remote with the given name and URL.
@@ -310,7 +532,7 @@ func (g *GitCli) FetchTags() ([]string, error) {
 
 // FetchWithRefspec fetches one or more refspecs from a remote with optional depth and retry.
 // When opts.Refspec contains space-separated values, each is passed as a separate argument.
-// Runs: git fetch [--recurse-submodules=yes] [--depth=N] <remote> --update-head-ok --force [<refspec>...]
+// Runs: git fetch [--recurse-submodules=yes] [--depth=N|--deepen=N|--unshallow] [--filter=<filter>] <remote> --update-head-ok --force [<refspec>...]
 func (g *GitCli) FetchWithRefspec(opts GitFetchOptions) error {
 	if opts.Remote == "" {
 		return errors.New("remote must not be empty")
@@ -324,6 +546,14 @@ func (g *GitCli) FetchWithRefspec(opts GitFetchOptions) error {
 
 	if opts.Depth > 0 {
 		gitArgs = append(gitArgs, fmt.Sprintf("--depth=%d", opts.Depth))
+	} else if opts.Deepen > 0 {
+		gitArgs = append(gitArgs, fmt.Sprintf("--deepen=%d", opts.Deepen))
+	} else if opts.Unshallow {
+		gitArgs = append(gitArgs, "--unshallow")
+	}
+
+	if opts.Filter != "" {
+		gitArgs = append(gitArgs, "--filter="+opts.Filter)
 	}
 
 	gitArgs = append(gitArgs, opts.Remote, "--update-head-ok", "--force")
@@ -332,25 +562,85 @@ func (g *GitCli) FetchWithRefspec(opts GitFetchOptions) error {
 		gitArgs = append(gitArgs, strings.Fields(opts.Refspec)...)
 	}
 
+	retryer := g.newNetworkRetryer(g.buildCmd(gitArgs), opts.MaxAttempts)
+
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	_, stderr, _, err := retryer.Run()
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return nil
+}
+
+// FetchMirror fetches all branches and tags from the URL into a bare mirror repository,
+// pruning deleted ones. The refs are updated in a single transaction, so readers of the mirror
+// never see a partial update. Automatic garbage collection is disabled, it could prune
+// objects that running clones borrow from the mirror.
+// Runs: git --git-dir=<dir> -c gc.auto=0 fetch --prune --force --atomic <url> +refs/heads/*:refs/heads/* +refs/tags/*:refs/tags/*
+func (g *GitCli) FetchMirror(opts GitFetchMirrorOptions) error {
+	if opts.GitDir == "" {
+		return errors.New("git dir must not be empty")
+	}
+	if opts.URL == "" {
+		return errors.New("url must not be empty")
+	}
+
+	refspecs := []string{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"}
+	gitArgs := append([]string{"--git-dir=" + opts.GitDir, "-c", "gc.auto=0", "fetch", "--prune", "--force", "--atomic", opts.URL}, refspecs...)
+
+	cmd := g.buildCmd(gitArgs)
+	cmd.Dir = opts.GitDir
+	retryer := g.newNetworkRetryer(cmd, opts.MaxAttempts)
+
+	fullCmd := shellJoin("git", append([]string{"--git-dir=" + opts.GitDir, "-c", "gc.auto=0", "fetch", "--prune", "--force", "--atomic", common.SanitizeURL(opts.URL)}, refspecs...)...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	_, _, _, err := retryer.Run()
+	if err != nil {
+		// Do not log the STDERR to avoid leaking credentials.
+		return fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return nil
+}
+
+// newNetworkRetryer creates a retryer for git commands talking to a remote.
+// Retries are stopped on errors that another attempt cannot fix, e.g. authentication failures.
+func (g *GitCli) newNetworkRetryer(cmd Cmd, maxAttempts int) *Retryer {
 	retryer := NewRetryer(func() (string, string, int, error) {
-		return g.Executor.Execute(g.buildCmd(gitArgs))
+		return g.Executor.Execute(cmd)
 	})
-	if opts.MaxAttempts > 0 {
-		retryer = retryer.WithMaxAttempts(opts.MaxAttempts)
+	if maxAttempts > 0 {
+		retryer = retryer.WithMaxAttempts(maxAttempts)
 	}
-	retryer = retryer.
+	return retryer.
 		StopOnExitCode(128).
 		StopIfOutputContains("Authentication failed").
 		StopIfOutputContains("could not read Username").
 		StopIfOutputContains("fatal: repository").
 		StopIfOutputContains("Permission denied").
 		StopIfOutputContains("Could not resolve hostname")
+}
 
+// Checkout checks out the specified ref (branch, tag, or commit SHA).
+// Runs: git checkout <ref>
+func (g *GitCli) Checkout(ref string) error {
+	if ref == "" {
+		return errors.New("ref must not be empty")
+	}
+
+	gitArgs := []string{"checkout", ref}
 	fullCmd := shellJoin("git", gitArgs...)
 
 	gitLog.Debugf("Running command:\n%s", fullCmd)
 
-	_, stderr, _, err := retryer.Run()
+	_, stderr, _, err := g.Executor.Execute(g.buildCmd(gitArgs))
 	if err != nil {
 		gitLog.Infof("[stderr]\n%s", stderr)
 		return fmt.Errorf("%s: %w", fullCmd, err)
@@ -359,14 +649,16 @@ func (g *GitCli) FetchWithRefspec(opts GitFetchOptions) error {
 	return nil
 }
 
-// Checkout checks out the specified ref (branch, tag, or commit SHA).
-// Runs: git checkout <ref>
-func (g *GitCli) Checkout(ref string) error {
-	if ref == "" {
-		return errors.New("ref must not be empty")
+// SubmoduleInit registers the submodules in the paths, or all if none given, in the repository config,
+// resolving their relative URLs. Nothing is fetched.
+// Runs: git [-C <dir>] submodule init [-- paths...]
+func (g *GitCli) SubmoduleInit(dir string, paths []string) error {
+	gitArgs := append(gitDirArgs(dir), "submodule", "init")
+	if len(paths) > 0 {
+		gitArgs = append(gitArgs, "--")
+		gitArgs = append(gitArgs, paths...)
 	}
 
-	gitArgs := []string{"checkout", ref}
 	fullCmd := shellJoin("git", gitArgs...)
 
 	gitLog.Debugf("Running command:\n%s", fullCmd)
@@ -380,24 +672,122 @@ func (g *GitCli) Checkout(ref string) error {
 	return nil
 }
 
-// SubmoduleUpdate initializes and/or updates submodules recursively.
-// Runs: git submodule update --recursive [--init] [--force] [--depth=N] [-- paths...]
-func (g *GitCli) SubmoduleUpdate(init bool, depth int, paths []string) error {
-	gitArgs := []string{"submodule", "update", "--recursive"}
+// SubmoduleList returns the submodules initialized in the repository, sorted by path.
+// The URLs are read from the repository config, as resolved by SubmoduleInit,
+// the paths from .gitmodules and the commits from the tree of HEAD.
+// Runs: git [-C <dir>] config -z --local --get-regexp ^submodule\..*\.url$
+// && git [-C <dir>] config -z -f .gitmodules --get-regexp ^submodule\..*\.path$
+// && git [-C <dir>] ls-tree -z HEAD -- <paths...>
+func (g *GitCli) SubmoduleList(dir string) ([]GitSubmodule, error) {
+	urls, err := g.configSubmoduleValues(dir, "url", "--local")
+	if err != nil {
+		return nil, err
+	}
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	paths, err := g.configSubmoduleValues(dir, "path", "-f", ".gitmodules")
+	if err != nil {
+		return nil, err
+	}
+
+	var submodules []GitSubmodule
+	for name, url := range urls {
+		path, ok := paths[name]
+		if !ok {
+			return nil, fmt.Errorf("submodule '%s' has no path in .gitmodules", name)
+		}
+		submodules = append(submodules, GitSubmodule{Name: name, Path: path, URL: url})
+	}
+	slices.SortFunc(submodules, func(a, b GitSubmodule) int { return strings.Compare(a.Path, b.Path) })
+
+	gitArgs := append(gitDirArgs(dir), "ls-tree", "-z", "HEAD", "--")
+	for _, submodule := range submodules {
+		gitArgs = append(gitArgs, submodule.Path)
+	}
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	stdout, stderr, _, err := g.Executor.Execute(g.buildCmd(gitArgs))
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return nil, fmt.Errorf("%s: %w", fullCmd, err)
+	}
 
-	if init {
+	// Entries are "<mode> <type> <object>\t<path>", submodules have the type commit
+	commits := map[string]string{}
+	for _, entry := range splitNulSeparated(stdout) {
+		info, path, found := strings.Cut(entry, "\t")
+		fields := strings.Fields(info)
+		if found && len(fields) == 3 && fields[1] == "commit" {
+			commits[path] = fields[2]
+		}
+	}
+	for i := range submodules {
+		submodules[i].Commit = commits[submodules[i].Path]
+	}
+
+	return submodules, nil
+}
+
+// configSubmoduleValues returns the values of the submodule.<name>.<key> entries
+// of a git config file by submodule name.
+func (g *GitCli) configSubmoduleValues(dir, key string, source ...string) (map[string]string, error) {
+	gitArgs := append(gitDirArgs(dir), "config", "-z")
+	gitArgs = append(gitArgs, source...)
+	gitArgs = append(gitArgs, "--get-regexp", `^submodule\..*\.`+key+`$`)
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	stdout, stderr, exitCode, err := g.Executor.Execute(g.buildCmd(gitArgs))
+	// git config exits with 1 when there are no matching entries
+	if err != nil && exitCode == 1 && stderr == "" {
+		return nil, nil
+	}
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return nil, fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	// With -z, entries are "<name>\n<value>"
+	values := map[string]string{}
+	for _, entry := range splitNulSeparated(stdout) {
+		name, value, _ := strings.Cut(entry, "\n")
+		name = strings.TrimSuffix(strings.TrimPrefix(name, "submodule."), "."+key)
+		values[name] = value
+	}
+	return values, nil
+}
+
+// SubmoduleUpdate checks out the commits recorded for the submodules, cloning them if needed.
+// A filter makes the submodules partial clones, it requires git 2.36 or newer.
+// Runs: git [-C <dir>] submodule update [--recursive] [--init] --force [--depth=N] [--filter=<filter>] [-- paths...]
+func (g *GitCli) SubmoduleUpdate(opts GitSubmoduleUpdateOptions) error {
+	gitArgs := append(gitDirArgs(opts.Dir), "submodule", "update")
+
+	if opts.Recursive {
+		gitArgs = append(gitArgs, "--recursive")
+	}
+
+	if opts.Init {
 		gitArgs = append(gitArgs, "--init")
 	}
 
 	gitArgs = append(gitArgs, "--force")
 
-	if depth > 0 {
-		gitArgs = append(gitArgs, fmt.Sprintf("--depth=%d", depth))
+	if opts.Depth > 0 {
+		gitArgs = append(gitArgs, fmt.Sprintf("--depth=%d", opts.Depth))
 	}
 
-	if len(paths) > 0 {
+	if opts.Filter != "" {
+		gitArgs = append(gitArgs, "--filter="+opts.Filter)
+	}
+
+	if len(opts.Paths) > 0 {
 		gitArgs = append(gitArgs, "--")
-		gitArgs = append(gitArgs, paths...)
+		gitArgs = append(gitArgs, opts.Paths...)
 	}
 
 	fullCmd := shellJoin("git", gitArgs...)
@@ -413,6 +803,14 @@ func (g *GitCli) SubmoduleUpdate(init bool, depth int, paths []string) error {
 	return nil
 }
 
+// gitDirArgs returns the arguments to run git in the given directory, relative to the working directory.
+func gitDirArgs(dir string) []string {
+	if dir == "" || dir == "." {
+		return nil
+	}
+	return []string{"-C", dir}
+}
+
 // SubmoduleFetchTags fetches tags from all submodules.
 // Runs: git submodule foreach --recursive git fetch --force origin refs/tags/*:refs/tags/*
 func (g *GitCli) SubmoduleFetchTags() error {
@@ -488,3 +886,234 @@ func (g *GitCli) Log(format string, count int) (string, error) {
 
 	return strings.TrimSpace(stdout), nil
 }
+
+// MergeBase returns the SHA of the best common ancestor of the two commits.
+// Fails when the commits have no common ancestor in the local history,
+// e.g. because the history of a shallow clone is too short.
+// Runs: git merge-base <a> <b>
+func (g *GitCli) MergeBase(a, b string) (string, error) {
+	if a == "" || b == "" {
+		return "", errors.New("commits must not be empty")
+	}
+
+	gitArgs := []string{"merge-base", a, b}
+
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	stdout, stderr, _, err := g.Executor.Execute(g.buildCmd(gitArgs))
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return "", fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return strings.TrimSpace(stdout), nil
+}
+
+// DiffNames returns the paths of files changed between two commits, relative to the repository root.
+// Renames are reported as a deletion and an addition, so both paths are included.
+// Runs: git diff --name-only --no-renames -z <from> <to>
+func (g *GitCli) DiffNames(from, to string) ([]string, error) {
+	if from == "" || to == "" {
+		return nil, errors.New("commits must not be empty")
+	}
+
+	gitArgs := []string{"diff", "--name-only", "--no-renames", "-z", from, to}
+
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	stdout, stderr, _, err := g.Executor.Execute(g.buildCmd(gitArgs))
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return nil, fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return splitNulSeparated(stdout), nil
+}
+
+// UnmergedFiles returns the paths of files with unresolved conflicts after a failed merge.
+// Runs: git diff --name-only --diff-filter=U -z
+func (g *GitCli) UnmergedFiles() ([]string, error) {
+	gitArgs := []string{"diff", "--name-only", "--diff-filter=U", "-z"}
+
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	stdout, stderr, _, err := g.Executor.Execute(g.buildCmd(gitArgs))
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return nil, fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return splitNulSeparated(stdout), nil
+}
+
+// splitNulSeparated splits the output of a git command run with -z into paths.
+func splitNulSeparated(output string) []string {
+	var paths []string
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// --- Signature operations ---
+
+var (
+	gpgGoodSignatureRegex  = regexp.MustCompile(`(?m)^\[GNUPG:\] GOODSIG \S+ (.*)$`)
+	gpgValidSignatureRegex = regexp.MustCompile(`(?m)^\[GNUPG:\] VALIDSIG (\S+)`)
+	sshGoodSignatureRegex  = regexp.MustCompile(`(?m)^Good "git" signature (?:for (.+) )?with \S+ key (\S+)$`)
+)
+
+// VerifyCommit verifies the signature of the commit the ref points to.
+// Git succeeds only for good signatures made by a trusted key: a key in the GPG keyring
+// or an SSH key listed in the file configured by gpg.ssh.allowedSignersFile.
+// Runs: git verify-commit --raw <ref>
+func (g *GitCli) VerifyCommit(ref string) (*GitSignature, error) {
+	return g.verifySignature("verify-commit", ref)
+}
+
+// VerifyTag verifies the signature of the annotated tag the ref points to.
+// Runs: git verify-tag --raw <ref>
+func (g *GitCli) VerifyTag(ref string) (*GitSignature, error) {
+	return g.verifySignature("verify-tag", ref)
+}
+
+func (g *GitCli) verifySignature(command, ref string) (*GitSignature, error) {
+	if ref == "" {
+		return nil, errors.New("ref must not be empty")
+	}
+
+	gitArgs := []string{command, "--raw", ref}
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	// The verification output is written to stderr.
+	_, stderr, _, err := g.Executor.Execute(g.buildCmd(gitArgs))
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return nil, fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return parseSignatureOutput(stderr), nil
+}
+
+// parseSignatureOutput extracts the signer details from the raw output of a successful
+// signature verification, i.e. the gpg status lines or the ssh-keygen output.
+func parseSignatureOutput(output string) *GitSignature {
+	if m := sshGoodSignatureRegex.FindStringSubmatch(output); m != nil {
+		return &GitSignature{Format: "ssh", Signer: m[1], Key: m[2]}
+	}
+
+	signature := &GitSignature{Format: "gpg"}
+	if m := gpgGoodSignatureRegex.FindStringSubmatch(output); m != nil {
+		signature.Signer = strings.TrimSpace(m[1])
+	}
+	if m := gpgValidSignatureRegex.FindStringSubmatch(output); m != nil {
+		signature.Key = m[1]
+	}
+	return signature
+}
+
+// --- LFS operations ---
+
+// LfsInstall configures the git LFS filters and hooks in the repository config.
+// Runs: git lfs install --local
+func (g *GitCli) LfsInstall() error {
+	gitArgs := []string{"lfs", "install", "--local"}
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	_, stderr, _, err := g.Executor.Execute(g.buildCmd(gitArgs))
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return nil
+}
+
+// LfsPull fetches the LFS objects referenced by HEAD from the remote and replaces
+// the pointer files in the working tree with their content.
+// When opts.Submodules is set, the pull runs in every initialized submodule instead.
+// Runs: git lfs pull [--include=<paths>] <remote>
+// or: git submodule foreach --recursive 'git lfs pull [--include=<paths>] <remote>'
+func (g *GitCli) LfsPull(opts GitLfsPullOptions) error {
+	if opts.Remote == "" {
+		return errors.New("remote must not be empty")
+	}
+
+	lfsArgs := []string{"lfs", "pull"}
+	if len(opts.Include) > 0 {
+		lfsArgs = append(lfsArgs, "--include="+strings.Join(opts.Include, ","))
+	}
+	lfsArgs = append(lfsArgs, opts.Remote)
+
+	gitArgs := lfsArgs
+	if opts.Submodules {
+		gitArgs = []string{"submodule", "foreach", "--recursive", shellJoin("git", lfsArgs...)}
+	}
+
+	retryer := g.newNetworkRetryer(g.buildCmd(gitArgs), opts.MaxAttempts)
+
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	_, stderr, _, err := retryer.Run()
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return nil
+}
+
+// LfsListFiles returns the LFS files referenced by HEAD, optionally restricted to the include paths.
+// When submodules is set, the files of every initialized submodule are returned instead.
+// Runs: git lfs ls-files --json [--include=<paths>]
+// or: git submodule foreach --quiet --recursive 'git lfs ls-files --json [--include=<paths>]'
+func (g *GitCli) LfsListFiles(submodules bool, include []string) ([]GitLfsFile, error) {
+	lfsArgs := []string{"lfs", "ls-files", "--json"}
+	if len(include) > 0 {
+		lfsArgs = append(lfsArgs, "--include="+strings.Join(include, ","))
+	}
+
+	gitArgs := lfsArgs
+	if submodules {
+		gitArgs = []string{"submodule", "foreach", "--quiet", "--recursive", shellJoin("git", lfsArgs...)}
+	}
+	fullCmd := shellJoin("git", gitArgs...)
+
+	gitLog.Debugf("Running command:\n%s", fullCmd)
+
+	stdout, stderr, _, err := g.Executor.Execute(g.buildCmd(gitArgs))
+	if err != nil {
+		gitLog.Infof("[stderr]\n%s", stderr)
+		return nil, fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	// Output of submodule foreach is a sequence of JSON documents, one per submodule.
+	files := []GitLfsFile{}
+	decoder := json.NewDecoder(strings.NewReader(stdout))
+	for {
+		var listing struct {
+			Files []GitLfsFile `json:"files"`
+		}
+		if err := decoder.Decode(&listing); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse output of %s: %w", fullCmd, err)
+		}
+		files = append(files, listing.Files...)
+	}
+	return files, nil
+}
@@ -0,0 +1,61 @@
+package cliwrappers
+
+import (
+	"errors"
+	"fmt"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var gpgLog = l.Logger.WithField("logger", "GpgCli")
+
+type GpgCliInterface interface {
+	// ImportKeys imports public keys into the keyring. Runs: gpg --homedir <dir> --batch --import <files...>
+	ImportKeys(keyFiles []string) error
+}
+
+var _ GpgCliInterface = &GpgCli{}
+
+// GpgCli runs gpg against a dedicated home directory, leaving the user's keyring untouched.
+type GpgCli struct {
+	Executor CliExecutorInterface
+	HomeDir  string
+}
+
+func NewGpgCli(executor CliExecutorInterface, homeDir string) (*GpgCli, error) {
+	gpgCliAvailable, err := CheckCliToolAvailable("gpg")
+	if err != nil {
+		return nil, err
+	}
+	if !gpgCliAvailable {
+		return nil, errors.New("gpg CLI is not available")
+	}
+
+	return &GpgCli{
+		Executor: executor,
+		HomeDir:  homeDir,
+	}, nil
+}
+
+func (g *GpgCli) ImportKeys(keyFiles []string) error {
+	if g.HomeDir == "" {
+		return errors.New("gpg home directory is empty")
+	}
+	if len(keyFiles) == 0 {
+		return errors.New("no key files to import")
+	}
+
+	args := append([]string{"--homedir", g.HomeDir, "--batch", "--import"}, keyFiles...)
+	cmd := Command("gpg", args...)
+	fullCmd := shellJoin(cmd.Name, cmd.Args...)
+
+	gpgLog.Debugf("Running command:\n%s", fullCmd)
+
+	_, stderr, _, err := g.Executor.Execute(cmd)
+	if err != nil {
+		gpgLog.Infof("[stderr]\n%s", stderr)
+		return fmt.Errorf("%s: %w", fullCmd, err)
+	}
+
+	return nil
+}
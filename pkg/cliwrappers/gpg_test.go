@@ -0,0 +1,65 @@
+package cliwrappers_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func setupGpgCli() (*cliwrappers.GpgCli, *mockExecutor) {
+	executor := &mockExecutor{}
+	gpgCli := &cliwrappers.GpgCli{Executor: executor, HomeDir: "/tmp/gnupg"}
+	return gpgCli, executor
+}
+
+func TestGpgCli_ImportKeys(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should import keys into the home directory", func(t *testing.T) {
+		gpgCli, executor := setupGpgCli()
+		var capturedCmd cliwrappers.Cmd
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			return "", "", 0, nil
+		}
+
+		err := gpgCli.ImportKeys([]string{"/keys/a.asc", "/keys/b.gpg"})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedCmd.Name).To(Equal("gpg"))
+		g.Expect(capturedCmd.Args).To(Equal([]string{
+			"--homedir", "/tmp/gnupg", "--batch", "--import", "/keys/a.asc", "/keys/b.gpg"}))
+	})
+
+	t.Run("should fail without key files", func(t *testing.T) {
+		gpgCli, _ := setupGpgCli()
+
+		err := gpgCli.ImportKeys(nil)
+
+		g.Expect(err).To(MatchError("no key files to import"))
+	})
+
+	t.Run("should fail without home directory", func(t *testing.T) {
+		gpgCli, _ := setupGpgCli()
+		gpgCli.HomeDir = ""
+
+		err := gpgCli.ImportKeys([]string{"/keys/a.asc"})
+
+		g.Expect(err).To(MatchError("gpg home directory is empty"))
+	})
+
+	t.Run("should return error on failure", func(t *testing.T) {
+		gpgCli, executor := setupGpgCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "gpg: no valid OpenPGP data found.", 2, errors.New("exit status 2")
+		}
+
+		err := gpgCli.ImportKeys([]string{"/keys/a.asc"})
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("gpg --homedir /tmp/gnupg --batch --import /keys/a.asc"))
+	})
+}
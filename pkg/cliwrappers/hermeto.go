@@ -5,6 +5,7 @@ package cliwrappers
 import (
 	"errors"
 	"os"
+	"strings"
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
@@ -12,7 +13,7 @@ import (
 var log = logger.Logger.WithField("logger", "HermetoCli")
 
 type HermetoCliInterface interface {
-	Version() error
+	Version() (string, error)
 	FetchDeps(params *HermetoFetchDepsParams) error
 	GenerateEnv(params *HermetoGenerateEnvParams) error
 	InjectFiles(params *HermetoInjectFilesParams) error
@@ -36,11 +37,11 @@ func NewHermetoCli(executor CliExecutorInterface, env []string) (*HermetoCli, er
 	return &HermetoCli{Executor: executor, Env: env}, nil
 }
 
-// Print the Hermeto version.
-func (hc *HermetoCli) Version() error {
+// Print and return the Hermeto version, e.g. "hermeto 0.1.0".
+func (hc *HermetoCli) Version() (string, error) {
 	args := []string{"--version"}
-	_, _, _, err := hc.Executor.Execute(Cmd{Name: "hermeto", Args: args, LogOutput: true})
-	return err
+	stdout, _, _, err := hc.Executor.Execute(Cmd{Name: "hermeto", Args: args, LogOutput: true})
+	return strings.TrimSpace(stdout), err
 }
 
 type HermetoFetchDepsParams struct {
@@ -30,8 +30,9 @@ func TestHermetoCliVersionOutput(t *testing.T) {
 		return capturedStdout, "", 0, nil
 	}
 
-	err := hermetoCli.Version()
+	version, err := hermetoCli.Version()
 	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(version).To(Equal("hermeto 0.1.0"))
 
 	g.Expect(capturedArgs).To(Equal([]string{"--version"}))
 	g.Expect(capturedStdout).To(Equal("hermeto 0.1.0"))
@@ -35,10 +35,19 @@ func NewOrasCli(executor CliExecutorInterface) (*OrasCli, error) {
 type OrasPushArgs struct {
 	DestinationImage string
 	FileName         string
-	ArtifactType     string
-	RegistryConfig   string
-	Format           string
-	Template         string
+	// Additional files pushed as separate layers after FileName
+	ExtraFiles     []OrasPushFile
+	ArtifactType   string
+	RegistryConfig string
+	Format         string
+	Template       string
+}
+
+// OrasPushFile is a file pushed as a layer with the given media type.
+// oras uses the file path as the layer title annotation.
+type OrasPushFile struct {
+	Path      string
+	MediaType string
 }
 
 // Push a file from local to the registry. Return the stdout and stderr output from oras command.
@@ -64,6 +73,16 @@ func (b *OrasCli) Push(args *OrasPushArgs) (string, string, error) {
 		orasArgs = append(orasArgs, "--template", args.Template)
 	}
 	orasArgs = append(orasArgs, args.DestinationImage, args.FileName)
+	for _, file := range args.ExtraFiles {
+		if file.Path == "" {
+			return "", "", fmt.Errorf("extra file path is empty")
+		}
+		if file.MediaType != "" {
+			orasArgs = append(orasArgs, file.Path+":"+file.MediaType)
+		} else {
+			orasArgs = append(orasArgs, file.Path)
+		}
+	}
 
 	orasLog.Debugf("Running command:\n%s", shellJoin("oras", orasArgs...))
 
@@ -119,6 +119,30 @@ func TestOrasCli_Push(t *testing.T) {
 		g.Expect(stderr).Should(Equal("push progress"))
 	})
 
+	t.Run("push extra files with media types", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).Should(Equal("oras"))
+			expectedArgs := []string{"push", artifactImage, fileName, "build-args:application/vnd.custom.build-args", "notes"}
+			g.Expect(cmd.Args).Should(Equal(expectedArgs))
+			return imageDigest, "push progress", 0, nil
+		}
+
+		pushArgs := &cliwrappers.OrasPushArgs{
+			DestinationImage: artifactImage,
+			FileName:         fileName,
+			ExtraFiles: []cliwrappers.OrasPushFile{
+				{Path: "build-args", MediaType: "application/vnd.custom.build-args"},
+				{Path: "notes"},
+			},
+		}
+
+		_, _, err := orasCli.Push(pushArgs)
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+	})
+
 	t.Run("should return error when missing destination image", func(t *testing.T) {
 		pushArgs := &cliwrappers.OrasPushArgs{
 			FileName: fileName,
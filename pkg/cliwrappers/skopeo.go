@@ -10,6 +10,8 @@ import (
 
 const (
 	UnsupportedOCIConfigMediaType = "application/vnd.unknown.config.v1+json"
+	// ManifestUnknownError is the registry error returned when the requested tag or digest doesn't exist
+	ManifestUnknownError = "manifest unknown"
 )
 
 var skopeoLog = l.Logger.WithField("logger", "ScopeoCli")
@@ -142,7 +144,9 @@ func (s *SkopeoCli) Inspect(args *SkopeoInspectArgs) (string, error) {
 	}).WithImageRegistryPreset().
 		StopIfOutputContains("unauthorized").
 		// Stop on unsupported config media type
-		StopIfOutputContains(UnsupportedOCIConfigMediaType)
+		StopIfOutputContains(UnsupportedOCIConfigMediaType).
+		// Stop if the image doesn't exist
+		StopIfOutputContains(ManifestUnknownError)
 
 	stdout, stderr, _, err := retryer.Run()
 	if err != nil {
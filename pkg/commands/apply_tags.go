@@ -2,19 +2,32 @@ package commands
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
 	"regexp"
 	"slices"
 	"strings"
+	"text/template"
+	"time"
 
+	"github.com/containerd/platforms"
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
 	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	go_digest "github.com/opencontainers/go-digest"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
+const (
+	labelConflictFail  = "fail"
+	labelConflictMerge = "merge"
+)
+
 var ApplyTagsParamsConfig = map[string]common.Parameter{
 	"image-url": {
 		Name:       "image-url",
@@ -46,15 +59,55 @@ var ApplyTagsParamsConfig = map[string]common.Parameter{
 		EnvVarName:   "KBC_APPLY_TAGS_FROM_IMAGE_LABEL",
 		TypeKind:     reflect.String,
 		DefaultValue: "",
-		Usage:        "Image label name to add tags from. Tags are comma or whitespace separated in the label value.",
+		Usage:        "Image label name to add tags from. Tags are comma or whitespace separated in the label value. For an image index, the label is read from all platform images.",
+	},
+	"label-conflict": {
+		Name:         "label-conflict",
+		EnvVarName:   "KBC_APPLY_TAGS_LABEL_CONFLICT",
+		TypeKind:     reflect.String,
+		DefaultValue: labelConflictFail,
+		Usage:        "What to do if platform images of an image index have different values of the label with tags: 'fail' or 'merge' the tags.",
+	},
+	"expand-semver": {
+		Name:         "expand-semver",
+		EnvVarName:   "KBC_APPLY_TAGS_EXPAND_SEMVER",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Expand semantic version tags, e.g. tag '1.2.3' also creates '1.2' and '1' tags. Pre-release versions are not expanded.",
+	},
+	"expand-semver-latest": {
+		Name:         "expand-semver-latest",
+		EnvVarName:   "KBC_APPLY_TAGS_EXPAND_SEMVER_LATEST",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Also create 'latest' tag if a semantic version tag is expanded. Requires --expand-semver.",
+	},
+	"protected-tags": {
+		Name:         "protected-tags",
+		EnvVarName:   "KBC_APPLY_TAGS_PROTECTED_TAGS",
+		TypeKind:     reflect.Array,
+		DefaultValue: "",
+		Usage:        "Regular expressions of tags that must not be overwritten or removed if they already point to a different digest, e.g. 'v[0-9]+\\.[0-9]+\\.[0-9]+'. A pattern must match the whole tag.",
+	},
+	"remove-tags": {
+		Name:         "remove-tags",
+		EnvVarName:   "KBC_APPLY_TAGS_REMOVE_TAGS",
+		TypeKind:     reflect.Array,
+		DefaultValue: "",
+		Usage:        "Tags to remove from the image repository. Only the tags are deleted, the manifests they point to and their other tags are kept.",
 	},
 }
 
 type ApplyTagsParams struct {
-	ImageUrl      string   `paramName:"image-url"`
-	Digest        string   `paramName:"digest"`
-	NewTags       []string `paramName:"tags"`
-	LabelWithTags string   `paramName:"tags-from-image-label"`
+	ImageUrl           string   `paramName:"image-url"`
+	Digest             string   `paramName:"digest"`
+	NewTags            []string `paramName:"tags"`
+	LabelWithTags      string   `paramName:"tags-from-image-label"`
+	LabelConflict      string   `paramName:"label-conflict"`
+	ExpandSemver       bool     `paramName:"expand-semver"`
+	ExpandSemverLatest bool     `paramName:"expand-semver-latest"`
+	ProtectedTags      []string `paramName:"protected-tags"`
+	RemoveTags         []string `paramName:"remove-tags"`
 }
 
 type ApplyTagsCliWrappers struct {
@@ -62,17 +115,24 @@ type ApplyTagsCliWrappers struct {
 }
 
 type ApplyTagsResults struct {
-	Tags []string `json:"tags"`
+	Tags        []string `json:"tags"`
+	RemovedTags []string `json:"removed_tags,omitempty"`
+	// PreviousDigests maps each created or removed tag to the digest it pointed to before.
+	// Empty digest means the tag did not exist.
+	PreviousDigests map[string]string `json:"previous_digests,omitempty"`
 }
 
 type ApplyTags struct {
-	Params        *ApplyTagsParams
-	CliWrappers   ApplyTagsCliWrappers
-	Results       ApplyTagsResults
-	ResultsWriter common.ResultsWriterInterface
+	Params         *ApplyTagsParams
+	CliWrappers    ApplyTagsCliWrappers
+	RegistryClient clients.RegistryTagsClientInterface
+	Results        ApplyTagsResults
+	ResultsWriter  common.ResultsWriterInterface
 
 	imageName     string
 	imageByDigest string
+
+	protectedTagsRegexes []*regexp.Regexp
 }
 
 func NewApplyTags(cmd *cobra.Command) (*ApplyTags, error) {
@@ -88,6 +148,7 @@ func NewApplyTags(cmd *cobra.Command) (*ApplyTags, error) {
 		return nil, err
 	}
 
+	applyTags.RegistryClient = clients.NewRegistryTagsClient()
 	applyTags.ResultsWriter = common.NewResultsWriter()
 
 	return applyTags, nil
@@ -120,15 +181,53 @@ func (c *ApplyTags) Run() error {
 		return err
 	}
 
-	tags := slices.Concat(c.Params.NewTags, tagsFromLabel)
+	newTags, err := c.renderTagTemplates(c.Params.NewTags)
+	if err != nil {
+		return err
+	}
+
+	tags := slices.Concat(newTags, tagsFromLabel)
+	if c.Params.ExpandSemver {
+		tags = expandSemverTags(tags, c.Params.ExpandSemverLatest)
+	}
+	for _, tag := range tags {
+		if !common.IsImageTagValid(tag) {
+			return fmt.Errorf("tag '%s' is invalid", tag)
+		}
+	}
 	l.Logger.Debugf("Tags to create: %s", strings.Join(tags, ", "))
 
+	for _, tag := range c.Params.RemoveTags {
+		if slices.Contains(tags, tag) {
+			return fmt.Errorf("tag '%s' is requested to be both created and removed", tag)
+		}
+	}
+
+	previousDigests, err := c.getPreviousDigests(slices.Concat(tags, c.Params.RemoveTags))
+	if err != nil {
+		return err
+	}
+	c.Results.PreviousDigests = previousDigests
+
+	if err := c.checkProtectedTags(tags, c.Params.RemoveTags, previousDigests); err != nil {
+		return err
+	}
+	if err := c.checkTagsToRemove(c.Params.RemoveTags, previousDigests); err != nil {
+		return err
+	}
+
 	if err := c.applyTags(tags); err != nil {
 		return err
 	}
 
 	c.Results.Tags = tags
 
+	removedTags, err := c.removeTags(c.Params.RemoveTags, previousDigests)
+	if err != nil {
+		return err
+	}
+	c.Results.RemovedTags = removedTags
+
 	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
 		fmt.Print(resultJson)
 	} else {
@@ -139,26 +238,169 @@ func (c *ApplyTags) Run() error {
 	return nil
 }
 
+// imageManifestRef is a reference to an image manifest with the platform it is built for.
+type imageManifestRef struct {
+	ref      string
+	platform string
+}
+
 // retrieveTagsFromImageLabel fetches list of tags from the given image label.
-// In fact, two skopeo invocations are needed (and this is optimal way):
+// In fact, at least two skopeo invocations are needed:
 //  1. Read the raw reference data (light request) to see if we have image manifest or image index.
-//     In case of an image index, get image manifest for any architecture (we need only labels).
 //  2. Perform actual inspect request on the image manifest.
+//     In case of an image index, the label is read from the image manifest of each platform.
+//     If the label values differ, the command fails or merges the tags depending on --label-conflict.
 func (c *ApplyTags) retrieveTagsFromImageLabel(labelName string) ([]string, error) {
+	if labelName == "" {
+		l.Logger.Debug("Label with additional tags is not set")
+		return nil, nil
+	}
+
+	manifestRefs, err := c.resolveImageManifestRefs()
+	if err != nil {
+		return nil, err
+	}
+	if len(manifestRefs) == 0 {
+		return nil, nil
+	}
+
+	var platformNames []string
+	var platformTags [][]string
+	for _, manifestRef := range manifestRefs {
+		tags, supported, err := c.readTagsLabel(manifestRef.ref, labelName)
+		if err != nil {
+			return nil, err
+		}
+		if !supported {
+			continue
+		}
+		platformNames = append(platformNames, manifestRef.platform)
+		platformTags = append(platformTags, tags)
+	}
+
+	tagsFromLabel, err := c.combinePlatformTags(labelName, platformNames, platformTags)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tagsFromLabel) == 0 {
+		l.Logger.Warnf("No tags given in '%s' image label", c.Params.LabelWithTags)
+		return nil, nil
+	}
+
+	l.Logger.Infof("Additional tags from '%s' image label: %s", c.Params.LabelWithTags, strings.Join(tagsFromLabel, ", "))
+
+	return tagsFromLabel, nil
+}
+
+// readTagsLabel reads and validates the tags from the label of the given image manifest.
+// Returns false if the image config has unsupported media type and the label cannot be read.
+func (c *ApplyTags) readTagsLabel(imageRef, labelName string) ([]string, bool, error) {
+	inspectArgs := &cliWrappers.SkopeoInspectArgs{
+		ImageRef:   imageRef,
+		Format:     fmt.Sprintf(`{{ index .Labels "%s" }}`, labelName),
+		RetryTimes: 3,
+		NoTags:     true,
+	}
+	tagsLabelValue, err := c.CliWrappers.SkopeoCli.Inspect(inspectArgs)
+	if err != nil {
+		if strings.Contains(err.Error(), cliWrappers.UnsupportedOCIConfigMediaType) {
+			// Skip the label with tags for unsupported config media type.
+			// Print warning message and continue.
+			l.Logger.Warnf("unsupported config media type '%s' of %s image. Skipping reading %s image label",
+				cliWrappers.UnsupportedOCIConfigMediaType, imageRef, labelName)
+			return nil, false, nil
+		}
+		l.Logger.Errorf("failed to retrieve tags from '%s' label value: %s", labelName, err.Error())
+		return nil, false, err
+	}
+	tagsLabelValue = strings.TrimSpace(tagsLabelValue)
+	l.Logger.Debugf("Tags label value of %s: %s", imageRef, tagsLabelValue)
+
+	if tagsLabelValue == "" {
+		return nil, true, nil
+	}
+
+	tagSeparatorRegex := regexp.MustCompile(`[\s,]+`)
+	tagsFromLabel := tagSeparatorRegex.Split(tagsLabelValue, -1)
+
+	// Validate the obtained tags
+	for _, tag := range tagsFromLabel {
+		if !common.IsImageTagValid(tag) {
+			return nil, false, fmt.Errorf("tag from label '%s' is invalid", tag)
+		}
+	}
+
+	return tagsFromLabel, true, nil
+}
+
+// combinePlatformTags combines tags read from the label of each platform image.
+// If all platforms agree on the tags, the tags are returned as is.
+// Otherwise, the tags are merged or an error is returned depending on --label-conflict.
+func (c *ApplyTags) combinePlatformTags(labelName string, platformNames []string, platformTags [][]string) ([]string, error) {
+	if len(platformTags) == 0 {
+		return nil, nil
+	}
+
+	conflict := false
+	firstTags := slices.Sorted(slices.Values(platformTags[0]))
+	for _, tags := range platformTags[1:] {
+		if !slices.Equal(firstTags, slices.Sorted(slices.Values(tags))) {
+			conflict = true
+			break
+		}
+	}
+	if !conflict {
+		return platformTags[0], nil
+	}
+
+	if c.Params.LabelConflict == labelConflictMerge {
+		var mergedTags []string
+		for _, tags := range platformTags {
+			for _, tag := range tags {
+				if !slices.Contains(mergedTags, tag) {
+					mergedTags = append(mergedTags, tag)
+				}
+			}
+		}
+		l.Logger.Warnf("Platform images have different '%s' label values, merging tags", labelName)
+		return mergedTags, nil
+	}
+
+	var details strings.Builder
+	for i, platform := range platformNames {
+		fmt.Fprintf(&details, "\n  %s: '%s'", platform, strings.Join(platformTags[i], " "))
+	}
+	return nil, fmt.Errorf("platform images of %s have different '%s' label values:%s",
+		c.imageByDigest, labelName, details.String())
+}
+
+// resolveImageManifestRef returns the reference of the image manifest to read the image config from.
+// For an image index, returns the reference of the first image manifest in the index.
+// Returns empty string (and prints a warning) if the reference doesn't point to an image.
+func (c *ApplyTags) resolveImageManifestRef() (string, error) {
+	manifestRefs, err := c.resolveImageManifestRefs()
+	if err != nil || len(manifestRefs) == 0 {
+		return "", err
+	}
+	return manifestRefs[0].ref, nil
+}
+
+// resolveImageManifestRefs returns references of the image manifests to read the image config from.
+// For an image index, returns the references of all platform image manifests in the index,
+// attestation manifests (with 'unknown' platform) are skipped.
+// Returns empty list (and prints a warning) if the reference doesn't point to an image.
+func (c *ApplyTags) resolveImageManifestRefs() ([]imageManifestRef, error) {
 	type imageManifest struct {
-		MediaType string `json:"mediaType,omitempty"`
-		Digest    string `json:"digest,omitempty"`
+		MediaType string          `json:"mediaType,omitempty"`
+		Digest    string          `json:"digest,omitempty"`
+		Platform  *ociv1.Platform `json:"platform,omitempty"`
 	}
 	type imageIndexManifest struct {
 		MediaType string          `json:"mediaType,omitempty"`
 		Manifests []imageManifest `json:"manifests,omitempty"`
 	}
 
-	if labelName == "" {
-		l.Logger.Debug("Label with additional tags is not set")
-		return nil, nil
-	}
-
 	// Do the raw inspect of the image to get image manifest digest for the inspection.
 	rawInspectArgs := &cliWrappers.SkopeoInspectArgs{
 		ImageRef:   c.imageByDigest,
@@ -176,77 +418,187 @@ func (c *ApplyTags) retrieveTagsFromImageLabel(labelName string) ([]string, erro
 		return nil, err
 	}
 
-	// Image reference to inspect labels onto.
-	targetImageReference := ""
-
 	if strings.Contains(imageIndex.MediaType, ".index.") || strings.Contains(imageIndex.MediaType, ".manifest.list.") {
 		// Provided by user reference is image index, e.g. "application/vnd.oci.image.index.v1+json"
-		// Pick image with arbitrary architecture for the target reference.
-		digest := ""
+		var manifestRefs []imageManifestRef
 		for _, manifest := range imageIndex.Manifests {
-			if strings.Contains(manifest.MediaType, ".manifest.") {
-				digest = manifest.Digest
-				break
+			if !strings.Contains(manifest.MediaType, ".manifest.") {
+				continue
+			}
+			platform := manifest.Digest
+			if manifest.Platform != nil {
+				if manifest.Platform.OS == "unknown" {
+					// Attestation manifest, e.g. provenance created by buildkit
+					continue
+				}
+				platform = platforms.Format(*manifest.Platform)
 			}
+			manifestRefs = append(manifestRefs, imageManifestRef{
+				ref:      c.imageName + "@" + manifest.Digest,
+				platform: platform,
+			})
 		}
-		if digest == "" {
+		if len(manifestRefs) == 0 {
 			// The index doesn't contain an image manifest, print warning and proceed.
 			l.Logger.Warnf("image index %s does not contain an image manifest", c.imageByDigest)
-			return nil, nil
 		}
-		targetImageReference = c.imageName + "@" + digest
+		return manifestRefs, nil
 	} else if strings.Contains(imageIndex.MediaType, ".manifest.") {
 		// Provided by user reference is image manifest, e.g. "application/vnd.docker.distribution.manifest.v2+json"
-		targetImageReference = c.imageByDigest
-	} else {
-		// Not supported OCI image type, print warning and proceed.
-		l.Logger.Warnf("unsupported OCI image type: %s in %s", imageIndex.MediaType, c.imageByDigest)
-		return nil, nil
+		return []imageManifestRef{{ref: c.imageByDigest, platform: c.Params.Digest}}, nil
 	}
+	// Not supported OCI image type, print warning and proceed.
+	l.Logger.Warnf("unsupported OCI image type: %s in %s", imageIndex.MediaType, c.imageByDigest)
+	return nil, nil
+}
 
-	// Perform inspect on the target image manifest
-	inspectArgs := &cliWrappers.SkopeoInspectArgs{
-		ImageRef:   targetImageReference,
-		Format:     fmt.Sprintf(`{{ index .Labels "%s" }}`, labelName),
-		RetryTimes: 3,
-		NoTags:     true,
+// tagTemplateData holds the variables available in tag templates.
+type tagTemplateData struct {
+	// Labels of the image config
+	Labels map[string]string
+	// Env of the image config, variable name => value
+	Env map[string]string
+	// Created is the image creation time
+	Created time.Time
+	// Digest is the encoded part of the image digest, without the algorithm
+	Digest string
+}
+
+var tagTemplateFuncs = template.FuncMap{
+	// trunc returns at most the first n characters of s, e.g. {{ trunc 7 .Env.GIT_COMMIT }}
+	"trunc": func(n int, s string) string {
+		if n < 0 || n >= len(s) {
+			return s
+		}
+		return s[:n]
+	},
+	"lower": strings.ToLower,
+	// replace replaces all occurrences of old with new in s, e.g. {{ replace "/" "-" .Labels.branch }}
+	"replace": func(old, new, s string) string {
+		return strings.ReplaceAll(s, old, new)
+	},
+}
+
+func isTagTemplate(tag string) bool {
+	return strings.Contains(tag, "{{")
+}
+
+func parseTagTemplate(tag string) (*template.Template, error) {
+	return template.New("tag").Option("missingkey=error").Funcs(tagTemplateFuncs).Parse(tag)
+}
+
+// renderTagTemplates renders Go templates in the given tags.
+// The image config is inspected only if at least one of the tags is a template.
+func (c *ApplyTags) renderTagTemplates(tags []string) ([]string, error) {
+	if !slices.ContainsFunc(tags, isTagTemplate) {
+		return tags, nil
 	}
-	tagsLabelValue, err := c.CliWrappers.SkopeoCli.Inspect(inspectArgs)
+
+	data, err := c.getTagTemplateData()
 	if err != nil {
-		if strings.Contains(err.Error(), cliWrappers.UnsupportedOCIConfigMediaType) {
-			// Skip the label with tags for unsupported config media type.
-			// Print warning message and continue.
-			l.Logger.Warnf("unsupported config media type '%s' of input image. Skipping reading %s image label",
-				cliWrappers.UnsupportedOCIConfigMediaType, c.Params.LabelWithTags)
-			return nil, nil
+		return nil, err
+	}
+
+	renderedTags := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !isTagTemplate(tag) {
+			renderedTags = append(renderedTags, tag)
+			continue
 		}
-		l.Logger.Errorf("failed to retrieve tags from '%s' label value: %s", c.Params.LabelWithTags, err.Error())
+		tmpl, err := parseTagTemplate(tag)
+		if err != nil {
+			return nil, fmt.Errorf("tag template '%s' is invalid: %w", tag, err)
+		}
+		var rendered strings.Builder
+		if err := tmpl.Execute(&rendered, data); err != nil {
+			return nil, fmt.Errorf("failed to render tag template '%s': %w", tag, err)
+		}
+		l.Logger.Debugf("Tag template '%s' rendered to '%s'", tag, rendered.String())
+		renderedTags = append(renderedTags, rendered.String())
+	}
+	return renderedTags, nil
+}
+
+func (c *ApplyTags) getTagTemplateData() (*tagTemplateData, error) {
+	data := &tagTemplateData{
+		Labels: map[string]string{},
+		Env:    map[string]string{},
+	}
+	if digest, err := go_digest.Parse(c.Params.Digest); err == nil {
+		data.Digest = digest.Encoded()
+	}
+
+	targetImageReference, err := c.resolveImageManifestRef()
+	if err != nil {
 		return nil, err
 	}
-	tagsLabelValue = strings.TrimSpace(tagsLabelValue)
-	l.Logger.Debugf("Tags label value: %s", tagsLabelValue)
+	if targetImageReference == "" {
+		return nil, fmt.Errorf("cannot read image config of %s for tag templates", c.imageByDigest)
+	}
 
-	if tagsLabelValue == "" {
-		l.Logger.Warnf("No tags given in '%s' image label", c.Params.LabelWithTags)
-		return nil, nil
+	inspectOutput, err := c.CliWrappers.SkopeoCli.Inspect(&cliWrappers.SkopeoInspectArgs{
+		ImageRef:   targetImageReference,
+		RetryTimes: 3,
+		NoTags:     true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect image config for tag templates: %w", err)
 	}
 
-	tagSeparatorRegex := regexp.MustCompile(`[\s,]+`)
-	tagsFromLabel := tagSeparatorRegex.Split(tagsLabelValue, -1)
+	var imageInfo struct {
+		Created *time.Time        `json:"Created"`
+		Labels  map[string]string `json:"Labels"`
+		Env     []string          `json:"Env"`
+	}
+	if err := json.Unmarshal([]byte(inspectOutput), &imageInfo); err != nil {
+		return nil, fmt.Errorf("failed to parse image inspect output: %w", err)
+	}
 
-	// Successfully obtained tags from the image label
-	// Validate the obtained tags
-	for _, tag := range tagsFromLabel {
-		if !common.IsImageTagValid(tag) {
-			return nil, fmt.Errorf("tag from label '%s' is invalid", tag)
+	if imageInfo.Created != nil {
+		data.Created = imageInfo.Created.UTC()
+	}
+	maps.Copy(data.Labels, imageInfo.Labels)
+	for _, env := range imageInfo.Env {
+		name, value, _ := strings.Cut(env, "=")
+		data.Env[name] = value
+	}
+
+	return data, nil
+}
+
+// semverTagRegex matches release versions with optional 'v' prefix, e.g. 'v1.2.3'.
+// Pre-release versions (e.g. '1.2.3-rc1') are intentionally not matched.
+var semverTagRegex = regexp.MustCompile(`^(v?)(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)$`)
+
+// expandSemverTags adds 'major.minor' and 'major' tags for each semantic version tag,
+// e.g. '1.2.3' => '1.2.3', '1.2', '1'. If addLatest is set and at least one semantic
+// version tag is found, 'latest' tag is added as well. The result has no duplicates.
+func expandSemverTags(tags []string, addLatest bool) []string {
+	var expandedTags []string
+	add := func(tag string) {
+		if !slices.Contains(expandedTags, tag) {
+			expandedTags = append(expandedTags, tag)
 		}
 	}
 
-	if len(tagsFromLabel) > 0 {
-		l.Logger.Infof("Additional tags from '%s' image label: %s", c.Params.LabelWithTags, strings.Join(tagsFromLabel, ", "))
+	semverFound := false
+	for _, tag := range tags {
+		add(tag)
+		match := semverTagRegex.FindStringSubmatch(tag)
+		if match == nil {
+			continue
+		}
+		semverFound = true
+		prefix, major, minor := match[1], match[2], match[3]
+		add(prefix + major + "." + minor)
+		add(prefix + major)
 	}
 
-	return tagsFromLabel, nil
+	if addLatest && semverFound {
+		add("latest")
+	}
+
+	return expandedTags
 }
 
 func (c *ApplyTags) applyTags(tags []string) error {
@@ -271,6 +623,130 @@ func (c *ApplyTags) applyTags(tags []string) error {
 	return nil
 }
 
+// getPreviousDigests returns the digests the given tags currently point to.
+// Tags that do not exist are mapped to empty string.
+func (c *ApplyTags) getPreviousDigests(tags []string) (map[string]string, error) {
+	previousDigests := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		if _, found := previousDigests[tag]; found {
+			continue
+		}
+		rawManifest, err := c.CliWrappers.SkopeoCli.Inspect(&cliWrappers.SkopeoInspectArgs{
+			ImageRef:   c.imageName + ":" + tag,
+			Raw:        true,
+			RetryTimes: 3,
+		})
+		if err != nil {
+			if strings.Contains(err.Error(), cliWrappers.ManifestUnknownError) {
+				l.Logger.Debugf("Tag '%s' does not exist", tag)
+				previousDigests[tag] = ""
+				continue
+			}
+			return nil, fmt.Errorf("failed to get current digest of tag '%s': %w", tag, err)
+		}
+		// The manifest digest is the digest of the raw manifest content
+		previousDigests[tag] = go_digest.FromString(rawManifest).String()
+		l.Logger.Debugf("Tag '%s' points to %s", tag, previousDigests[tag])
+	}
+	return previousDigests, nil
+}
+
+// checkProtectedTags ensures that protected tags are not moved to another digest or removed.
+// A protected tag which doesn't exist yet or already points to the target digest can be created.
+func (c *ApplyTags) checkProtectedTags(tags, removeTags []string, previousDigests map[string]string) error {
+	var violations []string
+	for _, tag := range tags {
+		previousDigest := previousDigests[tag]
+		if previousDigest == "" || previousDigest == c.Params.Digest {
+			continue
+		}
+		if pattern := c.matchProtectedTag(tag); pattern != "" {
+			violations = append(violations, fmt.Sprintf("tag '%s' matches protected pattern '%s' and already points to %s",
+				tag, pattern, previousDigest))
+		}
+	}
+	for _, tag := range removeTags {
+		if previousDigests[tag] == "" {
+			continue
+		}
+		if pattern := c.matchProtectedTag(tag); pattern != "" {
+			violations = append(violations, fmt.Sprintf("tag '%s' matches protected pattern '%s' and cannot be removed",
+				tag, pattern))
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("refusing to change protected tags of %s:\n%s", c.imageName, strings.Join(violations, "\n"))
+	}
+	return nil
+}
+
+// matchProtectedTag returns the first protected tag pattern matching the given tag or empty string.
+func (c *ApplyTags) matchProtectedTag(tag string) string {
+	for i, regex := range c.protectedTagsRegexes {
+		if regex.MatchString(tag) {
+			return c.Params.ProtectedTags[i]
+		}
+	}
+	return ""
+}
+
+// checkTagsToRemove ensures that none of the tags to remove points to the image being tagged.
+func (c *ApplyTags) checkTagsToRemove(removeTags []string, previousDigests map[string]string) error {
+	for _, tag := range removeTags {
+		if previousDigests[tag] == c.Params.Digest {
+			return fmt.Errorf("cannot remove tag '%s', it points to the image being tagged %s", tag, c.imageByDigest)
+		}
+	}
+	return nil
+}
+
+// removeTags deletes the given tags from the registry. Returns the list of removed tags.
+// Only the tags are deleted, the manifests they point to and their other tags are kept.
+// A tag which does not exist, or was removed in the meantime, is skipped.
+func (c *ApplyTags) removeTags(tags []string, previousDigests map[string]string) ([]string, error) {
+	var removedTags []string
+	var registryAuth *common.RegistryAuth
+	for i, tag := range tags {
+		if slices.Contains(tags[:i], tag) {
+			continue
+		}
+		if previousDigests[tag] == "" {
+			l.Logger.Warnf("Tag '%s' does not exist, skipping removal", tag)
+			continue
+		}
+		if registryAuth == nil {
+			registryAuth = c.registryAuth()
+		}
+
+		l.Logger.Debugf("Removing tag '%s' pointing to %s", tag, previousDigests[tag])
+		err := c.RegistryClient.DeleteTag(&clients.DeleteTagArgs{
+			ImageRef: c.imageName + ":" + tag,
+			Auth:     registryAuth,
+		})
+		if errors.Is(err, clients.ErrTagNotFound) {
+			l.Logger.Warnf("Tag '%s' was removed in the meantime, skipping removal", tag)
+			continue
+		}
+		if err != nil {
+			l.Logger.Errorf("failed to remove '%s' tag: %s", tag, err.Error())
+			return removedTags, err
+		}
+		removedTags = append(removedTags, tag)
+		l.Logger.Infof("Tag '%s' removed", tag)
+	}
+	return removedTags, nil
+}
+
+// registryAuth returns the credential of the image repository, or nil for anonymous access.
+func (c *ApplyTags) registryAuth() *common.RegistryAuth {
+	auth, err := common.SelectRegistryAuthFromDefaultAuthFile(c.imageName)
+	if err != nil {
+		l.Logger.Debugf("Using anonymous access for %s: %s", c.imageName, err.Error())
+		return nil
+	}
+	return auth
+}
+
 func (c *ApplyTags) validateParams() error {
 	// Validate imageName instead of Params.ImageUrl to avoid calling normalizeImageName second time.
 	if !common.IsImageNameValid(c.imageName) {
@@ -282,11 +758,45 @@ func (c *ApplyTags) validateParams() error {
 	}
 
 	for _, tag := range c.Params.NewTags {
+		if isTagTemplate(tag) {
+			// The rendered tag is validated after rendering
+			if _, err := parseTagTemplate(tag); err != nil {
+				return fmt.Errorf("tag template '%s' is invalid: %w", tag, err)
+			}
+			continue
+		}
 		if !common.IsImageTagValid(tag) {
 			return fmt.Errorf("tag '%s' is invalid", tag)
 		}
 	}
 
+	for _, tag := range c.Params.RemoveTags {
+		if !common.IsImageTagValid(tag) {
+			return fmt.Errorf("tag to remove '%s' is invalid", tag)
+		}
+	}
+
+	c.protectedTagsRegexes = nil
+	for _, pattern := range c.Params.ProtectedTags {
+		// The pattern must match the whole tag
+		regex, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("protected tag pattern '%s' is invalid: %w", pattern, err)
+		}
+		c.protectedTagsRegexes = append(c.protectedTagsRegexes, regex)
+	}
+
+	if c.Params.ExpandSemverLatest && !c.Params.ExpandSemver {
+		return errors.New("expand-semver-latest requires expand-semver")
+	}
+
+	switch c.Params.LabelConflict {
+	case "", labelConflictFail, labelConflictMerge:
+	default:
+		return fmt.Errorf("label conflict mode '%s' is invalid, must be one of: %s, %s",
+			c.Params.LabelConflict, labelConflictFail, labelConflictMerge)
+	}
+
 	if c.Params.LabelWithTags != "" && !c.isImageLabelNameValid(c.Params.LabelWithTags) {
 		return fmt.Errorf("image label name '%s' is invalid", c.Params.LabelWithTags)
 	}
@@ -3,11 +3,14 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
 	. "github.com/onsi/gomega"
+	go_digest "github.com/opencontainers/go-digest"
 	"github.com/spf13/cobra"
 )
 
@@ -163,6 +166,36 @@ func Test_validateParams(t *testing.T) {
 			errExpected:  true,
 			errSubstring: "image label name",
 		},
+		{
+			name: "should fail on invalid tag to remove",
+			params: ApplyTagsParams{
+				ImageUrl:   "quay.io/org/image",
+				Digest:     "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				RemoveTags: []string{"tag1", "ta:g2"},
+			},
+			errExpected:  true,
+			errSubstring: "tag to remove 'ta:g2'",
+		},
+		{
+			name: "should fail on invalid protected tag pattern",
+			params: ApplyTagsParams{
+				ImageUrl:      "quay.io/org/image",
+				Digest:        "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				ProtectedTags: []string{"v[0-9+"},
+			},
+			errExpected:  true,
+			errSubstring: "protected tag pattern 'v[0-9+'",
+		},
+		{
+			name: "should fail on invalid label conflict mode",
+			params: ApplyTagsParams{
+				ImageUrl:      "quay.io/org/image",
+				Digest:        "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				LabelConflict: "ignore",
+			},
+			errExpected:  true,
+			errSubstring: "label conflict mode 'ignore'",
+		},
 	}
 	c := &ApplyTags{}
 	for _, tc := range tests {
@@ -535,6 +568,91 @@ func Test_retrieveTagsFromImageLabel(t *testing.T) {
 	})
 }
 
+func Test_retrieveTagsFromImageLabel_multiPlatformIndex(t *testing.T) {
+	g := NewWithT(t)
+
+	const labelName = "more-tags"
+	const imageName = "quay.io/org/image"
+	const indexRef = imageName + "@sha256:12345fedcba"
+	const amd64Digest = "sha256:aaaa"
+	const arm64Digest = "sha256:bbbb"
+	const attestationDigest = "sha256:cccc"
+	indexJson := fmt.Sprintf(`{
+		"mediaType": "application/vnd.oci.image.index.v1+json",
+		"manifests": [
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "%s", "platform": {"os": "linux", "architecture": "amd64"}},
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "%s", "platform": {"os": "linux", "architecture": "arm64", "variant": "v8"}},
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "%s", "platform": {"os": "unknown", "architecture": "unknown"}}
+		]
+	}`, amd64Digest, arm64Digest, attestationDigest)
+
+	var inspectedRefs []string
+	newApplyTags := func(labelConflict string, labels map[string]string) *ApplyTags {
+		inspectedRefs = nil
+		return &ApplyTags{
+			Params: &ApplyTagsParams{LabelWithTags: labelName, LabelConflict: labelConflict},
+			CliWrappers: ApplyTagsCliWrappers{SkopeoCli: &mockSkopeoCli{
+				InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+					if args.Raw {
+						g.Expect(args.ImageRef).To(Equal(indexRef))
+						return indexJson, nil
+					}
+					inspectedRefs = append(inspectedRefs, args.ImageRef)
+					return labels[args.ImageRef], nil
+				},
+			}},
+			imageName:     imageName,
+			imageByDigest: indexRef,
+		}
+	}
+
+	t.Run("should read label from all platform images", func(t *testing.T) {
+		c := newApplyTags(labelConflictFail, map[string]string{
+			imageName + "@" + amd64Digest: "tag1 tag2",
+			imageName + "@" + arm64Digest: "tag2,tag1",
+		})
+
+		tags, err := c.retrieveTagsFromImageLabel(labelName)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(tags).To(Equal([]string{"tag1", "tag2"}))
+		g.Expect(inspectedRefs).To(Equal([]string{imageName + "@" + amd64Digest, imageName + "@" + arm64Digest}))
+	})
+
+	t.Run("should fail if platform images have different label values", func(t *testing.T) {
+		c := newApplyTags(labelConflictFail, map[string]string{
+			imageName + "@" + amd64Digest: "tag1 tag2",
+			imageName + "@" + arm64Digest: "tag1",
+		})
+
+		_, err := c.retrieveTagsFromImageLabel(labelName)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("have different 'more-tags' label values"))
+		g.Expect(err.Error()).To(ContainSubstring("linux/amd64: 'tag1 tag2'"))
+		g.Expect(err.Error()).To(ContainSubstring("linux/arm64/v8: 'tag1'"))
+	})
+
+	t.Run("should fail if label is missing in a platform image", func(t *testing.T) {
+		c := newApplyTags("", map[string]string{
+			imageName + "@" + amd64Digest: "tag1",
+		})
+
+		_, err := c.retrieveTagsFromImageLabel(labelName)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("linux/arm64/v8: ''"))
+	})
+
+	t.Run("should merge different label values", func(t *testing.T) {
+		c := newApplyTags(labelConflictMerge, map[string]string{
+			imageName + "@" + amd64Digest: "tag1 tag2",
+			imageName + "@" + arm64Digest: "tag3 tag1",
+		})
+
+		tags, err := c.retrieveTagsFromImageLabel(labelName)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(tags).To(Equal([]string{"tag1", "tag2", "tag3"}))
+	})
+}
+
 func Test_applyTags(t *testing.T) {
 	g := NewWithT(t)
 
@@ -936,6 +1054,10 @@ func Test_NewApplyTags(t *testing.T) {
 		cmd.Flags().String("image-url", "", "image")
 		cmd.Flags().String("digest", "", "digest")
 		cmd.Flags().StringArray("tags", nil, "tags")
+		cmd.Flags().Bool("expand-semver", false, "expand semver")
+		cmd.Flags().Bool("expand-semver-latest", false, "expand semver latest")
+		cmd.Flags().StringArray("protected-tags", nil, "protected tags")
+		cmd.Flags().StringArray("remove-tags", nil, "remove tags")
 		parseErr := cmd.Flags().Parse([]string{
 			"--image-url", "image",
 			"--digest", "sha256:abcdef1234",
@@ -951,3 +1073,402 @@ func Test_NewApplyTags(t *testing.T) {
 		g.Expect(applyTags.ResultsWriter).ToNot(BeNil())
 	})
 }
+
+func Test_expandSemverTags(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name      string
+		tags      []string
+		addLatest bool
+		expected  []string
+	}{
+		{
+			name:     "should expand semantic version",
+			tags:     []string{"1.2.3"},
+			expected: []string{"1.2.3", "1.2", "1"},
+		},
+		{
+			name:     "should keep v prefix",
+			tags:     []string{"v1.2.3"},
+			expected: []string{"v1.2.3", "v1.2", "v1"},
+		},
+		{
+			name:     "should not expand pre-release and non-semver tags",
+			tags:     []string{"1.2.3-rc1", "1.2", "latest", "01.2.3", "abc"},
+			expected: []string{"1.2.3-rc1", "1.2", "latest", "01.2.3", "abc"},
+		},
+		{
+			name:      "should add latest if opted in",
+			tags:      []string{"commit-sha", "2.0.1"},
+			addLatest: true,
+			expected:  []string{"commit-sha", "2.0.1", "2.0", "2", "latest"},
+		},
+		{
+			name:      "should not add latest if no semantic version found",
+			tags:      []string{"commit-sha"},
+			addLatest: true,
+			expected:  []string{"commit-sha"},
+		},
+		{
+			name:     "should not duplicate tags",
+			tags:     []string{"1.2", "1.2.3", "1.2.4"},
+			expected: []string{"1.2", "1.2.3", "1", "1.2.4"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g.Expect(expandSemverTags(tc.tags, tc.addLatest)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func Test_renderTagTemplates(t *testing.T) {
+	g := NewWithT(t)
+
+	const digest = "sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c"
+	const imageInspectOutput = `{
+		"Digest": "` + digest + `",
+		"Created": "2025-03-04T05:06:07.123Z",
+		"Labels": {"version": "1.4.2", "org.opencontainers.image.revision": "a1b2c3d4e5f6a7b8"},
+		"Env": ["PATH=/usr/bin", "GIT_COMMIT=0123456789abcdef", "EMPTY="]
+	}`
+
+	var _mockSkopeoCli *mockSkopeoCli
+	var c *ApplyTags
+	beforeEach := func() {
+		_mockSkopeoCli = &mockSkopeoCli{
+			InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+				if args.Raw {
+					return `{"mediaType":"application/vnd.oci.image.manifest.v1+json"}`, nil
+				}
+				return imageInspectOutput, nil
+			},
+		}
+		c = &ApplyTags{
+			CliWrappers:   ApplyTagsCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params:        &ApplyTagsParams{Digest: digest},
+			imageName:     "quay.io/org/image",
+			imageByDigest: "quay.io/org/image@" + digest,
+		}
+	}
+
+	t.Run("should not inspect image if there are no templates", func(t *testing.T) {
+		beforeEach()
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			return "", errors.New("should not be called")
+		}
+
+		tags, err := c.renderTagTemplates([]string{"tag1", "tag2"})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(tags).To(Equal([]string{"tag1", "tag2"}))
+	})
+
+	t.Run("should render templates", func(t *testing.T) {
+		beforeEach()
+
+		tags, err := c.renderTagTemplates([]string{
+			"static",
+			"{{ .Labels.version }}",
+			`{{ trunc 7 (index .Labels "org.opencontainers.image.revision") }}`,
+			"{{ .Created.Format \"20060102\" }}-{{ trunc 7 .Env.GIT_COMMIT }}",
+			"sha-{{ trunc 12 .Digest }}",
+			`{{ replace "." "-" .Labels.version }}`,
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(tags).To(Equal([]string{
+			"static",
+			"1.4.2",
+			"a1b2c3d",
+			"20250304-0123456",
+			"sha-806a5df5f709",
+			"1-4-2",
+		}))
+	})
+
+	t.Run("should fail on missing variable", func(t *testing.T) {
+		beforeEach()
+
+		_, err := c.renderTagTemplates([]string{"{{ .Labels.missing }}"})
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("failed to render tag template"))
+	})
+
+	t.Run("should fail if image inspect fails", func(t *testing.T) {
+		beforeEach()
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			return "", errors.New("inspect failed")
+		}
+
+		_, err := c.renderTagTemplates([]string{"{{ .Labels.version }}"})
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+func Test_Run_tagTemplatesAndSemver(t *testing.T) {
+	g := NewWithT(t)
+
+	const digest = "sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c"
+
+	var _mockSkopeoCli *mockSkopeoCli
+	var _mockResultsWriter *mockResultsWriter
+	var c *ApplyTags
+	var createdTags []string
+	beforeEach := func(labels string) {
+		createdTags = nil
+		_mockSkopeoCli = &mockSkopeoCli{
+			InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+				if args.Raw {
+					return `{"mediaType":"application/vnd.oci.image.manifest.v1+json"}`, nil
+				}
+				return `{"Labels": ` + labels + `}`, nil
+			},
+			CopyFunc: func(args *cliwrappers.SkopeoCopyArgs) error {
+				createdTags = append(createdTags, args.DestinationImage)
+				return nil
+			},
+		}
+		_mockResultsWriter = &mockResultsWriter{}
+		c = &ApplyTags{
+			CliWrappers: ApplyTagsCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params: &ApplyTagsParams{
+				ImageUrl: "quay.io/org/image",
+				Digest:   digest,
+			},
+			ResultsWriter: _mockResultsWriter,
+		}
+	}
+
+	t.Run("should apply rendered and expanded tags", func(t *testing.T) {
+		beforeEach(`{"version": "v3.1.4"}`)
+		c.Params.NewTags = []string{"{{ .Labels.version }}", "build"}
+		c.Params.ExpandSemver = true
+		c.Params.ExpandSemverLatest = true
+
+		err := c.Run()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Results.Tags).To(Equal([]string{"v3.1.4", "v3.1", "v3", "build", "latest"}))
+		g.Expect(createdTags).To(Equal([]string{
+			"quay.io/org/image:v3.1.4",
+			"quay.io/org/image:v3.1",
+			"quay.io/org/image:v3",
+			"quay.io/org/image:build",
+			"quay.io/org/image:latest",
+		}))
+	})
+
+	t.Run("should reject invalid rendered tag", func(t *testing.T) {
+		beforeEach(`{"version": "1.0/beta"}`)
+		c.Params.NewTags = []string{"{{ .Labels.version }}"}
+
+		err := c.Run()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("tag '1.0/beta' is invalid"))
+		g.Expect(createdTags).To(BeEmpty())
+	})
+
+	t.Run("should reject invalid tag template", func(t *testing.T) {
+		beforeEach(`{}`)
+		c.Params.NewTags = []string{"{{ .Labels.version"}
+
+		err := c.Run()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("tag template"))
+	})
+
+	t.Run("should reject expand-semver-latest without expand-semver", func(t *testing.T) {
+		beforeEach(`{}`)
+		c.Params.NewTags = []string{"1.0.0"}
+		c.Params.ExpandSemverLatest = true
+
+		err := c.Run()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("expand-semver-latest requires expand-semver"))
+	})
+}
+
+func Test_Run_protectedAndRemovedTags(t *testing.T) {
+	g := NewWithT(t)
+
+	const digest = "sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c"
+	const currentManifest = `{"mediaType":"application/vnd.oci.image.manifest.v1+json"}`
+	currentDigest := go_digest.FromString(currentManifest).String()
+
+	var _mockSkopeoCli *mockSkopeoCli
+	var _mockRegistryClient *mockRegistryTagsClient
+	var c *ApplyTags
+	var createdTags, deletedRefs []string
+	// existingTags maps tag to the raw manifest it points to
+	beforeEach := func(existingTags map[string]string) {
+		createdTags = nil
+		deletedRefs = nil
+		_mockSkopeoCli = &mockSkopeoCli{
+			InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+				g.Expect(args.Raw).To(BeTrue())
+				_, tag, _ := strings.Cut(args.ImageRef, "quay.io/org/image:")
+				if manifest, found := existingTags[tag]; found {
+					return manifest, nil
+				}
+				return "", errors.New("exit status 1: reading manifest " + tag + ": manifest unknown")
+			},
+			CopyFunc: func(args *cliwrappers.SkopeoCopyArgs) error {
+				createdTags = append(createdTags, args.DestinationImage)
+				return nil
+			},
+		}
+		_mockRegistryClient = &mockRegistryTagsClient{
+			DeleteTagFunc: func(args *clients.DeleteTagArgs) error {
+				deletedRefs = append(deletedRefs, args.ImageRef)
+				return nil
+			},
+		}
+		c = &ApplyTags{
+			CliWrappers:    ApplyTagsCliWrappers{SkopeoCli: _mockSkopeoCli},
+			RegistryClient: _mockRegistryClient,
+			Params: &ApplyTagsParams{
+				ImageUrl:      "quay.io/org/image",
+				Digest:        digest,
+				ProtectedTags: []string{`v[0-9]+\.[0-9]+\.[0-9]+`},
+			},
+			ResultsWriter: &mockResultsWriter{},
+		}
+	}
+
+	t.Run("should create new protected tag and report previous digests", func(t *testing.T) {
+		beforeEach(map[string]string{"latest": currentManifest})
+		c.Params.NewTags = []string{"v1.2.3", "latest"}
+
+		err := c.Run()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(createdTags).To(Equal([]string{"quay.io/org/image:v1.2.3", "quay.io/org/image:latest"}))
+		g.Expect(c.Results.PreviousDigests).To(Equal(map[string]string{"v1.2.3": "", "latest": currentDigest}))
+	})
+
+	t.Run("should refuse to move protected tag", func(t *testing.T) {
+		beforeEach(map[string]string{"v1.2.3": currentManifest, "latest": currentManifest})
+		c.Params.NewTags = []string{"latest", "v1.2.3"}
+
+		err := c.Run()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring(
+			"tag 'v1.2.3' matches protected pattern 'v[0-9]+\\.[0-9]+\\.[0-9]+' and already points to " + currentDigest))
+		g.Expect(createdTags).To(BeEmpty())
+	})
+
+	t.Run("should match protected pattern against whole tag", func(t *testing.T) {
+		beforeEach(map[string]string{"v1.2.3-rc": currentManifest})
+		c.Params.NewTags = []string{"v1.2.3-rc"}
+
+		err := c.Run()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(createdTags).To(Equal([]string{"quay.io/org/image:v1.2.3-rc"}))
+	})
+
+	t.Run("should remove tags", func(t *testing.T) {
+		beforeEach(map[string]string{"pr-1": currentManifest})
+		c.Params.NewTags = []string{"latest"}
+		c.Params.RemoveTags = []string{"pr-1", "pr-2"}
+
+		err := c.Run()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(deletedRefs).To(Equal([]string{"quay.io/org/image:pr-1"}))
+		g.Expect(c.Results.RemovedTags).To(Equal([]string{"pr-1"}))
+		g.Expect(c.Results.PreviousDigests).To(HaveKeyWithValue("pr-1", currentDigest))
+		g.Expect(c.Results.PreviousDigests).To(HaveKeyWithValue("pr-2", ""))
+	})
+
+	t.Run("should remove only the tag if other tags point to the same manifest", func(t *testing.T) {
+		beforeEach(map[string]string{"pr-1": currentManifest, "pr-1-rebuild": currentManifest, "v1.0.0": currentManifest})
+		c.Params.RemoveTags = []string{"pr-1"}
+
+		err := c.Run()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(deletedRefs).To(Equal([]string{"quay.io/org/image:pr-1"}))
+		g.Expect(c.Results.RemovedTags).To(Equal([]string{"pr-1"}))
+	})
+
+	t.Run("should remove a tag given twice once", func(t *testing.T) {
+		beforeEach(map[string]string{"pr-1": currentManifest, "pr-1-rebuild": currentManifest})
+		c.Params.RemoveTags = []string{"pr-1", "pr-1-rebuild", "pr-1"}
+
+		err := c.Run()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(deletedRefs).To(Equal([]string{"quay.io/org/image:pr-1", "quay.io/org/image:pr-1-rebuild"}))
+		g.Expect(c.Results.RemovedTags).To(Equal([]string{"pr-1", "pr-1-rebuild"}))
+	})
+
+	t.Run("should skip tag removed in the meantime", func(t *testing.T) {
+		beforeEach(map[string]string{"pr-1": currentManifest, "pr-2": currentManifest})
+		_mockRegistryClient.DeleteTagFunc = func(args *clients.DeleteTagArgs) error {
+			deletedRefs = append(deletedRefs, args.ImageRef)
+			if args.ImageRef == "quay.io/org/image:pr-1" {
+				return fmt.Errorf("failed to delete tag: %w", clients.ErrTagNotFound)
+			}
+			return nil
+		}
+		c.Params.RemoveTags = []string{"pr-1", "pr-2"}
+
+		err := c.Run()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(deletedRefs).To(Equal([]string{"quay.io/org/image:pr-1", "quay.io/org/image:pr-2"}))
+		g.Expect(c.Results.RemovedTags).To(Equal([]string{"pr-2"}))
+	})
+
+	t.Run("should fail if tag removal fails", func(t *testing.T) {
+		beforeEach(map[string]string{"pr-1": currentManifest})
+		_mockRegistryClient.DeleteTagFunc = func(args *clients.DeleteTagArgs) error {
+			return errors.New("failed to delete tag: UNSUPPORTED")
+		}
+		c.Params.RemoveTags = []string{"pr-1"}
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("UNSUPPORTED")))
+	})
+
+	t.Run("should refuse to remove protected tag", func(t *testing.T) {
+		beforeEach(map[string]string{"v1.0.0": currentManifest})
+		c.Params.RemoveTags = []string{"v1.0.0"}
+
+		err := c.Run()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("tag 'v1.0.0' matches protected pattern"))
+		g.Expect(deletedRefs).To(BeEmpty())
+	})
+
+	t.Run("should refuse to remove tag pointing to the tagged image", func(t *testing.T) {
+		beforeEach(map[string]string{"old": "{}"})
+		c.Params.Digest = go_digest.FromString("{}").String()
+		c.Params.NewTags = []string{"latest"}
+		c.Params.RemoveTags = []string{"old"}
+
+		err := c.Run()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("cannot remove tag 'old', it points to the image being tagged"))
+		g.Expect(createdTags).To(BeEmpty())
+		g.Expect(deletedRefs).To(BeEmpty())
+	})
+
+	t.Run("should refuse to create and remove the same tag", func(t *testing.T) {
+		beforeEach(nil)
+		c.Params.NewTags = []string{"latest"}
+		c.Params.RemoveTags = []string{"latest"}
+
+		err := c.Run()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("both created and removed"))
+	})
+
+	t.Run("should fail if tag lookup fails", func(t *testing.T) {
+		beforeEach(nil)
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			return "", errors.New("unauthorized")
+		}
+		c.Params.NewTags = []string{"latest"}
+
+		err := c.Run()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("failed to get current digest of tag 'latest'"))
+		g.Expect(createdTags).To(BeEmpty())
+	})
+}
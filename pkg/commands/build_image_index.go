@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
+	"text/tabwriter"
 
+	"github.com/containerd/platforms"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
@@ -28,7 +32,7 @@ var BuildImageIndexParamsConfig = map[string]common.Parameter{
 		ShortName:  "",
 		EnvVarName: "KBC_BUILD_IMAGE_INDEX_IMAGES",
 		TypeKind:   reflect.Slice,
-		Usage:      "List of Image Manifests to be referenced by the Image Index.",
+		Usage:      "List of Image Manifests to be referenced by the Image Index.\nImage Indexes are flattened: their child manifests are added directly.",
 		Required:   true,
 	},
 	"tls-verify": {
@@ -90,6 +94,15 @@ var BuildImageIndexParamsConfig = map[string]common.Parameter{
 		TypeKind:   reflect.String,
 		Usage:      "Write the image reference (with digest) into this file.",
 	},
+	"required-platforms": {
+		Name:       "required-platforms",
+		ShortName:  "",
+		EnvVarName: "KBC_BUILD_IMAGE_INDEX_REQUIRED_PLATFORMS",
+		TypeKind:   reflect.Slice,
+		Usage: "Platforms (os/arch[/variant], e.g. linux/amd64 linux/arm/v7) that the image index must contain.\n" +
+			"Each platform must be provided by exactly one input image, no other platforms are allowed,\n" +
+			"and the platform of each image config must match the platform it is declared for.",
+	},
 	"result-path-images": {
 		Name:       "result-path-images",
 		ShortName:  "",
@@ -111,6 +124,7 @@ type BuildImageIndexParams struct {
 	ResultPathImageURL    string   `paramName:"result-path-image-url"`
 	ResultPathImageRef    string   `paramName:"result-path-image-ref"`
 	ResultPathImages      string   `paramName:"result-path-images"`
+	RequiredPlatforms     []string `paramName:"required-platforms"`
 }
 
 type BuildImageIndexResults struct {
@@ -126,6 +140,8 @@ type BuildImageIndexResults struct {
 
 type BuildImageIndexCliWrappers struct {
 	BuildahCli cliwrappers.BuildahCliInterface
+	// Only set when required platforms are validated
+	SkopeoCli cliwrappers.SkopeoCliInterface
 }
 
 type BuildImageIndex struct {
@@ -167,6 +183,14 @@ func (c *BuildImageIndex) initCliWrappers() error {
 	}
 	c.CliWrappers.BuildahCli = buildahCli
 
+	if len(c.Params.RequiredPlatforms) > 0 {
+		skopeoCli, err := cliwrappers.NewSkopeoCli(executor)
+		if err != nil {
+			return err
+		}
+		c.CliWrappers.SkopeoCli = skopeoCli
+	}
+
 	return nil
 }
 
@@ -224,6 +248,31 @@ func (c *BuildImageIndex) Run() error {
 }
 
 func (c *BuildImageIndex) buildManifestIndex() error {
+	// Normalize the image references to strip the tag when both tag and digest are present.
+	// buildah does not support the repository:tag@digest format unless the image is available locally.
+	normalizedRefs := make([]string, 0, len(c.Params.Images))
+	inputIndexes := make([]*ociv1.Index, 0, len(c.Params.Images))
+	for _, imageRef := range c.Params.Images {
+		normalizedRef := common.NormalizeImageRefWithDigest(imageRef)
+		inputIndex, err := c.inspectInputImage(normalizedRef)
+		if err != nil {
+			return err
+		}
+		normalizedRefs = append(normalizedRefs, normalizedRef)
+		inputIndexes = append(inputIndexes, inputIndex)
+	}
+
+	if len(c.Params.RequiredPlatforms) > 0 {
+		l.Logger.Info("Validating required platforms")
+		platformImages, err := c.inspectPlatformImages(normalizedRefs, inputIndexes)
+		if err != nil {
+			return err
+		}
+		if err := checkRequiredPlatforms(c.Params.RequiredPlatforms, platformImages); err != nil {
+			return err
+		}
+	}
+
 	l.Logger.Infof("Creating manifest list: %s", c.Params.Image)
 	err := c.CliWrappers.BuildahCli.ManifestCreate(&cliwrappers.BuildahManifestCreateArgs{
 		ManifestName: c.Params.Image,
@@ -232,20 +281,29 @@ func (c *BuildImageIndex) buildManifestIndex() error {
 		return err
 	}
 
-	for _, imageRef := range c.Params.Images {
-		// Normalize the image reference to strip the tag when both tag and digest are present.
-		// buildah does not support the repository:tag@digest format unless the image is available locally.
-		normalizedRef := common.NormalizeImageRefWithDigest(imageRef)
+	for i, imageRef := range c.Params.Images {
+		normalizedRef := normalizedRefs[i]
+		inputIndex := inputIndexes[i]
 
 		// Special case: single image with always-build-index=false
 		if !c.Params.AlwaysBuildIndex && len(c.Params.Images) == 1 {
 			l.Logger.Info("Skipping image index generation. Returning results for single image.")
 			c.images = []string{normalizedRef}
+			if inputIndex != nil {
+				c.images = indexChildRefs(common.GetImageName(normalizedRef), inputIndex)
+			}
 			c.imageDigest = common.GetImageDigest(normalizedRef)
 			c.imageURL = common.GetImageURL(imageRef)
 			return nil
 		}
 
+		if inputIndex != nil {
+			if err := c.addIndexChildren(normalizedRef, inputIndex); err != nil {
+				return err
+			}
+			continue
+		}
+
 		l.Logger.Infof("Adding image to manifest: %s", normalizedRef)
 		err = c.CliWrappers.BuildahCli.ManifestAdd(&cliwrappers.BuildahManifestAddArgs{
 			ManifestName: c.Params.Image,
@@ -318,6 +376,276 @@ func (c *BuildImageIndex) buildManifestIndex() error {
 	return nil
 }
 
+// inspectInputImage fetches the manifest of an input image from the registry.
+// Returns the parsed index if the input is an image index (e.g. the per-arch
+// index of a dual-compression build), or nil if it is a single image manifest.
+func (c *BuildImageIndex) inspectInputImage(imageRef string) (*ociv1.Index, error) {
+	manifestJson, err := c.CliWrappers.BuildahCli.ManifestInspect(&cliwrappers.BuildahManifestInspectArgs{
+		ManifestName: "docker://" + imageRef,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect image %s: %w", imageRef, err)
+	}
+	return parseInputIndex(manifestJson)
+}
+
+// parseInputIndex returns the parsed index if the manifest JSON describes an
+// image index or a docker manifest list, or nil for an image manifest.
+func parseInputIndex(manifestJson string) (*ociv1.Index, error) {
+	var index ociv1.Index
+	if err := json.Unmarshal([]byte(manifestJson), &index); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest JSON: %w", err)
+	}
+	// mediaType is optional in OCI indexes, an image manifest never has a manifests field
+	if !isIndexMediaType(index.MediaType) && (index.MediaType != "" || len(index.Manifests) == 0) {
+		return nil, nil
+	}
+	for _, m := range index.Manifests {
+		if isIndexMediaType(m.MediaType) {
+			return nil, fmt.Errorf("nested image index %s is not supported", m.Digest)
+		}
+	}
+	return &index, nil
+}
+
+func isIndexMediaType(mediaType string) bool {
+	return strings.Contains(mediaType, ".index.") || strings.Contains(mediaType, ".manifest.list.")
+}
+
+// indexChildRefs returns digest-pinned references of all the index children in the given repository.
+func indexChildRefs(repository string, index *ociv1.Index) []string {
+	refs := make([]string, 0, len(index.Manifests))
+	for _, m := range index.Manifests {
+		refs = append(refs, repository+"@"+m.Digest.String())
+	}
+	return refs
+}
+
+// addIndexChildren flattens an input image index into the index being built.
+// Each child manifest is added on its own, keeping the platform and annotations
+// (e.g. the zstd compression annotation of dual-compression builds) of the input
+// index entry. Adding the input index with --all would lose the annotations.
+func (c *BuildImageIndex) addIndexChildren(indexRef string, index *ociv1.Index) error {
+	l.Logger.Infof("Flattening image index %s into the manifest (%d manifests)", indexRef, len(index.Manifests))
+
+	repository := common.GetImageName(indexRef)
+	for _, m := range index.Manifests {
+		childRef := repository + "@" + m.Digest.String()
+
+		addArgs := &cliwrappers.BuildahManifestAddArgs{
+			ManifestName: c.Params.Image,
+			ImageRef:     "docker://" + childRef,
+		}
+		if m.Platform != nil {
+			addArgs.Os = m.Platform.OS
+			addArgs.Arch = m.Platform.Architecture
+			addArgs.Variant = m.Platform.Variant
+		}
+
+		l.Logger.Infof("Adding image to manifest: %s", childRef)
+		if err := c.CliWrappers.BuildahCli.ManifestAdd(addArgs); err != nil {
+			return fmt.Errorf("failed to add image %s from index %s: %w", childRef, indexRef, err)
+		}
+
+		if len(m.Annotations) == 0 {
+			continue
+		}
+		annotations := make([]string, 0, len(m.Annotations))
+		for key, value := range m.Annotations {
+			annotations = append(annotations, key+"="+value)
+		}
+		slices.Sort(annotations)
+
+		err := c.CliWrappers.BuildahCli.ManifestAnnotate(&cliwrappers.BuildahManifestAnnotateArgs{
+			ManifestName:   c.Params.Image,
+			InstanceDigest: m.Digest.String(),
+			Annotations:    annotations,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to annotate image %s from index %s: %w", childRef, indexRef, err)
+		}
+	}
+
+	return nil
+}
+
+// platformImage is an image manifest referenced by the index inputs.
+type platformImage struct {
+	// input is the --images entry the manifest comes from
+	input string
+	// ref is the digest-pinned reference of the image manifest
+	ref string
+	// declared is the normalized platform the manifest is listed for in the input index,
+	// or the config platform if the input is the image manifest itself
+	declared string
+	// config is the normalized platform from the image config
+	config string
+}
+
+// skopeoImageInfo is a subset of the skopeo inspect output.
+type skopeoImageInfo struct {
+	Digest       string `json:"Digest"`
+	Os           string `json:"Os"`
+	Architecture string `json:"Architecture"`
+	Variant      string `json:"Variant"`
+}
+
+// platform returns the normalized platform of the image config.
+func (i *skopeoImageInfo) platform() string {
+	return platforms.Format(platforms.Normalize(ociv1.Platform{
+		OS:           i.Os,
+		Architecture: i.Architecture,
+		Variant:      i.Variant,
+	}))
+}
+
+// inspectPlatformImages collects the declared and the config platforms of all the image manifests
+// referenced by the inputs. inputIndexes holds the parsed index of each input, nil for image manifests.
+func (c *BuildImageIndex) inspectPlatformImages(inputs []string, inputIndexes []*ociv1.Index) ([]platformImage, error) {
+	var images []platformImage
+	for i, input := range inputs {
+		if inputIndexes[i] == nil {
+			info, err := c.inspectImageConfigPlatform(input)
+			if err != nil {
+				return nil, err
+			}
+			configPlatform := info.platform()
+			images = append(images, platformImage{
+				input:    input,
+				ref:      common.GetImageName(input) + "@" + info.Digest,
+				declared: configPlatform,
+				config:   configPlatform,
+			})
+			continue
+		}
+
+		repository := common.GetImageName(input)
+		for _, m := range inputIndexes[i].Manifests {
+			childRef := repository + "@" + m.Digest.String()
+			info, err := c.inspectImageConfigPlatform(childRef)
+			if err != nil {
+				return nil, err
+			}
+			configPlatform := info.platform()
+			declaredPlatform := configPlatform
+			if m.Platform != nil {
+				declaredPlatform = platforms.Format(platforms.Normalize(*m.Platform))
+			}
+			images = append(images, platformImage{
+				input:    input,
+				ref:      childRef,
+				declared: declaredPlatform,
+				config:   configPlatform,
+			})
+		}
+	}
+	return images, nil
+}
+
+func (c *BuildImageIndex) inspectImageConfigPlatform(imageRef string) (*skopeoImageInfo, error) {
+	output, err := c.CliWrappers.SkopeoCli.Inspect(&cliwrappers.SkopeoInspectArgs{
+		ImageRef:   imageRef,
+		RetryTimes: 3,
+		NoTags:     true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect image %s: %w", imageRef, err)
+	}
+	info := &skopeoImageInfo{}
+	if err := json.Unmarshal([]byte(output), info); err != nil {
+		return nil, fmt.Errorf("failed to parse inspect output of %s: %w", imageRef, err)
+	}
+	return info, nil
+}
+
+// checkRequiredPlatforms verifies that each of the required platforms is provided by exactly one
+// input, that no other platform is present and that every image config matches its declared platform.
+// On violation, returns an error with a table of the expected and the actual platforms.
+func checkRequiredPlatforms(requiredPlatforms []string, images []platformImage) error {
+	required := make([]string, 0, len(requiredPlatforms))
+	for _, platform := range requiredPlatforms {
+		normalized, err := normalizePlatform(platform)
+		if err != nil {
+			return fmt.Errorf("invalid required platform: %w", err)
+		}
+		required = append(required, normalized)
+	}
+
+	// platform => inputs that provide it, an input may provide a platform with several manifests
+	providers := make(map[string][]string)
+	var unexpected []string
+	var mismatches []platformImage
+	for _, image := range images {
+		if !slices.Contains(providers[image.declared], image.input) {
+			providers[image.declared] = append(providers[image.declared], image.input)
+		}
+		if !slices.Contains(required, image.declared) && !slices.Contains(unexpected, image.declared) {
+			unexpected = append(unexpected, image.declared)
+		}
+		if image.declared != image.config {
+			mismatches = append(mismatches, image)
+		}
+	}
+	slices.Sort(unexpected)
+
+	valid := len(unexpected) == 0 && len(mismatches) == 0
+	for _, platform := range required {
+		if len(providers[platform]) != 1 {
+			valid = false
+		}
+	}
+	if valid {
+		return nil
+	}
+
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PLATFORM\tEXPECTED\tACTUAL\tSTATUS\tINPUTS")
+	for _, platform := range slices.Concat(required, unexpected) {
+		expected := 0
+		if slices.Contains(required, platform) {
+			expected = 1
+		}
+		actual := len(providers[platform])
+		status := "ok"
+		switch {
+		case expected == 0:
+			status = "unexpected"
+		case actual == 0:
+			status = "missing"
+		case actual > 1:
+			status = "duplicate"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", platform, expected, actual, status, strings.Join(providers[platform], ","))
+	}
+	_ = w.Flush()
+
+	if len(mismatches) > 0 {
+		table.WriteString("\n")
+		w = tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "IMAGE\tDECLARED PLATFORM\tCONFIG PLATFORM")
+		for _, image := range mismatches {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", image.ref, image.declared, image.config)
+		}
+		_ = w.Flush()
+	}
+
+	return fmt.Errorf("image index platforms do not match the required platforms:\n%s", table.String())
+}
+
+// normalizePlatform parses an os/arch[/variant] platform and returns it in normalized form,
+// e.g. "linux/aarch64" => "linux/arm64".
+func normalizePlatform(platform string) (string, error) {
+	if n := strings.Count(platform, "/"); n < 1 || n > 2 {
+		return "", fmt.Errorf("platform '%s' must be in os/arch[/variant] format", platform)
+	}
+	parsed, err := platforms.Parse(platform)
+	if err != nil {
+		return "", fmt.Errorf("platform '%s' is invalid: %w", platform, err)
+	}
+	return platforms.Format(platforms.Normalize(parsed)), nil
+}
+
 func (c *BuildImageIndex) validateParams() error {
 	imageName := common.GetImageName(c.Params.Image)
 	if !common.IsImageNameValid(imageName) {
@@ -357,6 +685,18 @@ func (c *BuildImageIndex) validateParams() error {
 		}
 	}
 
+	seenPlatforms := make(map[string]bool)
+	for _, platform := range c.Params.RequiredPlatforms {
+		normalized, err := normalizePlatform(platform)
+		if err != nil {
+			return fmt.Errorf("invalid required platform: %w", err)
+		}
+		if seenPlatforms[normalized] {
+			return fmt.Errorf("duplicate required platform: %s", platform)
+		}
+		seenPlatforms[normalized] = true
+	}
+
 	validFormats := map[string]bool{"oci": true, "docker": true}
 	if !validFormats[c.Params.BuildahFormat] {
 		return fmt.Errorf("format must be 'oci' or 'docker', got '%s'", c.Params.BuildahFormat)
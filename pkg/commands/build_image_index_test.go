@@ -1,9 +1,13 @@
 package commands
 
 import (
+	"fmt"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 )
 
 func Test_BuildImageIndex_validateParams(t *testing.T) {
@@ -139,6 +143,38 @@ func Test_BuildImageIndex_validateParams(t *testing.T) {
 			},
 			errExpected: false,
 		},
+		{
+			name: "should allow required platforms",
+			params: BuildImageIndexParams{
+				Image:             "quay.io/org/myapp:latest",
+				Images:            []string{"quay.io/org/myapp@" + validDigest1},
+				BuildahFormat:     "oci",
+				RequiredPlatforms: []string{"linux/amd64", "linux/arm/v7"},
+			},
+			errExpected: false,
+		},
+		{
+			name: "should fail on required platform without architecture",
+			params: BuildImageIndexParams{
+				Image:             "quay.io/org/myapp:latest",
+				Images:            []string{"quay.io/org/myapp@" + validDigest1},
+				BuildahFormat:     "oci",
+				RequiredPlatforms: []string{"amd64"},
+			},
+			errExpected:  true,
+			errSubstring: "must be in os/arch\\[/variant\\] format",
+		},
+		{
+			name: "should fail on duplicate required platforms",
+			params: BuildImageIndexParams{
+				Image:             "quay.io/org/myapp:latest",
+				Images:            []string{"quay.io/org/myapp@" + validDigest1},
+				BuildahFormat:     "oci",
+				RequiredPlatforms: []string{"linux/arm64", "linux/aarch64"},
+			},
+			errExpected:  true,
+			errSubstring: "duplicate required platform",
+		},
 		{
 			name: "should fail on duplicate images",
 			params: BuildImageIndexParams{
@@ -341,3 +377,368 @@ func Test_BuildImageIndex_extractPlatformImages(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseInputIndex(t *testing.T) {
+	g := NewWithT(t)
+
+	const digest1 = "sha256:aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa1"
+
+	tests := []struct {
+		name          string
+		manifestJson  string
+		expectIndex   bool
+		expectedCount int
+		errSubstring  string
+	}{
+		{
+			name:         "should return nil for OCI image manifest",
+			manifestJson: `{"mediaType": "application/vnd.oci.image.manifest.v1+json", "layers": []}`,
+		},
+		{
+			name:         "should return nil for docker image manifest",
+			manifestJson: `{"mediaType": "application/vnd.docker.distribution.manifest.v2+json"}`,
+		},
+		{
+			name: "should parse OCI image index",
+			manifestJson: `{
+				"mediaType": "application/vnd.oci.image.index.v1+json",
+				"manifests": [{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + digest1 + `"}]
+			}`,
+			expectIndex:   true,
+			expectedCount: 1,
+		},
+		{
+			name: "should parse docker manifest list",
+			manifestJson: `{
+				"mediaType": "application/vnd.docker.distribution.manifest.list.v2+json",
+				"manifests": [{"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "digest": "` + digest1 + `"}]
+			}`,
+			expectIndex:   true,
+			expectedCount: 1,
+		},
+		{
+			name:          "should parse OCI image index without media type",
+			manifestJson:  `{"manifests": [{"digest": "` + digest1 + `"}]}`,
+			expectIndex:   true,
+			expectedCount: 1,
+		},
+		{
+			name: "should reject nested image index",
+			manifestJson: `{
+				"mediaType": "application/vnd.oci.image.index.v1+json",
+				"manifests": [{"mediaType": "application/vnd.oci.image.index.v1+json", "digest": "` + digest1 + `"}]
+			}`,
+			errSubstring: "nested image index",
+		},
+		{
+			name:         "should error on invalid JSON",
+			manifestJson: `{invalid json`,
+			errSubstring: "failed to parse manifest JSON",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			index, err := parseInputIndex(tc.manifestJson)
+
+			if tc.errSubstring != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.errSubstring))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			if tc.expectIndex {
+				g.Expect(index).ToNot(BeNil())
+				g.Expect(index.Manifests).To(HaveLen(tc.expectedCount))
+			} else {
+				g.Expect(index).To(BeNil())
+			}
+		})
+	}
+}
+
+func Test_BuildImageIndex_buildManifestIndex_flattensIndexes(t *testing.T) {
+	g := NewWithT(t)
+
+	const (
+		amd64Gzip = "sha256:aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa1"
+		amd64Zstd = "sha256:aaa222aaa222aaa222aaa222aaa222aaa222aaa222aaa222aaa222aaa222aaa2"
+		arm64Gzip = "sha256:bbb111bbb111bbb111bbb111bbb111bbb111bbb111bbb111bbb111bbb111bbb1"
+		arm64Zstd = "sha256:bbb222bbb222bbb222bbb222bbb222bbb222bbb222bbb222bbb222bbb222bbb2"
+		amd64Idx  = "sha256:ccc111ccc111ccc111ccc111ccc111ccc111ccc111ccc111ccc111ccc111ccc1"
+		arm64Idx  = "sha256:ccc222ccc222ccc222ccc222ccc222ccc222ccc222ccc222ccc222ccc222ccc2"
+		indexSha  = "sha256:ddd111ddd111ddd111ddd111ddd111ddd111ddd111ddd111ddd111ddd111ddd1"
+	)
+
+	perArchIndex := func(arch, gzipDigest, zstdDigest string) string {
+		return `{
+			"mediaType": "application/vnd.oci.image.index.v1+json",
+			"manifests": [
+				{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + gzipDigest + `",
+				 "platform": {"os": "linux", "architecture": "` + arch + `"}},
+				{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + zstdDigest + `",
+				 "platform": {"os": "linux", "architecture": "` + arch + `"},
+				 "annotations": {"io.github.containers.compression.zstd": "true"}}
+			]
+		}`
+	}
+
+	var addedArgs []cliwrappers.BuildahManifestAddArgs
+	var annotateArgs []cliwrappers.BuildahManifestAnnotateArgs
+
+	buildahCli := &mockBuildahCli{
+		ManifestInspectFunc: func(args *cliwrappers.BuildahManifestInspectArgs) (string, error) {
+			switch args.ManifestName {
+			case "docker://quay.io/org/myapp@" + amd64Idx:
+				return perArchIndex("amd64", amd64Gzip, amd64Zstd), nil
+			case "docker://quay.io/org/myapp@" + arm64Idx:
+				return perArchIndex("arm64", arm64Gzip, arm64Zstd), nil
+			case "quay.io/org/myapp:latest":
+				return `{
+					"mediaType": "application/vnd.oci.image.index.v1+json",
+					"manifests": [
+						{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + amd64Gzip + `"},
+						{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + amd64Zstd + `"},
+						{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + arm64Gzip + `"},
+						{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + arm64Zstd + `"}
+					]
+				}`, nil
+			}
+			return "", fmt.Errorf("unexpected inspect: %s", args.ManifestName)
+		},
+		ManifestAddFunc: func(args *cliwrappers.BuildahManifestAddArgs) error {
+			addedArgs = append(addedArgs, *args)
+			return nil
+		},
+		ManifestAnnotateFunc: func(args *cliwrappers.BuildahManifestAnnotateArgs) error {
+			annotateArgs = append(annotateArgs, *args)
+			return nil
+		},
+		ManifestPushFunc: func(args *cliwrappers.BuildahManifestPushArgs) (string, error) {
+			return indexSha, nil
+		},
+	}
+
+	c := &BuildImageIndex{
+		Params: &BuildImageIndexParams{
+			Image:            "quay.io/org/myapp:latest",
+			Images:           []string{"quay.io/org/myapp@" + amd64Idx, "quay.io/org/myapp@" + arm64Idx},
+			BuildahFormat:    "oci",
+			AlwaysBuildIndex: true,
+		},
+		CliWrappers: BuildImageIndexCliWrappers{BuildahCli: buildahCli},
+		imageName:   "quay.io/org/myapp",
+	}
+
+	err := c.buildManifestIndex()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(addedArgs).To(Equal([]cliwrappers.BuildahManifestAddArgs{
+		{ManifestName: "quay.io/org/myapp:latest", ImageRef: "docker://quay.io/org/myapp@" + amd64Gzip, Os: "linux", Arch: "amd64"},
+		{ManifestName: "quay.io/org/myapp:latest", ImageRef: "docker://quay.io/org/myapp@" + amd64Zstd, Os: "linux", Arch: "amd64"},
+		{ManifestName: "quay.io/org/myapp:latest", ImageRef: "docker://quay.io/org/myapp@" + arm64Gzip, Os: "linux", Arch: "arm64"},
+		{ManifestName: "quay.io/org/myapp:latest", ImageRef: "docker://quay.io/org/myapp@" + arm64Zstd, Os: "linux", Arch: "arm64"},
+	}))
+	g.Expect(annotateArgs).To(Equal([]cliwrappers.BuildahManifestAnnotateArgs{
+		{ManifestName: "quay.io/org/myapp:latest", InstanceDigest: amd64Zstd, Annotations: []string{"io.github.containers.compression.zstd=true"}},
+		{ManifestName: "quay.io/org/myapp:latest", InstanceDigest: arm64Zstd, Annotations: []string{"io.github.containers.compression.zstd=true"}},
+	}))
+	g.Expect(c.imageDigest).To(Equal(indexSha))
+	g.Expect(c.images).To(Equal([]string{
+		"quay.io/org/myapp@" + amd64Gzip,
+		"quay.io/org/myapp@" + amd64Zstd,
+		"quay.io/org/myapp@" + arm64Gzip,
+		"quay.io/org/myapp@" + arm64Zstd,
+	}))
+}
+
+func Test_BuildImageIndex_buildManifestIndex_singleIndexSkipsIndexCreation(t *testing.T) {
+	g := NewWithT(t)
+
+	const (
+		gzipDigest  = "sha256:aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa1"
+		zstdDigest  = "sha256:aaa222aaa222aaa222aaa222aaa222aaa222aaa222aaa222aaa222aaa222aaa2"
+		indexDigest = "sha256:ccc111ccc111ccc111ccc111ccc111ccc111ccc111ccc111ccc111ccc111ccc1"
+	)
+
+	buildahCli := &mockBuildahCli{
+		ManifestInspectFunc: func(args *cliwrappers.BuildahManifestInspectArgs) (string, error) {
+			return `{
+				"mediaType": "application/vnd.oci.image.index.v1+json",
+				"manifests": [
+					{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + gzipDigest + `"},
+					{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + zstdDigest + `"}
+				]
+			}`, nil
+		},
+		ManifestAddFunc: func(args *cliwrappers.BuildahManifestAddArgs) error {
+			return fmt.Errorf("unexpected manifest add")
+		},
+	}
+
+	c := &BuildImageIndex{
+		Params: &BuildImageIndexParams{
+			Image:            "quay.io/org/myapp:latest",
+			Images:           []string{"quay.io/org/myapp-amd64:v1@" + indexDigest},
+			BuildahFormat:    "oci",
+			AlwaysBuildIndex: false,
+		},
+		CliWrappers: BuildImageIndexCliWrappers{BuildahCli: buildahCli},
+		imageName:   "quay.io/org/myapp",
+	}
+
+	err := c.buildManifestIndex()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(c.imageDigest).To(Equal(indexDigest))
+	g.Expect(c.images).To(Equal([]string{
+		"quay.io/org/myapp-amd64@" + gzipDigest,
+		"quay.io/org/myapp-amd64@" + zstdDigest,
+	}))
+}
+
+func Test_checkRequiredPlatforms(t *testing.T) {
+	g := NewWithT(t)
+
+	image := func(input, ref, declared, config string) platformImage {
+		return platformImage{input: input, ref: ref, declared: declared, config: config}
+	}
+
+	tests := []struct {
+		name             string
+		required         []string
+		images           []platformImage
+		errSubstrings    []string
+		notErrSubstrings []string
+	}{
+		{
+			name:     "should pass when each required platform is provided once",
+			required: []string{"linux/amd64", "linux/arm64"},
+			images: []platformImage{
+				image("repo@sha256:a", "repo@sha256:a", "linux/amd64", "linux/amd64"),
+				image("repo@sha256:b", "repo@sha256:b", "linux/arm64", "linux/arm64"),
+			},
+		},
+		{
+			name:     "should count an input with several manifests of one platform once",
+			required: []string{"linux/amd64"},
+			images: []platformImage{
+				image("repo@sha256:idx", "repo@sha256:gzip", "linux/amd64", "linux/amd64"),
+				image("repo@sha256:idx", "repo@sha256:zstd", "linux/amd64", "linux/amd64"),
+			},
+		},
+		{
+			name:     "should normalize required platforms",
+			required: []string{"linux/aarch64/v8"},
+			images: []platformImage{
+				image("repo@sha256:b", "repo@sha256:b", "linux/arm64", "linux/arm64"),
+			},
+		},
+		{
+			name:     "should fail on missing platform",
+			required: []string{"linux/amd64", "linux/arm64"},
+			images: []platformImage{
+				image("repo@sha256:a", "repo@sha256:a", "linux/amd64", "linux/amd64"),
+			},
+			errSubstrings: []string{
+				"do not match the required platforms",
+				"PLATFORM", "EXPECTED", "ACTUAL",
+				"linux/arm64  1         0       missing",
+			},
+		},
+		{
+			name:     "should fail on duplicate platform",
+			required: []string{"linux/amd64"},
+			images: []platformImage{
+				image("repo@sha256:a", "repo@sha256:a", "linux/amd64", "linux/amd64"),
+				image("repo@sha256:b", "repo@sha256:b", "linux/amd64", "linux/amd64"),
+			},
+			errSubstrings: []string{"duplicate", "repo@sha256:a,repo@sha256:b"},
+		},
+		{
+			name:     "should fail on unexpected variant",
+			required: []string{"linux/arm/v7"},
+			images: []platformImage{
+				image("repo@sha256:a", "repo@sha256:a", "linux/arm/v6", "linux/arm/v6"),
+			},
+			errSubstrings: []string{"linux/arm/v7", "missing", "linux/arm/v6", "unexpected"},
+		},
+		{
+			name:     "should fail on config platform mismatch",
+			required: []string{"linux/arm64"},
+			images: []platformImage{
+				image("repo@sha256:idx", "repo@sha256:a", "linux/arm64", "linux/amd64"),
+			},
+			errSubstrings:    []string{"DECLARED PLATFORM", "CONFIG PLATFORM", "repo@sha256:a  linux/arm64"},
+			notErrSubstrings: []string{"missing"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkRequiredPlatforms(tc.required, tc.images)
+
+			if len(tc.errSubstrings) == 0 {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(HaveOccurred())
+			for _, substring := range tc.errSubstrings {
+				g.Expect(err.Error()).To(ContainSubstring(substring))
+			}
+			for _, substring := range tc.notErrSubstrings {
+				g.Expect(err.Error()).ToNot(ContainSubstring(substring))
+			}
+		})
+	}
+}
+
+func Test_BuildImageIndex_inspectPlatformImages(t *testing.T) {
+	g := NewWithT(t)
+
+	const (
+		manifestDigest = "sha256:aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa111aaa1"
+		childDigest    = "sha256:bbb111bbb111bbb111bbb111bbb111bbb111bbb111bbb111bbb111bbb111bbb1"
+		indexDigest    = "sha256:ccc111ccc111ccc111ccc111ccc111ccc111ccc111ccc111ccc111ccc111ccc1"
+	)
+
+	skopeoCli := &mockSkopeoCli{
+		InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			switch args.ImageRef {
+			case "quay.io/org/myapp-amd64:v1":
+				return `{"Digest": "` + manifestDigest + `", "Os": "linux", "Architecture": "amd64"}`, nil
+			case "quay.io/org/myapp-arm64@" + childDigest:
+				return `{"Digest": "` + childDigest + `", "Os": "linux", "Architecture": "amd64"}`, nil
+			}
+			return "", fmt.Errorf("unexpected inspect: %s", args.ImageRef)
+		},
+	}
+
+	index, err := parseInputIndex(`{
+		"mediaType": "application/vnd.oci.image.index.v1+json",
+		"manifests": [{"digest": "` + childDigest + `", "platform": {"os": "linux", "architecture": "arm64", "variant": "v8"}}]
+	}`)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	c := &BuildImageIndex{CliWrappers: BuildImageIndexCliWrappers{SkopeoCli: skopeoCli}}
+
+	images, err := c.inspectPlatformImages(
+		[]string{"quay.io/org/myapp-amd64:v1", "quay.io/org/myapp-arm64@" + indexDigest},
+		[]*ociv1.Index{nil, index},
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(images).To(Equal([]platformImage{
+		{
+			input:    "quay.io/org/myapp-amd64:v1",
+			ref:      "quay.io/org/myapp-amd64@" + manifestDigest,
+			declared: "linux/amd64",
+			config:   "linux/amd64",
+		},
+		{
+			input:    "quay.io/org/myapp-arm64@" + indexDigest,
+			ref:      "quay.io/org/myapp-arm64@" + childDigest,
+			declared: "linux/arm64",
+			config:   "linux/amd64",
+		},
+	}))
+}
@@ -0,0 +1,641 @@
+package commands
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/containerd/platforms"
+	"github.com/containers/image/v5/docker/reference"
+	go_digest "github.com/opencontainers/go-digest"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+const sourceImageTagSuffix = "-source"
+
+// Layout of the pushed source image.
+//
+// The source image is an OCI image with one layer per source component, in this order:
+//
+//	| Type                    | Layer content                                                          |
+//	|-------------------------|------------------------------------------------------------------------|
+//	| source                  | the source tree without .git, under src/                               |
+//	| prefetched-dependencies | a package manager directory of the prefetch output, under deps/<name>/ |
+//	| base-image-source       | a layer of the source image of a base image, copied as is              |
+//
+// Every layer has these annotations:
+//
+//	| Annotation                     | Value                                                         |
+//	|--------------------------------|---------------------------------------------------------------|
+//	| org.opencontainers.image.title | the component name, base image source layers keep their own   |
+//	| dev.konflux-ci.source.type     | the type from the table above                                 |
+//	| dev.konflux-ci.source.name     | source, the package manager name or the base image reference  |
+//	| dev.konflux-ci.source.image    | base-image-source only, the source image the layer comes from |
+//
+// Layers are written with fixed ownership and mtime, so the same inputs give the same image.
+const (
+	sourceComponentTypeSource       = "source"
+	sourceComponentTypePrefetched   = "prefetched-dependencies"
+	sourceComponentTypeBaseImageSrc = "base-image-source"
+
+	sourceTypeAnnotation        = "dev.konflux-ci.source.type"
+	sourceNameAnnotation        = "dev.konflux-ci.source.name"
+	sourceImageAnnotation       = "dev.konflux-ci.source.image"
+	sourceBinaryImageAnnotation = "dev.konflux-ci.source.binary-image"
+
+	// prefetchDepsDir is the directory of the prefetch-dependencies output dir
+	// with a subdirectory per package manager, e.g. deps/gomod
+	prefetchDepsDir = "deps"
+)
+
+var BuildSourceParamsConfig = map[string]common.Parameter{
+	"output-ref": {
+		Name:       "output-ref",
+		EnvVarName: "KBC_BUILD_SOURCE_OUTPUT_REF",
+		TypeKind:   reflect.String,
+		Usage:      "Reference of the binary image the sources belong to, with a tag, e.g. quay.io/org/app:v1. The source image is pushed to the same repository.",
+		Required:   true,
+	},
+	"source-image-tag": {
+		Name:       "source-image-tag",
+		EnvVarName: "KBC_BUILD_SOURCE_SOURCE_IMAGE_TAG",
+		TypeKind:   reflect.String,
+		Usage:      "Tag of the source image. Defaults to the tag of --output-ref with the '-source' suffix.",
+	},
+	"source-dir": {
+		Name:       "source-dir",
+		EnvVarName: "KBC_BUILD_SOURCE_SOURCE_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Path to the source tree, e.g. a git-clone checkout. The .git directories are not included.",
+		Required:   true,
+	},
+	"prefetch-output-dir": {
+		Name:       "prefetch-output-dir",
+		EnvVarName: "KBC_BUILD_SOURCE_PREFETCH_OUTPUT_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Path to the output directory of prefetch-dependencies. Each package manager directory under deps/ is added as a layer.",
+	},
+	"resolved-base-images-file": {
+		Name:       "resolved-base-images-file",
+		EnvVarName: "KBC_BUILD_SOURCE_RESOLVED_BASE_IMAGES_FILE",
+		TypeKind:   reflect.String,
+		Usage: "Path to the resolved base images file written by the build (--resolved-base-images-output). " +
+			"The layers of the source images of the base images are added, if found as <repository>:sha256-<digest hex>.src, " +
+			"or as <repository>:<version>-<release>-source from the version and release labels of the base image.",
+	},
+	"platform": {
+		Name:       "platform",
+		EnvVarName: "KBC_BUILD_SOURCE_PLATFORM",
+		TypeKind:   reflect.String,
+		Usage:      "Platform of the sources (os/arch[/variant], e.g. linux/amd64), required if --output-ref is an image index.",
+	},
+	"result-path-image-ref": {
+		Name:       "result-path-image-ref",
+		EnvVarName: "KBC_BUILD_SOURCE_RESULT_PATH_IMAGE_REF",
+		TypeKind:   reflect.String,
+		Usage:      "Write digested image reference of the pushed source image into this file.",
+	},
+}
+
+type BuildSourceParams struct {
+	OutputRef              string `paramName:"output-ref"`
+	SourceImageTag         string `paramName:"source-image-tag"`
+	SourceDir              string `paramName:"source-dir"`
+	PrefetchOutputDir      string `paramName:"prefetch-output-dir"`
+	ResolvedBaseImagesFile string `paramName:"resolved-base-images-file"`
+	Platform               string `paramName:"platform"`
+	ResultPathImageRef     string `paramName:"result-path-image-ref"`
+}
+
+type BuildSourceResults struct {
+	ImageRef   string                 `json:"image_ref"`
+	Components []SourceImageComponent `json:"components"`
+}
+
+// SourceImageComponent describes a layer of the source image.
+type SourceImageComponent struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	// Digest is the digest of the layer blob
+	Digest string `json:"digest"`
+	// SourceImage is the image the layer was copied from, for base image sources
+	SourceImage string `json:"source_image,omitempty"`
+}
+
+type BuildSource struct {
+	Params         *BuildSourceParams
+	RegistryClient clients.OCIRegistryClientInterface
+	Results        BuildSourceResults
+	ResultsWriter  common.ResultsWriterInterface
+}
+
+func NewBuildSource(cmd *cobra.Command) (*BuildSource, error) {
+	params := &BuildSourceParams{}
+	if err := common.ParseParameters(cmd, BuildSourceParamsConfig, params); err != nil {
+		return nil, err
+	}
+	return &BuildSource{
+		Params:         params,
+		RegistryClient: clients.NewOCIRegistryClient(),
+		ResultsWriter:  common.NewResultsWriter(),
+	}, nil
+}
+
+// sourceLayer is a layer of the source image with the component it contains.
+type sourceLayer struct {
+	component SourceImageComponent
+	layer     clients.ImageLayer
+	// diffID is the digest of the uncompressed layer content
+	diffID go_digest.Digest
+}
+
+func (c *BuildSource) Run() error {
+	common.LogParameters(BuildSourceParamsConfig, c.Params)
+
+	destinationImage, err := c.sourceImageRef()
+	if err != nil {
+		return err
+	}
+	if err := c.validateParams(); err != nil {
+		return err
+	}
+
+	platform, err := c.builtImagePlatform()
+	if err != nil {
+		return err
+	}
+
+	// Layers are written to files and streamed to the registry, they can be large
+	layersDir, err := os.MkdirTemp("", "build-source-")
+	if err != nil {
+		return fmt.Errorf("failed to create layers directory: %w", err)
+	}
+	defer os.RemoveAll(layersDir)
+
+	var layers []sourceLayer
+
+	treeLayer, err := c.createSourceTreeLayer(layersDir)
+	if err != nil {
+		return err
+	}
+	layers = append(layers, treeLayer)
+
+	if c.Params.PrefetchOutputDir != "" {
+		prefetchLayers, err := c.createPrefetchLayers(layersDir)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, prefetchLayers...)
+	}
+
+	if c.Params.ResolvedBaseImagesFile != "" {
+		baseImageLayers, err := c.collectBaseImageSourceLayers(platform)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, baseImageLayers...)
+	}
+
+	config, err := sourceImageConfig(layers, platform)
+	if err != nil {
+		return err
+	}
+
+	l.Logger.Debugf("Select registry authentication for %s", destinationImage)
+	registryAuth, err := common.SelectRegistryAuthFromDefaultAuthFile(destinationImage)
+	if err != nil {
+		return fmt.Errorf("cannot select registry authentication for image %s: %w", destinationImage, err)
+	}
+
+	imageLayers := make([]clients.ImageLayer, 0, len(layers))
+	for _, layer := range layers {
+		imageLayers = append(imageLayers, layer.layer)
+		c.Results.Components = append(c.Results.Components, layer.component)
+	}
+	imageRef, err := c.RegistryClient.PushImage(&clients.PushImageArgs{
+		DestinationImage: destinationImage,
+		Config:           config,
+		Layers:           imageLayers,
+		Annotations:      map[string]string{sourceBinaryImageAnnotation: c.Params.OutputRef},
+		Auth:             registryAuth,
+	})
+	if err != nil {
+		return fmt.Errorf("error on pushing source image %s: %w", destinationImage, err)
+	}
+	l.Logger.Infof("Source image with %d layers is pushed to %s", len(imageLayers), imageRef)
+
+	c.Results.ImageRef = imageRef
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	if c.Params.ResultPathImageRef != "" {
+		if err := c.ResultsWriter.WriteResultString(imageRef, c.Params.ResultPathImageRef); err != nil {
+			return fmt.Errorf("error on writing result image ref: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *BuildSource) validateParams() error {
+	info, err := os.Stat(c.Params.SourceDir)
+	if err != nil {
+		return fmt.Errorf("source directory '%s' is not accessible: %w", c.Params.SourceDir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("source directory '%s' is not a directory", c.Params.SourceDir)
+	}
+	if c.Params.PrefetchOutputDir != "" {
+		if _, err := os.Stat(c.Params.PrefetchOutputDir); err != nil {
+			return fmt.Errorf("prefetch output directory '%s' is not accessible: %w", c.Params.PrefetchOutputDir, err)
+		}
+	}
+	if c.Params.Platform != "" {
+		if _, err := normalizePlatform(c.Params.Platform); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourceImageRef returns the reference the source image is pushed to,
+// e.g. quay.io/org/app:v1-source for the output ref quay.io/org/app:v1.
+func (c *BuildSource) sourceImageRef() (string, error) {
+	imageName := common.GetImageName(c.Params.OutputRef)
+	if !common.IsImageNameValid(imageName) {
+		return "", fmt.Errorf("output ref '%s' is invalid", c.Params.OutputRef)
+	}
+
+	tag := c.Params.SourceImageTag
+	if tag == "" {
+		outputTag := common.GetImageTag(c.Params.OutputRef)
+		if outputTag == "" {
+			return "", fmt.Errorf("output ref '%s' has no tag, set the source image tag", c.Params.OutputRef)
+		}
+		tag = outputTag + sourceImageTagSuffix
+	}
+	if !common.IsImageTagValid(tag) {
+		return "", fmt.Errorf("source image tag '%s' is invalid", tag)
+	}
+	return imageName + ":" + tag, nil
+}
+
+// createSourceTreeLayer archives the source tree under src/.
+func (c *BuildSource) createSourceTreeLayer(layersDir string) (sourceLayer, error) {
+	l.Logger.Infof("Archiving source tree %s", c.Params.SourceDir)
+	return createDirectoryLayer(c.Params.SourceDir, "src", sourceComponentTypeSource, sourceComponentTypeSource, layersDir)
+}
+
+// createPrefetchLayers archives each package manager directory of the prefetch output under deps/<name>/.
+func (c *BuildSource) createPrefetchLayers(layersDir string) ([]sourceLayer, error) {
+	depsDir := filepath.Join(c.Params.PrefetchOutputDir, prefetchDepsDir)
+	entries, err := os.ReadDir(depsDir)
+	if os.IsNotExist(err) {
+		l.Logger.Infof("No prefetched dependencies in %s", c.Params.PrefetchOutputDir)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prefetched dependencies: %w", err)
+	}
+
+	var layers []sourceLayer
+	// ReadDir returns the entries sorted by name
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		l.Logger.Infof("Archiving prefetched %s dependencies", entry.Name())
+		layer, err := createDirectoryLayer(filepath.Join(depsDir, entry.Name()), prefetchDepsDir+"/"+entry.Name(),
+			sourceComponentTypePrefetched, entry.Name(), layersDir)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// createDirectoryLayer archives the directory as a reproducible gzipped layer in a file of layersDir.
+// Entries have the Unix epoch as mtime, there is no commit timestamp for all components.
+func createDirectoryLayer(dir, prefix, componentType, name, layersDir string) (sourceLayer, error) {
+	paths, err := common.ListTreePaths(dir)
+	if err != nil {
+		return sourceLayer{}, fmt.Errorf("failed to list files of %s: %w", dir, err)
+	}
+	file, err := os.CreateTemp(layersDir, "layer-*.tar.gz")
+	if err != nil {
+		return sourceLayer{}, fmt.Errorf("failed to create layer file: %w", err)
+	}
+	defer file.Close()
+
+	// The digest and the size of the blob are computed while it is written
+	digester := go_digest.Canonical.Digester()
+	size := &byteCounter{}
+	err = common.WriteReproducibleTarGz(io.MultiWriter(file, digester.Hash(), size), dir, paths,
+		common.TarGzOptions{Prefix: prefix, ModTime: time.Unix(0, 0)})
+	if err != nil {
+		return sourceLayer{}, fmt.Errorf("failed to archive %s: %w", dir, err)
+	}
+	if err := file.Close(); err != nil {
+		return sourceLayer{}, fmt.Errorf("failed to write layer file: %w", err)
+	}
+	diffID, err := gzipDiffID(file.Name())
+	if err != nil {
+		return sourceLayer{}, fmt.Errorf("failed to read layer of %s: %w", dir, err)
+	}
+
+	return sourceLayer{
+		component: SourceImageComponent{
+			Type:   componentType,
+			Name:   name,
+			Digest: digester.Digest().String(),
+		},
+		layer: clients.ImageLayer{
+			Descriptor: ociv1.Descriptor{
+				MediaType: ociv1.MediaTypeImageLayerGzip,
+				Digest:    digester.Digest(),
+				Size:      size.count,
+				Annotations: map[string]string{
+					ociv1.AnnotationTitle: name,
+					sourceTypeAnnotation:  componentType,
+					sourceNameAnnotation:  name,
+				},
+			},
+			Path: file.Name(),
+		},
+		diffID: diffID,
+	}, nil
+}
+
+// byteCounter counts the bytes written to it.
+type byteCounter struct {
+	count int64
+}
+
+func (b *byteCounter) Write(p []byte) (int, error) {
+	b.count += int64(len(p))
+	return len(p), nil
+}
+
+// gzipDiffID returns the digest of the uncompressed content of the gzipped file.
+func gzipDiffID(path string) (go_digest.Digest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress layer: %w", err)
+	}
+	defer gzipReader.Close()
+	return go_digest.FromReader(gzipReader)
+}
+
+// ociLayerMediaType returns the OCI media type of a Docker v2s2 layer, the source image is an OCI image.
+func ociLayerMediaType(mediaType string) string {
+	switch mediaType {
+	case "application/vnd.docker.image.rootfs.diff.tar.gzip":
+		return ociv1.MediaTypeImageLayerGzip
+	case "application/vnd.docker.image.rootfs.diff.tar":
+		return ociv1.MediaTypeImageLayer
+	}
+	return mediaType
+}
+
+// builtImagePlatform returns the platform of the binary image the sources belong to, from its image config.
+// An image index is resolved to the manifest of the platform given by --platform, it is an error if none is given.
+func (c *BuildSource) builtImagePlatform() (*ociv1.Platform, error) {
+	var platform *ociv1.Platform
+	if c.Params.Platform != "" {
+		parsed := platforms.Normalize(platforms.MustParse(c.Params.Platform))
+		platform = &parsed
+	}
+	config, err := c.imageConfig(c.Params.OutputRef, platform)
+	if errors.Is(err, clients.ErrPlatformNotSelected) {
+		return nil, fmt.Errorf("output ref %s is an image index, select the platform of the sources with --platform", c.Params.OutputRef)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image config of %s: %w", c.Params.OutputRef, err)
+	}
+	return &ociv1.Platform{OS: config.OS, Architecture: config.Architecture, Variant: config.Variant}, nil
+}
+
+// collectBaseImageSourceLayers copies the layers of the source images of the base images.
+// Base images and their source images which are image indexes are resolved to the manifest of the platform.
+// Base images without a source image are skipped with a warning.
+func (c *BuildSource) collectBaseImageSourceLayers(platform *ociv1.Platform) ([]sourceLayer, error) {
+	baseImages, err := readResolvedBaseImages(c.Params.ResolvedBaseImagesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var layers []sourceLayer
+	for _, baseImage := range baseImages {
+		sourceImage, manifest := c.findBaseImageSource(baseImage, platform)
+		if manifest == nil {
+			l.Logger.Warnf("No source image found for base image %s, its sources are not included", baseImage)
+			continue
+		}
+		l.Logger.Infof("Copying %d layers of source image %s of base image %s", len(manifest.Layers), sourceImage, baseImage)
+
+		// The layers are copied by the registry client, only their diff IDs are read from the image config
+		auth := c.pullAuth(sourceImage)
+		diffIDs, err := c.imageDiffIDs(sourceImage, manifest, auth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source image %s: %w", sourceImage, err)
+		}
+		for i, descriptor := range manifest.Layers {
+			annotations := map[string]string{}
+			for key, value := range descriptor.Annotations {
+				annotations[key] = value
+			}
+			annotations[sourceTypeAnnotation] = sourceComponentTypeBaseImageSrc
+			annotations[sourceNameAnnotation] = baseImage
+			annotations[sourceImageAnnotation] = sourceImage
+
+			layers = append(layers, sourceLayer{
+				component: SourceImageComponent{
+					Type:        sourceComponentTypeBaseImageSrc,
+					Name:        baseImage,
+					Digest:      descriptor.Digest.String(),
+					SourceImage: sourceImage,
+				},
+				layer: clients.ImageLayer{
+					Descriptor: ociv1.Descriptor{
+						MediaType:   ociLayerMediaType(descriptor.MediaType),
+						Digest:      descriptor.Digest,
+						Size:        descriptor.Size,
+						Annotations: annotations,
+					},
+					SourceImage: sourceImage,
+					SourceAuth:  auth,
+				},
+				diffID: diffIDs[i],
+			})
+		}
+	}
+	return layers, nil
+}
+
+// imageDiffIDs returns the diff IDs of the layers of the image manifest, listed in the image config.
+func (c *BuildSource) imageDiffIDs(imageRef string, manifest *ociv1.Manifest, auth *common.RegistryAuth) ([]go_digest.Digest, error) {
+	content, err := c.RegistryClient.GetBlob(&clients.GetBlobArgs{
+		ImageRef: imageRef,
+		Digest:   manifest.Config.Digest,
+		Auth:     auth,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var config ociv1.Image
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse image config: %w", err)
+	}
+	if len(config.RootFS.DiffIDs) != len(manifest.Layers) {
+		return nil, fmt.Errorf("image config has %d diff IDs for %d layers", len(config.RootFS.DiffIDs), len(manifest.Layers))
+	}
+	return config.RootFS.DiffIDs, nil
+}
+
+// findBaseImageSource returns the digested reference and the manifest of the source image
+// of the base image, or a nil manifest if there is none.
+func (c *BuildSource) findBaseImageSource(baseImage string, platform *ociv1.Platform) (string, *ociv1.Manifest) {
+	for _, candidate := range c.baseImageSourceCandidates(baseImage, platform) {
+		manifest, imageRef, err := c.RegistryClient.GetManifest(&clients.GetManifestArgs{
+			ImageRef: candidate,
+			Platform: platform,
+			Auth:     c.pullAuth(candidate),
+		})
+		if errors.Is(err, clients.ErrManifestNotFound) {
+			l.Logger.Debugf("Source image %s of base image %s does not exist", candidate, baseImage)
+			continue
+		}
+		if err != nil {
+			l.Logger.Warnf("Cannot read source image %s of base image %s: %s", candidate, baseImage, err.Error())
+			continue
+		}
+		return imageRef, manifest
+	}
+	return "", nil
+}
+
+// baseImageSourceCandidates returns the references the source image of the base image may have:
+// the Konflux tag <digest>.src, then <version>-<release>-source from the labels of the base image.
+func (c *BuildSource) baseImageSourceCandidates(baseImage string, platform *ociv1.Platform) []string {
+	imageName := common.GetImageName(baseImage)
+	imageDigest := common.GetImageDigest(baseImage)
+	candidates := []string{imageName + ":" + strings.Replace(imageDigest, ":", "-", 1) + ".src"}
+
+	config, err := c.imageConfig(baseImage, platform)
+	if err != nil {
+		l.Logger.Warnf("Cannot read labels of base image %s: %s", baseImage, err.Error())
+		return candidates
+	}
+	labels := config.Config.Labels
+	if version, release := labels["version"], labels["release"]; version != "" && release != "" {
+		tag := version + "-" + release + sourceImageTagSuffix
+		if common.IsImageTagValid(tag) {
+			candidates = append(candidates, imageName+":"+tag)
+		}
+	}
+	return candidates
+}
+
+// imageConfig fetches the image config of the image, resolved to the manifest of the platform.
+func (c *BuildSource) imageConfig(imageRef string, platform *ociv1.Platform) (*ociv1.Image, error) {
+	auth := c.pullAuth(imageRef)
+	manifest, manifestRef, err := c.RegistryClient.GetManifest(&clients.GetManifestArgs{ImageRef: imageRef, Platform: platform, Auth: auth})
+	if err != nil {
+		return nil, err
+	}
+	content, err := c.RegistryClient.GetBlob(&clients.GetBlobArgs{
+		ImageRef: manifestRef,
+		Digest:   manifest.Config.Digest,
+		Auth:     auth,
+	})
+	if err != nil {
+		return nil, err
+	}
+	config := &ociv1.Image{}
+	if err := json.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("failed to parse image config: %w", err)
+	}
+	return config, nil
+}
+
+// pullAuth returns the credential of the image, or nil for anonymous access.
+func (c *BuildSource) pullAuth(imageRef string) *common.RegistryAuth {
+	auth, err := common.SelectRegistryAuthFromDefaultAuthFile(imageRef)
+	if err != nil {
+		l.Logger.Debugf("Using anonymous access for %s: %s", imageRef, err.Error())
+		return nil
+	}
+	return auth
+}
+
+// readResolvedBaseImages returns the unique digested references of the resolved base images file,
+// lines of "<input-ref> <resolved-ref>". Base images without a digest have no source image and are skipped.
+func readResolvedBaseImages(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resolved base images: %w", err)
+	}
+	defer file.Close()
+
+	var baseImages []string
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid line %d of resolved base images file: %q", lineNumber, scanner.Text())
+		}
+		resolved := fields[1]
+		if _, err := reference.ParseNormalizedNamed(resolved); err != nil || common.GetImageDigest(resolved) == "" {
+			l.Logger.Debugf("Skipping base image %s without digest", resolved)
+			continue
+		}
+		if !seen[resolved] {
+			seen[resolved] = true
+			baseImages = append(baseImages, resolved)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read resolved base images: %w", err)
+	}
+	return baseImages, nil
+}
+
+// sourceImageConfig returns the image config listing the diff IDs of the layers.
+// The platform is the one of the built image. The creation time is left out,
+// so the same layers give the same config.
+func sourceImageConfig(layers []sourceLayer, platform *ociv1.Platform) ([]byte, error) {
+	config := ociv1.Image{
+		Platform: *platform,
+		RootFS:   ociv1.RootFS{Type: "layers", DiffIDs: []go_digest.Digest{}},
+	}
+	for _, layer := range layers {
+		config.RootFS.DiffIDs = append(config.RootFS.DiffIDs, layer.diffID)
+	}
+	return json.Marshal(config)
+}
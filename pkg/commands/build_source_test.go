@@ -0,0 +1,408 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	go_digest "github.com/opencontainers/go-digest"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+)
+
+func Test_BuildSource_sourceImageRef(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name        string
+		outputRef   string
+		tag         string
+		expected    string
+		errContains string
+	}{
+		{
+			name:      "should append source suffix to output tag",
+			outputRef: "quay.io/org/app:v1",
+			expected:  "quay.io/org/app:v1-source",
+		},
+		{
+			name:      "should use configured tag",
+			outputRef: "quay.io/org/app:v1@sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b",
+			tag:       "sources",
+			expected:  "quay.io/org/app:sources",
+		},
+		{
+			name:        "should fail without tag",
+			outputRef:   "quay.io/org/app@sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b",
+			errContains: "has no tag",
+		},
+		{
+			name:        "should fail with invalid tag",
+			outputRef:   "quay.io/org/app:v1",
+			tag:         "not/valid",
+			errContains: "source image tag 'not/valid' is invalid",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &BuildSource{Params: &BuildSourceParams{OutputRef: tc.outputRef, SourceImageTag: tc.tag}}
+			ref, err := c.sourceImageRef()
+			if tc.errContains != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.errContains)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ref).To(Equal(tc.expected))
+		})
+	}
+}
+
+func Test_readResolvedBaseImages(t *testing.T) {
+	g := NewWithT(t)
+
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	path := filepath.Join(t.TempDir(), "resolved-base-images")
+	content := "registry.io/ubi9:latest registry.io/ubi9:latest@" + digest + "\n" +
+		"\n" +
+		"builder registry.io/ubi9:latest@" + digest + "\n" +
+		"oci-archive:base.tar base\n"
+	g.Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+
+	baseImages, err := readResolvedBaseImages(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(baseImages).To(Equal([]string{"registry.io/ubi9:latest@" + digest}))
+
+	g.Expect(os.WriteFile(path, []byte("only-one-field\n"), 0644)).To(Succeed())
+	_, err = readResolvedBaseImages(path)
+	g.Expect(err).To(MatchError(ContainSubstring("invalid line 1")))
+}
+
+func Test_BuildSource_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	const baseDigest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	const baseImage = "registry.io/ubi9@" + baseDigest
+	const sourceDigest = "sha256:5d1c8a5a2f1ee7b3a9b0d1c2e3f405162738495a6b7c8d9e0f1a2b3c4d5e6f70"
+	const pushedRef = "localhost.reg.io/app@sha256:a7c0071906a9c6b654760e44a1fc8226f8268c70848148f19c35b02788b272a5"
+
+	workDir := t.TempDir()
+	t.Setenv("HOME", workDir)
+	g.Expect(os.Mkdir(filepath.Join(workDir, ".docker"), 0755)).To(Succeed())
+	const authConfig = `{"auths":{"localhost.reg.io":{"auth":"token"}}}`
+	g.Expect(os.WriteFile(filepath.Join(workDir, ".docker", "config.json"), []byte(authConfig), 0644)).To(Succeed())
+
+	sourceDir := filepath.Join(workDir, "source")
+	g.Expect(os.MkdirAll(filepath.Join(sourceDir, ".git"), 0755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(sourceDir, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n"), 0644)).To(Succeed())
+
+	prefetchDir := filepath.Join(workDir, "prefetch-output")
+	for _, path := range []string{"deps/gomod/cache/download/mod.zip", "deps/pip/requests.tar.gz", "bom.json"} {
+		g.Expect(os.MkdirAll(filepath.Dir(filepath.Join(prefetchDir, path)), 0755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(prefetchDir, path), []byte(path), 0644)).To(Succeed())
+	}
+
+	resolvedBaseImages := filepath.Join(workDir, "resolved-base-images")
+	g.Expect(os.WriteFile(resolvedBaseImages, []byte("registry.io/ubi9:latest "+baseImage+"\n"), 0644)).To(Succeed())
+
+	var baseSourceLayer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&baseSourceLayer)
+	_, _ = gzipWriter.Write([]byte("rpm sources"))
+	g.Expect(gzipWriter.Close()).To(Succeed())
+	baseSourceLayerDigest := go_digest.FromBytes(baseSourceLayer.Bytes())
+	baseConfig := []byte(`{"config":{"Labels":{"version":"9.4","release":"12"}}}`)
+	baseSourceConfig := []byte(`{"rootfs":{"type":"layers","diff_ids":["` + go_digest.FromString("rpm sources").String() + `"]}}`)
+	builtConfig := []byte(`{"architecture":"arm64","os":"linux","variant":"v8"}`)
+	builtPlatform := &ociv1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	builtManifestRef := "localhost.reg.io/app@" + go_digest.FromString("built manifest").String()
+
+	var registryClient *mockOCIRegistryClient
+	var c *BuildSource
+
+	beforeEach := func() {
+		registryClient = &mockOCIRegistryClient{}
+		registryClient.GetManifestFunc = func(args *clients.GetManifestArgs) (*ociv1.Manifest, string, error) {
+			if strings.HasPrefix(args.ImageRef, "registry.io/") {
+				g.Expect(args.Platform).To(Equal(builtPlatform))
+			}
+			switch args.ImageRef {
+			case "localhost.reg.io/app:v1":
+				g.Expect(args.Platform).To(BeNil())
+				return &ociv1.Manifest{Config: ociv1.Descriptor{Digest: go_digest.FromBytes(builtConfig)}}, builtManifestRef, nil
+			case "localhost.reg.io/app:index":
+				if args.Platform == nil {
+					return nil, "", fmt.Errorf("failed to get manifest: %w", clients.ErrPlatformNotSelected)
+				}
+				g.Expect(args.Platform).To(Equal(&ociv1.Platform{OS: "linux", Architecture: "arm64"}))
+				return &ociv1.Manifest{Config: ociv1.Descriptor{Digest: go_digest.FromBytes(builtConfig)}}, builtManifestRef, nil
+			case baseImage:
+				return &ociv1.Manifest{Config: ociv1.Descriptor{Digest: go_digest.FromBytes(baseConfig)}}, baseImage, nil
+			case "registry.io/ubi9:9.4-12-source":
+				return &ociv1.Manifest{
+					Config: ociv1.Descriptor{Digest: go_digest.FromBytes(baseSourceConfig)},
+					Layers: []ociv1.Descriptor{{
+						MediaType:   "application/vnd.docker.image.rootfs.diff.tar.gzip",
+						Digest:      baseSourceLayerDigest,
+						Size:        int64(baseSourceLayer.Len()),
+						Annotations: map[string]string{ociv1.AnnotationTitle: "bash-5.1.src.rpm"},
+					}},
+				}, "registry.io/ubi9@" + sourceDigest, nil
+			}
+			return nil, "", fmt.Errorf("failed to get manifest: %w", clients.ErrManifestNotFound)
+		}
+		registryClient.GetBlobFunc = func(args *clients.GetBlobArgs) ([]byte, error) {
+			if args.Digest == go_digest.FromBytes(builtConfig) {
+				g.Expect(args.ImageRef).To(Equal(builtManifestRef))
+				return builtConfig, nil
+			}
+			g.Expect(args.Auth).To(BeNil())
+			switch args.Digest {
+			case go_digest.FromBytes(baseConfig):
+				g.Expect(args.ImageRef).To(Equal(baseImage))
+				return baseConfig, nil
+			case go_digest.FromBytes(baseSourceConfig):
+				g.Expect(args.ImageRef).To(Equal("registry.io/ubi9@" + sourceDigest))
+				return baseSourceConfig, nil
+			}
+			return nil, errors.New("blob unknown")
+		}
+
+		c = &BuildSource{
+			Params: &BuildSourceParams{
+				OutputRef:              "localhost.reg.io/app:v1",
+				SourceDir:              sourceDir,
+				PrefetchOutputDir:      prefetchDir,
+				ResolvedBaseImagesFile: resolvedBaseImages,
+				ResultPathImageRef:     filepath.Join(workDir, "image-ref"),
+			},
+			RegistryClient: registryClient,
+			ResultsWriter:  &common.ResultsWriter{},
+		}
+	}
+
+	// The layer files are removed after the push, they are read when pushed
+	layerNames := func(layer clients.ImageLayer) []string {
+		content, err := os.ReadFile(layer.Path)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(go_digest.FromBytes(content)).To(Equal(layer.Descriptor.Digest))
+		g.Expect(int64(len(content))).To(Equal(layer.Descriptor.Size))
+		gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+		g.Expect(err).ToNot(HaveOccurred())
+		tarReader := tar.NewReader(gzipReader)
+		var names []string
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				return names
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			names = append(names, header.Name)
+		}
+	}
+
+	t.Run("should push source image with a layer per component", func(t *testing.T) {
+		beforeEach()
+
+		var pushArgs *clients.PushImageArgs
+		var pushedNames [][]string
+		registryClient.PushImageFunc = func(args *clients.PushImageArgs) (string, error) {
+			pushArgs = args
+			for _, layer := range args.Layers[:3] {
+				pushedNames = append(pushedNames, layerNames(layer))
+			}
+			return pushedRef, nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(pushArgs.DestinationImage).To(Equal("localhost.reg.io/app:v1-source"))
+		g.Expect(pushArgs.Auth).To(Equal(&common.RegistryAuth{Registry: "localhost.reg.io", Token: "token"}))
+		g.Expect(pushArgs.Annotations).To(Equal(map[string]string{sourceBinaryImageAnnotation: "localhost.reg.io/app:v1"}))
+		g.Expect(pushArgs.Layers).To(HaveLen(4))
+
+		g.Expect(pushArgs.Layers[0].Descriptor.Annotations).To(Equal(map[string]string{
+			ociv1.AnnotationTitle: "source", sourceTypeAnnotation: "source", sourceNameAnnotation: "source",
+		}))
+		g.Expect(pushedNames[0]).To(Equal([]string{"src/", "src/main.go"}))
+
+		g.Expect(pushArgs.Layers[1].Descriptor.Annotations).To(Equal(map[string]string{
+			ociv1.AnnotationTitle: "gomod", sourceTypeAnnotation: "prefetched-dependencies", sourceNameAnnotation: "gomod",
+		}))
+		g.Expect(pushedNames[1]).To(Equal([]string{
+			"deps/", "deps/gomod/", "deps/gomod/cache/", "deps/gomod/cache/download/", "deps/gomod/cache/download/mod.zip",
+		}))
+		g.Expect(pushArgs.Layers[2].Descriptor.Annotations[ociv1.AnnotationTitle]).To(Equal("pip"))
+		_, err = os.Stat(pushArgs.Layers[0].Path)
+		g.Expect(os.IsNotExist(err)).To(BeTrue())
+
+		g.Expect(pushArgs.Layers[3].Path).To(BeEmpty())
+		g.Expect(pushArgs.Layers[3].SourceImage).To(Equal("registry.io/ubi9@" + sourceDigest))
+		g.Expect(pushArgs.Layers[3].SourceAuth).To(BeNil())
+		g.Expect(pushArgs.Layers[3].Descriptor.Digest).To(Equal(baseSourceLayerDigest))
+		g.Expect(pushArgs.Layers[3].Descriptor.Size).To(Equal(int64(baseSourceLayer.Len())))
+		g.Expect(pushArgs.Layers[3].Descriptor.MediaType).To(Equal(ociv1.MediaTypeImageLayerGzip))
+		g.Expect(pushArgs.Layers[3].Descriptor.Annotations).To(Equal(map[string]string{
+			ociv1.AnnotationTitle: "bash-5.1.src.rpm",
+			sourceTypeAnnotation:  "base-image-source",
+			sourceNameAnnotation:  baseImage,
+			sourceImageAnnotation: "registry.io/ubi9@" + sourceDigest,
+		}))
+
+		var config ociv1.Image
+		g.Expect(json.Unmarshal(pushArgs.Config, &config)).To(Succeed())
+		g.Expect(config.RootFS.DiffIDs).To(HaveLen(4))
+		g.Expect(config.RootFS.DiffIDs[3]).To(Equal(go_digest.FromString("rpm sources")))
+		g.Expect(config.Platform).To(Equal(*builtPlatform))
+		g.Expect(config.Created).To(BeNil())
+
+		g.Expect(c.Results.ImageRef).To(Equal(pushedRef))
+		g.Expect(c.Results.Components).To(HaveLen(4))
+		g.Expect(c.Results.Components[3]).To(Equal(SourceImageComponent{
+			Type:        "base-image-source",
+			Name:        baseImage,
+			Digest:      baseSourceLayerDigest.String(),
+			SourceImage: "registry.io/ubi9@" + sourceDigest,
+		}))
+		imageRef, err := os.ReadFile(c.Params.ResultPathImageRef)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(imageRef)).To(Equal(pushedRef))
+	})
+
+	t.Run("should produce the same layers on every run", func(t *testing.T) {
+		beforeEach()
+		c.Params.ResolvedBaseImagesFile = ""
+
+		var configs [][]byte
+		registryClient.PushImageFunc = func(args *clients.PushImageArgs) (string, error) {
+			configs = append(configs, args.Config)
+			return pushedRef, nil
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+		g.Expect(os.Chtimes(filepath.Join(sourceDir, "main.go"), time.Now(), time.Now())).To(Succeed())
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(configs).To(HaveLen(2))
+		g.Expect(configs[1]).To(Equal(configs[0]))
+	})
+
+	t.Run("should skip base images without source image", func(t *testing.T) {
+		beforeEach()
+		getManifest := registryClient.GetManifestFunc
+		var requested []string
+		registryClient.GetManifestFunc = func(args *clients.GetManifestArgs) (*ociv1.Manifest, string, error) {
+			requested = append(requested, args.ImageRef)
+			if args.ImageRef == "registry.io/ubi9:9.4-12-source" {
+				return nil, "", errors.New("registry responded 500 Internal Server Error")
+			}
+			return getManifest(args)
+		}
+		var pushArgs *clients.PushImageArgs
+		registryClient.PushImageFunc = func(args *clients.PushImageArgs) (string, error) {
+			pushArgs = args
+			return pushedRef, nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(requested).To(Equal([]string{
+			"localhost.reg.io/app:v1",
+			baseImage,
+			"registry.io/ubi9:sha256-e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b.src",
+			"registry.io/ubi9:9.4-12-source",
+		}))
+		g.Expect(pushArgs.Layers).To(HaveLen(3))
+	})
+
+	t.Run("should return error when source image config does not match its layers", func(t *testing.T) {
+		beforeEach()
+		getBlob := registryClient.GetBlobFunc
+		registryClient.GetBlobFunc = func(args *clients.GetBlobArgs) ([]byte, error) {
+			if args.Digest == go_digest.FromBytes(baseSourceConfig) {
+				return []byte(`{"rootfs":{"type":"layers","diff_ids":[]}}`), nil
+			}
+			return getBlob(args)
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(MatchError(ContainSubstring("image config has 0 diff IDs for 1 layers")))
+	})
+
+	t.Run("should return error when built image cannot be read", func(t *testing.T) {
+		beforeEach()
+		c.Params.OutputRef = "localhost.reg.io/app:missing"
+
+		err := c.Run()
+
+		g.Expect(err).To(MatchError(ContainSubstring("failed to read image config of localhost.reg.io/app:missing")))
+	})
+
+	t.Run("should read the platform of the built image index from the selected manifest", func(t *testing.T) {
+		beforeEach()
+		c.Params.OutputRef = "localhost.reg.io/app:index"
+		c.Params.SourceImageTag = "index-source"
+		c.Params.Platform = "linux/aarch64"
+		registryClient.PushImageFunc = func(args *clients.PushImageArgs) (string, error) {
+			config := ociv1.Image{}
+			g.Expect(json.Unmarshal(args.Config, &config)).To(Succeed())
+			g.Expect(config.Platform).To(Equal(*builtPlatform))
+			return pushedRef, nil
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+	})
+
+	t.Run("should return error when built image is an image index and no platform is given", func(t *testing.T) {
+		beforeEach()
+		c.Params.OutputRef = "localhost.reg.io/app:index"
+
+		err := c.Run()
+
+		g.Expect(err).To(MatchError(ContainSubstring("output ref localhost.reg.io/app:index is an image index, select the platform of the sources with --platform")))
+	})
+
+	t.Run("should return error when platform is invalid", func(t *testing.T) {
+		beforeEach()
+		c.Params.Platform = "linux"
+
+		err := c.Run()
+
+		g.Expect(err).To(MatchError(ContainSubstring("platform 'linux' must be in os/arch[/variant] format")))
+	})
+
+	t.Run("should return error when push fails", func(t *testing.T) {
+		beforeEach()
+		registryClient.PushImageFunc = func(args *clients.PushImageArgs) (string, error) {
+			return "", errors.New("registry responded 500")
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(MatchError(ContainSubstring("error on pushing source image localhost.reg.io/app:v1-source: registry responded 500")))
+	})
+
+	t.Run("should return error when source directory does not exist", func(t *testing.T) {
+		beforeEach()
+		c.Params.SourceDir = filepath.Join(workDir, "missing")
+
+		err := c.Run()
+
+		g.Expect(err).To(MatchError(ContainSubstring("is not accessible")))
+	})
+}
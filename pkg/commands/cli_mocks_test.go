@@ -3,6 +3,9 @@ package commands
 import (
 	"runtime"
 
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 )
 
@@ -216,3 +219,53 @@ func (m *mockOrasCli) Push(args *cliwrappers.OrasPushArgs) (string, string, erro
 	}
 	return "", "", nil
 }
+
+var _ clients.RegistryTagsClientInterface = &mockRegistryTagsClient{}
+
+type mockRegistryTagsClient struct {
+	DeleteTagFunc func(args *clients.DeleteTagArgs) error
+}
+
+func (m *mockRegistryTagsClient) DeleteTag(args *clients.DeleteTagArgs) error {
+	if m.DeleteTagFunc != nil {
+		return m.DeleteTagFunc(args)
+	}
+	return nil
+}
+
+var _ clients.OCIRegistryClientInterface = &mockOCIRegistryClient{}
+
+type mockOCIRegistryClient struct {
+	PushArtifactFunc func(args *clients.PushArtifactArgs) (string, error)
+	PushImageFunc    func(args *clients.PushImageArgs) (string, error)
+	GetManifestFunc  func(args *clients.GetManifestArgs) (*ociv1.Manifest, string, error)
+	GetBlobFunc      func(args *clients.GetBlobArgs) ([]byte, error)
+}
+
+func (m *mockOCIRegistryClient) PushArtifact(args *clients.PushArtifactArgs) (string, error) {
+	if m.PushArtifactFunc != nil {
+		return m.PushArtifactFunc(args)
+	}
+	return "", nil
+}
+
+func (m *mockOCIRegistryClient) PushImage(args *clients.PushImageArgs) (string, error) {
+	if m.PushImageFunc != nil {
+		return m.PushImageFunc(args)
+	}
+	return "", nil
+}
+
+func (m *mockOCIRegistryClient) GetManifest(args *clients.GetManifestArgs) (*ociv1.Manifest, string, error) {
+	if m.GetManifestFunc != nil {
+		return m.GetManifestFunc(args)
+	}
+	return &ociv1.Manifest{}, "", nil
+}
+
+func (m *mockOCIRegistryClient) GetBlob(args *clients.GetBlobArgs) ([]byte, error) {
+	if m.GetBlobFunc != nil {
+		return m.GetBlobFunc(args)
+	}
+	return nil, nil
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	"github.com/konflux-ci/konflux-build-cli/pkg/config"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// Note, the environment variable must not start with KBC_CONFIG_, those override config keys.
+var ConfigShowParamsConfig = map[string]common.Parameter{
+	"output-format": {
+		Name:         "output-format",
+		EnvVarName:   "KBC_SHOW_CONFIG_OUTPUT_FORMAT",
+		TypeKind:     reflect.String,
+		DefaultValue: "text",
+		Usage:        "Format of the printed config (text or json).",
+	},
+}
+
+type ConfigShowParams struct {
+	OutputFormat string `paramName:"output-format"`
+}
+
+type ConfigShow struct {
+	Params        *ConfigShowParams
+	Results       *config.ResolvedConfig
+	Resolver      config.ConfigResolver
+	ResultsWriter common.ResultsWriterInterface
+}
+
+func NewConfigShow(cmd *cobra.Command) (*ConfigShow, error) {
+	params := &ConfigShowParams{}
+	if err := common.ParseParameters(cmd, ConfigShowParamsConfig, params); err != nil {
+		return nil, err
+	}
+	resolver, err := config.NewLayeredConfigReader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config reader: %w", err)
+	}
+	return &ConfigShow{
+		Params:        params,
+		Resolver:      resolver,
+		ResultsWriter: common.NewResultsWriter(),
+	}, nil
+}
+
+func (c *ConfigShow) Run() error {
+	common.LogParameters(ConfigShowParamsConfig, c.Params)
+
+	if c.Params.OutputFormat != "text" && c.Params.OutputFormat != "json" {
+		return fmt.Errorf("output-format must be 'text' or 'json', got '%s'", c.Params.OutputFormat)
+	}
+
+	resolved, err := c.Resolver.Resolve()
+	if err != nil {
+		return err
+	}
+	c.Results = resolved
+
+	if len(resolved.UnknownKeys) > 0 || len(resolved.InvalidKeys) > 0 {
+		l.Logger.Warnf("Config has %d unknown and %d invalid keys", len(resolved.UnknownKeys), len(resolved.InvalidKeys))
+	}
+
+	if c.Params.OutputFormat == "json" {
+		if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+			return fmt.Errorf("error on creating results JSON: %w", err)
+		} else {
+			fmt.Print(resultsJson)
+		}
+		return nil
+	}
+
+	fmt.Print(formatResolvedConfig(resolved))
+	return nil
+}
+
+// formatResolvedConfig renders the effective config values with their sources as a table,
+// followed by the unknown and invalid keys, if any.
+func formatResolvedConfig(resolved *config.ResolvedConfig) string {
+	var sb strings.Builder
+
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, value := range resolved.Values {
+		fmt.Fprintf(w, "%s\t%q\t%s\n", value.Key, value.Value, value.Source)
+	}
+	w.Flush()
+
+	if len(resolved.UnknownKeys) > 0 {
+		sb.WriteString("\nUnknown keys:\n")
+		for _, issue := range resolved.UnknownKeys {
+			fmt.Fprintf(&sb, "  %s (%s)\n", issue.Key, issue.Source)
+		}
+	}
+	if len(resolved.InvalidKeys) > 0 {
+		sb.WriteString("\nInvalid keys:\n")
+		for _, issue := range resolved.InvalidKeys {
+			fmt.Fprintf(&sb, "  %s (%s): %s\n", issue.Key, issue.Source, issue.Message)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/config"
+)
+
+type mockConfigResolver struct {
+	ResolveFunc func() (*config.ResolvedConfig, error)
+}
+
+func (m *mockConfigResolver) Resolve() (*config.ResolvedConfig, error) {
+	return m.ResolveFunc()
+}
+
+func Test_ConfigShow_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	resolved := &config.ResolvedConfig{
+		Values: []config.ConfigValue{
+			{Key: "allow-cache-proxy", Value: "true", Source: config.DefaultConfigSource},
+			{Key: "http-proxy", Value: "proxy.io:3128", Source: "env KBC_CONFIG_*"},
+		},
+		UnknownKeys: []config.ConfigIssue{
+			{Key: "some-key", Source: "configmap konflux-info/cluster-config", Message: "unknown config key"},
+		},
+		InvalidKeys: []config.ConfigIssue{
+			{Key: "allow-package-registry-proxy", Source: "file config.ini", Message: "invalid boolean value 'yes'"},
+		},
+	}
+	resolver := &mockConfigResolver{ResolveFunc: func() (*config.ResolvedConfig, error) {
+		return resolved, nil
+	}}
+
+	t.Run("should print config as JSON", func(t *testing.T) {
+		var printed any
+		c := &ConfigShow{
+			Params:   &ConfigShowParams{OutputFormat: "json"},
+			Resolver: resolver,
+			ResultsWriter: &mockResultsWriter{CreateResultJsonFunc: func(result any) (string, error) {
+				printed = result
+				data, err := json.Marshal(result)
+				return string(data), err
+			}},
+		}
+		g.Expect(c.Run()).To(Succeed())
+		g.Expect(c.Results).To(Equal(resolved))
+		g.Expect(printed).To(Equal(resolved))
+	})
+
+	t.Run("should print config as text", func(t *testing.T) {
+		c := &ConfigShow{
+			Params:        &ConfigShowParams{OutputFormat: "text"},
+			Resolver:      resolver,
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(Succeed())
+		g.Expect(c.Results).To(Equal(resolved))
+	})
+
+	t.Run("should fail on unsupported output format", func(t *testing.T) {
+		c := &ConfigShow{
+			Params:        &ConfigShowParams{OutputFormat: "yaml"},
+			Resolver:      resolver,
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("output-format")))
+	})
+
+	t.Run("should fail if config cannot be resolved", func(t *testing.T) {
+		c := &ConfigShow{
+			Params: &ConfigShowParams{OutputFormat: "text"},
+			Resolver: &mockConfigResolver{ResolveFunc: func() (*config.ResolvedConfig, error) {
+				return nil, errors.New("failed to read config")
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(MatchError("failed to read config"))
+	})
+}
+
+func Test_formatResolvedConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should format values with provenance and issues", func(t *testing.T) {
+		text := formatResolvedConfig(&config.ResolvedConfig{
+			Values: []config.ConfigValue{
+				{Key: "allow-cache-proxy", Value: "true", Source: "default"},
+				{Key: "no-proxy", Value: "", Source: "env KBC_CONFIG_*"},
+			},
+			UnknownKeys: []config.ConfigIssue{{Key: "some-key", Source: "file config.ini", Message: "unknown config key"}},
+			InvalidKeys: []config.ConfigIssue{{Key: "allow-cache-proxy", Source: "default", Message: "invalid boolean value"}},
+		})
+
+		g.Expect(text).To(Equal(`KEY                VALUE   SOURCE
+allow-cache-proxy  "true"  default
+no-proxy           ""      env KBC_CONFIG_*
+
+Unknown keys:
+  some-key (file config.ini)
+
+Invalid keys:
+  allow-cache-proxy (default): invalid boolean value
+`))
+	})
+
+	t.Run("should not list issues if there are none", func(t *testing.T) {
+		text := formatResolvedConfig(&config.ResolvedConfig{
+			Values: []config.ConfigValue{{Key: "http-proxy", Value: "proxy.io", Source: "default"}},
+		})
+		g.Expect(text).ToNot(ContainSubstring("keys:"))
+	})
+}
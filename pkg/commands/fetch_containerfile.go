@@ -0,0 +1,215 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var FetchContainerfileParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_FETCH_CONTAINERFILE_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Binary image URL. Containerfile is fetched from the image repository where this binary image is.",
+		Required:   true,
+	},
+	"image-digest": {
+		Name:       "image-digest",
+		ShortName:  "d",
+		EnvVarName: "KBC_FETCH_CONTAINERFILE_IMAGE_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Digest of the built binary image represented by argument --image-url. It is used to construct the tag of Containerfile image.",
+		Required:   true,
+	},
+	"tag-suffix": {
+		Name:         "tag-suffix",
+		ShortName:    "t",
+		EnvVarName:   "KBC_FETCH_CONTAINERFILE_TAG_SUFFIX",
+		TypeKind:     reflect.String,
+		DefaultValue: containerfileArtifactTagSuffix,
+		Usage:        "Suffix the artifact image tag was constructed with by push-containerfile.",
+		Required:     false,
+	},
+	"alternative-filename": {
+		Name:       "alternative-filename",
+		ShortName:  "n",
+		EnvVarName: "KBC_FETCH_CONTAINERFILE_ALTERNATIVE_FILENAME",
+		TypeKind:   reflect.String,
+		Usage:      "Alternative file name the Containerfile was pushed with, e.g. Dockerfile.",
+		Required:   false,
+	},
+	"output": {
+		Name:       "output",
+		ShortName:  "o",
+		EnvVarName: "KBC_FETCH_CONTAINERFILE_OUTPUT",
+		TypeKind:   reflect.String,
+		Usage:      "Write the fetched Containerfile to this path.",
+		Required:   true,
+	},
+	"result-path-image-ref": {
+		Name:       "result-path-image-ref",
+		ShortName:  "r",
+		EnvVarName: "KBC_FETCH_CONTAINERFILE_RESULT_PATH_IMAGE_REF",
+		TypeKind:   reflect.String,
+		Usage:      "Write digested image reference of the fetched Containerfile image into this file.",
+		Required:   false,
+	},
+}
+
+type FetchContainerfileParams struct {
+	ImageUrl            string `paramName:"image-url"`
+	ImageDigest         string `paramName:"image-digest"`
+	TagSuffix           string `paramName:"tag-suffix"`
+	AlternativeFilename string `paramName:"alternative-filename"`
+	Output              string `paramName:"output"`
+	ResultPathImageRef  string `paramName:"result-path-image-ref"`
+}
+
+type FetchContainerfileResults struct {
+	ImageRef string `json:"image_ref"`
+	Filename string `json:"filename"`
+}
+
+type FetchContainerfile struct {
+	Params         *FetchContainerfileParams
+	RegistryClient clients.OCIRegistryClientInterface
+	Results        FetchContainerfileResults
+	ResultsWriter  common.ResultsWriterInterface
+
+	imageName string
+}
+
+func NewFetchContainerfile(cmd *cobra.Command) (*FetchContainerfile, error) {
+	params := &FetchContainerfileParams{}
+	if err := common.ParseParameters(cmd, FetchContainerfileParamsConfig, params); err != nil {
+		return nil, err
+	}
+	return &FetchContainerfile{
+		Params:         params,
+		RegistryClient: clients.NewOCIRegistryClient(),
+		ResultsWriter:  common.NewResultsWriter(),
+	}, nil
+}
+
+func (c *FetchContainerfile) Run() error {
+	common.LogParameters(FetchContainerfileParamsConfig, c.Params)
+
+	imageUrl := c.Params.ImageUrl
+	c.imageName = common.GetImageName(imageUrl)
+
+	if err := c.validateParams(); err != nil {
+		return err
+	}
+
+	l.Logger.Debugf("Select registry authentication for %s", imageUrl)
+	registryAuth, err := common.SelectRegistryAuthFromDefaultAuthFile(imageUrl)
+	if err != nil {
+		l.Logger.Warnf("Cannot select registry authentication for image %s, fetching anonymously: %s", imageUrl, err.Error())
+		registryAuth = nil
+	}
+
+	tag := containerfileImageTag(c.Params.ImageDigest, c.Params.TagSuffix)
+	artifactImage := fmt.Sprintf("%s:%s", c.imageName, tag)
+
+	manifest, artifactImageRef, err := c.RegistryClient.GetManifest(&clients.GetManifestArgs{
+		ImageRef: artifactImage,
+		Auth:     registryAuth,
+	})
+	if err != nil {
+		return fmt.Errorf("error on fetching Containerfile artifact %s: %w", artifactImage, err)
+	}
+
+	layer, err := c.findContainerfileLayer(manifest)
+	if err != nil {
+		return fmt.Errorf("error on finding Containerfile in artifact %s: %w", artifactImageRef, err)
+	}
+	filename := layer.Annotations[ociv1.AnnotationTitle]
+
+	content, err := c.RegistryClient.GetBlob(&clients.GetBlobArgs{
+		ImageRef: artifactImageRef,
+		Digest:   layer.Digest,
+		Auth:     registryAuth,
+	})
+	if err != nil {
+		return fmt.Errorf("error on fetching Containerfile %s from %s: %w", filename, artifactImageRef, err)
+	}
+
+	if err := os.WriteFile(c.Params.Output, content, 0644); err != nil { //nolint:gosec // output path is given by the user
+		return fmt.Errorf("error on writing Containerfile to %s: %w", c.Params.Output, err)
+	}
+
+	l.Logger.Infof("Containerfile '%s' is fetched from %s and written to %s", filename, artifactImageRef, c.Params.Output)
+
+	c.Results.ImageRef = artifactImageRef
+	c.Results.Filename = filename
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	if c.Params.ResultPathImageRef != "" {
+		err = c.ResultsWriter.WriteResultString(artifactImageRef, c.Params.ResultPathImageRef)
+		if err != nil {
+			return fmt.Errorf("error on writing result image digest: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// findContainerfileLayer returns the layer of the artifact containing the Containerfile.
+// If alternative file name is given, the layer with this title is returned.
+// Otherwise, the first layer with the Containerfile media type, see the artifact layout in push_containerfile.go.
+func (c *FetchContainerfile) findContainerfileLayer(manifest *ociv1.Manifest) (*ociv1.Descriptor, error) {
+	for i, layer := range manifest.Layers {
+		if c.Params.AlternativeFilename != "" {
+			if layer.Annotations[ociv1.AnnotationTitle] == c.Params.AlternativeFilename {
+				return &manifest.Layers[i], nil
+			}
+			continue
+		}
+		if layer.MediaType == containerfileLayerMediaType {
+			return &manifest.Layers[i], nil
+		}
+	}
+	if c.Params.AlternativeFilename != "" {
+		return nil, fmt.Errorf("no layer with title '%s'", c.Params.AlternativeFilename)
+	}
+	return nil, fmt.Errorf("no layer with media type '%s'", containerfileLayerMediaType)
+}
+
+func (c *FetchContainerfile) validateParams() error {
+	if !common.IsImageNameValid(c.imageName) {
+		return fmt.Errorf("image name '%s' is invalid", c.imageName)
+	}
+
+	if !common.IsImageDigestValid(c.Params.ImageDigest) {
+		return fmt.Errorf("image digest '%s' is invalid", c.Params.ImageDigest)
+	}
+
+	if !regexp.MustCompile(tagSuffixRegex).MatchString(c.Params.TagSuffix) {
+		return fmt.Errorf("tag suffix includes invalid characters or exceeds the max length of 57 characters")
+	}
+
+	if strings.Contains(c.Params.AlternativeFilename, "/") {
+		return fmt.Errorf("path is included in alternative file name '%s'", c.Params.AlternativeFilename)
+	}
+
+	if c.Params.Output == "" {
+		return fmt.Errorf("output path is empty")
+	}
+
+	return nil
+}
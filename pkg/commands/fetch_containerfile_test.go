@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	go_digest "github.com/opencontainers/go-digest"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+)
+
+func TestFetchContainerfile_validateParams(t *testing.T) {
+	g := NewWithT(t)
+
+	validParams := func() *FetchContainerfileParams {
+		return &FetchContainerfileParams{
+			ImageDigest: imageDigest,
+			TagSuffix:   ".containerfile",
+			Output:      "Containerfile",
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		imageName     string
+		modify        func(p *FetchContainerfileParams)
+		expectedError string
+	}{
+		{name: "should accept valid params", imageName: "quay.io/org/app", modify: func(p *FetchContainerfileParams) {}},
+		{name: "should fail on invalid image name", imageName: "quay.io/org/App", modify: func(p *FetchContainerfileParams) {},
+			expectedError: "image name 'quay.io/org/App' is invalid"},
+		{name: "should fail on invalid digest", imageName: "quay.io/org/app", modify: func(p *FetchContainerfileParams) { p.ImageDigest = "sha256:1" },
+			expectedError: "image digest 'sha256:1' is invalid"},
+		{name: "should fail on invalid tag suffix", imageName: "quay.io/org/app", modify: func(p *FetchContainerfileParams) { p.TagSuffix = "^suffix" },
+			expectedError: "tag suffix includes invalid characters"},
+		{name: "should fail on path in alternative file name", imageName: "quay.io/org/app", modify: func(p *FetchContainerfileParams) { p.AlternativeFilename = "a/Dockerfile" },
+			expectedError: "path is included in alternative file name"},
+		{name: "should fail on empty output", imageName: "quay.io/org/app", modify: func(p *FetchContainerfileParams) { p.Output = "" },
+			expectedError: "output path is empty"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			params := validParams()
+			tc.modify(params)
+			c := &FetchContainerfile{Params: params, imageName: tc.imageName}
+
+			err := c.validateParams()
+			if tc.expectedError == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+			}
+		})
+	}
+}
+
+func TestFetchContainerfile_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	const artifactImageRef = "localhost.reg.io/app@sha256:a7c0071906a9c6b654760e44a1fc8226f8268c70848148f19c35b02788b272a5"
+
+	containerfileContent := []byte("FROM fedora")
+	buildArgsContent := []byte("VERSION=1")
+	blobs := map[go_digest.Digest][]byte{
+		go_digest.FromBytes(containerfileContent): containerfileContent,
+		go_digest.FromBytes(buildArgsContent):     buildArgsContent,
+	}
+	manifest := &ociv1.Manifest{
+		MediaType: ociv1.MediaTypeImageManifest,
+		Layers: []ociv1.Descriptor{
+			{
+				MediaType:   "application/vnd.oci.image.layer.v1.tar",
+				Digest:      go_digest.FromBytes(containerfileContent),
+				Annotations: map[string]string{ociv1.AnnotationTitle: "Dockerfile"},
+			},
+			{
+				MediaType:   "application/vnd.konflux.build-args",
+				Digest:      go_digest.FromBytes(buildArgsContent),
+				Annotations: map[string]string{ociv1.AnnotationTitle: "build-args"},
+			},
+		},
+	}
+
+	workDir := t.TempDir()
+	t.Setenv("HOME", workDir)
+	os.Mkdir(filepath.Join(workDir, ".docker"), 0755)
+	os.WriteFile(filepath.Join(workDir, ".docker", "config.json"), []byte(`{"auths":{"localhost.reg.io":{"auth":"token"}}}`), 0644)
+
+	newRegistryClient := func() *mockOCIRegistryClient {
+		return &mockOCIRegistryClient{
+			GetManifestFunc: func(args *clients.GetManifestArgs) (*ociv1.Manifest, string, error) {
+				g.Expect(args.ImageRef).To(Equal("localhost.reg.io/app:sha256-e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b.dockerfile"))
+				g.Expect(args.Auth).To(Equal(&common.RegistryAuth{Registry: "localhost.reg.io", Token: "token"}))
+				return manifest, artifactImageRef, nil
+			},
+			GetBlobFunc: func(args *clients.GetBlobArgs) ([]byte, error) {
+				g.Expect(args.ImageRef).To(Equal(artifactImageRef))
+				content, found := blobs[args.Digest]
+				if !found {
+					return nil, fmt.Errorf("blob unknown")
+				}
+				return content, nil
+			},
+		}
+	}
+	newFetchContainerfile := func(alternativeFilename string) *FetchContainerfile {
+		return &FetchContainerfile{
+			Params: &FetchContainerfileParams{
+				ImageUrl:            "localhost.reg.io/app",
+				ImageDigest:         imageDigest,
+				TagSuffix:           ".dockerfile",
+				AlternativeFilename: alternativeFilename,
+				Output:              filepath.Join(t.TempDir(), "Containerfile"),
+				ResultPathImageRef:  filepath.Join(t.TempDir(), "image-ref"),
+			},
+			RegistryClient: newRegistryClient(),
+			ResultsWriter:  &common.ResultsWriter{},
+		}
+	}
+
+	t.Run("should fetch Containerfile layer by media type", func(t *testing.T) {
+		c := newFetchContainerfile("")
+
+		err := c.Run()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		content, err := os.ReadFile(c.Params.Output)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(content).To(Equal(containerfileContent))
+		g.Expect(c.Results).To(Equal(FetchContainerfileResults{ImageRef: artifactImageRef, Filename: "Dockerfile"}))
+
+		imageRef, _ := os.ReadFile(c.Params.ResultPathImageRef)
+		g.Expect(string(imageRef)).To(Equal(artifactImageRef))
+	})
+
+	t.Run("should fetch layer by alternative file name", func(t *testing.T) {
+		c := newFetchContainerfile("Dockerfile")
+
+		err := c.Run()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		content, _ := os.ReadFile(c.Params.Output)
+		g.Expect(content).To(Equal(containerfileContent))
+	})
+
+	t.Run("should fail if alternative file name is not in the artifact", func(t *testing.T) {
+		c := newFetchContainerfile("Containerfile")
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("no layer with title 'Containerfile'")))
+		g.Expect(c.Params.Output).ToNot(BeAnExistingFile())
+	})
+
+	t.Run("should fail if artifact cannot be fetched", func(t *testing.T) {
+		c := newFetchContainerfile("")
+		c.RegistryClient = &mockOCIRegistryClient{
+			GetManifestFunc: func(args *clients.GetManifestArgs) (*ociv1.Manifest, string, error) {
+				return nil, "", fmt.Errorf("registry responded 404 Not Found")
+			},
+		}
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("error on fetching Containerfile artifact")))
+		g.Expect(err).To(MatchError(ContainSubstring("404 Not Found")))
+	})
+}
@@ -0,0 +1,82 @@
+package gitclone
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// commitSHARegex matches full or abbreviated SHA-1 and SHA-256 commit IDs.
+var commitSHARegex = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+
+// verifyBundle checks that the bundle file is valid and returns its absolute path,
+// which is used as the origin remote URL. Relative paths would be resolved against
+// the checkout directory by git.
+func (c *GitClone) verifyBundle() (string, error) {
+	bundlePath, err := filepath.Abs(c.Params.Bundle)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve bundle path: %w", err)
+	}
+
+	l.Logger.Infof("Verifying bundle %s", bundlePath)
+	if err := c.CliWrappers.GitCli.BundleVerify(bundlePath); err != nil {
+		return "", fmt.Errorf("invalid bundle %s: %w", bundlePath, err)
+	}
+	return bundlePath, nil
+}
+
+// createBundle writes the cloned history into a bundle file that can be cloned from
+// with the same revision and target branch parameters.
+func (c *GitClone) createBundle() error {
+	refs := []string{"HEAD"}
+
+	// The revision and the target branch are fetched into FETCH_HEAD and remote-tracking
+	// branches, create the refs a fetch of the same names from the bundle resolves to.
+	if ref := revisionBundleRef(c.Params.Revision); ref != "" {
+		if err := c.CliWrappers.GitCli.UpdateRef(ref, c.Results.Commit); err != nil {
+			return err
+		}
+		refs = append(refs, ref)
+	}
+	if c.Params.MergeTargetBranch {
+		ref := "refs/heads/" + c.Params.TargetBranch
+		if ref != refs[len(refs)-1] {
+			mergeRef := fmt.Sprintf("%s/%s", c.mergeRemote(), c.Params.TargetBranch)
+			if err := c.CliWrappers.GitCli.UpdateRef(ref, mergeRef); err != nil {
+				return err
+			}
+			refs = append(refs, ref)
+		}
+	}
+	if c.Params.FetchTags {
+		refs = append(refs, "--tags")
+	}
+
+	// git runs in the checkout directory, relative paths must not be resolved against it
+	bundlePath, err := filepath.Abs(c.Params.CreateBundle)
+	if err != nil {
+		return fmt.Errorf("failed to resolve create-bundle path: %w", err)
+	}
+
+	l.Logger.Infof("Creating bundle %s", bundlePath)
+	if err := c.CliWrappers.GitCli.BundleCreate(bundlePath, refs); err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	return nil
+}
+
+// revisionBundleRef returns the ref to store the revision as in a bundle.
+// Commit IDs need no ref, fetching a commit reachable from HEAD works with bundles.
+func revisionBundleRef(revision string) string {
+	switch {
+	case revision == "" || commitSHARegex.MatchString(revision):
+		return ""
+	case strings.HasPrefix(revision, "refs/"):
+		return revision
+	default:
+		return "refs/heads/" + revision
+	}
+}
@@ -0,0 +1,180 @@
+package gitclone
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_GitClone_performClone_bundle(t *testing.T) {
+	g := NewWithT(t)
+
+	var _mockGitCli *mockGitCli
+	var c *GitClone
+
+	beforeEach := func() {
+		_mockGitCli = &mockGitCli{}
+		c = &GitClone{
+			CliWrappers: CliWrappers{GitCli: _mockGitCli},
+			Params: &Params{
+				Bundle:           "repo.bundle",
+				Revision:         "main",
+				Depth:            1,
+				RetryMaxAttempts: 10,
+				OutputDir:        t.TempDir(),
+			},
+		}
+	}
+
+	t.Run("should verify bundle and fetch full history from it", func(t *testing.T) {
+		beforeEach()
+		bundlePath, err := filepath.Abs("repo.bundle")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		var calls []string
+		_mockGitCli.BundleVerifyFunc = func(path string) error {
+			g.Expect(path).To(Equal(bundlePath))
+			calls = append(calls, "bundle-verify")
+			return nil
+		}
+		_mockGitCli.RemoteAddFunc = func(name, url string) (string, error) {
+			g.Expect(url).To(Equal(bundlePath))
+			calls = append(calls, "remote-add")
+			return "", nil
+		}
+		_mockGitCli.FetchWithRefspecFunc = func(opts cliwrappers.GitFetchOptions) error {
+			g.Expect(opts.Depth).To(Equal(0))
+			g.Expect(opts.Refspec).To(Equal("main"))
+			calls = append(calls, "fetch")
+			return nil
+		}
+
+		err = c.performClone()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(calls).To(Equal([]string{"bundle-verify", "remote-add", "fetch"}))
+	})
+
+	t.Run("should fail on invalid bundle", func(t *testing.T) {
+		beforeEach()
+		_mockGitCli.BundleVerifyFunc = func(path string) error {
+			return errors.New("does not look like a v2 or v3 bundle file")
+		}
+		_mockGitCli.RemoteAddFunc = func(name, url string) (string, error) {
+			t.Fatal("remote must not be added for invalid bundle")
+			return "", nil
+		}
+
+		err := c.performClone()
+
+		g.Expect(err).To(MatchError(ContainSubstring("invalid bundle")))
+	})
+}
+
+func Test_GitClone_gatherCommitInfo_bundle(t *testing.T) {
+	g := NewWithT(t)
+
+	c := &GitClone{
+		CliWrappers: CliWrappers{GitCli: &mockGitCli{}},
+		Params:      &Params{Bundle: "/bundles/repo.bundle"},
+	}
+
+	g.Expect(c.gatherCommitInfo()).To(Succeed())
+	// The bundle path is not reported as the repository URL
+	g.Expect(c.Results.URL).To(BeEmpty())
+	g.Expect(c.Results.ChainsGitURL).To(BeEmpty())
+
+	c.Params.URL = "https://git.test/user/repo.git"
+	g.Expect(c.gatherCommitInfo()).To(Succeed())
+	g.Expect(c.Results.URL).To(Equal("https://git.test/user/repo.git"))
+	g.Expect(c.Results.ChainsGitURL).To(Equal("https://git.test/user/repo.git"))
+}
+
+func Test_GitClone_createBundle(t *testing.T) {
+	g := NewWithT(t)
+
+	var _mockGitCli *mockGitCli
+	var c *GitClone
+	var updatedRefs map[string]string
+
+	beforeEach := func() {
+		_mockGitCli = &mockGitCli{}
+		updatedRefs = map[string]string{}
+		_mockGitCli.UpdateRefFunc = func(ref, sha string) error {
+			updatedRefs[ref] = sha
+			return nil
+		}
+		c = &GitClone{
+			CliWrappers: CliWrappers{GitCli: _mockGitCli},
+			Params: &Params{
+				URL:          "https://git.test/user/repo.git",
+				CreateBundle: "/out/repo.bundle",
+			},
+			Results: Results{Commit: "abc123"},
+		}
+	}
+
+	t.Run("should bundle HEAD, revision, target branch and tags", func(t *testing.T) {
+		beforeEach()
+		c.Params.Revision = "feature"
+		c.Params.MergeTargetBranch = true
+		c.Params.TargetBranch = "main"
+		c.Params.FetchTags = true
+
+		var bundleRefs []string
+		_mockGitCli.BundleCreateFunc = func(path string, refs []string) error {
+			g.Expect(path).To(Equal("/out/repo.bundle"))
+			bundleRefs = refs
+			return nil
+		}
+
+		err := c.createBundle()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(updatedRefs).To(Equal(map[string]string{
+			"refs/heads/feature": "abc123",
+			"refs/heads/main":    "origin/main",
+		}))
+		g.Expect(bundleRefs).To(Equal([]string{"HEAD", "refs/heads/feature", "refs/heads/main", "--tags"}))
+	})
+
+	t.Run("should bundle only HEAD for commit revision", func(t *testing.T) {
+		beforeEach()
+		c.Params.Revision = "0123456789abcdef0123456789abcdef01234567"
+
+		var bundleRefs []string
+		_mockGitCli.BundleCreateFunc = func(path string, refs []string) error {
+			bundleRefs = refs
+			return nil
+		}
+
+		err := c.createBundle()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(updatedRefs).To(BeEmpty())
+		g.Expect(bundleRefs).To(Equal([]string{"HEAD"}))
+	})
+
+	t.Run("should return error when bundle cannot be created", func(t *testing.T) {
+		beforeEach()
+		_mockGitCli.BundleCreateFunc = func(path string, refs []string) error {
+			return errors.New("permission denied")
+		}
+
+		err := c.createBundle()
+
+		g.Expect(err).To(MatchError(ContainSubstring("failed to create bundle")))
+	})
+}
+
+func Test_revisionBundleRef(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(revisionBundleRef("")).To(BeEmpty())
+	g.Expect(revisionBundleRef("abc1234")).To(BeEmpty())
+	g.Expect(revisionBundleRef("refs/pull/1/head")).To(Equal("refs/pull/1/head"))
+	g.Expect(revisionBundleRef("release-1.0")).To(Equal("refs/heads/release-1.0"))
+}
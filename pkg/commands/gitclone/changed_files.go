@@ -0,0 +1,165 @@
+package gitclone
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+const (
+	// changedFilesBaseRef is the local ref the changed files base branch is fetched into,
+	// so that it doesn't interfere with the remote-tracking branches.
+	changedFilesBaseRef = "refs/changed-files/base"
+	// changedFilesDeepenStep is the number of commits the first deepening of a shallow
+	// clone fetches. Each following deepening doubles it, until the full history is fetched.
+	changedFilesDeepenStep = 50
+	// changedFilesMaxDeepen is the number of deepenings before falling back to fetching the full history.
+	changedFilesMaxDeepen = 5
+)
+
+func (c *GitClone) changedFilesEnabled() bool {
+	return c.Params.ChangedFilesOutput != "" || c.Params.ComponentAffectedOutput != ""
+}
+
+// changedFilesBase returns the branch to compute the changed files against.
+func (c *GitClone) changedFilesBase() string {
+	if c.Params.ChangedFilesBase != "" {
+		return c.Params.ChangedFilesBase
+	}
+	if c.Params.MergeTargetBranch {
+		return c.Params.TargetBranch
+	}
+	return ""
+}
+
+// computeChangedFiles lists the files changed between the merge base of the base branch
+// and the checked-out revision, before merging the target branch, and writes the list
+// and whether the component is affected to the result files.
+func (c *GitClone) computeChangedFiles() error {
+	patternList, err := parseCSV(c.Params.ChangedFilesPatterns)
+	if err != nil {
+		return fmt.Errorf("failed to parse changed-files-patterns: %w", err)
+	}
+	patterns, err := common.CompilePathPatterns(patternList)
+	if err != nil {
+		return fmt.Errorf("changed-files-patterns: %w", err)
+	}
+
+	base := c.changedFilesBase()
+	remote := "origin"
+	if c.Params.ChangedFilesBase == "" {
+		remote = c.mergeRemote()
+	}
+	refspec := fmt.Sprintf("+%s:%s", base, changedFilesBaseRef)
+
+	l.Logger.Infof("Computing files changed in %s since branch '%s'", c.Results.Commit, base)
+	err = c.CliWrappers.GitCli.FetchWithRefspec(cliwrappers.GitFetchOptions{
+		Remote:      remote,
+		Refspec:     refspec,
+		Depth:       c.Params.Depth,
+		Filter:      c.Params.CloneFilter,
+		MaxAttempts: max(c.Params.RetryMaxAttempts, 1),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch changed files base '%s': %w", base, err)
+	}
+
+	mergeBase, err := c.findMergeBase(remote, refspec)
+	if err != nil {
+		return fmt.Errorf("failed to find merge base of %s and branch '%s': %w", c.Results.Commit, base, err)
+	}
+
+	files, err := c.CliWrappers.GitCli.DiffNames(mergeBase, c.Results.Commit)
+	if err != nil {
+		return err
+	}
+
+	affected := false
+	for _, file := range files {
+		if len(patterns) == 0 || patterns.Match(file) {
+			affected = true
+			break
+		}
+	}
+	l.Logger.Infof("%d file(s) changed since merge base %s, component affected: %t", len(files), mergeBase, affected)
+
+	if c.Params.ChangedFilesOutput != "" {
+		content := ""
+		if len(files) > 0 {
+			content = strings.Join(files, "\n") + "\n"
+		}
+		if err := c.ResultsWriter.WriteResultString(content, c.Params.ChangedFilesOutput); err != nil {
+			return fmt.Errorf("failed to write changed files: %w", err)
+		}
+	}
+	if c.Params.ComponentAffectedOutput != "" {
+		if err := c.ResultsWriter.WriteResultString(strconv.FormatBool(affected), c.Params.ComponentAffectedOutput); err != nil {
+			return fmt.Errorf("failed to write component affected result: %w", err)
+		}
+	}
+
+	c.Results.ChangedFiles = &ChangedFiles{
+		Base:              base,
+		MergeBase:         mergeBase,
+		Count:             len(files),
+		ComponentAffected: affected,
+	}
+	return nil
+}
+
+// findMergeBase returns the merge base of the revision and the fetched base branch.
+// While the repository is shallow and the merge base is not in the fetched history,
+// the history of both is deepened, and eventually fetched completely.
+func (c *GitClone) findMergeBase(remote, refspec string) (string, error) {
+	for attempt := 0; ; attempt++ {
+		mergeBase, err := c.CliWrappers.GitCli.MergeBase(c.Results.Commit, changedFilesBaseRef)
+		if err == nil {
+			return mergeBase, nil
+		}
+
+		shallow, statErr := c.isShallowRepository()
+		if statErr != nil {
+			return "", statErr
+		}
+		if !shallow {
+			return "", err
+		}
+
+		// The revision is fetched by SHA too, as its shallow boundary is
+		// deepened only when it's reachable from the fetched refs.
+		opts := cliwrappers.GitFetchOptions{
+			Remote:      remote,
+			Refspec:     c.Results.Commit + " " + refspec,
+			Filter:      c.Params.CloneFilter,
+			MaxAttempts: max(c.Params.RetryMaxAttempts, 1),
+		}
+		if attempt < changedFilesMaxDeepen {
+			opts.Deepen = changedFilesDeepenStep << attempt
+			l.Logger.Infof("Merge base not found in shallow history, deepening by %d commits", opts.Deepen)
+		} else {
+			opts.Unshallow = true
+			l.Logger.Info("Merge base not found in shallow history, fetching full history")
+		}
+		if err := c.CliWrappers.GitCli.FetchWithRefspec(opts); err != nil {
+			return "", err
+		}
+	}
+}
+
+func (c *GitClone) isShallowRepository() (bool, error) {
+	_, err := os.Stat(filepath.Join(c.getCheckoutDir(), ".git", "shallow"))
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check for shallow repository: %w", err)
+}
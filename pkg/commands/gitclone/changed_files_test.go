@@ -0,0 +1,213 @@
+package gitclone
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_GitClone_computeChangedFiles(t *testing.T) {
+	g := NewWithT(t)
+
+	const commit = "abc123"
+
+	var _mockGitCli *mockGitCli
+	var _mockResultsWriter *mockResultsWriter
+	var c *GitClone
+
+	beforeEach := func() {
+		_mockGitCli = &mockGitCli{}
+		_mockResultsWriter = &mockResultsWriter{}
+		c = &GitClone{
+			CliWrappers:   CliWrappers{GitCli: _mockGitCli},
+			ResultsWriter: _mockResultsWriter,
+			Params: &Params{
+				URL:                     "https://git.test/user/repo.git",
+				OutputDir:               t.TempDir(),
+				Depth:                   1,
+				ChangedFilesBase:        "main",
+				ChangedFilesOutput:      "/results/changed-files",
+				ComponentAffectedOutput: "/results/component-affected",
+				RetryMaxAttempts:        3,
+			},
+			Results: Results{Commit: commit},
+		}
+	}
+
+	makeShallow := func() {
+		gitDir := filepath.Join(c.getCheckoutDir(), ".git")
+		g.Expect(os.MkdirAll(gitDir, 0755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(gitDir, "shallow"), []byte(commit+"\n"), 0644)).To(Succeed())
+	}
+
+	t.Run("should write changed files and component affected result", func(t *testing.T) {
+		beforeEach()
+		c.Params.ChangedFilesPatterns = "services/api/*,go.mod"
+
+		var fetchOpts []cliwrappers.GitFetchOptions
+		_mockGitCli.FetchWithRefspecFunc = func(opts cliwrappers.GitFetchOptions) error {
+			fetchOpts = append(fetchOpts, opts)
+			return nil
+		}
+		_mockGitCli.MergeBaseFunc = func(a, b string) (string, error) {
+			g.Expect(a).To(Equal(commit))
+			g.Expect(b).To(Equal(changedFilesBaseRef))
+			return "base123", nil
+		}
+		_mockGitCli.DiffNamesFunc = func(from, to string) ([]string, error) {
+			g.Expect(from).To(Equal("base123"))
+			g.Expect(to).To(Equal(commit))
+			return []string{"README.md", "services/api/main.go"}, nil
+		}
+
+		err := c.computeChangedFiles()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(fetchOpts).To(Equal([]cliwrappers.GitFetchOptions{
+			{Remote: "origin", Refspec: "+main:" + changedFilesBaseRef, Depth: 1, MaxAttempts: 3},
+		}))
+		g.Expect(_mockResultsWriter.WrittenResults).To(Equal(map[string]string{
+			"/results/changed-files":      "README.md\nservices/api/main.go\n",
+			"/results/component-affected": "true",
+		}))
+		g.Expect(c.Results.ChangedFiles).To(Equal(&ChangedFiles{
+			Base: "main", MergeBase: "base123", Count: 2, ComponentAffected: true,
+		}))
+	})
+
+	t.Run("should report component not affected when no changed file matches", func(t *testing.T) {
+		beforeEach()
+		c.Params.ChangedFilesPatterns = "services/api/*"
+		_mockGitCli.MergeBaseFunc = func(a, b string) (string, error) { return "base123", nil }
+		_mockGitCli.DiffNamesFunc = func(from, to string) ([]string, error) {
+			return []string{"services/web/main.go"}, nil
+		}
+
+		err := c.computeChangedFiles()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(_mockResultsWriter.WrittenResults["/results/component-affected"]).To(Equal("false"))
+		g.Expect(c.Results.ChangedFiles.ComponentAffected).To(BeFalse())
+	})
+
+	t.Run("should report component affected by any change without patterns", func(t *testing.T) {
+		beforeEach()
+		_mockGitCli.MergeBaseFunc = func(a, b string) (string, error) { return commit, nil }
+
+		err := c.computeChangedFiles()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(_mockResultsWriter.WrittenResults).To(Equal(map[string]string{
+			"/results/changed-files":      "",
+			"/results/component-affected": "false",
+		}))
+	})
+
+	t.Run("should use target branch from the merge remote by default", func(t *testing.T) {
+		beforeEach()
+		c.Params.ChangedFilesBase = ""
+		c.Params.MergeTargetBranch = true
+		c.Params.TargetBranch = "release-1.0"
+		c.Params.MergeSourceRepoURL = "https://git.test/upstream/repo.git"
+
+		var fetchOpts []cliwrappers.GitFetchOptions
+		_mockGitCli.FetchWithRefspecFunc = func(opts cliwrappers.GitFetchOptions) error {
+			fetchOpts = append(fetchOpts, opts)
+			return nil
+		}
+		_mockGitCli.MergeBaseFunc = func(a, b string) (string, error) { return "base123", nil }
+
+		err := c.computeChangedFiles()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(fetchOpts).To(HaveLen(1))
+		g.Expect(fetchOpts[0].Remote).To(Equal("merge-source"))
+		g.Expect(fetchOpts[0].Refspec).To(Equal("+release-1.0:" + changedFilesBaseRef))
+		g.Expect(c.Results.ChangedFiles.Base).To(Equal("release-1.0"))
+	})
+
+	t.Run("should deepen shallow history until merge base is found", func(t *testing.T) {
+		beforeEach()
+		makeShallow()
+
+		var fetchOpts []cliwrappers.GitFetchOptions
+		_mockGitCli.FetchWithRefspecFunc = func(opts cliwrappers.GitFetchOptions) error {
+			fetchOpts = append(fetchOpts, opts)
+			return nil
+		}
+		_mockGitCli.MergeBaseFunc = func(a, b string) (string, error) {
+			if len(fetchOpts) < 3 {
+				return "", errors.New("exit status 1")
+			}
+			return "base123", nil
+		}
+
+		err := c.computeChangedFiles()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(fetchOpts).To(HaveLen(3))
+		g.Expect(fetchOpts[1]).To(Equal(cliwrappers.GitFetchOptions{
+			Remote: "origin", Refspec: commit + " +main:" + changedFilesBaseRef, Deepen: 50, MaxAttempts: 3,
+		}))
+		g.Expect(fetchOpts[2].Deepen).To(Equal(100))
+		g.Expect(c.Results.ChangedFiles.MergeBase).To(Equal("base123"))
+	})
+
+	t.Run("should fetch full history after deepening limit", func(t *testing.T) {
+		beforeEach()
+		makeShallow()
+
+		var fetchOpts []cliwrappers.GitFetchOptions
+		_mockGitCli.FetchWithRefspecFunc = func(opts cliwrappers.GitFetchOptions) error {
+			fetchOpts = append(fetchOpts, opts)
+			if opts.Unshallow {
+				g.Expect(os.Remove(filepath.Join(c.getCheckoutDir(), ".git", "shallow"))).To(Succeed())
+			}
+			return nil
+		}
+		_mockGitCli.MergeBaseFunc = func(a, b string) (string, error) {
+			return "", errors.New("exit status 1")
+		}
+
+		err := c.computeChangedFiles()
+
+		g.Expect(err).To(MatchError(ContainSubstring("failed to find merge base of abc123 and branch 'main'")))
+		g.Expect(fetchOpts).To(HaveLen(changedFilesMaxDeepen + 2))
+		g.Expect(fetchOpts[changedFilesMaxDeepen].Deepen).To(Equal(800))
+		g.Expect(fetchOpts[changedFilesMaxDeepen+1].Unshallow).To(BeTrue())
+	})
+
+	t.Run("should fail without deepening when repository is not shallow", func(t *testing.T) {
+		beforeEach()
+
+		fetches := 0
+		_mockGitCli.FetchWithRefspecFunc = func(opts cliwrappers.GitFetchOptions) error {
+			fetches++
+			return nil
+		}
+		_mockGitCli.MergeBaseFunc = func(a, b string) (string, error) {
+			return "", errors.New("exit status 1")
+		}
+
+		err := c.computeChangedFiles()
+
+		g.Expect(err).To(MatchError(ContainSubstring("failed to find merge base")))
+		g.Expect(fetches).To(Equal(1))
+	})
+
+	t.Run("should fail when base branch cannot be fetched", func(t *testing.T) {
+		beforeEach()
+		_mockGitCli.FetchWithRefspecFunc = func(opts cliwrappers.GitFetchOptions) error {
+			return errors.New("couldn't find remote ref main")
+		}
+
+		err := c.computeChangedFiles()
+
+		g.Expect(err).To(MatchError(ContainSubstring("failed to fetch changed files base 'main'")))
+		g.Expect(_mockResultsWriter.WrittenResults).To(BeEmpty())
+	})
+}
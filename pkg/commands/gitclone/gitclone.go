@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
@@ -15,6 +16,8 @@ import (
 
 type CliWrappers struct {
 	GitCli cliwrappers.GitCliInterface
+	// GpgCli is initialized only when GPG keys are to be imported for signature verification.
+	GpgCli cliwrappers.GpgCliInterface
 }
 
 type GitClone struct {
@@ -23,6 +26,10 @@ type GitClone struct {
 	Results       Results
 	ResultsWriter common.ResultsWriterInterface
 	internalDir   string
+
+	// referenceLock is held while the clone borrows objects from referenceObjectsDir.
+	referenceLock       *fileLock
+	referenceObjectsDir string
 }
 
 func New(cmd *cobra.Command) (*GitClone, error) {
@@ -100,6 +107,13 @@ func (c *GitClone) Run() error {
 		}
 	}
 
+	if c.Params.ReferenceDir != "" {
+		if err := c.prepareReference(); err != nil {
+			return err
+		}
+		defer c.releaseReference()
+	}
+
 	if err := c.performClone(); err != nil {
 		return err
 	}
@@ -114,6 +128,30 @@ func (c *GitClone) Run() error {
 		}
 	}
 
+	if c.Params.MergeRefs != "" {
+		if err := c.mergeRefs(); err != nil {
+			return err
+		}
+	}
+
+	if c.changedFilesEnabled() {
+		if err := c.computeChangedFiles(); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.LFS {
+		if err := c.pullLFSObjects(); err != nil {
+			return err
+		}
+	}
+
+	if c.referenceObjectsDir != "" {
+		if err := c.dissociateReference(); err != nil {
+			return err
+		}
+	}
+
 	if c.Params.EnableSymlinkCheck {
 		exclude, err := parseCSV(c.Params.SymlinkCheckIgnorePattern)
 		if err != nil {
@@ -124,6 +162,18 @@ func (c *GitClone) Run() error {
 		}
 	}
 
+	if c.Params.CreateBundle != "" {
+		if err := c.createBundle(); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.SourceArchiveOutput != "" {
+		if err := c.createSourceArchive(); err != nil {
+			return err
+		}
+	}
+
 	return c.outputResults()
 }
 
@@ -136,6 +186,9 @@ func (c *GitClone) logParams() {
 	common.LogParameters(ParamsConfig, c.Params, "url", "merge-source-repo-url")
 }
 
+// cloneFilterRegex matches the object filter specs of git rev-list --filter.
+var cloneFilterRegex = regexp.MustCompile(`^(blob:none|blob:limit=\d+[kmgKMG]?|tree:\d+|object:type=(blob|tree|commit|tag)|sparse:oid=[\w./:^{}-]+|combine:[\w%:=+.-]+)$`)
+
 // normalizeGitURL strips trailing slashes and ".git" suffix for URL comparison.
 func normalizeGitURL(rawURL string) string {
 	rawURL = strings.TrimSuffix(rawURL, "/")
@@ -144,12 +197,27 @@ func normalizeGitURL(rawURL string) string {
 }
 
 func (c *GitClone) validateParams() error {
-	if c.Params.URL == "" {
-		return fmt.Errorf("url parameter is required")
+	if c.Params.URL == "" && c.Params.Bundle == "" {
+		return fmt.Errorf("url parameter is required unless bundle is set")
+	}
+	if c.Params.Bundle != "" && c.Params.CloneFilter != "" {
+		return fmt.Errorf("clone-filter cannot be used with bundle")
+	}
+	if c.Params.Bundle != "" && c.Params.ReferenceDir != "" {
+		return fmt.Errorf("reference-dir cannot be used with bundle")
+	}
+	if c.Params.CreateBundle != "" && c.Params.Bundle == "" && c.Params.Depth != 0 {
+		return fmt.Errorf("create-bundle requires depth to be 0, a bundle of a shallow clone is incomplete")
+	}
+	if c.Params.CreateBundle != "" && c.Params.CloneFilter != "" {
+		return fmt.Errorf("create-bundle cannot be used with clone-filter")
 	}
 	if c.Params.Depth < 0 {
 		return fmt.Errorf("depth must be >= 0 (0 means full history)")
 	}
+	if c.Params.CloneFilter != "" && !cloneFilterRegex.MatchString(c.Params.CloneFilter) {
+		return fmt.Errorf("clone-filter '%s' is invalid, expected a filter spec such as blob:none, blob:limit=<n>[kmg], tree:<depth> or object:type=<type>", c.Params.CloneFilter)
+	}
 	if c.Params.MergeSourceDepth < 0 {
 		return fmt.Errorf("merge-source-depth must be >= 0 (0 means full history)")
 	}
@@ -162,6 +230,51 @@ func (c *GitClone) validateParams() error {
 	if c.Params.MergeTargetBranch && c.Params.TargetBranch == "" {
 		return fmt.Errorf("target-branch is required when merge-target-branch is true")
 	}
+	if c.Params.VerifySignatures && c.Params.SignersDirectory == "" {
+		return fmt.Errorf("signers-directory is required when verify-signatures is true")
+	}
+	if c.Params.VerifyTagSignature && !c.Params.VerifySignatures {
+		return fmt.Errorf("verify-tag-signature requires verify-signatures to be true")
+	}
+	if c.Params.VerifyTagSignature && c.Params.Revision == "" {
+		return fmt.Errorf("revision is required when verify-tag-signature is true")
+	}
+	if c.Params.SourceArchiveDigestOutput != "" && c.Params.SourceArchiveOutput == "" {
+		return fmt.Errorf("source-archive-digest-output requires source-archive-output")
+	}
+	if c.Params.SourceArchiveOutput != "" {
+		if err := c.checkSourceArchiveOutput(); err != nil {
+			return err
+		}
+	}
+	if _, err := newSubmodulePolicy(c.Params); err != nil {
+		return err
+	}
+	if _, err := parseMergeRefs(c.Params.MergeRefs); err != nil {
+		return err
+	}
+	if c.changedFilesEnabled() && c.changedFilesBase() == "" {
+		return fmt.Errorf("changed-files-base is required when merge-target-branch is false")
+	}
+	if patterns, err := parseCSV(c.Params.ChangedFilesPatterns); err != nil {
+		return fmt.Errorf("failed to parse changed-files-patterns: %w", err)
+	} else if _, err := common.CompilePathPatterns(patterns); err != nil {
+		return fmt.Errorf("changed-files-patterns: %w", err)
+	}
+
+	if c.Params.ReferenceDir != "" {
+		absReference, err := filepath.Abs(c.Params.ReferenceDir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve reference dir: %w", err)
+		}
+		absCheckout, err := filepath.Abs(c.getCheckoutDir())
+		if err != nil {
+			return fmt.Errorf("failed to resolve checkout dir: %w", err)
+		}
+		if absReference == absCheckout || strings.HasPrefix(absReference, absCheckout+string(filepath.Separator)) {
+			return fmt.Errorf("reference-dir must not be inside the checkout directory")
+		}
+	}
 
 	// Validate subdirectory for path traversal
 	if c.Params.Subdirectory != "" {
@@ -267,6 +380,24 @@ func (c *GitClone) performClone() error {
 		return err
 	}
 
+	if c.referenceObjectsDir != "" {
+		if err := c.addReferenceAlternates(); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.VerifySignatures {
+		if err := c.setupSignatureVerification(); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.LFS {
+		if err := c.CliWrappers.GitCli.LfsInstall(); err != nil {
+			return fmt.Errorf("failed to configure git LFS, is git-lfs installed? %w", err)
+		}
+	}
+
 	// Configure sparse checkout if directories are specified
 	if c.Params.SparseCheckoutDirectories != "" {
 		directories, err := parseCSV(c.Params.SparseCheckoutDirectories)
@@ -278,8 +409,17 @@ func (c *GitClone) performClone() error {
 		}
 	}
 
-	l.Logger.Debugf("Adding remote origin: %s", common.SanitizeURL(c.Params.URL))
-	if _, err := c.CliWrappers.GitCli.RemoteAdd("origin", c.Params.URL); err != nil {
+	originURL := c.Params.URL
+	if c.Params.Bundle != "" {
+		bundlePath, err := c.verifyBundle()
+		if err != nil {
+			return err
+		}
+		originURL = bundlePath
+	}
+
+	l.Logger.Debugf("Adding remote origin: %s", common.SanitizeURL(originURL))
+	if _, err := c.CliWrappers.GitCli.RemoteAdd("origin", originURL); err != nil {
 		return err
 	}
 
@@ -299,6 +439,20 @@ func (c *GitClone) performClone() error {
 		return err
 	}
 
+	// Verify before fetching anything else, FETCH_HEAD still points to the fetched revision.
+	if c.Params.VerifySignatures {
+		if c.Params.VerifyTagSignature {
+			if err := c.verifySignature(checkoutRef, signatureTypeTag); err != nil {
+				return err
+			}
+		}
+		if err := c.verifySignature("HEAD", signatureTypeCommit); err != nil {
+			return err
+		}
+	}
+
+	// In a partial clone, origin is a promisor remote and tags are fetched
+	// with the filter it was configured with by the first fetch.
 	if c.Params.FetchTags {
 		if _, err := c.CliWrappers.GitCli.FetchTags(); err != nil {
 			return err
@@ -311,7 +465,7 @@ func (c *GitClone) performClone() error {
 		if err != nil {
 			return fmt.Errorf("failed to parse submodule-paths: %w", err)
 		}
-		if err := c.CliWrappers.GitCli.SubmoduleUpdate(true, c.Params.Depth, paths); err != nil {
+		if err := c.updateSubmodules(paths); err != nil {
 			return err
 		}
 
@@ -333,14 +487,21 @@ func (c *GitClone) fetchRevision() error {
 		refspec = c.Params.Revision
 	}
 
-	l.Logger.Debugf("Fetching from origin (depth=%d, refspec=%s)", c.Params.Depth, refspec)
+	// Bundles don't support shallow fetches, the full history is always fetched from them.
+	depth := c.Params.Depth
+	if c.Params.Bundle != "" {
+		depth = 0
+	}
+
+	l.Logger.Debugf("Fetching from origin (depth=%d, filter=%s, refspec=%s)", depth, c.Params.CloneFilter, refspec)
 
 	maxAttempts := max(c.Params.RetryMaxAttempts, 1)
 
 	err := c.CliWrappers.GitCli.FetchWithRefspec(cliwrappers.GitFetchOptions{
 		Remote:      "origin",
 		Refspec:     refspec,
-		Depth:       c.Params.Depth,
+		Depth:       depth,
+		Filter:      c.Params.CloneFilter,
 		Submodules:  c.Params.Submodules,
 		MaxAttempts: maxAttempts,
 	})
@@ -357,6 +518,22 @@ func parseCSV(input string) ([]string, error) {
 	return reader.Read()
 }
 
+// configureMergeAuthor sets the identity git uses for merge commits.
+func (c *GitClone) configureMergeAuthor() error {
+	if err := c.CliWrappers.GitCli.ConfigLocal("user.email", c.Params.MergeCommitAuthorEmail); err != nil {
+		return err
+	}
+	return c.CliWrappers.GitCli.ConfigLocal("user.name", c.Params.MergeCommitAuthorName)
+}
+
+// mergeRemote returns the name of the remote to fetch the target branch from.
+func (c *GitClone) mergeRemote() string {
+	if c.Params.MergeSourceRepoURL != "" && normalizeGitURL(c.Params.URL) != normalizeGitURL(c.Params.MergeSourceRepoURL) {
+		return "merge-source"
+	}
+	return "origin"
+}
+
 func (c *GitClone) mergeTargetBranch() error {
 	if c.Params.Depth == 1 {
 		l.Logger.Warning("Shallow clone with depth=1 may cause merge conflicts due to insufficient commit history.")
@@ -366,28 +543,27 @@ func (c *GitClone) mergeTargetBranch() error {
 		l.Logger.Warning("Shallow fetch with merge-source-depth=1 may cause merge conflicts due to insufficient commit history.")
 	}
 
-	mergeRemote := "origin"
-	if c.Params.MergeSourceRepoURL != "" {
-		normalizedOrigin := normalizeGitURL(c.Params.URL)
-		normalizedMerge := normalizeGitURL(c.Params.MergeSourceRepoURL)
-
-		if normalizedOrigin == normalizedMerge {
+	mergeRemote := c.mergeRemote()
+	if mergeRemote == "origin" {
+		if c.Params.MergeSourceRepoURL != "" {
 			l.Logger.Debug("Merge source URL is the same as origin. Using existing 'origin' remote.")
-		} else {
-			l.Logger.Debugf("Merging from different repository: '%s'", c.Params.MergeSourceRepoURL)
-			mergeRemote = "merge-source"
-			if _, err := c.CliWrappers.GitCli.RemoteAdd(mergeRemote, c.Params.MergeSourceRepoURL); err != nil {
-				return err
-			}
+		}
+	} else {
+		l.Logger.Debugf("Merging from different repository: '%s'", c.Params.MergeSourceRepoURL)
+		if _, err := c.CliWrappers.GitCli.RemoteAdd(mergeRemote, c.Params.MergeSourceRepoURL); err != nil {
+			return err
 		}
 	}
 
 	maxAttempts := max(c.Params.RetryMaxAttempts, 1)
 
+	// With a partial clone, the merge source must be a promisor remote too,
+	// so that the objects missing for the merge can be fetched from it.
 	err := c.CliWrappers.GitCli.FetchWithRefspec(cliwrappers.GitFetchOptions{
 		Remote:      mergeRemote,
 		Refspec:     c.Params.TargetBranch,
 		Depth:       c.Params.MergeSourceDepth,
+		Filter:      c.Params.CloneFilter,
 		Submodules:  false,
 		MaxAttempts: maxAttempts,
 	})
@@ -395,12 +571,15 @@ func (c *GitClone) mergeTargetBranch() error {
 		return err
 	}
 
-	err = c.CliWrappers.GitCli.ConfigLocal("user.email", c.Params.MergeCommitAuthorEmail)
-	if err != nil {
-		return err
+	mergeRef := fmt.Sprintf("%s/%s", mergeRemote, c.Params.TargetBranch)
+
+	if c.Params.VerifySignatures {
+		if err := c.verifySignature(mergeRef, signatureTypeCommit); err != nil {
+			return err
+		}
 	}
-	err = c.CliWrappers.GitCli.ConfigLocal("user.name", c.Params.MergeCommitAuthorName)
-	if err != nil {
+
+	if err := c.configureMergeAuthor(); err != nil {
 		return err
 	}
 
@@ -410,7 +589,6 @@ func (c *GitClone) mergeTargetBranch() error {
 		return err
 	}
 
-	mergeRef := fmt.Sprintf("%s/%s", mergeRemote, c.Params.TargetBranch)
 	message := fmt.Sprintf("Merge branch '%s' from %s into %s", c.Params.TargetBranch, mergeRemote, currentSha)
 	merge, err := c.CliWrappers.GitCli.Merge(mergeRef, message)
 	if err != nil {
@@ -54,6 +54,188 @@ func Test_GitClone_validateParams(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "should pass with clone filter",
+			params: Params{
+				URL:              "https://git.test/user/repo.git",
+				CloneFilter:      "blob:none",
+				RetryMaxAttempts: 1,
+			},
+			expectError: false,
+		},
+		{
+			name: "should pass with treeless clone filter",
+			params: Params{
+				URL:              "https://git.test/user/repo.git",
+				CloneFilter:      "tree:0",
+				RetryMaxAttempts: 1,
+			},
+			expectError: false,
+		},
+		{
+			name: "should fail with invalid clone filter",
+			params: Params{
+				URL:              "https://git.test/user/repo.git",
+				CloneFilter:      "--upload-pack=evil",
+				RetryMaxAttempts: 1,
+			},
+			expectError: true,
+			errContains: "clone-filter '--upload-pack=evil' is invalid",
+		},
+		{
+			name: "should fail with reference dir inside checkout directory",
+			params: Params{
+				URL:              "https://git.test/user/repo.git",
+				OutputDir:        "/workspace/source",
+				ReferenceDir:     "/workspace/source/.cache",
+				RetryMaxAttempts: 1,
+			},
+			expectError: true,
+			errContains: "reference-dir must not be inside the checkout directory",
+		},
+		{
+			name: "should fail when verifying signatures without signers directory",
+			params: Params{
+				URL:              "https://git.test/user/repo.git",
+				RetryMaxAttempts: 1,
+				VerifySignatures: true,
+			},
+			expectError: true,
+			errContains: "signers-directory is required",
+		},
+		{
+			name: "should fail when verifying tag signature without verify-signatures",
+			params: Params{
+				URL:                "https://git.test/user/repo.git",
+				Revision:           "v1.0.0",
+				RetryMaxAttempts:   1,
+				VerifyTagSignature: true,
+			},
+			expectError: true,
+			errContains: "verify-tag-signature requires verify-signatures",
+		},
+		{
+			name: "should fail when verifying tag signature without revision",
+			params: Params{
+				URL:                "https://git.test/user/repo.git",
+				RetryMaxAttempts:   1,
+				VerifySignatures:   true,
+				SignersDirectory:   "/signers",
+				VerifyTagSignature: true,
+			},
+			expectError: true,
+			errContains: "revision is required when verify-tag-signature is true",
+		},
+		{
+			name: "should fail with invalid merge ref",
+			params: Params{
+				URL:              "https://git.test/user/repo.git",
+				MergeRefs:        "refs/pull/1/head,main:other",
+				RetryMaxAttempts: 1,
+			},
+			expectError: true,
+			errContains: "merge-refs entry 'main:other' has an invalid ref",
+		},
+		{
+			name: "should fail with source archive digest output without archive",
+			params: Params{
+				URL:                       "https://git.test/user/repo.git",
+				SourceArchiveDigestOutput: "/results/digest",
+				RetryMaxAttempts:          1,
+			},
+			expectError: true,
+			errContains: "source-archive-digest-output requires source-archive-output",
+		},
+		{
+			name: "should fail with negative submodule max depth",
+			params: Params{
+				URL:               "https://git.test/user/repo.git",
+				SubmoduleMaxDepth: -1,
+				RetryMaxAttempts:  1,
+			},
+			expectError: true,
+			errContains: "submodule-max-depth must be >= 0",
+		},
+		{
+			name: "should fail with absolute submodule URL pattern",
+			params: Params{
+				URL:                  "https://git.test/user/repo.git",
+				SubmoduleAllowedURLs: "/srv/*",
+				RetryMaxAttempts:     1,
+			},
+			expectError: true,
+			errContains: "submodule-allowed-urls",
+		},
+		{
+			name: "should accept bundle without URL",
+			params: Params{
+				Bundle:           "/bundles/repo.bundle",
+				RetryMaxAttempts: 1,
+			},
+			expectError: false,
+		},
+		{
+			name: "should fail with bundle and clone filter",
+			params: Params{
+				Bundle:           "/bundles/repo.bundle",
+				CloneFilter:      "blob:none",
+				RetryMaxAttempts: 1,
+			},
+			expectError: true,
+			errContains: "clone-filter cannot be used with bundle",
+		},
+		{
+			name: "should fail when creating bundle of shallow clone",
+			params: Params{
+				URL:              "https://git.test/user/repo.git",
+				Depth:            1,
+				CreateBundle:     "/out/repo.bundle",
+				RetryMaxAttempts: 1,
+			},
+			expectError: true,
+			errContains: "create-bundle requires depth to be 0",
+		},
+		{
+			name: "should accept creating bundle from bundle",
+			params: Params{
+				Bundle:           "/bundles/repo.bundle",
+				Depth:            1,
+				CreateBundle:     "/out/repo.bundle",
+				RetryMaxAttempts: 1,
+			},
+			expectError: false,
+		},
+		{
+			name: "should fail when computing changed files without base",
+			params: Params{
+				URL:                "https://git.test/user/repo.git",
+				RetryMaxAttempts:   1,
+				ChangedFilesOutput: "/tmp/changed-files",
+			},
+			expectError: true,
+			errContains: "changed-files-base is required when merge-target-branch is false",
+		},
+		{
+			name: "should accept target branch as changed files base",
+			params: Params{
+				URL:                     "https://git.test/user/repo.git",
+				RetryMaxAttempts:        1,
+				MergeTargetBranch:       true,
+				TargetBranch:            "main",
+				ComponentAffectedOutput: "/tmp/component-affected",
+			},
+			expectError: false,
+		},
+		{
+			name: "should fail with absolute changed files pattern",
+			params: Params{
+				URL:                  "https://git.test/user/repo.git",
+				RetryMaxAttempts:     1,
+				ChangedFilesPatterns: "src/*,/etc/*",
+			},
+			expectError: true,
+			errContains: "must not start with '/'",
+		},
 		{
 			name:        "should fail with empty URL",
 			params:      Params{},
@@ -428,12 +610,26 @@ func Test_GitClone_performClone(t *testing.T) {
 		c.Params.Submodules = true
 		c.Params.SubmodulePaths = "lib,vendor"
 
+		_mockGitCli.SubmoduleInitFunc = func(dir string, paths []string) error {
+			if dir == "" {
+				g.Expect(paths).To(Equal([]string{"lib", "vendor"}))
+			}
+			return nil
+		}
+		_mockGitCli.SubmoduleListFunc = func(dir string) ([]cliwrappers.GitSubmodule, error) {
+			if dir != "" {
+				return nil, nil
+			}
+			return []cliwrappers.GitSubmodule{
+				{Name: "lib", Path: "lib", URL: "https://git.test/user/lib.git", Commit: "abc"},
+				{Name: "vendor", Path: "vendor", URL: "https://git.test/user/vendor.git", Commit: "def"},
+			}, nil
+		}
 		isSubmoduleUpdateCalled := false
-		_mockGitCli.SubmoduleUpdateFunc = func(init bool, depth int, paths []string) error {
+		_mockGitCli.SubmoduleUpdateFunc = func(opts cliwrappers.GitSubmoduleUpdateOptions) error {
 			isSubmoduleUpdateCalled = true
-			g.Expect(init).To(BeTrue())
-			g.Expect(depth).To(Equal(1))
-			g.Expect(paths).To(Equal([]string{"lib", "vendor"}))
+			g.Expect(opts.Depth).To(Equal(1))
+			g.Expect(opts.Paths).To(Equal([]string{"lib", "vendor"}))
 			return nil
 		}
 
@@ -444,6 +640,70 @@ func Test_GitClone_performClone(t *testing.T) {
 	})
 }
 
+func Test_GitClone_performClone_cloneFilter(t *testing.T) {
+	g := NewWithT(t)
+
+	_mockGitCli := &mockGitCli{}
+	c := &GitClone{
+		CliWrappers: CliWrappers{GitCli: _mockGitCli},
+		Params: &Params{
+			URL:                       "https://git.test/user/repo.git",
+			Revision:                  "main",
+			Depth:                     1,
+			CloneFilter:               "blob:none",
+			SparseCheckoutDirectories: "src",
+			Submodules:                true,
+			FetchTags:                 true,
+			RetryMaxAttempts:          10,
+			OutputDir:                 t.TempDir(),
+		},
+	}
+
+	var calls []string
+	_mockGitCli.SetSparseCheckoutFunc = func(directories []string) error {
+		calls = append(calls, "sparse-checkout")
+		return nil
+	}
+	_mockGitCli.FetchWithRefspecFunc = func(opts cliwrappers.GitFetchOptions) error {
+		calls = append(calls, "fetch --filter="+opts.Filter)
+		return nil
+	}
+	_mockGitCli.CheckoutFunc = func(ref string) error {
+		calls = append(calls, "checkout")
+		return nil
+	}
+	_mockGitCli.FetchTagsFunc = func() ([]string, error) {
+		calls = append(calls, "fetch-tags")
+		return nil, nil
+	}
+	_mockGitCli.SubmoduleListFunc = func(dir string) ([]cliwrappers.GitSubmodule, error) {
+		if dir != "" {
+			return nil, nil
+		}
+		return []cliwrappers.GitSubmodule{{Name: "lib", Path: "lib", URL: "https://git.test/user/lib.git"}}, nil
+	}
+	_mockGitCli.SubmoduleUpdateFunc = func(opts cliwrappers.GitSubmoduleUpdateOptions) error {
+		calls = append(calls, "submodule-update --filter="+opts.Filter)
+		return nil
+	}
+	_mockGitCli.SubmoduleFetchTagsFunc = func() error {
+		calls = append(calls, "submodule-fetch-tags")
+		return nil
+	}
+
+	err := c.performClone()
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(calls).To(Equal([]string{
+		"sparse-checkout",
+		"fetch --filter=blob:none",
+		"checkout",
+		"fetch-tags",
+		"submodule-update --filter=blob:none",
+		"submodule-fetch-tags",
+	}))
+}
+
 func Test_GitClone_outputResults(t *testing.T) {
 	g := NewWithT(t)
 
@@ -874,6 +1134,24 @@ func Test_GitClone_mergeTargetBranch(t *testing.T) {
 		g.Expect(err.Error()).To(ContainSubstring("merge conflict"))
 	})
 
+	t.Run("should fetch merge source with clone filter", func(t *testing.T) {
+		beforeEach()
+		c.Params.CloneFilter = "blob:none"
+		c.Params.MergeSourceRepoURL = "https://git.test/upstream/repo.git"
+
+		var fetchOpts cliwrappers.GitFetchOptions
+		_mockGitCli.FetchWithRefspecFunc = func(opts cliwrappers.GitFetchOptions) error {
+			fetchOpts = opts
+			return nil
+		}
+
+		err := c.mergeTargetBranch()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(fetchOpts.Remote).To(Equal("merge-source"))
+		g.Expect(fetchOpts.Filter).To(Equal("blob:none"))
+	})
+
 	t.Run("should set config with correct email and name", func(t *testing.T) {
 		beforeEach()
 
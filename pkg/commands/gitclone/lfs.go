@@ -0,0 +1,74 @@
+package gitclone
+
+import (
+	"fmt"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// pullLFSObjects replaces the LFS pointer files of the checked out revision with their content.
+// Sparse checkout directories restrict the objects pulled for the repository itself.
+// Submodules are pulled only when enabled, and only those initialized, i.e. the ones
+// in submodule-paths if specified.
+func (c *GitClone) pullLFSObjects() error {
+	include, err := parseCSV(c.Params.SparseCheckoutDirectories)
+	if err != nil {
+		return fmt.Errorf("failed to parse sparse-checkout-directories: %w", err)
+	}
+
+	maxAttempts := max(c.Params.RetryMaxAttempts, 1)
+
+	l.Logger.Debug("Pulling LFS objects")
+	err = c.CliWrappers.GitCli.LfsPull(cliwrappers.GitLfsPullOptions{
+		Remote:      "origin",
+		Include:     include,
+		MaxAttempts: maxAttempts,
+	})
+	if err != nil {
+		return err
+	}
+
+	files, err := c.CliWrappers.GitCli.LfsListFiles(false, include)
+	if err != nil {
+		return err
+	}
+
+	if c.Params.Submodules {
+		l.Logger.Debug("Pulling LFS objects of submodules")
+		err = c.CliWrappers.GitCli.LfsPull(cliwrappers.GitLfsPullOptions{
+			Remote:      "origin",
+			Submodules:  true,
+			MaxAttempts: maxAttempts,
+		})
+		if err != nil {
+			return err
+		}
+
+		submoduleFiles, err := c.CliWrappers.GitCli.LfsListFiles(true, nil)
+		if err != nil {
+			return err
+		}
+		files = append(files, submoduleFiles...)
+	}
+
+	c.Results.LFSObjects, c.Results.LFSSize = countLFSObjects(files)
+	l.Logger.Infof("Fetched %d LFS objects (%d bytes)", c.Results.LFSObjects, c.Results.LFSSize)
+
+	return nil
+}
+
+// countLFSObjects returns the number and total size of distinct downloaded LFS objects.
+// Files with the same content share one object.
+func countLFSObjects(files []cliwrappers.GitLfsFile) (int, int64) {
+	seen := make(map[string]bool)
+	var size int64
+	for _, file := range files {
+		if !file.Downloaded || seen[file.Oid] {
+			continue
+		}
+		seen[file.Oid] = true
+		size += file.Size
+	}
+	return len(seen), size
+}
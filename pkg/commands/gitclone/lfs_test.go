@@ -0,0 +1,154 @@
+package gitclone
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_GitClone_pullLFSObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	var _mockGitCli *mockGitCli
+	var c *GitClone
+
+	beforeEach := func() {
+		_mockGitCli = &mockGitCli{}
+		c = &GitClone{
+			CliWrappers: CliWrappers{GitCli: _mockGitCli},
+			Params: &Params{
+				URL:              "https://git.test/user/repo.git",
+				LFS:              true,
+				RetryMaxAttempts: 5,
+			},
+		}
+	}
+
+	t.Run("should pull LFS objects restricted to sparse checkout directories", func(t *testing.T) {
+		beforeEach()
+		c.Params.SparseCheckoutDirectories = "src,docs"
+
+		var pullOpts []cliwrappers.GitLfsPullOptions
+		_mockGitCli.LfsPullFunc = func(opts cliwrappers.GitLfsPullOptions) error {
+			pullOpts = append(pullOpts, opts)
+			return nil
+		}
+		_mockGitCli.LfsListFilesFunc = func(submodules bool, include []string) ([]cliwrappers.GitLfsFile, error) {
+			g.Expect(submodules).To(BeFalse())
+			g.Expect(include).To(Equal([]string{"src", "docs"}))
+			return []cliwrappers.GitLfsFile{
+				{Name: "src/a.bin", Oid: "aaa", Size: 100, Downloaded: true},
+				{Name: "docs/a-copy.bin", Oid: "aaa", Size: 100, Downloaded: true},
+				{Name: "docs/b.bin", Oid: "bbb", Size: 20, Downloaded: true},
+			}, nil
+		}
+
+		err := c.pullLFSObjects()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(pullOpts).To(Equal([]cliwrappers.GitLfsPullOptions{
+			{Remote: "origin", Include: []string{"src", "docs"}, MaxAttempts: 5},
+		}))
+		g.Expect(c.Results.LFSObjects).To(Equal(2))
+		g.Expect(c.Results.LFSSize).To(Equal(int64(120)))
+	})
+
+	t.Run("should pull LFS objects of submodules when enabled", func(t *testing.T) {
+		beforeEach()
+		c.Params.Submodules = true
+
+		var pullOpts []cliwrappers.GitLfsPullOptions
+		_mockGitCli.LfsPullFunc = func(opts cliwrappers.GitLfsPullOptions) error {
+			pullOpts = append(pullOpts, opts)
+			return nil
+		}
+		_mockGitCli.LfsListFilesFunc = func(submodules bool, include []string) ([]cliwrappers.GitLfsFile, error) {
+			if submodules {
+				return []cliwrappers.GitLfsFile{{Name: "model.bin", Oid: "ccc", Size: 1000, Downloaded: true}}, nil
+			}
+			return []cliwrappers.GitLfsFile{{Name: "a.bin", Oid: "aaa", Size: 100, Downloaded: true}}, nil
+		}
+
+		err := c.pullLFSObjects()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(pullOpts).To(Equal([]cliwrappers.GitLfsPullOptions{
+			{Remote: "origin", MaxAttempts: 5},
+			{Remote: "origin", Submodules: true, MaxAttempts: 5},
+		}))
+		g.Expect(c.Results.LFSObjects).To(Equal(2))
+		g.Expect(c.Results.LFSSize).To(Equal(int64(1100)))
+	})
+
+	t.Run("should fail if pull fails", func(t *testing.T) {
+		beforeEach()
+
+		_mockGitCli.LfsPullFunc = func(opts cliwrappers.GitLfsPullOptions) error {
+			return errors.New("lfs pull failed")
+		}
+
+		err := c.pullLFSObjects()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("lfs pull failed"))
+	})
+}
+
+func Test_GitClone_Run_lfs(t *testing.T) {
+	g := NewWithT(t)
+
+	_mockGitCli := &mockGitCli{}
+	c := &GitClone{
+		CliWrappers:   CliWrappers{GitCli: _mockGitCli},
+		ResultsWriter: &mockResultsWriter{},
+		Params: &Params{
+			URL:              "https://git.test/user/repo.git",
+			LFS:              true,
+			SSLVerify:        true,
+			OutputDir:        t.TempDir(),
+			RetryMaxAttempts: 1,
+		},
+	}
+
+	var calls []string
+	_mockGitCli.SetEnvFunc = func(key, value string) {
+		calls = append(calls, "env "+key+"="+value)
+	}
+	_mockGitCli.LfsInstallFunc = func() error {
+		calls = append(calls, "lfs install")
+		return nil
+	}
+	_mockGitCli.CheckoutFunc = func(ref string) error {
+		calls = append(calls, "checkout")
+		return nil
+	}
+	_mockGitCli.LfsPullFunc = func(opts cliwrappers.GitLfsPullOptions) error {
+		calls = append(calls, "lfs pull")
+		return nil
+	}
+
+	err := c.Run()
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(calls).To(Equal([]string{"env GIT_LFS_SKIP_SMUDGE=1", "lfs install", "checkout", "lfs pull"}))
+}
+
+func Test_countLFSObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	count, size := countLFSObjects([]cliwrappers.GitLfsFile{
+		{Name: "a.bin", Oid: "aaa", Size: 10, Downloaded: true},
+		{Name: "b.bin", Oid: "bbb", Size: 20, Downloaded: false},
+		{Name: "c.bin", Oid: "aaa", Size: 10, Downloaded: true},
+	})
+
+	g.Expect(count).To(Equal(1))
+	g.Expect(size).To(Equal(int64(10)))
+
+	count, size = countLFSObjects(nil)
+
+	g.Expect(count).To(Equal(0))
+	g.Expect(size).To(Equal(int64(0)))
+}
@@ -0,0 +1,19 @@
+//go:build !unix
+
+package gitclone
+
+import "fmt"
+
+type fileLock struct{}
+
+func lockFile(path string, exclusive bool) (*fileLock, error) {
+	return nil, fmt.Errorf("locking of reference-dir is only supported on Unix platforms")
+}
+
+func (l *fileLock) downgrade() error {
+	return nil
+}
+
+func (l *fileLock) unlock() error {
+	return nil
+}
@@ -0,0 +1,56 @@
+//go:build unix
+
+package gitclone
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// fileLock is an advisory lock of a file, shared with other processes locking the same file.
+type fileLock struct {
+	file *os.File
+}
+
+// lockFile blocks until it acquires an exclusive or shared lock of the file, creating it if needed.
+func lockFile(path string, exclusive bool) (*fileLock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644) //nolint:gosec // path is within reference-dir
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	lock := &fileLock{file: file}
+	if err := lock.flock(exclusive); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return lock, nil
+}
+
+// downgrade turns an exclusive lock into a shared one.
+// Note, the conversion is not atomic, another process may acquire an exclusive lock in between.
+func (l *fileLock) downgrade() error {
+	return l.flock(false)
+}
+
+func (l *fileLock) unlock() error {
+	return l.file.Close()
+}
+
+func (l *fileLock) flock(exclusive bool) error {
+	how := unix.LOCK_SH
+	if exclusive {
+		how = unix.LOCK_EX
+	}
+	for {
+		err := unix.Flock(int(l.file.Fd()), how)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock %s: %w", l.file.Name(), err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,170 @@
+package gitclone
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// mergeRefsPrefix is the namespace the refs to merge are fetched into,
+// so that refs of different repositories with the same name don't clash.
+const mergeRefsPrefix = "refs/merge-refs/"
+
+// mergeRefSpec is an entry of merge-refs.
+type mergeRefSpec struct {
+	// URL of the repository to fetch the ref from, empty for origin.
+	URL string
+	Ref string
+}
+
+// parseMergeRefs parses the merge-refs parameter, a CSV list of '[<url>#]<ref>' entries.
+func parseMergeRefs(input string) ([]mergeRefSpec, error) {
+	entries, err := parseCSV(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse merge-refs: %w", err)
+	}
+
+	var specs []mergeRefSpec
+	for _, entry := range entries {
+		spec := mergeRefSpec{Ref: entry}
+		if i := strings.LastIndex(entry, "#"); i >= 0 {
+			spec.URL, spec.Ref = entry[:i], entry[i+1:]
+			if spec.URL == "" {
+				return nil, fmt.Errorf("merge-refs entry '%s' has an empty repository URL", common.SanitizeURL(entry))
+			}
+		}
+		if spec.Ref == "" {
+			return nil, fmt.Errorf("merge-refs entry '%s' has an empty ref", common.SanitizeURL(entry))
+		}
+		// The ref is used as the source of a refspec
+		if strings.HasPrefix(spec.Ref, "+") || strings.ContainsAny(spec.Ref, ": \t") {
+			return nil, fmt.Errorf("merge-refs entry '%s' has an invalid ref, it must not start with '+' or contain ':' or whitespace", common.SanitizeURL(entry))
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// mergeRefs merges the refs of merge-refs into HEAD in order, each with its own merge commit.
+// Refs from repositories other than origin are fetched through additional remotes.
+func (c *GitClone) mergeRefs() error {
+	specs, err := parseMergeRefs(c.Params.MergeRefs)
+	if err != nil {
+		return err
+	}
+
+	if err := c.configureMergeAuthor(); err != nil {
+		return err
+	}
+
+	maxAttempts := max(c.Params.RetryMaxAttempts, 1)
+	remotes := map[string]string{}
+
+	for i, spec := range specs {
+		remote := "origin"
+		repoURL := c.Results.URL
+		// URLs that differ only in credentials refer to the same repository
+		normalized := normalizeGitURL(stripURLCredentials(spec.URL))
+		if spec.URL != "" && normalized != normalizeGitURL(stripURLCredentials(c.Params.URL)) {
+			repoURL = spec.URL
+			if existing, ok := remotes[normalized]; ok {
+				remote = existing
+			} else {
+				remote = fmt.Sprintf("merge-ref-%d", len(remotes)+1)
+				l.Logger.Debugf("Adding remote %s: %s", remote, common.SanitizeURL(spec.URL))
+				if _, err := c.CliWrappers.GitCli.RemoteAdd(remote, spec.URL); err != nil {
+					return err
+				}
+				remotes[normalized] = remote
+			}
+		}
+
+		fetchedRef := fmt.Sprintf("%s%d", mergeRefsPrefix, i)
+		l.Logger.Infof("Merging '%s' from %s (%d/%d)", spec.Ref, remote, i+1, len(specs))
+		err := c.CliWrappers.GitCli.FetchWithRefspec(cliwrappers.GitFetchOptions{
+			Remote:      remote,
+			Refspec:     fmt.Sprintf("+%s:%s", spec.Ref, fetchedRef),
+			Depth:       c.Params.MergeSourceDepth,
+			Filter:      c.Params.CloneFilter,
+			MaxAttempts: maxAttempts,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fetch merge ref '%s' from %s: %w", spec.Ref, remote, err)
+		}
+
+		sha, err := c.CliWrappers.GitCli.RevParse(fetchedRef, false, 0)
+		if err != nil {
+			return err
+		}
+
+		if c.Params.VerifySignatures {
+			if err := c.verifySignature(fetchedRef, signatureTypeCommit); err != nil {
+				return err
+			}
+		}
+
+		currentSha, err := c.CliWrappers.GitCli.RevParse("HEAD", false, 0)
+		if err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("Merge '%s' (%s) from %s into %s", spec.Ref, sha, remote, currentSha)
+		if _, err := c.CliWrappers.GitCli.Merge(fetchedRef, message); err != nil {
+			return c.mergeConflictError(spec, sha, err)
+		}
+
+		mergeCommit, err := c.CliWrappers.GitCli.RevParse("HEAD", false, 0)
+		if err != nil {
+			return err
+		}
+		l.Logger.Infof("Merged '%s' (%s), HEAD is now %s", spec.Ref, sha, mergeCommit)
+
+		c.Results.MergedRefs = append(c.Results.MergedRefs, MergedRefResult{
+			Ref:         spec.Ref,
+			URL:         stripURLCredentials(repoURL),
+			Sha:         sha,
+			MergeCommit: mergeCommit,
+		})
+		c.Results.MergedSha = mergeCommit
+	}
+
+	return nil
+}
+
+// stripURLCredentials removes the user info from a URL.
+// Unlike common.SanitizeURL, it leaves no placeholder, so the result can be compared.
+func stripURLCredentials(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.User == nil {
+		return rawURL
+	}
+	parsed.User = nil
+	return parsed.String()
+}
+
+// mergeConflictError reports the files a failed merge left in conflict, if any.
+func (c *GitClone) mergeConflictError(spec mergeRefSpec, sha string, mergeErr error) error {
+	files, err := c.CliWrappers.GitCli.UnmergedFiles()
+	if err != nil || len(files) == 0 {
+		return fmt.Errorf("failed to merge '%s' (%s): %w", spec.Ref, sha, mergeErr)
+	}
+
+	report := fmt.Sprintf("merge of '%s' (%s) conflicts in %d file(s):", spec.Ref, sha, len(files))
+	for _, file := range files {
+		report += "\n  " + file
+	}
+	if len(c.Results.MergedRefs) > 0 {
+		merged := make([]string, 0, len(c.Results.MergedRefs))
+		for _, ref := range c.Results.MergedRefs {
+			merged = append(merged, ref.Ref)
+		}
+		report += fmt.Sprintf("\nsuccessfully merged before: %s", strings.Join(merged, ", "))
+	}
+	l.Logger.Error(report)
+
+	return fmt.Errorf("%s: %w", report, mergeErr)
+}
@@ -16,14 +16,30 @@ type mockGitCli struct {
 	RemoteAddFunc          func(name, url string) (string, error)
 	FetchWithRefspecFunc   func(opts cliwrappers.GitFetchOptions) error
 	CheckoutFunc           func(ref string) error
-	SubmoduleUpdateFunc    func(init bool, depth int, paths []string) error
+	SubmoduleInitFunc      func(dir string, paths []string) error
+	SubmoduleListFunc      func(dir string) ([]cliwrappers.GitSubmodule, error)
+	SubmoduleUpdateFunc    func(opts cliwrappers.GitSubmoduleUpdateOptions) error
 	SubmoduleFetchTagsFunc func() error
 	RevParseFunc           func(ref string, short bool, length int) (string, error)
 	LogFunc                func(format string, count int) (string, error)
+	MergeBaseFunc          func(a, b string) (string, error)
+	DiffNamesFunc          func(from, to string) ([]string, error)
+	UnmergedFilesFunc      func() ([]string, error)
 	ConfigLocalFunc        func(key, value string) error
 	CommitFunc             func(message string) (string, error)
 	MergeFunc              func(ref, message string) (string, error)
 	FetchTagsFunc          func() ([]string, error)
+	InitBareFunc           func(dir string) error
+	FetchMirrorFunc        func(opts cliwrappers.GitFetchMirrorOptions) error
+	RepackAllFunc          func() error
+	UpdateRefFunc          func(ref, sha string) error
+	BundleVerifyFunc       func(path string) error
+	BundleCreateFunc       func(path string, refs []string) error
+	VerifyCommitFunc       func(ref string) (*cliwrappers.GitSignature, error)
+	VerifyTagFunc          func(ref string) (*cliwrappers.GitSignature, error)
+	LfsInstallFunc         func() error
+	LfsPullFunc            func(opts cliwrappers.GitLfsPullOptions) error
+	LfsListFilesFunc       func(submodules bool, include []string) ([]cliwrappers.GitLfsFile, error)
 }
 
 func (m *mockGitCli) SetEnv(key, value string) {
@@ -81,9 +97,23 @@ func (m *mockGitCli) Checkout(ref string) error {
 	return nil
 }
 
-func (m *mockGitCli) SubmoduleUpdate(init bool, depth int, paths []string) error {
+func (m *mockGitCli) SubmoduleInit(dir string, paths []string) error {
+	if m.SubmoduleInitFunc != nil {
+		return m.SubmoduleInitFunc(dir, paths)
+	}
+	return nil
+}
+
+func (m *mockGitCli) SubmoduleList(dir string) ([]cliwrappers.GitSubmodule, error) {
+	if m.SubmoduleListFunc != nil {
+		return m.SubmoduleListFunc(dir)
+	}
+	return nil, nil
+}
+
+func (m *mockGitCli) SubmoduleUpdate(opts cliwrappers.GitSubmoduleUpdateOptions) error {
 	if m.SubmoduleUpdateFunc != nil {
-		return m.SubmoduleUpdateFunc(init, depth, paths)
+		return m.SubmoduleUpdateFunc(opts)
 	}
 	return nil
 }
@@ -123,6 +153,117 @@ func (m *mockGitCli) Log(format string, count int) (string, error) {
 	return "", nil
 }
 
+func (m *mockGitCli) MergeBase(a, b string) (string, error) {
+	if m.MergeBaseFunc != nil {
+		return m.MergeBaseFunc(a, b)
+	}
+	return "", nil
+}
+
+func (m *mockGitCli) DiffNames(from, to string) ([]string, error) {
+	if m.DiffNamesFunc != nil {
+		return m.DiffNamesFunc(from, to)
+	}
+	return nil, nil
+}
+
+func (m *mockGitCli) UnmergedFiles() ([]string, error) {
+	if m.UnmergedFilesFunc != nil {
+		return m.UnmergedFilesFunc()
+	}
+	return nil, nil
+}
+
+func (m *mockGitCli) UpdateRef(ref, sha string) error {
+	if m.UpdateRefFunc != nil {
+		return m.UpdateRefFunc(ref, sha)
+	}
+	return nil
+}
+
+func (m *mockGitCli) BundleVerify(path string) error {
+	if m.BundleVerifyFunc != nil {
+		return m.BundleVerifyFunc(path)
+	}
+	return nil
+}
+
+func (m *mockGitCli) BundleCreate(path string, refs []string) error {
+	if m.BundleCreateFunc != nil {
+		return m.BundleCreateFunc(path, refs)
+	}
+	return nil
+}
+
+func (m *mockGitCli) InitBare(dir string) error {
+	if m.InitBareFunc != nil {
+		return m.InitBareFunc(dir)
+	}
+	return nil
+}
+
+func (m *mockGitCli) FetchMirror(opts cliwrappers.GitFetchMirrorOptions) error {
+	if m.FetchMirrorFunc != nil {
+		return m.FetchMirrorFunc(opts)
+	}
+	return nil
+}
+
+func (m *mockGitCli) RepackAll() error {
+	if m.RepackAllFunc != nil {
+		return m.RepackAllFunc()
+	}
+	return nil
+}
+
+func (m *mockGitCli) VerifyCommit(ref string) (*cliwrappers.GitSignature, error) {
+	if m.VerifyCommitFunc != nil {
+		return m.VerifyCommitFunc(ref)
+	}
+	return &cliwrappers.GitSignature{}, nil
+}
+
+func (m *mockGitCli) VerifyTag(ref string) (*cliwrappers.GitSignature, error) {
+	if m.VerifyTagFunc != nil {
+		return m.VerifyTagFunc(ref)
+	}
+	return &cliwrappers.GitSignature{}, nil
+}
+
+func (m *mockGitCli) LfsInstall() error {
+	if m.LfsInstallFunc != nil {
+		return m.LfsInstallFunc()
+	}
+	return nil
+}
+
+func (m *mockGitCli) LfsPull(opts cliwrappers.GitLfsPullOptions) error {
+	if m.LfsPullFunc != nil {
+		return m.LfsPullFunc(opts)
+	}
+	return nil
+}
+
+func (m *mockGitCli) LfsListFiles(submodules bool, include []string) ([]cliwrappers.GitLfsFile, error) {
+	if m.LfsListFilesFunc != nil {
+		return m.LfsListFilesFunc(submodules, include)
+	}
+	return nil, nil
+}
+
+var _ cliwrappers.GpgCliInterface = &mockGpgCli{}
+
+type mockGpgCli struct {
+	ImportKeysFunc func(keyFiles []string) error
+}
+
+func (m *mockGpgCli) ImportKeys(keyFiles []string) error {
+	if m.ImportKeysFunc != nil {
+		return m.ImportKeysFunc(keyFiles)
+	}
+	return nil
+}
+
 var _ common.ResultsWriterInterface = &mockResultsWriter{}
 
 type mockResultsWriter struct {
@@ -12,8 +12,14 @@ var ParamsConfig = map[string]common.Parameter{
 		ShortName:  "u",
 		EnvVarName: "KBC_GIT_CLONE_URL",
 		TypeKind:   reflect.String,
-		Usage:      "Repository URL to clone from.",
-		Required:   true,
+		Usage:      "Repository URL to clone from. Required unless bundle is set.",
+	},
+	"bundle": {
+		Name:         "bundle",
+		EnvVarName:   "KBC_GIT_CLONE_BUNDLE",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage:        "Path to a git bundle file to clone from instead of the URL, e.g. one written by create-bundle. The bundle is verified before fetching the revision from it. Set url too, to the repository the bundle was created from, otherwise the repository URL results are empty.",
 	},
 	"revision": {
 		Name:         "revision",
@@ -43,6 +49,36 @@ var ParamsConfig = map[string]common.Parameter{
 		DefaultValue: "",
 		Usage:        "CSV list of specific submodule paths to initialize and fetch. Only submodules in the specified directories and their subdirectories will be fetched. Empty string fetches all submodules. Parameter 'submodules' must be set to 'true' to make this parameter applicable.",
 	},
+	"submodule-allowed-urls": {
+		Name:         "submodule-allowed-urls",
+		EnvVarName:   "KBC_GIT_CLONE_SUBMODULE_ALLOWED_URLS",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage: "CSV list of hosts (e.g. 'github.com', '*.example.com') or URL patterns (e.g. 'https://github.com/org/*') submodules may be fetched from, including nested ones. " +
+			"Use '*' and '?' as wildcards ('*' matches across '/'). Empty list allows any host. " +
+			"Remote helper URLs such as ext:: are always rejected.",
+	},
+	"submodule-allow-local-urls": {
+		Name:         "submodule-allow-local-urls",
+		EnvVarName:   "KBC_GIT_CLONE_SUBMODULE_ALLOW_LOCAL_URLS",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Allow submodules with file:// URLs or local paths, including relative URLs of a repository cloned from a local path.",
+	},
+	"submodule-max-depth": {
+		Name:         "submodule-max-depth",
+		EnvVarName:   "KBC_GIT_CLONE_SUBMODULE_MAX_DEPTH",
+		TypeKind:     reflect.Int,
+		DefaultValue: "0",
+		Usage:        "Fail if submodules are nested deeper than N levels, 1 allows only the submodules of the repository itself. 0 means unlimited.",
+	},
+	"lfs": {
+		Name:         "lfs",
+		EnvVarName:   "KBC_GIT_CLONE_LFS",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Fetch and check out Git LFS objects for the checked-out revision. Only objects in the sparse checkout directories and the fetched submodules are fetched.",
+	},
 	"depth": {
 		Name:         "depth",
 		EnvVarName:   "KBC_GIT_CLONE_DEPTH",
@@ -50,6 +86,13 @@ var ParamsConfig = map[string]common.Parameter{
 		DefaultValue: "1",
 		Usage:        "Perform a shallow clone, fetching only the most recent N commits. Set to 0 to fetch the full commit history.",
 	},
+	"clone-filter": {
+		Name:         "clone-filter",
+		EnvVarName:   "KBC_GIT_CLONE_CLONE_FILTER",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage:        "Perform a partial clone with the given object filter, e.g. 'blob:none' for a blobless clone or 'tree:0' for a treeless clone. Missing objects are fetched on demand from the remote. Combine with sparse-checkout-directories to fetch only the blobs of the checked out directories. Filtering submodules requires git 2.36 or newer.",
+	},
 	"short-commit-length": {
 		Name:         "short-commit-length",
 		EnvVarName:   "KBC_GIT_CLONE_SHORT_COMMIT_LENGTH",
@@ -137,19 +180,79 @@ var ParamsConfig = map[string]common.Parameter{
 		DefaultValue: "0",
 		Usage:        "Perform a shallow fetch of the target branch, fetching only the most recent N commits. If 0, fetches the full history of the target branch.",
 	},
+	"merge-refs": {
+		Name:         "merge-refs",
+		EnvVarName:   "KBC_GIT_CLONE_MERGE_REFS",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage: "CSV list of refs to merge into the checked-out revision one after another, after the target branch when merge-target-branch is set. " +
+			"Each entry is a branch, tag, commit SHA or ref (e.g. refs/pull/42/head) fetched from origin, or '<repository-url>#<ref>' to fetch it from another repository. " +
+			"The merge stops at the first conflict. Parameter 'merge-source-depth' applies to the fetches.",
+	},
 	"merge-commit-author-name": {
 		Name:         "merge-commit-author-name",
 		EnvVarName:   "KBC_GIT_CLONE_MERGE_COMMIT_AUTHOR_NAME",
 		TypeKind:     reflect.String,
 		DefaultValue: "Konflux CI Git Clone",
-		Usage:        "Author name used for merge commits when --merge-target-branch is set.",
+		Usage:        "Author name used for merge commits when --merge-target-branch or --merge-refs is set.",
 	},
 	"merge-commit-author-email": {
 		Name:         "merge-commit-author-email",
 		EnvVarName:   "KBC_GIT_CLONE_MERGE_COMMIT_AUTHOR_EMAIL",
 		TypeKind:     reflect.String,
 		DefaultValue: "git-clone@konflux-ci.dev",
-		Usage:        "Author email used for merge commits when --merge-target-branch is set.",
+		Usage:        "Author email used for merge commits when --merge-target-branch or --merge-refs is set.",
+	},
+	"verify-signatures": {
+		Name:         "verify-signatures",
+		EnvVarName:   "KBC_GIT_CLONE_VERIFY_SIGNATURES",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Fail unless the checked-out commit, and the target branch head when merge-target-branch is set, are signed by a key from signers-directory.",
+	},
+	"verify-tag-signature": {
+		Name:         "verify-tag-signature",
+		EnvVarName:   "KBC_GIT_CLONE_VERIFY_TAG_SIGNATURE",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Also require the revision to be an annotated tag signed by a key from signers-directory. Parameter 'verify-signatures' must be set to 'true' to make this parameter applicable.",
+	},
+	"signers-directory": {
+		Name:         "signers-directory",
+		EnvVarName:   "KBC_GIT_CLONE_SIGNERS_DIRECTORY",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage:        "Path to directory containing the trusted signers: an 'allowed_signers' file for SSH signatures and/or GPG public keys (*.asc, *.gpg, *.pgp files).",
+	},
+	"changed-files-base": {
+		Name:         "changed-files-base",
+		EnvVarName:   "KBC_GIT_CLONE_CHANGED_FILES_BASE",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage:        "Branch to compute the changed files against. The files changed between the merge base of this branch and the checked-out revision are reported. Defaults to target-branch when merge-target-branch is set. The history of a shallow clone is deepened as needed to find the merge base.",
+	},
+	"changed-files-output": {
+		Name:         "changed-files-output",
+		EnvVarName:   "KBC_GIT_CLONE_CHANGED_FILES_OUTPUT",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage:        "Path to a file to write the list of changed files into, one path per line, relative to the repository root.",
+	},
+	"changed-files-patterns": {
+		Name:         "changed-files-patterns",
+		EnvVarName:   "KBC_GIT_CLONE_CHANGED_FILES_PATTERNS",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage: "CSV list of path patterns of the files that belong to the component. The component is affected if any changed file matches. " +
+			"Patterns are relative to the repository root and must not start with '/'. " +
+			"Use '*' and '?' as wildcards ('*' matches across '/'). Empty list matches all files.",
+	},
+	"component-affected-output": {
+		Name:         "component-affected-output",
+		EnvVarName:   "KBC_GIT_CLONE_COMPONENT_AFFECTED_OUTPUT",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage:        "Path to a file to write 'true' into if any changed file matches changed-files-patterns, 'false' otherwise.",
 	},
 	"output-dir": {
 		Name:         "output-dir",
@@ -159,6 +262,35 @@ var ParamsConfig = map[string]common.Parameter{
 		DefaultValue: ".",
 		Usage:        "Output directory where the repository will be cloned (the subdirectory parameter will be appended to this).",
 	},
+	"create-bundle": {
+		Name:         "create-bundle",
+		EnvVarName:   "KBC_GIT_CLONE_CREATE_BUNDLE",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage:        "Path to write a git bundle file of the cloned history into, for a later clone with the bundle parameter. The bundle contains HEAD, the revision as a ref with the same name, the target branch when merged, and the tags when fetched. Requires depth 0 when cloning from the URL.",
+	},
+	"source-archive-output": {
+		Name:         "source-archive-output",
+		EnvVarName:   "KBC_GIT_CLONE_SOURCE_ARCHIVE_OUTPUT",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage: "Path to write a reproducible tar.gz of the checked out tree into, including submodules and excluding .git. " +
+			"Entries are sorted, owned by root and have the commit timestamp as mtime. Must be outside of the checkout directory.",
+	},
+	"source-archive-digest-output": {
+		Name:         "source-archive-digest-output",
+		EnvVarName:   "KBC_GIT_CLONE_SOURCE_ARCHIVE_DIGEST_OUTPUT",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage:        "Path to a file to write the sha256 digest of the source archive into, e.g. 'sha256:abc...'. Requires source-archive-output.",
+	},
+	"reference-dir": {
+		Name:         "reference-dir",
+		EnvVarName:   "KBC_GIT_CLONE_REFERENCE_DIR",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage:        "Path to a persistent directory for bare mirrors of cloned repositories. The mirror of the repository is created or updated, and used as a reference to download only the missing objects. The checkout is dissociated from the mirror afterwards.",
+	},
 	"retry-max-attempts": {
 		Name:         "retry-max-attempts",
 		EnvVarName:   "KBC_GIT_CLONE_RETRY_MAX_ATTEMPTS",
@@ -184,11 +316,17 @@ var ParamsConfig = map[string]common.Parameter{
 
 type Params struct {
 	URL                       string `paramName:"url"`
+	Bundle                    string `paramName:"bundle"`
 	Revision                  string `paramName:"revision"`
 	Refspec                   string `paramName:"refspec"`
 	Submodules                bool   `paramName:"submodules"`
 	SubmodulePaths            string `paramName:"submodule-paths"`
+	SubmoduleAllowedURLs      string `paramName:"submodule-allowed-urls"`
+	SubmoduleAllowLocalURLs   bool   `paramName:"submodule-allow-local-urls"`
+	SubmoduleMaxDepth         int    `paramName:"submodule-max-depth"`
+	LFS                       bool   `paramName:"lfs"`
 	Depth                     int    `paramName:"depth"`
+	CloneFilter               string `paramName:"clone-filter"`
 	ShortCommitLength         int    `paramName:"short-commit-length"`
 	SSLVerify                 bool   `paramName:"ssl-verify"`
 	Subdirectory              string `paramName:"subdirectory"`
@@ -201,9 +339,21 @@ type Params struct {
 	TargetBranch              string `paramName:"target-branch"`
 	MergeSourceRepoURL        string `paramName:"merge-source-repo-url"`
 	MergeSourceDepth          int    `paramName:"merge-source-depth"`
+	MergeRefs                 string `paramName:"merge-refs"`
 	MergeCommitAuthorName     string `paramName:"merge-commit-author-name"`
 	MergeCommitAuthorEmail    string `paramName:"merge-commit-author-email"`
+	VerifySignatures          bool   `paramName:"verify-signatures"`
+	VerifyTagSignature        bool   `paramName:"verify-tag-signature"`
+	SignersDirectory          string `paramName:"signers-directory"`
+	ChangedFilesBase          string `paramName:"changed-files-base"`
+	ChangedFilesOutput        string `paramName:"changed-files-output"`
+	ChangedFilesPatterns      string `paramName:"changed-files-patterns"`
+	ComponentAffectedOutput   string `paramName:"component-affected-output"`
 	OutputDir                 string `paramName:"output-dir"`
+	CreateBundle              string `paramName:"create-bundle"`
+	SourceArchiveOutput       string `paramName:"source-archive-output"`
+	SourceArchiveDigestOutput string `paramName:"source-archive-digest-output"`
+	ReferenceDir              string `paramName:"reference-dir"`
 	RetryMaxAttempts          int    `paramName:"retry-max-attempts"`
 	BasicAuthDirectory        string `paramName:"basic-auth-directory"`
 	SSHDirectory              string `paramName:"ssh-directory"`
@@ -0,0 +1,148 @@
+package gitclone
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// referenceMirrorName returns the directory name of the mirror of the repository in reference-dir.
+// The name doesn't depend on credentials in the URL or on its ".git" suffix.
+func referenceMirrorName(repoURL string) string {
+	sum := sha256.Sum256([]byte(normalizeGitURL(common.SanitizeURL(repoURL))))
+	return hex.EncodeToString(sum[:16]) + ".git"
+}
+
+// prepareReference creates or updates the bare mirror of the repository in reference-dir.
+// The mirror is created in a temporary directory and renamed when complete, updates are
+// done in a single ref transaction, both under an exclusive lock of the mirror.
+// Afterwards, the lock is held shared until releaseReference, so that the mirror isn't
+// changed while the clone borrows objects from it.
+// Failing to create or update the mirror is not fatal, the clone just downloads more.
+func (c *GitClone) prepareReference() error {
+	referenceDir := c.Params.ReferenceDir
+	if err := os.MkdirAll(referenceDir, 0755); err != nil {
+		return fmt.Errorf("failed to create reference directory: %w", err)
+	}
+
+	mirrorDir, err := filepath.Abs(filepath.Join(referenceDir, referenceMirrorName(c.Params.URL)))
+	if err != nil {
+		return fmt.Errorf("failed to resolve reference mirror path: %w", err)
+	}
+
+	l.Logger.Debugf("Locking reference mirror %s", mirrorDir)
+	lock, err := lockFile(mirrorDir+".lock", true)
+	if err != nil {
+		return err
+	}
+	c.referenceLock = lock
+
+	maxAttempts := max(c.Params.RetryMaxAttempts, 1)
+
+	if _, err := os.Stat(mirrorDir); err == nil {
+		l.Logger.Infof("Updating reference mirror %s", mirrorDir)
+		err := c.CliWrappers.GitCli.FetchMirror(cliwrappers.GitFetchMirrorOptions{
+			GitDir:      mirrorDir,
+			URL:         c.Params.URL,
+			MaxAttempts: maxAttempts,
+		})
+		if err != nil {
+			l.Logger.Warnf("Failed to update reference mirror, using it as is: %s", err.Error())
+		}
+	} else {
+		l.Logger.Infof("Creating reference mirror %s", mirrorDir)
+		if err := c.createReferenceMirror(mirrorDir, maxAttempts); err != nil {
+			l.Logger.Warnf("Failed to create reference mirror, cloning without it: %s", err.Error())
+			c.releaseReference()
+			return nil
+		}
+	}
+
+	if err := lock.downgrade(); err != nil {
+		c.releaseReference()
+		return err
+	}
+
+	c.referenceObjectsDir = filepath.Join(mirrorDir, "objects")
+	return nil
+}
+
+// createReferenceMirror fetches the repository into a temporary bare repository
+// and moves it to mirrorDir, so that an incomplete mirror is never used.
+func (c *GitClone) createReferenceMirror(mirrorDir string, maxAttempts int) error {
+	tmpDir, err := os.MkdirTemp(filepath.Dir(mirrorDir), ".tmp-"+filepath.Base(mirrorDir)+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary mirror directory: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	if err := c.CliWrappers.GitCli.InitBare(tmpDir); err != nil {
+		return err
+	}
+	err = c.CliWrappers.GitCli.FetchMirror(cliwrappers.GitFetchMirrorOptions{
+		GitDir:      tmpDir,
+		URL:         c.Params.URL,
+		MaxAttempts: maxAttempts,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpDir, mirrorDir); err != nil {
+		return fmt.Errorf("failed to move mirror into place: %w", err)
+	}
+	return nil
+}
+
+// alternatesPath returns the path of the file listing the object stores the repository borrows from.
+func (c *GitClone) alternatesPath() string {
+	return filepath.Join(c.getCheckoutDir(), ".git", "objects", "info", "alternates")
+}
+
+// addReferenceAlternates makes the initialized repository borrow objects from the reference mirror,
+// so that fetches download only the objects missing in the mirror.
+func (c *GitClone) addReferenceAlternates() error {
+	alternates := c.alternatesPath()
+	if err := os.MkdirAll(filepath.Dir(alternates), 0755); err != nil {
+		return fmt.Errorf("failed to create objects info directory: %w", err)
+	}
+	if err := os.WriteFile(alternates, []byte(c.referenceObjectsDir+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write alternates: %w", err)
+	}
+	l.Logger.Debugf("Using objects of reference mirror %s", c.referenceObjectsDir)
+	return nil
+}
+
+// dissociateReference copies the objects borrowed from the reference mirror into the repository,
+// like git clone --dissociate, so that the checkout doesn't depend on the mirror.
+func (c *GitClone) dissociateReference() error {
+	l.Logger.Debug("Dissociating from reference mirror")
+	if err := c.CliWrappers.GitCli.RepackAll(); err != nil {
+		return fmt.Errorf("failed to dissociate from reference mirror: %w", err)
+	}
+	if err := os.Remove(c.alternatesPath()); err != nil {
+		return fmt.Errorf("failed to remove alternates: %w", err)
+	}
+	c.releaseReference()
+	return nil
+}
+
+// releaseReference releases the lock of the reference mirror, if held.
+func (c *GitClone) releaseReference() {
+	if c.referenceLock == nil {
+		return
+	}
+	if err := c.referenceLock.unlock(); err != nil {
+		l.Logger.Warnf("Failed to unlock reference mirror: %s", err.Error())
+	}
+	c.referenceLock = nil
+	c.referenceObjectsDir = ""
+}
@@ -8,13 +8,75 @@ import (
 
 // Results holds commit information gathered after a successful clone.
 type Results struct {
-	Commit          string `json:"commit"`
-	ShortCommit     string `json:"shortCommit"`
-	URL             string `json:"url"`
-	CommitTimestamp string `json:"commitTimestamp"`
-	MergedSha       string `json:"mergedSha,omitempty"`
-	ChainsGitURL    string `json:"CHAINS-GIT_URL"`
-	ChainsGitCommit string `json:"CHAINS-GIT_COMMIT"`
+	Commit          string            `json:"commit"`
+	ShortCommit     string            `json:"shortCommit"`
+	URL             string            `json:"url"`
+	CommitTimestamp string            `json:"commitTimestamp"`
+	MergedSha       string            `json:"mergedSha,omitempty"`
+	MergedRefs      []MergedRefResult `json:"mergedRefs,omitempty"`
+	Submodules      []SubmoduleResult `json:"submodules,omitempty"`
+	LFSObjects      int               `json:"lfsObjects,omitempty"`
+	LFSSize         int64             `json:"lfsSize,omitempty"`
+	Signatures      []SignatureResult `json:"signatures,omitempty"`
+	ChangedFiles    *ChangedFiles     `json:"changedFiles,omitempty"`
+	SourceArchive   *SourceArchive    `json:"sourceArchive,omitempty"`
+	ChainsGitURL    string            `json:"CHAINS-GIT_URL"`
+	ChainsGitCommit string            `json:"CHAINS-GIT_COMMIT"`
+}
+
+// MergedRefResult describes a ref merged by merge-refs.
+type MergedRefResult struct {
+	// Ref is the ref as given in merge-refs, without the repository URL.
+	Ref string `json:"ref"`
+	// URL is the repository the ref was fetched from, with credentials removed.
+	URL string `json:"url"`
+	// Sha is the SHA of the merged commit.
+	Sha string `json:"sha"`
+	// MergeCommit is the SHA of the resulting merge commit.
+	MergeCommit string `json:"mergeCommit"`
+}
+
+// SubmoduleResult describes a fetched submodule.
+type SubmoduleResult struct {
+	// Path is relative to the checkout directory, for nested submodules too.
+	Path string `json:"path"`
+	// URL is the resolved submodule URL, with credentials removed.
+	URL    string `json:"url"`
+	Commit string `json:"commit"`
+}
+
+// SourceArchive describes the archive written by source-archive-output.
+type SourceArchive struct {
+	Path string `json:"path"`
+	// Digest is the sha256 digest of the archive file, e.g. sha256:abc...
+	Digest string `json:"digest"`
+}
+
+// SignatureResult holds the outcome of a signature verification of a commit or tag.
+type SignatureResult struct {
+	// Ref is the verified ref as given to git, e.g. HEAD or origin/main.
+	Ref string `json:"ref"`
+	// Object is the SHA of the verified commit or tag object.
+	Object string `json:"object"`
+	// Type is "commit" or "tag".
+	Type string `json:"type"`
+	// Status is the verification status, currently always "good" as verification failures abort the clone.
+	Status string `json:"status"`
+	// Format is the signature format, "gpg" or "ssh".
+	Format string `json:"format"`
+	Signer string `json:"signer"`
+	Key    string `json:"key"`
+}
+
+// ChangedFiles summarizes the files changed by the checked-out revision.
+type ChangedFiles struct {
+	// Base is the branch the changes are computed against.
+	Base string `json:"base"`
+	// MergeBase is the SHA of the merge base of the base branch and the revision.
+	MergeBase string `json:"mergeBase"`
+	Count     int    `json:"count"`
+	// ComponentAffected is true when any changed file matches changed-files-patterns.
+	ComponentAffected bool `json:"componentAffected"`
 }
 
 func (c *GitClone) gatherCommitInfo() error {
@@ -39,6 +101,10 @@ func (c *GitClone) gatherCommitInfo() error {
 	}
 	c.Results.CommitTimestamp = timestamp
 
+	// The local bundle path is not a repository URL, it must not end up in the provenance
+	if c.Params.URL == "" && c.Params.Bundle != "" {
+		l.Logger.Warn("Cloned from bundle without url, the repository URL is not reported in the results")
+	}
 	c.Results.URL = c.Params.URL
 
 	// CHAINS results are duplicates for Tekton Chains provenance
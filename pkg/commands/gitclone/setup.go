@@ -13,9 +13,10 @@ import (
 )
 
 const (
-	envGitConfigGlobal = "GIT_CONFIG_GLOBAL"
-	envGitSSHCommand   = "GIT_SSH_COMMAND"
-	envGitSSLNoVerify  = "GIT_SSL_NO_VERIFY"
+	envGitConfigGlobal  = "GIT_CONFIG_GLOBAL"
+	envGitSSHCommand    = "GIT_SSH_COMMAND"
+	envGitSSLNoVerify   = "GIT_SSL_NO_VERIFY"
+	envGitLFSSkipSmudge = "GIT_LFS_SKIP_SMUDGE"
 )
 
 // cleanCheckoutDir removes all contents from the checkout directory while preserving
@@ -58,12 +59,18 @@ func (c *GitClone) cleanCheckoutDir() error {
 	return nil
 }
 
-// setupGitConfig configures git settings for SSL verification.
+// setupGitConfig configures git settings for SSL verification and LFS.
 func (c *GitClone) setupGitConfig() {
 	if !c.Params.SSLVerify {
 		l.Logger.Debug("Disabling SSL verification (GIT_SSL_NO_VERIFY=true)")
 		c.CliWrappers.GitCli.SetEnv(envGitSSLNoVerify, "true")
 	}
+	if c.Params.LFS {
+		// Checkouts leave LFS pointer files in place, the objects are pulled
+		// afterwards with retries and restricted to the checked out paths.
+		l.Logger.Debug("Skipping LFS smudge filter on checkout (GIT_LFS_SKIP_SMUDGE=1)")
+		c.CliWrappers.GitCli.SetEnv(envGitLFSSkipSmudge, "1")
+	}
 }
 
 // setupBasicAuth sets up git credentials from a basic-auth workspace.
@@ -0,0 +1,129 @@
+package gitclone
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+const (
+	signatureTypeCommit = "commit"
+	signatureTypeTag    = "tag"
+
+	envGnupgHome = "GNUPGHOME"
+
+	allowedSignersFileName = "allowed_signers"
+)
+
+// gpgKeyFileExtensions are the extensions of GPG public key files imported from signers-directory.
+var gpgKeyFileExtensions = []string{".asc", ".gpg", ".pgp"}
+
+// setupSignatureVerification configures git to trust only the signers from signers-directory.
+// The SSH allowed signers file is copied into internalDir and set in the repository config.
+// GPG keys are imported into a dedicated keyring in internalDir. All keys of this keyring
+// are trusted, the keyring contains nothing else.
+// Must be called after the repository is initialized.
+func (c *GitClone) setupSignatureVerification() error {
+	signersDir := c.Params.SignersDirectory
+
+	entries, err := os.ReadDir(signersDir)
+	if err != nil {
+		return fmt.Errorf("failed to read signers directory: %w", err)
+	}
+
+	allowedSignersPath := ""
+	var gpgKeyPaths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		srcPath := filepath.Join(signersDir, name)
+		// Skip directories and symlinks to dirs
+		if !fileExists(srcPath) {
+			continue
+		}
+		if name == allowedSignersFileName {
+			allowedSignersPath = srcPath
+		} else if slices.Contains(gpgKeyFileExtensions, filepath.Ext(name)) {
+			gpgKeyPaths = append(gpgKeyPaths, srcPath)
+		}
+	}
+
+	if allowedSignersPath == "" && len(gpgKeyPaths) == 0 {
+		return fmt.Errorf("no trusted signers found in %s: expected an %s file or GPG public keys (%v)",
+			signersDir, allowedSignersFileName, gpgKeyFileExtensions)
+	}
+
+	if allowedSignersPath != "" {
+		l.Logger.Debugf("Setting up SSH allowed signers from %s", allowedSignersPath)
+		destAllowedSigners := filepath.Join(c.internalDir, allowedSignersFileName)
+		if err := copyFile(allowedSignersPath, destAllowedSigners, 0400); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", allowedSignersFileName, err)
+		}
+		if err := c.CliWrappers.GitCli.ConfigLocal("gpg.ssh.allowedSignersFile", destAllowedSigners); err != nil {
+			return err
+		}
+	}
+
+	// Always use the dedicated keyring, so that keys of the user's keyring are never trusted.
+	gnupgHome := filepath.Join(c.internalDir, "gnupg")
+	if err := os.MkdirAll(gnupgHome, 0700); err != nil {
+		return fmt.Errorf("failed to create GPG home directory: %w", err)
+	}
+	// Keys are trusted by being imported, there is no web of trust to consult.
+	if err := os.WriteFile(filepath.Join(gnupgHome, "gpg.conf"), []byte("trust-model always\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write gpg.conf: %w", err)
+	}
+	c.CliWrappers.GitCli.SetEnv(envGnupgHome, gnupgHome)
+
+	if len(gpgKeyPaths) > 0 {
+		l.Logger.Debugf("Importing GPG keys: %v", gpgKeyPaths)
+		if c.CliWrappers.GpgCli == nil {
+			gpgCli, err := cliwrappers.NewGpgCli(cliwrappers.NewCliExecutor(), gnupgHome)
+			if err != nil {
+				return err
+			}
+			c.CliWrappers.GpgCli = gpgCli
+		}
+		if err := c.CliWrappers.GpgCli.ImportKeys(gpgKeyPaths); err != nil {
+			return fmt.Errorf("failed to import GPG keys: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// verifySignature verifies the signature of the commit or tag the ref points to
+// and records the signer in the results.
+func (c *GitClone) verifySignature(ref, signatureType string) error {
+	// Resolve the object first, the ref may be FETCH_HEAD which changes on next fetch.
+	object, err := c.CliWrappers.GitCli.RevParse(ref, false, 0)
+	if err != nil {
+		return err
+	}
+
+	var signature *cliwrappers.GitSignature
+	if signatureType == signatureTypeTag {
+		signature, err = c.CliWrappers.GitCli.VerifyTag(object)
+	} else {
+		signature, err = c.CliWrappers.GitCli.VerifyCommit(object)
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s (%s) is not signed by a trusted signer: %w", signatureType, ref, object, err)
+	}
+
+	l.Logger.Infof("Verified %s signature of %s (%s): signer '%s', key %s", signatureType, ref, object, signature.Signer, signature.Key)
+
+	c.Results.Signatures = append(c.Results.Signatures, SignatureResult{
+		Ref:    ref,
+		Object: object,
+		Type:   signatureType,
+		Status: "good",
+		Format: signature.Format,
+		Signer: signature.Signer,
+		Key:    signature.Key,
+	})
+	return nil
+}
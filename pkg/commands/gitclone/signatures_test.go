@@ -0,0 +1,268 @@
+package gitclone
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_GitClone_setupSignatureVerification(t *testing.T) {
+	g := NewWithT(t)
+
+	var _mockGitCli *mockGitCli
+	var _mockGpgCli *mockGpgCli
+	var c *GitClone
+	var signersDir string
+
+	beforeEach := func() {
+		signersDir = t.TempDir()
+		_mockGitCli = &mockGitCli{}
+		_mockGpgCli = &mockGpgCli{}
+		c = &GitClone{
+			CliWrappers: CliWrappers{GitCli: _mockGitCli, GpgCli: _mockGpgCli},
+			Params: &Params{
+				VerifySignatures: true,
+				SignersDirectory: signersDir,
+			},
+			internalDir: t.TempDir(),
+		}
+	}
+
+	t.Run("should configure SSH allowed signers", func(t *testing.T) {
+		beforeEach()
+		g.Expect(os.WriteFile(filepath.Join(signersDir, "allowed_signers"), []byte("dev@example.com ssh-ed25519 AAAA"), 0644)).To(Succeed())
+
+		configured := map[string]string{}
+		_mockGitCli.ConfigLocalFunc = func(key, value string) error {
+			configured[key] = value
+			return nil
+		}
+		env := map[string]string{}
+		_mockGitCli.SetEnvFunc = func(key, value string) {
+			env[key] = value
+		}
+		_mockGpgCli.ImportKeysFunc = func(keyFiles []string) error {
+			t.Fatal("no GPG keys expected to be imported")
+			return nil
+		}
+
+		err := c.setupSignatureVerification()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		allowedSigners := filepath.Join(c.internalDir, "allowed_signers")
+		g.Expect(configured).To(Equal(map[string]string{"gpg.ssh.allowedSignersFile": allowedSigners}))
+		content, err := os.ReadFile(allowedSigners)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(content)).To(Equal("dev@example.com ssh-ed25519 AAAA"))
+		g.Expect(env).To(HaveKeyWithValue("GNUPGHOME", filepath.Join(c.internalDir, "gnupg")))
+	})
+
+	t.Run("should import GPG keys into dedicated keyring", func(t *testing.T) {
+		beforeEach()
+		g.Expect(os.WriteFile(filepath.Join(signersDir, "dev.asc"), []byte("key"), 0644)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(signersDir, "ops.gpg"), []byte("key"), 0644)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(signersDir, "README"), []byte("docs"), 0644)).To(Succeed())
+		g.Expect(os.Mkdir(filepath.Join(signersDir, "sub.asc"), 0755)).To(Succeed())
+
+		var importedKeys []string
+		_mockGpgCli.ImportKeysFunc = func(keyFiles []string) error {
+			importedKeys = keyFiles
+			return nil
+		}
+
+		err := c.setupSignatureVerification()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(importedKeys).To(Equal([]string{
+			filepath.Join(signersDir, "dev.asc"),
+			filepath.Join(signersDir, "ops.gpg"),
+		}))
+		gpgConf, err := os.ReadFile(filepath.Join(c.internalDir, "gnupg", "gpg.conf"))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(gpgConf)).To(Equal("trust-model always\n"))
+	})
+
+	t.Run("should fail when no signers found", func(t *testing.T) {
+		beforeEach()
+		g.Expect(os.WriteFile(filepath.Join(signersDir, "README"), []byte("docs"), 0644)).To(Succeed())
+
+		err := c.setupSignatureVerification()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("no trusted signers found"))
+	})
+
+	t.Run("should fail when signers directory does not exist", func(t *testing.T) {
+		beforeEach()
+		c.Params.SignersDirectory = filepath.Join(signersDir, "missing")
+
+		err := c.setupSignatureVerification()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("failed to read signers directory"))
+	})
+
+	t.Run("should fail when GPG key import fails", func(t *testing.T) {
+		beforeEach()
+		g.Expect(os.WriteFile(filepath.Join(signersDir, "dev.asc"), []byte("key"), 0644)).To(Succeed())
+
+		_mockGpgCli.ImportKeysFunc = func(keyFiles []string) error {
+			return errors.New("no valid OpenPGP data found")
+		}
+
+		err := c.setupSignatureVerification()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("failed to import GPG keys"))
+	})
+}
+
+func Test_GitClone_verifySignatures(t *testing.T) {
+	g := NewWithT(t)
+
+	const fullSha = "abc123def456789012345678901234567890abcd"
+	const tagSha = "1111111111111111111111111111111111111111"
+	const targetSha = "2222222222222222222222222222222222222222"
+
+	var _mockGitCli *mockGitCli
+	var c *GitClone
+
+	signersDir := t.TempDir()
+	g.Expect(os.WriteFile(filepath.Join(signersDir, "allowed_signers"), []byte("dev@example.com ssh-ed25519 AAAA"), 0644)).To(Succeed())
+
+	beforeEach := func() {
+		_mockGitCli = &mockGitCli{}
+		_mockGitCli.RevParseFunc = func(ref string, short bool, length int) (string, error) {
+			switch ref {
+			case "FETCH_HEAD":
+				return tagSha, nil
+			case "origin/main":
+				return targetSha, nil
+			}
+			return fullSha, nil
+		}
+		c = &GitClone{
+			CliWrappers: CliWrappers{GitCli: _mockGitCli},
+			Params: &Params{
+				URL:              "https://git.test/user/repo.git",
+				Revision:         "v1.0.0",
+				OutputDir:        t.TempDir(),
+				TargetBranch:     "main",
+				RetryMaxAttempts: 1,
+				VerifySignatures: true,
+				SignersDirectory: signersDir,
+			},
+			internalDir: t.TempDir(),
+		}
+	}
+
+	t.Run("should verify checked out commit after checkout", func(t *testing.T) {
+		beforeEach()
+
+		var calls []string
+		_mockGitCli.CheckoutFunc = func(ref string) error {
+			calls = append(calls, "checkout")
+			return nil
+		}
+		_mockGitCli.VerifyCommitFunc = func(ref string) (*cliwrappers.GitSignature, error) {
+			calls = append(calls, "verify-commit "+ref)
+			return &cliwrappers.GitSignature{Format: "ssh", Signer: "dev@example.com", Key: "SHA256:abc"}, nil
+		}
+		_mockGitCli.VerifyTagFunc = func(ref string) (*cliwrappers.GitSignature, error) {
+			t.Fatal("tag signature is not expected to be verified")
+			return nil, nil
+		}
+		_mockGitCli.FetchTagsFunc = func() ([]string, error) {
+			calls = append(calls, "fetch-tags")
+			return nil, nil
+		}
+		c.Params.FetchTags = true
+
+		err := c.performClone()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(calls).To(Equal([]string{"checkout", "verify-commit " + fullSha, "fetch-tags"}))
+		g.Expect(c.Results.Signatures).To(Equal([]SignatureResult{
+			{Ref: "HEAD", Object: fullSha, Type: "commit", Status: "good", Format: "ssh", Signer: "dev@example.com", Key: "SHA256:abc"},
+		}))
+	})
+
+	t.Run("should verify tag signature of fetched revision", func(t *testing.T) {
+		beforeEach()
+		c.Params.VerifyTagSignature = true
+
+		_mockGitCli.VerifyTagFunc = func(ref string) (*cliwrappers.GitSignature, error) {
+			g.Expect(ref).To(Equal(tagSha))
+			return &cliwrappers.GitSignature{Format: "gpg", Signer: "Dev <dev@example.com>", Key: "FPR"}, nil
+		}
+
+		err := c.performClone()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Results.Signatures).To(HaveLen(2))
+		g.Expect(c.Results.Signatures[0]).To(Equal(SignatureResult{
+			Ref: "FETCH_HEAD", Object: tagSha, Type: "tag", Status: "good", Format: "gpg", Signer: "Dev <dev@example.com>", Key: "FPR",
+		}))
+		g.Expect(c.Results.Signatures[1].Type).To(Equal("commit"))
+	})
+
+	t.Run("should fail when commit signature is not trusted", func(t *testing.T) {
+		beforeEach()
+
+		_mockGitCli.VerifyCommitFunc = func(ref string) (*cliwrappers.GitSignature, error) {
+			return nil, errors.New("git verify-commit --raw: exit status 1")
+		}
+		isSubmoduleUpdateCalled := false
+		_mockGitCli.SubmoduleUpdateFunc = func(opts cliwrappers.GitSubmoduleUpdateOptions) error {
+			isSubmoduleUpdateCalled = true
+			return nil
+		}
+		c.Params.Submodules = true
+
+		err := c.performClone()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("commit HEAD (" + fullSha + ") is not signed by a trusted signer"))
+		g.Expect(isSubmoduleUpdateCalled).To(BeFalse())
+		g.Expect(c.Results.Signatures).To(BeEmpty())
+	})
+
+	t.Run("should verify target branch head before merging", func(t *testing.T) {
+		beforeEach()
+
+		var verified []string
+		_mockGitCli.VerifyCommitFunc = func(ref string) (*cliwrappers.GitSignature, error) {
+			verified = append(verified, ref)
+			return &cliwrappers.GitSignature{Format: "ssh", Signer: "maintainer@example.com"}, nil
+		}
+
+		err := c.mergeTargetBranch()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(verified).To(Equal([]string{targetSha}))
+		g.Expect(c.Results.Signatures).To(Equal([]SignatureResult{
+			{Ref: "origin/main", Object: targetSha, Type: "commit", Status: "good", Format: "ssh", Signer: "maintainer@example.com"},
+		}))
+	})
+
+	t.Run("should not merge unsigned target branch", func(t *testing.T) {
+		beforeEach()
+
+		_mockGitCli.VerifyCommitFunc = func(ref string) (*cliwrappers.GitSignature, error) {
+			return nil, errors.New("exit status 1")
+		}
+		_mockGitCli.MergeFunc = func(ref, message string) (string, error) {
+			t.Fatal("merge is not expected")
+			return "", nil
+		}
+
+		err := c.mergeTargetBranch()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("commit origin/main (" + targetSha + ") is not signed by a trusted signer"))
+	})
+}
@@ -0,0 +1,93 @@
+package gitclone
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// checkSourceArchiveOutput rejects archive paths inside the checkout directory,
+// the archive would contain itself.
+func (c *GitClone) checkSourceArchiveOutput() error {
+	archivePath, err := filepath.Abs(c.Params.SourceArchiveOutput)
+	if err != nil {
+		return fmt.Errorf("failed to resolve source-archive-output path: %w", err)
+	}
+	checkoutDir, err := filepath.Abs(c.getCheckoutDir())
+	if err != nil {
+		return fmt.Errorf("failed to resolve checkout directory: %w", err)
+	}
+	if rel, err := filepath.Rel(checkoutDir, archivePath); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("source-archive-output must not be inside the checkout directory")
+	}
+	return nil
+}
+
+// createSourceArchive writes a reproducible tar.gz of the checked out tree, including submodules
+// and excluding .git, with the commit timestamp as mtime of all entries.
+func (c *GitClone) createSourceArchive() error {
+	timestamp, err := strconv.ParseInt(c.Results.CommitTimestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid commit timestamp %q: %w", c.Results.CommitTimestamp, err)
+	}
+	mtime := time.Unix(timestamp, 0).UTC()
+
+	checkoutDir := c.getCheckoutDir()
+	paths, err := common.ListTreePaths(checkoutDir)
+	if err != nil {
+		return fmt.Errorf("failed to list files of %s: %w", checkoutDir, err)
+	}
+
+	// Write into a temporary file next to the output, so an incomplete archive is never left behind
+	archivePath := c.Params.SourceArchiveOutput
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0755); err != nil {
+		return fmt.Errorf("failed to create source archive directory: %w", err)
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(archivePath), ".source-archive-*")
+	if err != nil {
+		return fmt.Errorf("failed to create source archive: %w", err)
+	}
+	defer func() {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+	}()
+
+	l.Logger.Infof("Creating source archive %s of %d entries", archivePath, len(paths))
+	digest := sha256.New()
+	if err := common.WriteReproducibleTarGz(io.MultiWriter(tmpFile, digest), checkoutDir, paths, common.TarGzOptions{ModTime: mtime}); err != nil {
+		return fmt.Errorf("failed to write source archive: %w", err)
+	}
+	if err := tmpFile.Chmod(0644); err != nil {
+		return fmt.Errorf("failed to write source archive: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write source archive: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), archivePath); err != nil {
+		return fmt.Errorf("failed to write source archive: %w", err)
+	}
+
+	archiveDigest := "sha256:" + hex.EncodeToString(digest.Sum(nil))
+	l.Logger.Infof("Source archive digest: %s", archiveDigest)
+
+	if c.Params.SourceArchiveDigestOutput != "" {
+		if err := c.ResultsWriter.WriteResultString(archiveDigest, c.Params.SourceArchiveDigestOutput); err != nil {
+			return fmt.Errorf("failed to write source archive digest: %w", err)
+		}
+	}
+
+	c.Results.SourceArchive = &SourceArchive{
+		Path:   archivePath,
+		Digest: archiveDigest,
+	}
+	return nil
+}
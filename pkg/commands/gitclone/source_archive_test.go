@@ -0,0 +1,167 @@
+package gitclone
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_GitClone_createSourceArchive(t *testing.T) {
+	g := NewWithT(t)
+
+	var _mockResultsWriter *mockResultsWriter
+	var c *GitClone
+
+	beforeEach := func() {
+		_mockResultsWriter = &mockResultsWriter{}
+		outputDir := t.TempDir()
+		c = &GitClone{
+			ResultsWriter: _mockResultsWriter,
+			Params: &Params{
+				OutputDir:                 filepath.Join(outputDir, "source"),
+				SourceArchiveOutput:       filepath.Join(outputDir, "archive", "source.tar.gz"),
+				SourceArchiveDigestOutput: "/results/source-archive-digest",
+			},
+			Results: Results{CommitTimestamp: "1704067200"},
+		}
+
+		checkoutDir := c.getCheckoutDir()
+		for path, content := range map[string]string{
+			"README.md":            "readme\n",
+			"src/main.go":          "package main\n",
+			".git/HEAD":            "ref: refs/heads/main\n",
+			"lib/.git":             "gitdir: ../.git/modules/lib\n",
+			"lib/lib.go":           "package lib\n",
+			"lib/a/nested/file.go": "package nested\n",
+		} {
+			fullPath := filepath.Join(checkoutDir, path)
+			g.Expect(os.MkdirAll(filepath.Dir(fullPath), 0755)).To(Succeed())
+			g.Expect(os.WriteFile(fullPath, []byte(content), 0600)).To(Succeed())
+		}
+		g.Expect(os.WriteFile(filepath.Join(checkoutDir, "build.sh"), []byte("#!/bin/sh\n"), 0700)).To(Succeed())
+		g.Expect(os.Symlink("../README.md", filepath.Join(checkoutDir, "src", "README.md"))).To(Succeed())
+	}
+
+	readArchive := func(path string) []*tar.Header {
+		file, err := os.Open(path)
+		g.Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+		gzipReader, err := gzip.NewReader(file)
+		g.Expect(err).ToNot(HaveOccurred())
+		tarReader := tar.NewReader(gzipReader)
+
+		var headers []*tar.Header
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			headers = append(headers, header)
+		}
+		return headers
+	}
+
+	t.Run("should archive sorted tree without .git and normalized metadata", func(t *testing.T) {
+		beforeEach()
+
+		err := c.createSourceArchive()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		headers := readArchive(c.Params.SourceArchiveOutput)
+
+		var names []string
+		for _, header := range headers {
+			names = append(names, header.Name)
+			g.Expect(header.ModTime.Equal(time.Unix(1704067200, 0))).To(BeTrue())
+			g.Expect(header.Uid).To(Equal(0))
+			g.Expect(header.Gid).To(Equal(0))
+			g.Expect(header.Uname).To(Equal("root"))
+
+			switch header.Name {
+			case "build.sh":
+				g.Expect(header.Mode).To(Equal(int64(0755)))
+			case "README.md":
+				g.Expect(header.Mode).To(Equal(int64(0644)))
+			case "src/README.md":
+				g.Expect(header.Typeflag).To(Equal(byte(tar.TypeSymlink)))
+				g.Expect(header.Linkname).To(Equal("../README.md"))
+			}
+		}
+		g.Expect(names).To(Equal([]string{
+			"README.md",
+			"build.sh",
+			"lib/",
+			"lib/a/",
+			"lib/a/nested/",
+			"lib/a/nested/file.go",
+			"lib/lib.go",
+			"src/",
+			"src/README.md",
+			"src/main.go",
+		}))
+	})
+
+	t.Run("should report digest of the archive", func(t *testing.T) {
+		beforeEach()
+
+		err := c.createSourceArchive()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		content, err := os.ReadFile(c.Params.SourceArchiveOutput)
+		g.Expect(err).ToNot(HaveOccurred())
+		sum := sha256.Sum256(content)
+		expectedDigest := "sha256:" + hex.EncodeToString(sum[:])
+
+		g.Expect(_mockResultsWriter.WrittenResults["/results/source-archive-digest"]).To(Equal(expectedDigest))
+		g.Expect(c.Results.SourceArchive).To(Equal(&SourceArchive{Path: c.Params.SourceArchiveOutput, Digest: expectedDigest}))
+	})
+
+	t.Run("should produce identical archives regardless of file metadata", func(t *testing.T) {
+		beforeEach()
+		g.Expect(c.createSourceArchive()).To(Succeed())
+		firstDigest := c.Results.SourceArchive.Digest
+
+		checkoutDir := c.getCheckoutDir()
+		later := time.Now().Add(time.Hour)
+		g.Expect(os.Chtimes(filepath.Join(checkoutDir, "README.md"), later, later)).To(Succeed())
+		g.Expect(os.Chmod(filepath.Join(checkoutDir, "src", "main.go"), 0664)).To(Succeed())
+
+		g.Expect(c.createSourceArchive()).To(Succeed())
+		g.Expect(c.Results.SourceArchive.Digest).To(Equal(firstDigest))
+	})
+
+	t.Run("should fail with invalid commit timestamp", func(t *testing.T) {
+		beforeEach()
+		c.Results.CommitTimestamp = ""
+
+		err := c.createSourceArchive()
+
+		g.Expect(err).To(MatchError(ContainSubstring("invalid commit timestamp")))
+		_, err = os.Stat(c.Params.SourceArchiveOutput)
+		g.Expect(err).To(MatchError(os.ErrNotExist))
+	})
+}
+
+func Test_GitClone_checkSourceArchiveOutput(t *testing.T) {
+	g := NewWithT(t)
+
+	c := &GitClone{Params: &Params{OutputDir: "/workspace/source", Subdirectory: "repo"}}
+
+	c.Params.SourceArchiveOutput = "/workspace/source/repo/source.tar.gz"
+	g.Expect(c.checkSourceArchiveOutput()).To(MatchError(ContainSubstring("must not be inside the checkout directory")))
+
+	c.Params.SourceArchiveOutput = "/workspace/source/repo..tar.gz"
+	g.Expect(c.checkSourceArchiveOutput()).To(Succeed())
+
+	c.Params.SourceArchiveOutput = "/workspace/source/source.tar.gz"
+	g.Expect(c.checkSourceArchiveOutput()).To(Succeed())
+}
@@ -0,0 +1,187 @@
+package gitclone
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// remoteHelperURLRegex matches the '<transport>::<address>' syntax of git remote helpers,
+// e.g. ext:: which runs an arbitrary command.
+var remoteHelperURLRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*::`)
+
+// submodulePolicy restricts the submodules fetched by the clone.
+type submodulePolicy struct {
+	// hosts are patterns matched against the host of the submodule URL.
+	hosts common.PathPatterns
+	// urls are patterns matched against the whole submodule URL without credentials.
+	urls       common.PathPatterns
+	allowLocal bool
+	maxDepth   int
+}
+
+func newSubmodulePolicy(params *Params) (*submodulePolicy, error) {
+	if params.SubmoduleMaxDepth < 0 {
+		return nil, fmt.Errorf("submodule-max-depth must be >= 0 (0 means unlimited)")
+	}
+
+	entries, err := parseCSV(params.SubmoduleAllowedURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse submodule-allowed-urls: %w", err)
+	}
+	var hostPatterns, urlPatterns []string
+	for _, entry := range entries {
+		if strings.Contains(entry, "://") {
+			urlPatterns = append(urlPatterns, entry)
+		} else {
+			hostPatterns = append(hostPatterns, strings.ToLower(entry))
+		}
+	}
+
+	policy := &submodulePolicy{allowLocal: params.SubmoduleAllowLocalURLs, maxDepth: params.SubmoduleMaxDepth}
+	if policy.hosts, err = common.CompilePathPatterns(hostPatterns); err != nil {
+		return nil, fmt.Errorf("submodule-allowed-urls: %w", err)
+	}
+	if policy.urls, err = common.CompilePathPatterns(urlPatterns); err != nil {
+		return nil, fmt.Errorf("submodule-allowed-urls: %w", err)
+	}
+	return policy, nil
+}
+
+// checkURL returns an error if the resolved submodule URL is not allowed.
+func (p *submodulePolicy) checkURL(rawURL string) error {
+	if remoteHelperURLRegex.MatchString(rawURL) {
+		return fmt.Errorf("remote helper transports are not allowed")
+	}
+
+	host, local := parseRepositoryURL(rawURL)
+	if local {
+		if !p.allowLocal {
+			return fmt.Errorf("local paths and file:// URLs are not allowed unless submodule-allow-local-urls is set")
+		}
+		return nil
+	}
+
+	if len(p.hosts) == 0 && len(p.urls) == 0 {
+		return nil
+	}
+	if p.hosts.Match(strings.ToLower(host)) || p.urls.Match(stripURLCredentials(rawURL)) {
+		return nil
+	}
+	return fmt.Errorf("not allowed by submodule-allowed-urls")
+}
+
+// parseRepositoryURL returns the host of a git repository URL, and whether it refers to
+// the local filesystem. Like git, URLs without a scheme are scp-like 'host:path' if
+// a colon precedes the first slash, local paths otherwise.
+func parseRepositoryURL(rawURL string) (host string, local bool) {
+	if scheme, _, found := strings.Cut(rawURL, "://"); found {
+		if strings.EqualFold(scheme, "file") {
+			return "", true
+		}
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return "", false
+		}
+		return parsed.Hostname(), false
+	}
+
+	colon := strings.Index(rawURL, ":")
+	slash := strings.Index(rawURL, "/")
+	if colon > 0 && (slash < 0 || colon < slash) {
+		host = rawURL[:colon]
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+		return strings.Trim(host, "[]"), false
+	}
+	return "", true
+}
+
+// updateSubmodules fetches the submodules one nesting level at a time, so that the policy
+// is enforced on the resolved URLs before anything is fetched from them, and the symlink
+// check of a submodule runs before its own submodules are checked out.
+func (c *GitClone) updateSubmodules(paths []string) error {
+	policy, err := newSubmodulePolicy(c.Params)
+	if err != nil {
+		return err
+	}
+
+	var symlinkExcludes []string
+	if c.Params.EnableSymlinkCheck {
+		if symlinkExcludes, err = parseCSV(c.Params.SymlinkCheckIgnorePattern); err != nil {
+			return fmt.Errorf("failed to parse symlink-check-ignore-pattern: %w", err)
+		}
+	}
+
+	return c.updateSubmodulesIn("", paths, 1, policy, symlinkExcludes)
+}
+
+// updateSubmodulesIn updates the submodules of the repository in dir, relative to the checkout
+// directory, and recurses into them. depth is the nesting level of the submodules, starting with 1.
+func (c *GitClone) updateSubmodulesIn(dir string, paths []string, depth int, policy *submodulePolicy, symlinkExcludes []string) error {
+	if err := c.CliWrappers.GitCli.SubmoduleInit(dir, paths); err != nil {
+		return err
+	}
+	submodules, err := c.CliWrappers.GitCli.SubmoduleList(dir)
+	if err != nil {
+		return err
+	}
+	if len(submodules) == 0 {
+		return nil
+	}
+
+	for _, submodule := range submodules {
+		submodulePath := path.Join(dir, submodule.Path)
+		if policy.maxDepth > 0 && depth > policy.maxDepth {
+			return fmt.Errorf("submodule %s is nested %d levels deep, more than submodule-max-depth %d", submodulePath, depth, policy.maxDepth)
+		}
+		if err := policy.checkURL(submodule.URL); err != nil {
+			return fmt.Errorf("submodule %s URL %s rejected: %w", submodulePath, common.SanitizeURL(submodule.URL), err)
+		}
+	}
+
+	submodulePaths := make([]string, 0, len(submodules))
+	for _, submodule := range submodules {
+		submodulePaths = append(submodulePaths, submodule.Path)
+	}
+	l.Logger.Debugf("Updating submodules %v of %q", submodulePaths, dir)
+	err = c.CliWrappers.GitCli.SubmoduleUpdate(cliwrappers.GitSubmoduleUpdateOptions{
+		Dir:    dir,
+		Depth:  c.Params.Depth,
+		Filter: c.Params.CloneFilter,
+		Paths:  submodulePaths,
+	})
+	if err != nil {
+		return err
+	}
+
+	checkoutDir := c.getCheckoutDir()
+	for _, submodule := range submodules {
+		submodulePath := path.Join(dir, submodule.Path)
+		if c.Params.EnableSymlinkCheck {
+			if err := common.CheckSymlinksWithin(filepath.Join(checkoutDir, submodulePath), checkoutDir, symlinkExcludes); err != nil {
+				return fmt.Errorf("symlink check of submodule %s: %w", submodulePath, err)
+			}
+		}
+
+		c.Results.Submodules = append(c.Results.Submodules, SubmoduleResult{
+			Path:   submodulePath,
+			URL:    stripURLCredentials(submodule.URL),
+			Commit: submodule.Commit,
+		})
+
+		if err := c.updateSubmodulesIn(submodulePath, nil, depth+1, policy, symlinkExcludes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
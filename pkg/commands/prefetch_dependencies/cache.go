@@ -0,0 +1,290 @@
+package prefetch_dependencies
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Layout of a cache entry, <cache-dir>/<key>/:
+//
+//	output/   the output directory, with the prefetched dependencies and the SBOM
+//	env/<n>   the n-th file of --env-files
+const (
+	cacheOutputDir = "output"
+	cacheEnvDir    = "env"
+)
+
+// lockfileNames are the files of the source directory that determine the prefetched dependencies.
+var lockfileNames = []string{
+	"go.mod", "go.sum", "go.work", "go.work.sum",
+	"package.json", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock", ".yarnrc.yml", "pnpm-lock.yaml", "pnpm-workspace.yaml",
+	"pyproject.toml", "setup.py", "setup.cfg",
+	"Gemfile", "Gemfile.lock",
+	"Cargo.toml", "Cargo.lock",
+	"rpms.in.yaml", "rpms.lock.yaml",
+	"artifacts.lock.yaml",
+}
+
+// Directories not searched for lockfiles.
+var lockfileSkipDirs = []string{".git", "node_modules"}
+
+func isLockfile(name string) bool {
+	if slices.Contains(lockfileNames, name) {
+		return true
+	}
+	// requirements.txt, requirements-build.txt, requirements-dev.txt, ...
+	return strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt")
+}
+
+// cacheKeyData is everything the prefetch output depends on.
+type cacheKeyData struct {
+	Input               string            `json:"input"`
+	HermetoVersion      string            `json:"hermetoVersion"`
+	ProxyEnv            []string          `json:"proxyEnv"`
+	ConfigFile          string            `json:"configFile"`
+	SBOMFormat          string            `json:"sbomFormat"`
+	Mode                string            `json:"mode"`
+	OutputDirMountPoint string            `json:"outputDirMountPoint"`
+	EnvFileFormats      []string          `json:"envFileFormats"`
+	RegisterRHSM        bool              `json:"registerRHSM"`
+	Lockfiles           map[string]string `json:"lockfiles"`
+}
+
+// Compute the cache key of the prefetch: the SHA-256 of the normalized input, the lockfiles,
+// the Hermeto version, the proxy settings and the options affecting the output.
+func (pd *PrefetchDependencies) cacheKey(input *Input, hermetoVersion string) (string, error) {
+	encodedInput, err := input.encode()
+	if err != nil {
+		return "", err
+	}
+
+	proxyEnv := slices.Clone(pd.PackageProxyEnv)
+	slices.Sort(proxyEnv)
+
+	data := cacheKeyData{
+		Input:               encodedInput,
+		HermetoVersion:      hermetoVersion,
+		ProxyEnv:            proxyEnv,
+		SBOMFormat:          pd.Config.SBOMFormat,
+		Mode:                pd.Config.Mode,
+		OutputDirMountPoint: pd.Config.OutputDirMountPoint,
+		RegisterRHSM:        pd.Config.RHSMOrg != "" && pd.Config.RHSMActivationKey != "",
+	}
+
+	// The format of an env file is inferred from its suffix
+	for _, envFile := range pd.Config.EnvFiles {
+		data.EnvFileFormats = append(data.EnvFileFormats, filepath.Ext(envFile))
+	}
+
+	if pd.Config.ConfigFile != "" {
+		if data.ConfigFile, err = hashFile(pd.Config.ConfigFile); err != nil {
+			return "", fmt.Errorf("failed to hash config file: %w", err)
+		}
+	}
+
+	if data.Lockfiles, err = hashLockfiles(pd.Config.SourceDir, input); err != nil {
+		return "", fmt.Errorf("failed to hash lockfiles: %w", err)
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Hash the lockfiles of the source directory and the files referenced by the input,
+// by their slash-separated path relative to the source directory.
+func hashLockfiles(sourceDir string, input *Input) (map[string]string, error) {
+	var paths []string
+	err := filepath.WalkDir(sourceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != sourceDir && slices.Contains(lockfileSkipDirs, d.Name()) {
+			return filepath.SkipDir
+		}
+		if d.Type().IsRegular() && isLockfile(d.Name()) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range input.Packages {
+		packageDir := filepath.Join(sourceDir, pkg.Path)
+		referenced := slices.Concat(pkg.RequirementsFiles, pkg.RequirementsBuildFiles)
+		if pkg.Lockfile != "" {
+			referenced = append(referenced, pkg.Lockfile)
+		}
+		for _, path := range referenced {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(packageDir, path)
+			}
+			// Missing files are reported by Hermeto
+			if _, err := os.Stat(path); err == nil {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	hashes := map[string]string{}
+	for _, path := range paths {
+		rel, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return nil, err
+		}
+		if hashes[filepath.ToSlash(rel)], err = hashFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path) //nolint:gosec // path within the source directory or from controlled input
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Restore the output directory and the env files from the cache.
+// Returns false if there is no cache entry for the key.
+func (pd *PrefetchDependencies) restoreFromCache(key string) (bool, error) {
+	entryDir := filepath.Join(pd.Config.CacheDir, key)
+	if _, err := os.Stat(entryDir); os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if err := copyTree(filepath.Join(entryDir, cacheOutputDir), pd.Config.OutputDir); err != nil {
+		return false, fmt.Errorf("failed to restore output directory: %w", err)
+	}
+	for i, envFile := range pd.Config.EnvFiles {
+		cached := filepath.Join(entryDir, cacheEnvDir, strconv.Itoa(i))
+		if err := copyCacheFile(cached, envFile); err != nil {
+			return false, fmt.Errorf("failed to restore env file %s: %w", envFile, err)
+		}
+	}
+	return true, nil
+}
+
+// Store the output directory and the env files in the cache.
+// The entry is written to a temporary directory and renamed, so an entry is always complete.
+func (pd *PrefetchDependencies) populateCache(key string) error {
+	entryDir := filepath.Join(pd.Config.CacheDir, key)
+	if err := os.MkdirAll(pd.Config.CacheDir, 0755); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(pd.Config.CacheDir, ".tmp-"+key+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := copyTree(pd.Config.OutputDir, filepath.Join(tmpDir, cacheOutputDir)); err != nil {
+		return fmt.Errorf("failed to store output directory: %w", err)
+	}
+	for i, envFile := range pd.Config.EnvFiles {
+		if err := copyCacheFile(envFile, filepath.Join(tmpDir, cacheEnvDir, strconv.Itoa(i))); err != nil {
+			return fmt.Errorf("failed to store env file %s: %w", envFile, err)
+		}
+	}
+
+	if err := os.Chmod(tmpDir, 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpDir, entryDir); err != nil {
+		// A concurrent run stored the same entry
+		if _, statErr := os.Stat(entryDir); statErr == nil {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Copy the directory tree, keeping file modes and symlinks.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			_ = os.Remove(target)
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFileMode(path, target, info.Mode().Perm())
+		default:
+			return fmt.Errorf("unsupported file type of %s", path)
+		}
+	})
+}
+
+func copyCacheFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return copyFileMode(src, dst, info.Mode().Perm())
+}
+
+func copyFileMode(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src) //nolint:gosec // path from the output or cache directory
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// Files may be read-only, e.g. the env files
+	_ = os.Remove(dst)
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm) //nolint:gosec // path from the output or cache directory
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
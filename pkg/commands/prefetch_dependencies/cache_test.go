@@ -0,0 +1,187 @@
+package prefetch_dependencies
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+type mockHermetoCli struct {
+	VersionFunc     func() (string, error)
+	FetchDepsFunc   func(params *cliwrappers.HermetoFetchDepsParams) error
+	GenerateEnvFunc func(params *cliwrappers.HermetoGenerateEnvParams) error
+	InjectFilesFunc func(params *cliwrappers.HermetoInjectFilesParams) error
+}
+
+func (m *mockHermetoCli) Version() (string, error) {
+	if m.VersionFunc != nil {
+		return m.VersionFunc()
+	}
+	return "hermeto 0.1.0", nil
+}
+
+func (m *mockHermetoCli) FetchDeps(params *cliwrappers.HermetoFetchDepsParams) error {
+	if m.FetchDepsFunc != nil {
+		return m.FetchDepsFunc(params)
+	}
+	return nil
+}
+
+func (m *mockHermetoCli) GenerateEnv(params *cliwrappers.HermetoGenerateEnvParams) error {
+	if m.GenerateEnvFunc != nil {
+		return m.GenerateEnvFunc(params)
+	}
+	return nil
+}
+
+func (m *mockHermetoCli) InjectFiles(params *cliwrappers.HermetoInjectFilesParams) error {
+	if m.InjectFilesFunc != nil {
+		return m.InjectFilesFunc(params)
+	}
+	return nil
+}
+
+func TestCacheKey(t *testing.T) {
+	g := NewWithT(t)
+
+	sourceDir := t.TempDir()
+	g.Expect(os.MkdirAll(filepath.Join(sourceDir, "api", "node_modules", "dep"), 0755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(sourceDir, "go.sum"), []byte("v1"), 0644)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main"), 0644)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(sourceDir, "api", "requirements-build.txt"), []byte("setuptools"), 0644)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(sourceDir, "api", "deps.in"), []byte("requests"), 0644)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(sourceDir, "api", "node_modules", "dep", "package.json"), []byte("{}"), 0644)).To(Succeed())
+
+	input, issues := parseInput(`[{"type": "gomod"}, {"type": "pip", "path": "api", "requirements_files": ["deps.in"]}]`)
+	g.Expect(issues).To(BeEmpty())
+
+	pd := &PrefetchDependencies{
+		Config:          &Params{SourceDir: sourceDir, SBOMFormat: "spdx", Mode: "strict", EnvFiles: []string{"prefetch.env"}},
+		PackageProxyEnv: []string{"HERMETO_NPM__PROXY_URL=https://npm.proxy", "HERMETO_GOMOD__PROXY_URL=https://go.proxy"},
+	}
+
+	t.Run("should hash lockfiles and files referenced by the input", func(t *testing.T) {
+		hashes, err := hashLockfiles(sourceDir, input)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(hashes).To(HaveKey("go.sum"))
+		g.Expect(hashes).To(HaveKey("api/requirements-build.txt"))
+		g.Expect(hashes).To(HaveKey("api/deps.in"))
+		g.Expect(hashes).To(HaveLen(3))
+	})
+
+	key, err := pd.cacheKey(input, "hermeto 0.1.0")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(key).To(HaveLen(64))
+
+	t.Run("should not depend on other source files and proxy order", func(t *testing.T) {
+		g.Expect(os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n"), 0644)).To(Succeed())
+		pd.PackageProxyEnv = []string{pd.PackageProxyEnv[1], pd.PackageProxyEnv[0]}
+
+		g.Expect(pd.cacheKey(input, "hermeto 0.1.0")).To(Equal(key))
+	})
+
+	t.Run("should change with the Hermeto version", func(t *testing.T) {
+		g.Expect(pd.cacheKey(input, "hermeto 0.2.0")).ToNot(Equal(key))
+	})
+
+	t.Run("should change with the proxy settings", func(t *testing.T) {
+		proxyEnv := pd.PackageProxyEnv
+		defer func() { pd.PackageProxyEnv = proxyEnv }()
+		pd.PackageProxyEnv = proxyEnv[:1]
+
+		g.Expect(pd.cacheKey(input, "hermeto 0.1.0")).ToNot(Equal(key))
+	})
+
+	t.Run("should change with the input", func(t *testing.T) {
+		otherInput, _ := parseInput(`[{"type": "gomod"}]`)
+		g.Expect(pd.cacheKey(otherInput, "hermeto 0.1.0")).ToNot(Equal(key))
+	})
+
+	t.Run("should change with a lockfile", func(t *testing.T) {
+		g.Expect(os.WriteFile(filepath.Join(sourceDir, "go.sum"), []byte("v2"), 0644)).To(Succeed())
+		g.Expect(pd.cacheKey(input, "hermeto 0.1.0")).ToNot(Equal(key))
+	})
+}
+
+func TestRunWithCache(t *testing.T) {
+	g := NewWithT(t)
+
+	workDir := t.TempDir()
+	sourceDir := filepath.Join(workDir, "source")
+	g.Expect(os.Mkdir(sourceDir, 0755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(sourceDir, "go.sum"), []byte("v1"), 0644)).To(Succeed())
+	cacheDir := filepath.Join(workDir, "cache")
+
+	var fetchDepsCalls, injectFilesCalls int
+	hermetoCli := &mockHermetoCli{
+		FetchDepsFunc: func(params *cliwrappers.HermetoFetchDepsParams) error {
+			fetchDepsCalls++
+			g.Expect(params.Input).To(Equal(`{"packages":[{"type":"gomod"}]}`))
+			g.Expect(os.MkdirAll(filepath.Join(params.OutputDir, "deps", "gomod"), 0755)).To(Succeed())
+			g.Expect(os.WriteFile(filepath.Join(params.OutputDir, "deps", "gomod", "mod.zip"), []byte("zip"), 0644)).To(Succeed())
+			return os.WriteFile(filepath.Join(params.OutputDir, "bom.json"), []byte("{}"), 0644)
+		},
+		GenerateEnvFunc: func(params *cliwrappers.HermetoGenerateEnvParams) error {
+			return os.WriteFile(params.Output, []byte("export GOFLAGS=-mod=mod\n"), 0444)
+		},
+		InjectFilesFunc: func(params *cliwrappers.HermetoInjectFilesParams) error {
+			injectFilesCalls++
+			return nil
+		},
+	}
+
+	newPrefetch := func(run string) *PrefetchDependencies {
+		return &PrefetchDependencies{
+			Config: &Params{
+				Input:               `{"type": "gomod"}`,
+				SourceDir:           sourceDir,
+				OutputDir:           filepath.Join(workDir, run, "output"),
+				SBOMFormat:          "spdx",
+				Mode:                "strict",
+				OutputDirMountPoint: "/tmp",
+				EnvFiles:            []string{filepath.Join(workDir, run, "prefetch.env")},
+				CacheDir:            cacheDir,
+			},
+			HermetoCli: hermetoCli,
+		}
+	}
+
+	t.Run("should prefetch and populate the cache on miss", func(t *testing.T) {
+		g.Expect(newPrefetch("run1").Run()).To(Succeed())
+
+		g.Expect(fetchDepsCalls).To(Equal(1))
+		g.Expect(injectFilesCalls).To(Equal(1))
+		entries, err := os.ReadDir(cacheDir)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(entries).To(HaveLen(1))
+		g.Expect(filepath.Join(cacheDir, entries[0].Name(), "output", "deps", "gomod", "mod.zip")).To(BeARegularFile())
+		g.Expect(filepath.Join(cacheDir, entries[0].Name(), "env", "0")).To(BeARegularFile())
+	})
+
+	t.Run("should restore from the cache on hit", func(t *testing.T) {
+		g.Expect(newPrefetch("run2").Run()).To(Succeed())
+
+		g.Expect(fetchDepsCalls).To(Equal(1))
+		g.Expect(injectFilesCalls).To(Equal(2))
+		g.Expect(filepath.Join(workDir, "run2", "output", "deps", "gomod", "mod.zip")).To(BeARegularFile())
+		g.Expect(filepath.Join(workDir, "run2", "output", "bom.json")).To(BeARegularFile())
+		env, err := os.ReadFile(filepath.Join(workDir, "run2", "prefetch.env"))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(env)).To(Equal("export GOFLAGS=-mod=mod\n"))
+	})
+
+	t.Run("should prefetch again when a lockfile changes", func(t *testing.T) {
+		g.Expect(os.WriteFile(filepath.Join(sourceDir, "go.sum"), []byte("v2"), 0644)).To(Succeed())
+
+		g.Expect(newPrefetch("run3").Run()).To(Succeed())
+
+		g.Expect(fetchDepsCalls).To(Equal(2))
+		entries, err := os.ReadDir(cacheDir)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(entries).To(HaveLen(2))
+	})
+}
@@ -0,0 +1,572 @@
+package prefetch_dependencies
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	modeStrict     = "strict"
+	modePermissive = "permissive"
+)
+
+// Input is the Hermeto input: the package managers to prefetch and the flags.
+//
+// The user input can be given in any form accepted by Hermeto:
+//
+//	gomod
+//	{"type": "gomod"}
+//	[{"type": "gomod"}, {"type": "pip", "path": "api"}]
+//	{"packages": [{"type": "gomod"}], "flags": ["cgo-disable"]}
+//
+// and is always passed to Hermeto in the last form.
+type Input struct {
+	Packages []PackageInput `json:"packages"`
+	Flags    []string       `json:"flags,omitempty"`
+}
+
+// PackageInput is a package manager entry. Options not supported by the package manager are empty.
+type PackageInput struct {
+	Type string `json:"type"`
+	// Path is the package directory relative to the source directory, defaults to the source directory
+	Path string `json:"path,omitempty"`
+
+	// bundler, pip
+	AllowBinary *bool `json:"allow_binary,omitempty"`
+	// pip, relative to Path
+	RequirementsFiles      []string `json:"requirements_files,omitempty"`
+	RequirementsBuildFiles []string `json:"requirements_build_files,omitempty"`
+	// generic, relative to Path
+	Lockfile string `json:"lockfile,omitempty"`
+	// rpm
+	IncludeSummaryInSBOM *bool       `json:"include_summary_in_sbom,omitempty"`
+	Options              *RPMOptions `json:"options,omitempty"`
+
+	// location of the entry in the user input, for error messages
+	location string
+	// keys of an entry of a package manager unknown to this CLI, besides type and path,
+	// passed to Hermeto as is
+	passthrough map[string]any
+}
+
+// MarshalJSON encodes the entry, with the keys of an unknown package manager passed through.
+func (pkg PackageInput) MarshalJSON() ([]byte, error) {
+	type plain PackageInput
+	if pkg.passthrough == nil {
+		return json.Marshal(plain(pkg))
+	}
+	data := maps.Clone(pkg.passthrough)
+	data["type"] = pkg.Type
+	if pkg.Path != "" {
+		data["path"] = pkg.Path
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(data); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+type RPMOptions struct {
+	SSL *RPMSSLOptions `json:"ssl,omitempty"`
+	// DNF holds the dnf options per repository id, passed as is
+	DNF map[string]map[string]any `json:"dnf,omitempty"`
+}
+
+type RPMSSLOptions struct {
+	ClientKey  string `json:"client_key,omitempty"`
+	ClientCert string `json:"client_cert,omitempty"`
+	CABundle   string `json:"ca_bundle,omitempty"`
+	SSLVerify  *bool  `json:"ssl_verify,omitempty"`
+}
+
+// packageManagerKeys are the keys supported by each package manager, besides type and path.
+var packageManagerKeys = map[string][]string{
+	"bundler": {"allow_binary"},
+	"cargo":   {},
+	"generic": {"lockfile"},
+	"gomod":   {},
+	"npm":     {},
+	"pip":     {"allow_binary", "requirements_files", "requirements_build_files"},
+	"pnpm":    {},
+	"rpm":     {"include_summary_in_sbom", "options"},
+	"yarn":    {},
+}
+
+// knownFlags are the Hermeto fetch-deps flags.
+var knownFlags = []string{"cgo-disable", "dev-package-managers", "force-gomod-tidy", "gomod-vendor", "gomod-vendor-check"}
+
+// inputIssue is a problem of the input at a location like $.packages[1].path.
+type inputIssue struct {
+	location string
+	message  string
+	// Fatal issues fail the command in permissive mode too,
+	// the others are reported as warnings and the offending part is dropped,
+	// except entries of unknown package managers, which are passed through.
+	fatal bool
+}
+
+func (i inputIssue) String() string {
+	return i.location + ": " + i.message
+}
+
+// InputError lists the issues of an invalid input.
+type InputError struct {
+	Issues []string
+}
+
+func (e *InputError) Error() string {
+	return "invalid input:\n  " + strings.Join(e.Issues, "\n  ")
+}
+
+// Parse and validate the user input.
+// Issues fail in strict mode and are logged as warnings in permissive mode, except fatal issues.
+func loadInput(input, sourceDir, mode string) (*Input, error) {
+	if mode != modeStrict && mode != modePermissive {
+		return nil, fmt.Errorf("invalid mode '%s', must be %s or %s", mode, modeStrict, modePermissive)
+	}
+
+	parsed, issues := parseInput(input)
+	if parsed != nil {
+		issues = append(issues, parsed.validate(sourceDir)...)
+	}
+
+	var failures []string
+	for _, issue := range issues {
+		if issue.fatal || mode == modeStrict {
+			failures = append(failures, issue.String())
+		} else {
+			log.Warnf("Ignoring invalid input in permissive mode: %s", issue)
+		}
+	}
+	if len(failures) > 0 {
+		return nil, &InputError{Issues: failures}
+	}
+	return parsed, nil
+}
+
+// Parse the user input to the Hermeto input.
+// A plain string, which is not JSON, is the type of a single package manager.
+func parseInput(input string) (*Input, []inputIssue) {
+	trimmed := strings.TrimSpace(input)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, `"`) {
+		trimmed = fmt.Sprintf("%q", trimmed)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	var data any
+	err := decoder.Decode(&data)
+	if err == nil && decoder.More() {
+		err = errors.New("unexpected data after the top-level value")
+	}
+	if err != nil {
+		return nil, []inputIssue{{location: jsonErrorLocation(trimmed, err, decoder.InputOffset()), message: err.Error(), fatal: true}}
+	}
+
+	p := &inputParser{}
+	result := &Input{}
+	switch value := data.(type) {
+	case string:
+		result.Packages = p.packages([]any{map[string]any{"type": value}}, "$")
+	case []any:
+		result.Packages = p.packages(value, "$")
+	case map[string]any:
+		if _, ok := value["packages"]; !ok {
+			result.Packages = p.packages([]any{value}, "$")
+			// Report the single package as the root object
+			for i := range p.issues {
+				p.issues[i].location = strings.Replace(p.issues[i].location, "$[0]", "$", 1)
+			}
+			for i := range result.Packages {
+				result.Packages[i].location = "$"
+			}
+			break
+		}
+		for _, key := range sortedKeys(value) {
+			switch key {
+			case "packages":
+				packages, ok := value[key].([]any)
+				if !ok {
+					p.fatalf("$.packages", "must be an array")
+					continue
+				}
+				result.Packages = p.packages(packages, "$.packages")
+			case "flags":
+				result.Flags = p.stringArray(value[key], "$.flags")
+			default:
+				p.warnf("$."+key, "unknown key")
+			}
+		}
+	default:
+		p.fatalf("$", "must be a string, an object or an array")
+	}
+
+	if slices.ContainsFunc(p.issues, func(issue inputIssue) bool { return issue.fatal }) {
+		return nil, p.issues
+	}
+	return result, p.issues
+}
+
+// Location of a JSON decoding error as line and column.
+func jsonErrorLocation(input string, err error, offset int64) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// The offset is after the invalid character
+		offset = syntaxErr.Offset - 1
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		offset = int64(len(input))
+	}
+	before := input[:min(int(offset), len(input))]
+	line := strings.Count(before, "\n") + 1
+	column := len(before) - strings.LastIndex(before, "\n")
+	return fmt.Sprintf("line %d, column %d", line, column)
+}
+
+// inputParser converts the decoded JSON to the typed input and collects the issues with their locations.
+type inputParser struct {
+	issues []inputIssue
+}
+
+func (p *inputParser) fatalf(location, format string, args ...any) {
+	p.issues = append(p.issues, inputIssue{location: location, message: fmt.Sprintf(format, args...), fatal: true})
+}
+
+func (p *inputParser) warnf(location, format string, args ...any) {
+	p.issues = append(p.issues, inputIssue{location: location, message: fmt.Sprintf(format, args...)})
+}
+
+func (p *inputParser) packages(items []any, location string) []PackageInput {
+	packages := []PackageInput{}
+	for i, item := range items {
+		itemLocation := fmt.Sprintf("%s[%d]", location, i)
+		data, ok := item.(map[string]any)
+		if !ok {
+			p.fatalf(itemLocation, "must be an object")
+			continue
+		}
+		if pkg, ok := p.packageInput(data, itemLocation); ok {
+			pkg.location = itemLocation
+			packages = append(packages, pkg)
+		}
+	}
+	return packages
+}
+
+func (p *inputParser) packageInput(data map[string]any, location string) (PackageInput, bool) {
+	pkg := PackageInput{}
+	typeValue, ok := data["type"]
+	if !ok {
+		p.fatalf(location, "missing key 'type'")
+		return pkg, false
+	}
+	pkg.Type, ok = typeValue.(string)
+	if !ok {
+		p.fatalf(location+".type", "must be a string")
+		return pkg, false
+	}
+	supportedKeys, ok := packageManagerKeys[pkg.Type]
+	if !ok {
+		// Hermeto may support more package managers than this CLI knows, e.g. with dev-package-managers,
+		// so the entry is passed through unvalidated in permissive mode
+		p.warnf(location+".type", "unknown package manager '%s', known package managers are %s", pkg.Type, strings.Join(sortedKeys(packageManagerKeys), ", "))
+		pkg.passthrough = map[string]any{}
+		for key, value := range data {
+			switch key {
+			case "type":
+			case "path":
+				pkg.Path = p.string(value, location+".path")
+			default:
+				pkg.passthrough[key] = value
+			}
+		}
+		return pkg, true
+	}
+
+	for _, key := range sortedKeys(data) {
+		keyLocation := location + "." + key
+		value := data[key]
+		switch {
+		case key == "type":
+		case key == "path":
+			pkg.Path = p.string(value, keyLocation)
+		case !slices.Contains(supportedKeys, key):
+			p.warnf(keyLocation, "unknown key for package manager '%s'", pkg.Type)
+		case key == "allow_binary":
+			pkg.AllowBinary = p.bool(value, keyLocation)
+		case key == "requirements_files":
+			pkg.RequirementsFiles = p.stringArray(value, keyLocation)
+		case key == "requirements_build_files":
+			pkg.RequirementsBuildFiles = p.stringArray(value, keyLocation)
+		case key == "lockfile":
+			pkg.Lockfile = p.string(value, keyLocation)
+		case key == "include_summary_in_sbom":
+			pkg.IncludeSummaryInSBOM = p.bool(value, keyLocation)
+		case key == "options":
+			pkg.Options = p.rpmOptions(value, keyLocation)
+		}
+	}
+	return pkg, true
+}
+
+func (p *inputParser) rpmOptions(value any, location string) *RPMOptions {
+	data, ok := value.(map[string]any)
+	if !ok {
+		p.fatalf(location, "must be an object")
+		return nil
+	}
+	options := &RPMOptions{}
+	for _, key := range sortedKeys(data) {
+		keyLocation := location + "." + key
+		switch key {
+		case "ssl":
+			options.SSL = p.rpmSSLOptions(data[key], keyLocation)
+		case "dnf":
+			options.DNF = p.dnfOptions(data[key], keyLocation)
+		default:
+			p.warnf(keyLocation, "unknown key")
+		}
+	}
+	return options
+}
+
+func (p *inputParser) rpmSSLOptions(value any, location string) *RPMSSLOptions {
+	data, ok := value.(map[string]any)
+	if !ok {
+		p.fatalf(location, "must be an object")
+		return nil
+	}
+	ssl := &RPMSSLOptions{}
+	for _, key := range sortedKeys(data) {
+		keyLocation := location + "." + key
+		switch key {
+		case "client_key":
+			ssl.ClientKey = p.string(data[key], keyLocation)
+		case "client_cert":
+			ssl.ClientCert = p.string(data[key], keyLocation)
+		case "ca_bundle":
+			ssl.CABundle = p.string(data[key], keyLocation)
+		case "ssl_verify":
+			ssl.SSLVerify = p.bool(data[key], keyLocation)
+		default:
+			p.warnf(keyLocation, "unknown key")
+		}
+	}
+	return ssl
+}
+
+func (p *inputParser) dnfOptions(value any, location string) map[string]map[string]any {
+	data, ok := value.(map[string]any)
+	if !ok {
+		p.fatalf(location, "must be an object")
+		return nil
+	}
+	dnf := map[string]map[string]any{}
+	for _, repoID := range sortedKeys(data) {
+		repoOptions, ok := data[repoID].(map[string]any)
+		if !ok {
+			p.fatalf(location+"."+repoID, "must be an object")
+			continue
+		}
+		dnf[repoID] = repoOptions
+	}
+	return dnf
+}
+
+func (p *inputParser) string(value any, location string) string {
+	s, ok := value.(string)
+	if !ok {
+		p.fatalf(location, "must be a string")
+	}
+	return s
+}
+
+func (p *inputParser) bool(value any, location string) *bool {
+	b, ok := value.(bool)
+	if !ok {
+		p.fatalf(location, "must be a boolean")
+		return nil
+	}
+	return &b
+}
+
+func (p *inputParser) stringArray(value any, location string) []string {
+	items, ok := value.([]any)
+	if !ok {
+		p.fatalf(location, "must be an array of strings")
+		return nil
+	}
+	result := []string{}
+	for i, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			p.fatalf(fmt.Sprintf("%s[%d]", location, i), "must be a string")
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Validate the paths, the flags and the combination of the entries.
+// Duplicate package entries and flags, and unknown flags, are dropped from the input.
+func (in *Input) validate(sourceDir string) []inputIssue {
+	var issues []inputIssue
+
+	seen := map[string]string{}
+	packages := []PackageInput{}
+	for _, pkg := range in.Packages {
+		issues = append(issues, pkg.validatePaths(sourceDir, pkg.location)...)
+
+		key := pkg.Type + " " + filepath.Clean(pkg.pathOrDefault())
+		if first, ok := seen[key]; ok {
+			issues = append(issues, inputIssue{
+				location: pkg.location,
+				message:  fmt.Sprintf("duplicate of %s, package manager '%s' at path '%s'", first, pkg.Type, pkg.pathOrDefault()),
+			})
+			continue
+		}
+		seen[key] = pkg.location
+		packages = append(packages, pkg)
+	}
+	in.Packages = packages
+
+	flags := []string{}
+	for i, flag := range in.Flags {
+		location := fmt.Sprintf("$.flags[%d]", i)
+		switch {
+		case !slices.Contains(knownFlags, flag):
+			issues = append(issues, inputIssue{location: location, message: fmt.Sprintf("unknown flag '%s', must be one of %s", flag, strings.Join(knownFlags, ", "))})
+		case slices.Contains(flags, flag):
+			issues = append(issues, inputIssue{location: location, message: fmt.Sprintf("duplicate flag '%s'", flag)})
+		default:
+			flags = append(flags, flag)
+		}
+	}
+	in.Flags = flags
+
+	if slices.Contains(in.Flags, "gomod-vendor") && slices.Contains(in.Flags, "gomod-vendor-check") {
+		issues = append(issues, inputIssue{location: "$.flags", message: "flags 'gomod-vendor' and 'gomod-vendor-check' are incompatible"})
+	}
+	for _, flag := range in.Flags {
+		if strings.HasPrefix(flag, "gomod-") || flag == "cgo-disable" || flag == "force-gomod-tidy" {
+			if !in.hasPackageManager("gomod") {
+				issues = append(issues, inputIssue{location: "$.flags", message: fmt.Sprintf("flag '%s' requires a gomod package", flag)})
+			}
+		}
+	}
+
+	return issues
+}
+
+func (in *Input) hasPackageManager(packageManager string) bool {
+	return slices.ContainsFunc(in.Packages, func(pkg PackageInput) bool { return pkg.Type == packageManager })
+}
+
+func (pkg *PackageInput) pathOrDefault() string {
+	if pkg.Path == "" {
+		return "."
+	}
+	return pkg.Path
+}
+
+// Check that the package path and the files of the package are inside the source directory.
+func (pkg *PackageInput) validatePaths(sourceDir, location string) []inputIssue {
+	var issues []inputIssue
+	if filepath.IsAbs(pkg.Path) {
+		return []inputIssue{{location: location + ".path", message: "must be relative to the source directory", fatal: true}}
+	}
+	packageDir := filepath.Join(sourceDir, pkg.Path)
+	if err := checkWithin(sourceDir, packageDir); err != nil {
+		return []inputIssue{{location: location + ".path", message: err.Error(), fatal: true}}
+	}
+
+	check := func(path, pathLocation string) {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(packageDir, path)
+		}
+		if err := checkWithin(sourceDir, path); err != nil {
+			issues = append(issues, inputIssue{location: pathLocation, message: err.Error(), fatal: true})
+		}
+	}
+	for i, file := range pkg.RequirementsFiles {
+		check(file, fmt.Sprintf("%s.requirements_files[%d]", location, i))
+	}
+	for i, file := range pkg.RequirementsBuildFiles {
+		check(file, fmt.Sprintf("%s.requirements_build_files[%d]", location, i))
+	}
+	if pkg.Lockfile != "" {
+		check(pkg.Lockfile, location+".lockfile")
+	}
+	return issues
+}
+
+// Check that the path is inside the source directory, following symlinks of existing paths.
+func checkWithin(sourceDir, path string) error {
+	root, err := filepath.Abs(sourceDir)
+	if err != nil {
+		return err
+	}
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if !isWithin(root, target) {
+		return fmt.Errorf("path '%s' is outside of the source directory", path)
+	}
+
+	resolvedTarget, err := filepath.EvalSymlinks(target)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+	if !isWithin(resolvedRoot, resolvedTarget) {
+		return fmt.Errorf("path '%s' resolves outside of the source directory", path)
+	}
+	return nil
+}
+
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Encode the input for Hermeto.
+func (in *Input) encode() (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(in); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
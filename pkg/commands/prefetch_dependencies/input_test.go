@@ -0,0 +1,175 @@
+package prefetch_dependencies
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseInput(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should convert plain string to a package", func(t *testing.T) {
+		input, issues := parseInput("gomod")
+		g.Expect(issues).To(BeEmpty())
+		g.Expect(input.encode()).To(Equal(`{"packages":[{"type":"gomod"}]}`))
+	})
+
+	t.Run("should parse input JSON object", func(t *testing.T) {
+		input, issues := parseInput(`{"type": "pip", "path": "api", "requirements_files": ["requirements.txt"], "allow_binary": true}`)
+		g.Expect(issues).To(BeEmpty())
+		g.Expect(input.encode()).To(Equal(`{"packages":[{"type":"pip","path":"api","allow_binary":true,"requirements_files":["requirements.txt"]}]}`))
+	})
+
+	t.Run("should parse input JSON array", func(t *testing.T) {
+		input, issues := parseInput(`[{"type": "gomod"}, {"type": "npm", "path": "web"}]`)
+		g.Expect(issues).To(BeEmpty())
+		g.Expect(input.encode()).To(Equal(`{"packages":[{"type":"gomod"},{"type":"npm","path":"web"}]}`))
+	})
+
+	t.Run("should parse input JSON object with packages array and flags", func(t *testing.T) {
+		input, issues := parseInput(`{"packages": [{"type": "generic", "lockfile": "artifacts.lock.yaml"}], "flags": ["cgo-disable"]}`)
+		g.Expect(issues).To(BeEmpty())
+		g.Expect(input.encode()).To(Equal(`{"packages":[{"type":"generic","lockfile":"artifacts.lock.yaml"}],"flags":["cgo-disable"]}`))
+	})
+
+	t.Run("should report syntax error with line and column", func(t *testing.T) {
+		input, issues := parseInput("{\n  \"packages\": [\n    {\"type\": \"gomod\",}\n  ]\n}")
+		g.Expect(input).To(BeNil())
+		g.Expect(issues).To(HaveLen(1))
+		g.Expect(issues[0].String()).To(Equal("line 3, column 22: invalid character '}' looking for beginning of object key string"))
+		g.Expect(issues[0].fatal).To(BeTrue())
+	})
+
+	t.Run("should report truncated JSON", func(t *testing.T) {
+		_, issues := parseInput(`[{"type": "gomod"}`)
+		g.Expect(issues).To(HaveLen(1))
+		g.Expect(issues[0].String()).To(Equal("line 1, column 19: unexpected EOF"))
+	})
+
+	t.Run("should report unknown keys", func(t *testing.T) {
+		input, issues := parseInput(`{"packages": [{"type": "gomod", "allow_binary": true}, {"type": "rpm", "options": {"ssl": {"verify": false}}}], "extra": 1}`)
+		g.Expect(input).ToNot(BeNil())
+		g.Expect(issues).To(Equal([]inputIssue{
+			{location: "$.extra", message: "unknown key"},
+			{location: "$.packages[0].allow_binary", message: "unknown key for package manager 'gomod'"},
+			{location: "$.packages[1].options.ssl.verify", message: "unknown key"},
+		}))
+		g.Expect(input.encode()).To(Equal(`{"packages":[{"type":"gomod"},{"type":"rpm","options":{"ssl":{}}}]}`))
+	})
+
+	t.Run("should report unknown key of single package at the root", func(t *testing.T) {
+		_, issues := parseInput(`{"type": "npm", "lockfile": "package-lock.json"}`)
+		g.Expect(issues).To(Equal([]inputIssue{{location: "$.lockfile", message: "unknown key for package manager 'npm'"}}))
+	})
+
+	t.Run("should pass unknown package manager through", func(t *testing.T) {
+		input, issues := parseInput(`{"packages": [{"type": "x-maven", "path": "app", "lockfile": "lock.yaml", "depth": 2}], "flags": ["dev-package-managers"]}`)
+		g.Expect(issues).To(Equal([]inputIssue{
+			{location: "$.packages[0].type", message: "unknown package manager 'x-maven', known package managers are bundler, cargo, generic, gomod, npm, pip, pnpm, rpm, yarn"},
+		}))
+		g.Expect(input.encode()).To(Equal(`{"packages":[{"depth":2,"lockfile":"lock.yaml","path":"app","type":"x-maven"}],"flags":["dev-package-managers"]}`))
+	})
+
+	t.Run("should report invalid values as fatal", func(t *testing.T) {
+		input, issues := parseInput(`[{"type": "pip", "path": 1, "requirements_files": ["a.txt", 2]}, {"type": "maven"}, {"path": "."}, "gomod"]`)
+		g.Expect(input).To(BeNil())
+		g.Expect(issues).To(Equal([]inputIssue{
+			{location: "$[0].path", message: "must be a string", fatal: true},
+			{location: "$[0].requirements_files[1]", message: "must be a string", fatal: true},
+			{location: "$[1].type", message: "unknown package manager 'maven', known package managers are bundler, cargo, generic, gomod, npm, pip, pnpm, rpm, yarn"},
+			{location: "$[2]", message: "missing key 'type'", fatal: true},
+			{location: "$[3]", message: "must be an object", fatal: true},
+		}))
+	})
+}
+
+func TestValidateInput(t *testing.T) {
+	g := NewWithT(t)
+
+	sourceDir := t.TempDir()
+	g.Expect(os.Mkdir(filepath.Join(sourceDir, "api"), 0755)).To(Succeed())
+	g.Expect(os.Symlink(os.TempDir(), filepath.Join(sourceDir, "tmp"))).To(Succeed())
+
+	validate := func(input string) (*Input, []inputIssue) {
+		parsed, issues := parseInput(input)
+		g.Expect(issues).To(BeEmpty())
+		return parsed, parsed.validate(sourceDir)
+	}
+
+	t.Run("should accept paths inside the source directory", func(t *testing.T) {
+		_, issues := validate(`[{"type": "pip", "path": "api", "requirements_files": ["requirements.txt", "../requirements-dev.txt"]}, {"type": "generic", "lockfile": "` + filepath.Join(sourceDir, "lock.yaml") + `"}]`)
+		g.Expect(issues).To(BeEmpty())
+	})
+
+	t.Run("should reject paths outside of the source directory", func(t *testing.T) {
+		_, issues := validate(`{"packages": [{"type": "gomod", "path": "../other"}, {"type": "gomod", "path": "/abs"}, {"type": "pip", "path": "api", "requirements_build_files": ["../../build.txt"]}, {"type": "generic", "lockfile": "/etc/passwd"}, {"type": "npm", "path": "tmp"}]}`)
+		g.Expect(issues).To(HaveLen(5))
+		g.Expect(issues[0]).To(Equal(inputIssue{location: "$.packages[0].path", message: "path '" + filepath.Join(sourceDir, "../other") + "' is outside of the source directory", fatal: true}))
+		g.Expect(issues[1]).To(Equal(inputIssue{location: "$.packages[1].path", message: "must be relative to the source directory", fatal: true}))
+		g.Expect(issues[2].location).To(Equal("$.packages[2].requirements_build_files[0]"))
+		g.Expect(issues[3].location).To(Equal("$.packages[3].lockfile"))
+		g.Expect(issues[4]).To(Equal(inputIssue{location: "$.packages[4].path", message: "path '" + filepath.Join(sourceDir, "tmp") + "' resolves outside of the source directory", fatal: true}))
+	})
+
+	t.Run("should drop duplicate packages and flags", func(t *testing.T) {
+		input, issues := validate(`{"packages": [{"type": "gomod"}, {"type": "gomod", "path": "./"}, {"type": "gomod", "path": "api"}], "flags": ["cgo-disable", "cgo-disable", "unknown"]}`)
+		g.Expect(issues).To(Equal([]inputIssue{
+			{location: "$.packages[1]", message: "duplicate of $.packages[0], package manager 'gomod' at path './'"},
+			{location: "$.flags[1]", message: "duplicate flag 'cgo-disable'"},
+			{location: "$.flags[2]", message: "unknown flag 'unknown', must be one of cgo-disable, dev-package-managers, force-gomod-tidy, gomod-vendor, gomod-vendor-check"},
+		}))
+		g.Expect(input.encode()).To(Equal(`{"packages":[{"type":"gomod"},{"type":"gomod","path":"api"}],"flags":["cgo-disable"]}`))
+	})
+
+	t.Run("should report incompatible flags", func(t *testing.T) {
+		_, issues := validate(`{"packages": [{"type": "npm"}], "flags": ["gomod-vendor", "gomod-vendor-check"]}`)
+		g.Expect(issues).To(Equal([]inputIssue{
+			{location: "$.flags", message: "flags 'gomod-vendor' and 'gomod-vendor-check' are incompatible"},
+			{location: "$.flags", message: "flag 'gomod-vendor' requires a gomod package"},
+			{location: "$.flags", message: "flag 'gomod-vendor-check' requires a gomod package"},
+		}))
+	})
+}
+
+func TestLoadInput(t *testing.T) {
+	g := NewWithT(t)
+
+	sourceDir := t.TempDir()
+	const input = `{"packages": [{"type": "gomod", "unknown": true}], "flags": ["cgo-disable", "cgo-disable"]}`
+
+	t.Run("should fail on any issue in strict mode", func(t *testing.T) {
+		_, err := loadInput(input, sourceDir, "strict")
+		g.Expect(err).To(MatchError("invalid input:\n" +
+			"  $.packages[0].unknown: unknown key for package manager 'gomod'\n" +
+			"  $.flags[1]: duplicate flag 'cgo-disable'"))
+	})
+
+	t.Run("should drop invalid entries in permissive mode", func(t *testing.T) {
+		parsed, err := loadInput(input, sourceDir, "permissive")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(parsed.encode()).To(Equal(`{"packages":[{"type":"gomod"}],"flags":["cgo-disable"]}`))
+	})
+
+	t.Run("should fail on fatal issues in permissive mode", func(t *testing.T) {
+		_, err := loadInput(`{"type": "gomod", "path": "../other"}`, sourceDir, "permissive")
+		g.Expect(err).To(MatchError(ContainSubstring("$.path: path '")))
+	})
+
+	t.Run("should handle unknown package manager by mode", func(t *testing.T) {
+		const unknown = `{"packages": [{"type": "x-maven"}], "flags": ["dev-package-managers"]}`
+		_, err := loadInput(unknown, sourceDir, "strict")
+		g.Expect(err).To(MatchError(ContainSubstring("$.packages[0].type: unknown package manager 'x-maven'")))
+
+		parsed, err := loadInput(unknown, sourceDir, "permissive")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(parsed.encode()).To(Equal(`{"packages":[{"type":"x-maven"}],"flags":["dev-package-managers"]}`))
+	})
+
+	t.Run("should reject unknown mode", func(t *testing.T) {
+		_, err := loadInput(input, sourceDir, "lenient")
+		g.Expect(err).To(MatchError("invalid mode 'lenient', must be strict or permissive"))
+	})
+}
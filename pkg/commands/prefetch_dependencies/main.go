@@ -1,7 +1,6 @@
 package prefetch_dependencies
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -20,6 +19,8 @@ type PrefetchDependencies struct {
 	Config                 *Params
 	HermetoCli             cliwrappers.HermetoCliInterface
 	SubscriptionManagerCli cliwrappers.SubscriptionManagerCliInterface
+	// PackageProxyEnv is the package registry proxy configuration passed to Hermeto
+	PackageProxyEnv []string
 }
 
 func getPackageProxyConfiguration() ([]string, error) {
@@ -38,34 +39,24 @@ func getPackageProxyConfiguration() ([]string, error) {
 			"is not set to `true` on the cluster level")
 		return hermetoEnv, err
 	}
-	// Note that empty URLs must be sanitized here, or it would result in validation
-	// error in Hermeto.
-	if packageProxyConfig.BundlerProxy != "" {
-		envEntry := fmt.Sprintf("HERMETO_BUNDLER__PROXY_URL=%s", packageProxyConfig.BundlerProxy)
-		hermetoEnv = append(hermetoEnv, envEntry)
-	}
-	if packageProxyConfig.CargoProxy != "" {
-		envEntry := fmt.Sprintf("HERMETO_CARGO__PROXY_URL=%s", packageProxyConfig.CargoProxy)
-		hermetoEnv = append(hermetoEnv, envEntry)
-	}
-	if packageProxyConfig.GomodProxy != "" {
-		envEntry := fmt.Sprintf("HERMETO_GOMOD__PROXY_URL=%s", packageProxyConfig.GomodProxy)
-		hermetoEnv = append(hermetoEnv, envEntry)
-	}
-	if packageProxyConfig.NpmProxy != "" {
-		envEntry := fmt.Sprintf("HERMETO_NPM__PROXY_URL=%s", packageProxyConfig.NpmProxy)
-		hermetoEnv = append(hermetoEnv, envEntry)
-	}
-	if packageProxyConfig.PipProxy != "" {
-		envEntry := fmt.Sprintf("HERMETO_PIP__PROXY_URL=%s", packageProxyConfig.PipProxy)
-		hermetoEnv = append(hermetoEnv, envEntry)
-	}
-	if packageProxyConfig.PnpmProxy != "" {
-		envEntry := fmt.Sprintf("HERMETO_PNPM__PROXY_URL=%s", packageProxyConfig.PnpmProxy)
-		hermetoEnv = append(hermetoEnv, envEntry)
-	}
-	if packageProxyConfig.YarnProxy != "" {
-		envEntry := fmt.Sprintf("HERMETO_YARN__PROXY_URL=%s", packageProxyConfig.YarnProxy)
+	for _, packageManager := range cfg.HermetoPackageManagers {
+		proxyURL := packageProxyConfig.PackageManagerProxy[packageManager]
+		// Note that empty URLs must be sanitized here, or it would result in validation
+		// error in Hermeto. Such package managers fetch directly from upstream.
+		if proxyURL == "" {
+			log.Debugf("No package registry proxy configured for %s", packageManager)
+			continue
+		}
+		if !packageProxyConfig.IsProxyAllowed(packageManager) {
+			log.Infof("Not using package registry proxy for %s because allow-package-registry-proxy-%s "+
+				"is not set to `true` on the cluster level", packageManager, packageManager)
+			continue
+		}
+		if err := cfg.ValidateProxyURL(proxyURL); err != nil {
+			log.Warnf("Not using package registry proxy for %s: %s", packageManager, err)
+			continue
+		}
+		envEntry := fmt.Sprintf("HERMETO_%s__PROXY_URL=%s", strings.ToUpper(packageManager), proxyURL)
 		hermetoEnv = append(hermetoEnv, envEntry)
 	}
 
@@ -96,14 +87,15 @@ func New(cmd *cobra.Command) (*PrefetchDependencies, error) {
 		return nil, err
 	}
 
-	prefetchDependencies := PrefetchDependencies{Config: &local_config, HermetoCli: hermetoCli}
+	prefetchDependencies := PrefetchDependencies{Config: &local_config, HermetoCli: hermetoCli, PackageProxyEnv: hermetoEnv}
 	return &prefetchDependencies, nil
 }
 
 func (pd *PrefetchDependencies) Run() error {
 	common.LogParameters(ParamsConfig, pd.Config)
 
-	if err := pd.HermetoCli.Version(); err != nil {
+	hermetoVersion, err := pd.HermetoCli.Version()
+	if err != nil {
 		return fmt.Errorf("hermeto --version command failed: %w", err)
 	}
 
@@ -120,8 +112,32 @@ func (pd *PrefetchDependencies) Run() error {
 		return fmt.Errorf("failed to setup Git authentication: %w", err)
 	}
 
-	decodedJSONInput := parseInput(pd.Config.Input)
-	if containsRPM(decodedJSONInput) {
+	input, err := loadInput(pd.Config.Input, pd.Config.SourceDir, pd.Config.Mode)
+	if err != nil {
+		return err
+	}
+
+	var cacheKey string
+	if pd.Config.CacheDir != "" {
+		cacheKey, err = pd.cacheKey(input, hermetoVersion)
+		if err != nil {
+			return fmt.Errorf("failed to compute cache key: %w", err)
+		}
+		restored, err := pd.restoreFromCache(cacheKey)
+		if err != nil {
+			log.Warnf("Failed to restore prefetched dependencies from cache, prefetching again: %s", err)
+			if err := os.RemoveAll(pd.Config.OutputDir); err != nil {
+				return fmt.Errorf("failed to clean up output directory: %w", err)
+			}
+		} else if restored {
+			log.Infof("Restored prefetched dependencies from cache entry %s", cacheKey)
+			return pd.injectFiles()
+		} else {
+			log.Infof("No cache entry %s for prefetched dependencies", cacheKey)
+		}
+	}
+
+	if containsRPM(input) {
 		registerRHSM := pd.Config.RHSMOrg != "" && pd.Config.RHSMActivationKey != ""
 		if registerRHSM {
 			if err := pd.registerRHSM(); err != nil {
@@ -130,24 +146,22 @@ func (pd *PrefetchDependencies) Run() error {
 			defer pd.unregisterRHSM()
 		}
 
-		modifiedInput, err := injectRPMInput(decodedJSONInput, registerRHSM)
-		if err != nil {
+		if err := injectRPMInput(input, registerRHSM); err != nil {
 			return fmt.Errorf("failed to inject RPM input: %w", err)
 		}
-		decodedJSONInput = modifiedInput
 	}
 
-	encodedJSONInput, err := json.Marshal(decodedJSONInput)
+	encodedJSONInput, err := input.encode()
 	if err != nil {
 		return err
 	}
 
-	log.Debugf("Using modified input for Hermeto:\n%s", string(encodedJSONInput))
+	log.Debugf("Using modified input for Hermeto:\n%s", encodedJSONInput)
 
 	fetchDepsParams := cliwrappers.HermetoFetchDepsParams{
 		SourceDir:  pd.Config.SourceDir,
 		OutputDir:  pd.Config.OutputDir,
-		Input:      string(encodedJSONInput),
+		Input:      encodedJSONInput,
 		ConfigFile: pd.Config.ConfigFile,
 		SBOMFormat: pd.Config.SBOMFormat,
 		Mode:       pd.Config.Mode,
@@ -167,6 +181,18 @@ func (pd *PrefetchDependencies) Run() error {
 		}
 	}
 
+	if cacheKey != "" {
+		if err := pd.populateCache(cacheKey); err != nil {
+			log.Warnf("Failed to store prefetched dependencies in cache: %s", err)
+		} else {
+			log.Infof("Stored prefetched dependencies in cache entry %s", cacheKey)
+		}
+	}
+
+	return pd.injectFiles()
+}
+
+func (pd *PrefetchDependencies) injectFiles() error {
 	injectFilesParams := cliwrappers.HermetoInjectFilesParams{
 		OutputDir:    pd.Config.OutputDir,
 		ForOutputDir: pd.Config.OutputDirMountPoint,
@@ -19,7 +19,7 @@ func Test_getHermetoEnvFromConfigMap(t *testing.T) {
 			return &config.KonfluxConfig{
 				HermetoProxy: &config.HermetoProxyConfig{
 					PackageRegistryProxyAllowed: true,
-					NpmProxy:                    fakeProxyUrl,
+					PackageManagerProxy:         map[string]string{"npm": fakeProxyUrl},
 				},
 			}, nil
 		}
@@ -65,4 +65,43 @@ func Test_getHermetoEnvFromConfigMap(t *testing.T) {
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(parsedConfig).To(BeEmpty())
 	})
+
+	t.Run("includes rpm and generic proxies and skips disallowed and invalid ones", func(t *testing.T) {
+		config.GetKonfluxConfig = func() (*config.KonfluxConfig, error) {
+			return &config.KonfluxConfig{
+				HermetoProxy: &config.HermetoProxyConfig{
+					PackageRegistryProxyAllowed: true,
+					PackageManagerProxy: map[string]string{
+						"generic": "https://generic.example.com",
+						"gomod":   "gomod.example.com",
+						"npm":     "https://npm.example.com",
+						"rpm":     "https://rpm.example.com",
+					},
+					PackageManagerProxyAllowed: map[string]bool{"npm": false},
+				},
+			}, nil
+		}
+		parsedConfig, err := getPackageProxyConfiguration()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(parsedConfig).To(Equal([]string{
+			"HERMETO_GENERIC__PROXY_URL=https://generic.example.com",
+			"HERMETO_RPM__PROXY_URL=https://rpm.example.com",
+		}))
+	})
+
+	t.Run("returns empty env when package registry proxy is not allowed", func(t *testing.T) {
+		config.GetKonfluxConfig = func() (*config.KonfluxConfig, error) {
+			return &config.KonfluxConfig{
+				HermetoProxy: &config.HermetoProxyConfig{
+					PackageManagerProxy:        map[string]string{"npm": "https://npm.example.com"},
+					PackageManagerProxyAllowed: map[string]bool{"npm": true},
+				},
+			}, nil
+		}
+		parsedConfig, err := getPackageProxyConfiguration()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(parsedConfig).To(BeEmpty())
+	})
 }
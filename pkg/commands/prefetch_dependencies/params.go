@@ -52,7 +52,7 @@ var ParamsConfig = map[string]common.Parameter{
 		TypeKind:     reflect.String,
 		EnvVarName:   "KBC_PD_MODE",
 		DefaultValue: "strict",
-		Usage:        "how to handle input requirements: strict (fail) or permissive (warn)",
+		Usage:        "how to handle input requirements and invalid input entries: strict (fail) or permissive (warn)",
 		Required:     false,
 	},
 	"output-dir-mount-point": {
@@ -95,6 +95,14 @@ var ParamsConfig = map[string]common.Parameter{
 		Usage:        "directory with git auth credentials (.git-credentials, .gitconfig or username/password)",
 		Required:     false,
 	},
+	"cache-dir": {
+		Name:         "cache-dir",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_CACHE_DIR",
+		DefaultValue: "",
+		Usage:        "directory to cache prefetched dependencies in, keyed by the input, lockfiles, Hermeto version and proxy settings",
+		Required:     false,
+	},
 	"enable-package-registry-proxy": { // Pipeline-level registry proxy switch.
 		Name:         "enable-package-registry-proxy",
 		EnvVarName:   "KBC_PD_ENABLE_PACKAGE_REGISTRY_PROXY",
@@ -117,5 +125,6 @@ type Params struct {
 	RHSMOrg                    string   `paramName:"rhsm-org"`
 	RHSMActivationKey          string   `paramName:"rhsm-activation-key"`
 	GitAuthDirectory           string   `paramName:"git-auth-directory"`
+	CacheDir                   string   `paramName:"cache-dir"`
 	EnablePackageRegistryProxy bool     `paramName:"enable-package-registry-proxy"`
 }
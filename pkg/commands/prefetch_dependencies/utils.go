@@ -1,14 +1,11 @@
 package prefetch_dependencies
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"maps"
 	"net/url"
 	"os"
 	"path/filepath"
-	"slices"
 	"strings"
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
@@ -16,46 +13,17 @@ import (
 
 const readOnlyFileMode = os.FileMode(0444)
 
-// Parse the user input to a valid JSON object.
-func parseInput(input string) any {
-	var result any
-	if err := json.Unmarshal([]byte(input), &result); err != nil {
-		// Input is not valid JSON => assume it is a string.
-		return map[string]any{"type": input}
-	}
-
-	// Input is valid JSON => return it as is.
-	return result
-}
-
-// Check if the user input contains an RPM package.
-func containsRPM(input any) bool {
-	switch data := input.(type) {
-	case []any:
-		if slices.ContainsFunc(data, containsRPM) {
-			return true
-		}
-
-	case map[string]any:
-		if packages, ok := data["packages"].([]any); ok {
-			if slices.ContainsFunc(packages, containsRPM) {
-				return true
-			}
-		}
-
-		if typeValue, ok := data["type"]; ok && typeValue == "rpm" {
-			return true
-		}
-	}
-	return false
+// Check if the input contains an RPM package.
+func containsRPM(input *Input) bool {
+	return input.hasPackageManager("rpm")
 }
 
-// Modify the user input for RPM packages.
-func injectRPMInput(input any, registeredWithRHSM bool) (any, error) {
-	withSummary := injectSummaryInSBOMField(input)
+// Modify the input for RPM packages.
+func injectRPMInput(input *Input, registeredWithRHSM bool) error {
+	injectSummaryInSBOMField(input)
 
 	if !registeredWithRHSM {
-		return withSummary, nil
+		return nil
 	}
 
 	// Glob ignores file system errors such as I/O errors reading directories.
@@ -73,78 +41,46 @@ func injectRPMInput(input any, registeredWithRHSM bool) (any, error) {
 	}
 
 	if clientKeyPath == "" || clientCertPath == "" {
-		return withSummary, errors.New("no entitlement certificate files found")
+		return errors.New("no entitlement certificate files found")
 	}
 
 	rhsmCaBundlePath := "/etc/rhsm/ca/redhat-uep.pem"
-	ssl := map[string]any{
-		"client_key":  clientKeyPath,
-		"client_cert": clientCertPath,
-		"ca_bundle":   rhsmCaBundlePath,
+	ssl := RPMSSLOptions{
+		ClientKey:  clientKeyPath,
+		ClientCert: clientCertPath,
+		CABundle:   rhsmCaBundlePath,
 	}
-	return injectSSLOptions(withSummary, ssl), nil
+	injectSSLOptions(input, ssl)
+	return nil
 }
 
 // Inject a flag to enable RPM summary in the SBOM.
-func injectSummaryInSBOMField(input any) any {
-	switch data := input.(type) {
-	case []any:
-		// Array format: [{"type": "rpm"}]
-		for i, item := range data {
-			data[i] = injectSummaryInSBOMField(item)
-		}
-		return data
-
-	case map[string]any:
-		// Object format with "packages" field: {"packages": [{"type": "rpm"}]}
-		if packages, ok := data["packages"].([]any); ok {
-			for i, item := range packages {
-				packages[i] = injectSummaryInSBOMField(item)
-			}
-			return data
-		}
-
-		// Object format with "type" field: {"type": "rpm"}
-		if typeValue, ok := data["type"]; ok && typeValue == "rpm" {
-			data["include_summary_in_sbom"] = true
-			return data
+func injectSummaryInSBOMField(input *Input) {
+	includeSummary := true
+	for i := range input.Packages {
+		if input.Packages[i].Type == "rpm" {
+			input.Packages[i].IncludeSummaryInSBOM = &includeSummary
 		}
 	}
-	return input
 }
 
-// Inject SSL options for all RPM packages.
-func injectSSLOptions(input any, ssl map[string]any) any {
-	switch data := input.(type) {
-	case []any:
-		// Array format: [{"type": "rpm"}]
-		for i, item := range data {
-			data[i] = injectSSLOptions(item, ssl)
+// Inject SSL options for all RPM packages, keeping the other existing SSL options.
+func injectSSLOptions(input *Input, ssl RPMSSLOptions) {
+	for i := range input.Packages {
+		pkg := &input.Packages[i]
+		if pkg.Type != "rpm" {
+			continue
 		}
-		return data
-
-	case map[string]any:
-		// Object format with "packages" field: {"packages": [{"type": "rpm"}]}
-		if packages, ok := data["packages"].([]any); ok {
-			for i, item := range packages {
-				packages[i] = injectSSLOptions(item, ssl)
-			}
-			return data
+		if pkg.Options == nil {
+			pkg.Options = &RPMOptions{}
 		}
-
-		// Object format with "type" field: {"type": "rpm"}
-		if typeValue, ok := data["type"]; ok && typeValue == "rpm" {
-			if existingOptions, ok := data["options"].(map[string]any); ok {
-				if existingSSL, ok := existingOptions["ssl"].(map[string]any); ok {
-					maps.Copy(existingSSL, ssl)
-					return data
-				}
-			}
-			data["options"] = map[string]any{"ssl": ssl}
-			return data
+		if pkg.Options.SSL == nil {
+			pkg.Options.SSL = &RPMSSLOptions{}
 		}
+		pkg.Options.SSL.ClientKey = ssl.ClientKey
+		pkg.Options.SSL.ClientCert = ssl.ClientCert
+		pkg.Options.SSL.CABundle = ssl.CABundle
 	}
-	return input
 }
 
 func cpFile(sourcePath, destinationPath string) error {
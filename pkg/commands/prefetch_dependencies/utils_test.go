@@ -9,71 +9,32 @@ import (
 	. "github.com/onsi/gomega"
 )
 
-func TestParseInput(t *testing.T) {
-	g := NewWithT(t)
-
-	t.Run("should parse input JSON object", func(t *testing.T) {
-		input := `{"foo": "bar"}`
-		data := parseInput(input)
-		g.Expect(data).To(Equal(map[string]any{"foo": "bar"}))
-	})
-
-	t.Run("should parse input JSON array", func(t *testing.T) {
-		input := `[{"foo": "bar"}, {"foo": "baz"}]`
-		data := parseInput(input)
-		g.Expect(data).To(Equal([]any{map[string]any{"foo": "bar"}, map[string]any{"foo": "baz"}}))
-	})
-
-	t.Run("should parse input JSON object with packages array", func(t *testing.T) {
-		input := `{"packages": [{"foo": "bar"}, {"foo": "baz"}]}`
-		data := parseInput(input)
-		g.Expect(data).To(Equal(map[string]any{"packages": []any{map[string]any{"foo": "bar"}, map[string]any{"foo": "baz"}}}))
-	})
-
-	t.Run("should convert plain string to JSON object", func(t *testing.T) {
-		input := "foo"
-		data := parseInput(input)
-		g.Expect(data).To(Equal(map[string]any{"type": "foo"}))
-	})
-}
-
 func TestContainsRPM(t *testing.T) {
 	g := NewWithT(t)
 
-	t.Run("should return false for empty object", func(t *testing.T) {
-		input := `{}`
-		data := parseInput(input)
-		g.Expect(containsRPM(data)).To(BeFalse())
-	})
-
 	t.Run("should return false for empty array", func(t *testing.T) {
-		input := `[]`
-		data := parseInput(input)
-		g.Expect(containsRPM(data)).To(BeFalse())
+		input, _ := parseInput(`[]`)
+		g.Expect(containsRPM(input)).To(BeFalse())
 	})
 
 	t.Run("should return true for RPM package", func(t *testing.T) {
-		input := `{"type": "rpm"}`
-		data := parseInput(input)
-		g.Expect(containsRPM(data)).To(BeTrue())
+		input, _ := parseInput(`{"type": "rpm"}`)
+		g.Expect(containsRPM(input)).To(BeTrue())
 	})
 
 	t.Run("should return false for non-RPM package", func(t *testing.T) {
-		input := `{"type": "yarn"}`
-		data := parseInput(input)
-		g.Expect(containsRPM(data)).To(BeFalse())
+		input, _ := parseInput(`{"type": "yarn"}`)
+		g.Expect(containsRPM(input)).To(BeFalse())
 	})
 
 	t.Run("should return true if any item in packages array is RPM", func(t *testing.T) {
-		input := `{"packages": [{"type": "rpm"}, {"type": "yarn"}]}`
-		data := parseInput(input)
-		g.Expect(containsRPM(data)).To(BeTrue())
+		input, _ := parseInput(`{"packages": [{"type": "rpm"}, {"type": "yarn"}]}`)
+		g.Expect(containsRPM(input)).To(BeTrue())
 	})
 
 	t.Run("should return true if any item in top-level array is RPM", func(t *testing.T) {
-		input := `[{"type": "rpm"}, {"type": "yarn"}]`
-		data := parseInput(input)
-		g.Expect(containsRPM(data)).To(BeTrue())
+		input, _ := parseInput(`[{"type": "rpm"}, {"type": "yarn"}]`)
+		g.Expect(containsRPM(input)).To(BeTrue())
 	})
 }
 
@@ -81,31 +42,37 @@ func TestInjectSummaryInSBOMField(t *testing.T) {
 	g := NewWithT(t)
 
 	t.Run("should inject summary in SBOM field for an RPM package", func(t *testing.T) {
-		input := `{"type": "rpm"}`
-		data := parseInput(input)
-		g.Expect(injectSummaryInSBOMField(data)).To(Equal(map[string]any{"type": "rpm", "include_summary_in_sbom": true}))
+		input, _ := parseInput(`[{"type": "rpm"}, {"type": "gomod"}]`)
+		injectSummaryInSBOMField(input)
+		encoded, err := input.encode()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(encoded).To(Equal(`{"packages":[{"type":"rpm","include_summary_in_sbom":true},{"type":"gomod"}]}`))
 	})
 }
 
 func TestInjectSSLOptions(t *testing.T) {
 	g := NewWithT(t)
 
-	exampleSSLOptions := map[string]any{
-		"client_key":  "client_key",
-		"client_cert": "client_cert",
-		"ca_bundle":   "ca_bundle",
+	exampleSSLOptions := RPMSSLOptions{
+		ClientKey:  "client_key",
+		ClientCert: "client_cert",
+		CABundle:   "ca_bundle",
 	}
 
 	t.Run("should inject SSL options", func(t *testing.T) {
-		input := `{"type": "rpm"}`
-		data := parseInput(input)
-		g.Expect(injectSSLOptions(data, exampleSSLOptions)).To(Equal(map[string]any{"type": "rpm", "options": map[string]any{"ssl": exampleSSLOptions}}))
+		input, _ := parseInput(`{"type": "rpm"}`)
+		injectSSLOptions(input, exampleSSLOptions)
+		encoded, err := input.encode()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(encoded).To(Equal(`{"packages":[{"type":"rpm","options":{"ssl":{"client_key":"client_key","client_cert":"client_cert","ca_bundle":"ca_bundle"}}}]}`))
 	})
 
 	t.Run("should overwrite existing SSL options", func(t *testing.T) {
-		input := `{"type": "rpm", "options": {"ssl": {"client_key": "my_client_key"}}}`
-		data := parseInput(input)
-		g.Expect(injectSSLOptions(data, exampleSSLOptions)).To(Equal(map[string]any{"type": "rpm", "options": map[string]any{"ssl": map[string]any{"client_key": "client_key", "client_cert": "client_cert", "ca_bundle": "ca_bundle"}}}))
+		input, _ := parseInput(`{"type": "rpm", "options": {"ssl": {"client_key": "my_client_key", "ssl_verify": false}, "dnf": {"repo": {"gpgcheck": 0}}}}`)
+		injectSSLOptions(input, exampleSSLOptions)
+		encoded, err := input.encode()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(encoded).To(Equal(`{"packages":[{"type":"rpm","options":{"ssl":{"client_key":"client_key","client_cert":"client_cert","ca_bundle":"ca_bundle","ssl_verify":false},"dnf":{"repo":{"gpgcheck":0}}}}]}`))
 	})
 }
 
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,12 +11,16 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
 const (
+	pushBackendNative = "native"
+	pushBackendOras   = "oras"
+
 	containerfileArtifactTagSuffix = ".containerfile"
 	containerfileArtifactType      = "application/vnd.konflux.containerfile"
 	containerfileContext           = "."
@@ -25,6 +30,33 @@ const (
 	tagSuffixRegex = "^[a-zA-Z0-9._-]{1,57}$"
 )
 
+// Layout of the pushed artifact.
+//
+// The artifact is an OCI image manifest with the artifact type given by --artifact-type.
+// Every file is a separate layer, the org.opencontainers.image.title annotation of a layer
+// is the file name listed below. Layers are in this order, only the first one is mandatory:
+//
+//	| Title                                          | Media type                                   | Content                                                  |
+//	|------------------------------------------------|----------------------------------------------|----------------------------------------------------------|
+//	| Containerfile name or --alternative-filename   | application/vnd.oci.image.layer.v1.tar       | the Containerfile                                        |
+//	| ignore file name, e.g. .dockerignore           | application/vnd.konflux.containerignore      | .containerignore/.dockerignore used by the build         |
+//	| build-args                                     | application/vnd.konflux.build-args           | file given by --build-args-file                          |
+//	| resolved-base-images                           | application/vnd.konflux.resolved-base-images | file given by --resolved-base-images-file                |
+//	| buildprobe.yaml                                | application/vnd.konflux.buildprobe+yaml      | file given by --buildprobe-file                          |
+//
+// The Containerfile layer has the oras default media type, so that existing consumers are not affected.
+const (
+	containerfileLayerMediaType      = "application/vnd.oci.image.layer.v1.tar"
+	ignoreFileLayerMediaType         = "application/vnd.konflux.containerignore"
+	buildArgsLayerMediaType          = "application/vnd.konflux.build-args"
+	resolvedBaseImagesLayerMediaType = "application/vnd.konflux.resolved-base-images"
+	buildprobeLayerMediaType         = "application/vnd.konflux.buildprobe+yaml"
+
+	buildArgsLayerTitle          = "build-args"
+	resolvedBaseImagesLayerTitle = "resolved-base-images"
+	buildprobeLayerTitle         = "buildprobe.yaml"
+)
+
 var PushContainerfileParamsConfig = map[string]common.Parameter{
 	"image-url": {
 		Name:       "image-url",
@@ -101,6 +133,43 @@ var PushContainerfileParamsConfig = map[string]common.Parameter{
 		Usage:      "Alternative file name in the artifact image, e.g. Dockerfile.",
 		Required:   false,
 	},
+	"build-args-file": {
+		Name:       "build-args-file",
+		EnvVarName: "KBC_PUSH_CONTAINERFILE_BUILD_ARGS_FILE",
+		TypeKind:   reflect.String,
+		Usage:      "Path to the build arguments file used by the build. If set, it is pushed as a layer of the artifact.",
+		Required:   false,
+	},
+	"resolved-base-images-file": {
+		Name:       "resolved-base-images-file",
+		EnvVarName: "KBC_PUSH_CONTAINERFILE_RESOLVED_BASE_IMAGES_FILE",
+		TypeKind:   reflect.String,
+		Usage:      "Path to the resolved base images file written by the build (--resolved-base-images-output). If set, it is pushed as a layer of the artifact.",
+		Required:   false,
+	},
+	"buildprobe-file": {
+		Name:       "buildprobe-file",
+		EnvVarName: "KBC_PUSH_CONTAINERFILE_BUILDPROBE_FILE",
+		TypeKind:   reflect.String,
+		Usage:      "Path to the Buildprobe result written by the build (--buildprobe-output). If set, it is pushed as a layer of the artifact.",
+		Required:   false,
+	},
+	"push-backend": {
+		Name:         "push-backend",
+		EnvVarName:   "KBC_PUSH_CONTAINERFILE_PUSH_BACKEND",
+		TypeKind:     reflect.String,
+		DefaultValue: pushBackendNative,
+		Usage:        "How to push the artifact: 'native' pushes directly to the registry, 'oras' uses the oras CLI.",
+		Required:     false,
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_PUSH_CONTAINERFILE_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Verify the TLS on the registry endpoint (for push to a non-TLS registry). Applies to the native push backend.",
+		Required:     false,
+	},
 }
 
 type PushContainerfileParams struct {
@@ -113,6 +182,11 @@ type PushContainerfileParams struct {
 	Source              string `paramName:"source"`
 	ResultPathImageRef  string `paramName:"result-path-image-ref"`
 	AlternativeFilename string `paramName:"alternative-filename"`
+	BuildArgsFile       string `paramName:"build-args-file"`
+	ResolvedBaseImages  string `paramName:"resolved-base-images-file"`
+	BuildprobeFile      string `paramName:"buildprobe-file"`
+	PushBackend         string `paramName:"push-backend"`
+	TLSVerify           bool   `paramName:"tls-verify"`
 }
 
 type PushContainerfileResults struct {
@@ -124,10 +198,11 @@ type PushContainerfileCliWrappers struct {
 }
 
 type PushContainerfile struct {
-	Params        *PushContainerfileParams
-	CliWrappers   PushContainerfileCliWrappers
-	Results       PushContainerfileResults
-	ResultsWriter common.ResultsWriterInterface
+	Params         *PushContainerfileParams
+	CliWrappers    PushContainerfileCliWrappers
+	RegistryClient clients.OCIRegistryClientInterface
+	Results        PushContainerfileResults
+	ResultsWriter  common.ResultsWriterInterface
 
 	imageName string
 }
@@ -141,8 +216,14 @@ func NewPushContainerfile(cmd *cobra.Command) (*PushContainerfile, error) {
 		Params:        params,
 		ResultsWriter: common.NewResultsWriter(),
 	}
-	if err := pushContainerfile.initCliWrappers(); err != nil {
-		return nil, err
+	if params.PushBackend == pushBackendOras {
+		if err := pushContainerfile.initCliWrappers(); err != nil {
+			return nil, err
+		}
+	} else {
+		registryClient := clients.NewOCIRegistryClient()
+		registryClient.TLSVerify = params.TLSVerify
+		pushContainerfile.RegistryClient = registryClient
 	}
 	return pushContainerfile, nil
 }
@@ -167,12 +248,6 @@ func (c *PushContainerfile) Run() error {
 		return err
 	}
 
-	curDir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("error getting current directory: %w", err)
-	}
-	l.Logger.Debugf("Using current directory: %s\n", curDir)
-
 	containerfilePath, err := common.SearchDockerfile(common.DockerfileSearchOpts{
 		SourceDir:  c.Params.Source,
 		ContextDir: c.Params.Context,
@@ -188,28 +263,117 @@ func (c *PushContainerfile) Run() error {
 		return nil
 	}
 
-	if err := c.verifyContainerfileIsInSourceDir(containerfilePath); err != nil {
+	if err := c.verifyFileIsInSourceDir(containerfilePath); err != nil {
 		return fmt.Errorf("checking containerfile is inside source directory: %w", err)
 	}
 
 	l.Logger.Debugf("Got Containerfile: %s", containerfilePath)
 
+	metadataLayers, err := c.collectMetadataLayers(containerfilePath)
+	if err != nil {
+		return err
+	}
+
 	l.Logger.Debugf("Select registry authentication for %s", imageUrl)
 	registryAuth, err := common.SelectRegistryAuthFromDefaultAuthFile(imageUrl)
 	if err != nil {
 		return fmt.Errorf("cannot select registry authentication for image %s: %w", imageUrl, err)
 	}
 
+	tag := c.generateContainerfileImageTag()
+	destinationImage := fmt.Sprintf("%s:%s", c.imageName, tag)
+
+	pushFilename := filepath.Base(containerfilePath)
+	if c.Params.AlternativeFilename != "" {
+		pushFilename = filepath.Base(c.Params.AlternativeFilename)
+	}
+	for _, layer := range metadataLayers {
+		if layer.title == pushFilename {
+			return fmt.Errorf("file %s has the same name as the Containerfile in the artifact", layer.path)
+		}
+	}
+
+	var artifactImageRef string
+	if c.Params.PushBackend == pushBackendOras {
+		artifactImageRef, err = c.pushWithOras(registryAuth, destinationImage, containerfilePath, pushFilename, metadataLayers)
+	} else {
+		artifactImageRef, err = c.pushNative(registryAuth, destinationImage, containerfilePath, pushFilename, metadataLayers)
+	}
+	if err != nil {
+		return fmt.Errorf("error on pushing Containerfile %s: %w", containerfilePath, err)
+	}
+
+	l.Logger.Infof("Containerfile '%s' is pushed to registry with tag: %s", containerfilePath, tag)
+
+	c.Results.ImageRef = artifactImageRef
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	if c.Params.ResultPathImageRef != "" {
+		err = c.ResultsWriter.WriteResultString(artifactImageRef, c.Params.ResultPathImageRef)
+		if err != nil {
+			return fmt.Errorf("error on writing result image digest: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// pushNative pushes the artifact to the registry directly using the OCI distribution API.
+// Returns the digested reference of the pushed artifact.
+func (c *PushContainerfile) pushNative(registryAuth *common.RegistryAuth, destinationImage, containerfilePath, pushFilename string, layers metadataLayers) (string, error) {
+	content, err := os.ReadFile(containerfilePath) //nolint:gosec // containerfile path is validated
+	if err != nil {
+		return "", fmt.Errorf("error on reading file %s: %w", containerfilePath, err)
+	}
+	artifactLayers := []clients.ArtifactLayer{{
+		Content:   content,
+		MediaType: containerfileLayerMediaType,
+		Title:     pushFilename,
+	}}
+	for _, layer := range layers {
+		content, err := os.ReadFile(layer.path)
+		if err != nil {
+			return "", fmt.Errorf("error on reading file %s: %w", layer.path, err)
+		}
+		artifactLayers = append(artifactLayers, clients.ArtifactLayer{
+			Content:   content,
+			MediaType: layer.mediaType,
+			Title:     layer.title,
+		})
+	}
+
+	return c.RegistryClient.PushArtifact(&clients.PushArtifactArgs{
+		DestinationImage: destinationImage,
+		ArtifactType:     c.Params.ArtifactType,
+		Layers:           artifactLayers,
+		Auth:             registryAuth,
+	})
+}
+
+// pushWithOras pushes the artifact by oras CLI.
+// oras requires a registry config file and uses the pushed file paths as layer titles,
+// so the files are pushed from a work directory.
+// Returns the digested reference of the pushed artifact.
+func (c *PushContainerfile) pushWithOras(registryAuth *common.RegistryAuth, destinationImage, containerfilePath, pushFilename string, layers metadataLayers) (string, error) {
+	curDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("error getting current directory: %w", err)
+	}
+
 	registryConfigFile, err := os.CreateTemp("", "oras-push-registry-config-*")
 	if err != nil {
-		return fmt.Errorf("error on creating temporary file for registry config: %w", err)
+		return "", fmt.Errorf("error on creating temporary file for registry config: %w", err)
 	}
 	_, err = fmt.Fprintf(registryConfigFile, `{"auths":{"%s":{"auth":"%s"}}}`, registryAuth.Registry, registryAuth.Token)
 	if err != nil {
-		return fmt.Errorf("error on writing registry config file: %w", err)
+		return "", fmt.Errorf("error on writing registry config file: %w", err)
 	}
 	if err = registryConfigFile.Close(); err != nil {
-		return fmt.Errorf("error on closing registry config file after write: %w", err)
+		return "", fmt.Errorf("error on closing registry config file after write: %w", err)
 	}
 	defer func() {
 		if err := os.Remove(registryConfigFile.Name()); err != nil {
@@ -217,41 +381,39 @@ func (c *PushContainerfile) Run() error {
 		}
 	}()
 
-	tag := c.generateContainerfileImageTag()
-
 	absContainerfilePath, err := filepath.Abs(containerfilePath)
 	if err != nil {
-		return fmt.Errorf("error on getting absolute path of %s: %w", containerfilePath, err)
+		return "", fmt.Errorf("error on getting absolute path of %s: %w", containerfilePath, err)
 	}
 
-	var pushFilename string
 	var workDir string
 
-	if c.Params.AlternativeFilename != "" {
-		pushFilename = filepath.Base(c.Params.AlternativeFilename)
+	if pushFilename != filepath.Base(absContainerfilePath) || len(layers) > 0 {
+		// Files are pushed with the file name as layer title, so copy them
+		// under the expected names into a work directory.
 		workDir, err = os.MkdirTemp("", "push-containerfile-")
 		if err != nil {
-			return fmt.Errorf("error on creating temporary directory: %w", err)
+			return "", fmt.Errorf("error on creating temporary directory: %w", err)
 		}
 		defer func() {
 			if err := os.RemoveAll(workDir); err != nil {
 				l.Logger.Warnf("failed to remove '%s' directory: %s", workDir, err.Error())
 			}
 		}()
-		content, err := os.ReadFile(absContainerfilePath) //nolint:gosec // containerfile path is validated
-		if err != nil {
-			return fmt.Errorf("error on reading file %s: %w", absContainerfilePath, err)
+		if err := copyFileToDir(absContainerfilePath, workDir, pushFilename); err != nil {
+			return "", err
 		}
-		if err := os.WriteFile(filepath.Join(workDir, pushFilename), content, 0644); err != nil { //nolint:gosec // G703: path from controlled work directory
-			return fmt.Errorf("error on writing file: %w", err)
+		for _, layer := range layers {
+			if err := copyFileToDir(layer.path, workDir, layer.title); err != nil {
+				return "", err
+			}
 		}
 	} else {
-		pushFilename = filepath.Base(absContainerfilePath)
 		workDir = filepath.Dir(absContainerfilePath)
 	}
 
 	if err := os.Chdir(workDir); err != nil {
-		return fmt.Errorf("error on changing directory to %s: %w", workDir, err)
+		return "", fmt.Errorf("error on changing directory to %s: %w", workDir, err)
 	}
 	defer func() {
 		if err := os.Chdir(curDir); err != nil {
@@ -264,52 +426,137 @@ func (c *PushContainerfile) Run() error {
 		RegistryConfig:   registryConfigFile.Name(),
 		Format:           "go-template",
 		Template:         "{{.reference}}",
-		DestinationImage: fmt.Sprintf("%s:%s", c.imageName, tag),
+		DestinationImage: destinationImage,
 		FileName:         pushFilename,
+		ExtraFiles:       layers.orasPushFiles(),
 	})
 	if err != nil {
-		return fmt.Errorf("error on pushing Containerfile %s: %w", containerfilePath, err)
+		return "", err
 	}
 
-	l.Logger.Infof("Containerfile '%s' is pushed to registry with tag: %s", containerfilePath, tag)
+	return strings.TrimSpace(stdout), nil
+}
 
-	artifactImageRef := strings.TrimSpace(stdout)
+func (c *PushContainerfile) verifyFileIsInSourceDir(path string) error {
+	resolvedSource, err := common.ResolvePath(c.Params.Source)
+	if err != nil {
+		return fmt.Errorf("resolving source path: %w", err)
+	}
+	resolvedPath, err := common.ResolvePath(path)
+	if err != nil {
+		return fmt.Errorf("resolving path %s: %w", path, err)
+	}
+	if !resolvedPath.IsRelativeTo(resolvedSource) {
+		return fmt.Errorf("'%s' is outside '%s'", path, c.Params.Source)
+	}
+	return nil
+}
 
-	c.Results.ImageRef = artifactImageRef
-	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
-		return fmt.Errorf("error on creating results JSON: %w", err)
-	} else {
-		fmt.Print(resultsJson)
+// metadataLayer is a build metadata file pushed as a layer of the artifact.
+type metadataLayer struct {
+	path      string
+	title     string
+	mediaType string
+}
+
+type metadataLayers []metadataLayer
+
+func (layers metadataLayers) orasPushFiles() []cliwrappers.OrasPushFile {
+	var files []cliwrappers.OrasPushFile
+	for _, layer := range layers {
+		files = append(files, cliwrappers.OrasPushFile{Path: layer.title, MediaType: layer.mediaType})
 	}
+	return files
+}
 
-	if c.Params.ResultPathImageRef != "" {
-		err = c.ResultsWriter.WriteResultString(artifactImageRef, c.Params.ResultPathImageRef)
-		if err != nil {
-			return fmt.Errorf("error on writing result image digest: %w", err)
+// collectMetadataLayers returns the build metadata files to push along with the Containerfile.
+// See the artifact layout description at the top of this file.
+func (c *PushContainerfile) collectMetadataLayers(containerfilePath string) (metadataLayers, error) {
+	var layers metadataLayers
+
+	ignoreFile, err := c.findIgnoreFile(containerfilePath)
+	if err != nil {
+		return nil, err
+	}
+	if ignoreFile != "" {
+		layers = append(layers, metadataLayer{
+			path:      ignoreFile,
+			title:     filepath.Base(ignoreFile),
+			mediaType: ignoreFileLayerMediaType,
+		})
+	}
+
+	for _, layer := range []metadataLayer{
+		{path: c.Params.BuildArgsFile, title: buildArgsLayerTitle, mediaType: buildArgsLayerMediaType},
+		{path: c.Params.ResolvedBaseImages, title: resolvedBaseImagesLayerTitle, mediaType: resolvedBaseImagesLayerMediaType},
+		{path: c.Params.BuildprobeFile, title: buildprobeLayerTitle, mediaType: buildprobeLayerMediaType},
+	} {
+		if layer.path == "" {
+			continue
 		}
+		if _, err := os.Stat(layer.path); err != nil {
+			return nil, fmt.Errorf("cannot read build metadata file: %w", err)
+		}
+		layers = append(layers, layer)
 	}
 
-	return nil
+	for _, layer := range layers {
+		l.Logger.Debugf("Build metadata file %s is pushed as layer %s", layer.path, layer.title)
+	}
+
+	return layers, nil
 }
 
-func (c *PushContainerfile) verifyContainerfileIsInSourceDir(containerfilePath string) error {
-	resolvedSource, err := common.ResolvePath(c.Params.Source)
-	if err != nil {
-		return fmt.Errorf("resolving source path: %w", err)
+// findIgnoreFile finds the ignore file used by the build the same way as buildah does:
+// per-containerfile <containerfile>.containerignore or <containerfile>.dockerignore first,
+// then .containerignore or .dockerignore in the build context.
+// Returns empty string if there is no ignore file.
+func (c *PushContainerfile) findIgnoreFile(containerfilePath string) (string, error) {
+	contextDir := filepath.Join(c.Params.Source, c.Params.Context)
+	candidates := []string{
+		containerfilePath + ".containerignore",
+		containerfilePath + ".dockerignore",
+		filepath.Join(contextDir, ".containerignore"),
+		filepath.Join(contextDir, ".dockerignore"),
+	}
+	for _, candidate := range candidates {
+		_, err := os.Stat(candidate)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("error on checking ignore file %s: %w", candidate, err)
+		}
+		if err := c.verifyFileIsInSourceDir(candidate); err != nil {
+			return "", fmt.Errorf("checking ignore file is inside source directory: %w", err)
+		}
+		l.Logger.Debugf("Got ignore file: %s", candidate)
+		return candidate, nil
 	}
-	resolvedContainerfile, err := common.ResolvePath(containerfilePath)
+	return "", nil
+}
+
+// copyFileToDir copies the file into the directory under the given name.
+func copyFileToDir(path, dir, name string) error {
+	content, err := os.ReadFile(path) //nolint:gosec // path is validated or given by the user
 	if err != nil {
-		return fmt.Errorf("resolving containerfile path: %w", err)
+		return fmt.Errorf("error on reading file %s: %w", path, err)
 	}
-	if !resolvedContainerfile.IsRelativeTo(resolvedSource) {
-		return fmt.Errorf("'%s' is outside '%s'", containerfilePath, c.Params.Source)
+	if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil { //nolint:gosec // G703: path from controlled work directory
+		return fmt.Errorf("error on writing file: %w", err)
 	}
 	return nil
 }
 
 func (c *PushContainerfile) generateContainerfileImageTag() string {
-	digest := strings.Replace(c.Params.ImageDigest, ":", "-", 1)
-	return digest + c.Params.TagSuffix
+	return containerfileImageTag(c.Params.ImageDigest, c.Params.TagSuffix)
+}
+
+// containerfileImageTag returns the tag of the Containerfile artifact of the image with the given digest,
+// e.g. sha256-1234567.containerfile
+func containerfileImageTag(imageDigest, tagSuffix string) string {
+	digest := strings.Replace(imageDigest, ":", "-", 1)
+	return digest + tagSuffix
 }
 
 func (c *PushContainerfile) validateParams() error {
@@ -326,6 +573,13 @@ func (c *PushContainerfile) validateParams() error {
 		return fmt.Errorf("tag suffix includes invalid characters or exceeds the max length of 57 characters")
 	}
 
+	switch c.Params.PushBackend {
+	case "", pushBackendNative, pushBackendOras:
+	default:
+		return fmt.Errorf("push backend '%s' is invalid, must be one of: %s, %s",
+			c.Params.PushBackend, pushBackendNative, pushBackendOras)
+	}
+
 	altFilename := c.Params.AlternativeFilename
 	if strings.Contains(altFilename, "/") {
 		return fmt.Errorf("path is included in alternative file name '%s'", altFilename)
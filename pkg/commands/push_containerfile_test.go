@@ -10,6 +10,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/sirupsen/logrus"
 
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
@@ -80,6 +81,21 @@ func TestValidateParams(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("Capture invalid push backend", func(t *testing.T) {
+		cmd := PushContainerfile{
+			Params: &PushContainerfileParams{
+				ImageDigest: imageDigest,
+				TagSuffix:   ".containerfile",
+				PushBackend: "docker",
+			},
+			imageName: "localhost:5000/cool/app",
+		}
+		err := cmd.validateParams()
+		if err == nil || !regexp.MustCompile("^push backend 'docker' is invalid").MatchString(err.Error()) {
+			t.Errorf("Expected error about invalid push backend, got: %v", err)
+		}
+	})
 }
 
 func TestGenerateContainerfileImageTag(t *testing.T) {
@@ -157,6 +173,7 @@ func TestRun(t *testing.T) {
 						Containerfile:      "Containerfile",
 						Context:            ".",
 						TagSuffix:          ".containerfile",
+						PushBackend:        pushBackendOras,
 						ArtifactType:       "application/vnd.konflux.containerfile",
 						ResultPathImageRef: filepath.Join(workDir, "results", "image-ref"),
 					},
@@ -217,6 +234,7 @@ func TestRun(t *testing.T) {
 						Containerfile:       "Containerfile",
 						Context:             ".",
 						TagSuffix:           ".dockerfile",
+						PushBackend:         pushBackendOras,
 						AlternativeFilename: tc.alternativeFilename,
 					},
 					ResultsWriter: &common.ResultsWriter{},
@@ -233,6 +251,157 @@ func TestRun(t *testing.T) {
 		}
 	})
 
+	t.Run("Successful push with build metadata layers", func(t *testing.T) {
+		artifactImageRef := "localhost.reg.io/app@sha256:a7c0071906a9c6b654760e44a1fc8226f8268c70848148f19c35b02788b272a5"
+
+		os.MkdirAll(filepath.Join(workDir, "source", "meta"), 0755)
+		os.WriteFile(filepath.Join(workDir, "source", "meta", "Containerfile"), []byte("FROM fedora"), 0644)
+		os.WriteFile(filepath.Join(workDir, "source", "meta", ".dockerignore"), []byte("*.log"), 0644)
+		os.WriteFile(filepath.Join(workDir, "args"), []byte("VERSION=1"), 0644)
+		os.WriteFile(filepath.Join(workDir, "base-images"), []byte("fedora registry.io/fedora@sha256:1234"), 0644)
+		os.WriteFile(filepath.Join(workDir, "probe.yaml"), []byte("image: app"), 0644)
+
+		isPushCalled := false
+		orasCli := &mockOrasCli{}
+		orasCli.PushFunc = func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+			isPushCalled = true
+			g.Expect(args.FileName).Should(Equal("Containerfile"))
+			g.Expect(args.ExtraFiles).Should(Equal([]cliwrappers.OrasPushFile{
+				{Path: ".dockerignore", MediaType: "application/vnd.konflux.containerignore"},
+				{Path: "build-args", MediaType: "application/vnd.konflux.build-args"},
+				{Path: "resolved-base-images", MediaType: "application/vnd.konflux.resolved-base-images"},
+				{Path: "buildprobe.yaml", MediaType: "application/vnd.konflux.buildprobe+yaml"},
+			}))
+			expectedContents := map[string]string{
+				"Containerfile":        "FROM fedora",
+				".dockerignore":        "*.log",
+				"build-args":           "VERSION=1",
+				"resolved-base-images": "fedora registry.io/fedora@sha256:1234",
+				"buildprobe.yaml":      "image: app",
+			}
+			for name, expectedContent := range expectedContents {
+				content, err := os.ReadFile(name)
+				g.Expect(err).ShouldNot(HaveOccurred())
+				g.Expect(string(content)).Should(Equal(expectedContent))
+			}
+			return artifactImageRef, "", nil
+		}
+
+		cmd := &PushContainerfile{
+			Params: &PushContainerfileParams{
+				ImageUrl:           "localhost.reg.io/app",
+				ImageDigest:        imageDigest,
+				Source:             "source",
+				Context:            "meta",
+				TagSuffix:          ".containerfile",
+				PushBackend:        pushBackendOras,
+				BuildArgsFile:      filepath.Join(workDir, "args"),
+				ResolvedBaseImages: filepath.Join(workDir, "base-images"),
+				BuildprobeFile:     filepath.Join(workDir, "probe.yaml"),
+				ResultPathImageRef: filepath.Join(workDir, "results", "image-ref"),
+			},
+			ResultsWriter: &common.ResultsWriter{},
+			CliWrappers:   PushContainerfileCliWrappers{OrasCli: orasCli},
+		}
+
+		err := cmd.Run()
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(isPushCalled).Should(BeTrue())
+
+		actualImageRef, _ := os.ReadFile(cmd.Params.ResultPathImageRef)
+		g.Expect(string(actualImageRef)).Should(Equal(artifactImageRef))
+	})
+
+	t.Run("should return error when build metadata file does not exist", func(t *testing.T) {
+		cmd := &PushContainerfile{
+			Params: &PushContainerfileParams{
+				ImageUrl:      "localhost.reg.io/app",
+				ImageDigest:   imageDigest,
+				Source:        "source",
+				Containerfile: "Containerfile",
+				Context:       ".",
+				TagSuffix:     ".containerfile",
+				BuildArgsFile: filepath.Join(workDir, "nonexistent"),
+			},
+			ResultsWriter: &common.ResultsWriter{},
+			CliWrappers:   PushContainerfileCliWrappers{OrasCli: &mockOrasCli{}},
+		}
+
+		err := cmd.Run()
+		g.Expect(err).Should(MatchError(ContainSubstring("cannot read build metadata file")))
+	})
+
+	t.Run("Successful native push", func(t *testing.T) {
+		artifactImageRef := "localhost.reg.io/app@sha256:a7c0071906a9c6b654760e44a1fc8226f8268c70848148f19c35b02788b272a5"
+
+		os.MkdirAll(filepath.Join(workDir, "source", "meta"), 0755)
+		os.WriteFile(filepath.Join(workDir, "source", "meta", "Containerfile"), []byte("FROM fedora"), 0644)
+		os.WriteFile(filepath.Join(workDir, "source", "meta", ".dockerignore"), []byte("*.log"), 0644)
+		os.WriteFile(filepath.Join(workDir, "args"), []byte("VERSION=1"), 0644)
+
+		isPushCalled := false
+		registryClient := &mockOCIRegistryClient{}
+		registryClient.PushArtifactFunc = func(args *clients.PushArtifactArgs) (string, error) {
+			isPushCalled = true
+			expectedImage := "localhost.reg.io/app:sha256-e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b.dockerfile"
+			g.Expect(args.DestinationImage).Should(Equal(expectedImage))
+			g.Expect(args.ArtifactType).Should(Equal("application/vnd.konflux.containerfile"))
+			g.Expect(args.Auth).Should(Equal(&common.RegistryAuth{Registry: "localhost.reg.io", Token: "token"}))
+			g.Expect(args.Layers).Should(Equal([]clients.ArtifactLayer{
+				{Content: []byte("FROM fedora"), MediaType: "application/vnd.oci.image.layer.v1.tar", Title: "Dockerfile"},
+				{Content: []byte("*.log"), MediaType: "application/vnd.konflux.containerignore", Title: ".dockerignore"},
+				{Content: []byte("VERSION=1"), MediaType: "application/vnd.konflux.build-args", Title: "build-args"},
+			}))
+			return artifactImageRef, nil
+		}
+
+		cmd := &PushContainerfile{
+			Params: &PushContainerfileParams{
+				ImageUrl:            "localhost.reg.io/app",
+				ImageDigest:         imageDigest,
+				Source:              "source",
+				Context:             "meta",
+				TagSuffix:           ".dockerfile",
+				ArtifactType:        "application/vnd.konflux.containerfile",
+				AlternativeFilename: "Dockerfile",
+				BuildArgsFile:       filepath.Join(workDir, "args"),
+				ResultPathImageRef:  filepath.Join(workDir, "results", "image-ref"),
+			},
+			ResultsWriter:  &common.ResultsWriter{},
+			RegistryClient: registryClient,
+		}
+
+		err := cmd.Run()
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(isPushCalled).Should(BeTrue())
+
+		actualImageRef, _ := os.ReadFile(cmd.Params.ResultPathImageRef)
+		g.Expect(string(actualImageRef)).Should(Equal(artifactImageRef))
+	})
+
+	t.Run("should return error when native push fails", func(t *testing.T) {
+		registryClient := &mockOCIRegistryClient{}
+		registryClient.PushArtifactFunc = func(args *clients.PushArtifactArgs) (string, error) {
+			return "", fmt.Errorf("registry responded 500")
+		}
+
+		cmd := &PushContainerfile{
+			Params: &PushContainerfileParams{
+				ImageUrl:      "localhost.reg.io/app",
+				ImageDigest:   imageDigest,
+				Source:        "source",
+				Containerfile: "Containerfile",
+				Context:       ".",
+				TagSuffix:     ".containerfile",
+			},
+			ResultsWriter:  &common.ResultsWriter{},
+			RegistryClient: registryClient,
+		}
+
+		err := cmd.Run()
+		g.Expect(err).Should(MatchError(ContainSubstring("registry responded 500")))
+	})
+
 	t.Run("should return error when containerfile resolves outside source directory", func(t *testing.T) {
 		outsideDir := filepath.Join(workDir, "outside")
 		os.MkdirAll(outsideDir, 0755)
@@ -321,6 +490,7 @@ func TestRun(t *testing.T) {
 				Containerfile: "Containerfile",
 				Context:       ".",
 				TagSuffix:     ".containerfile",
+				PushBackend:   pushBackendOras,
 			},
 			ResultsWriter: &common.ResultsWriter{},
 			CliWrappers:   PushContainerfileCliWrappers{OrasCli: orasCli},
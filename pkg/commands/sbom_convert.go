@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+	"github.com/konflux-ci/konflux-build-cli/pkg/sbom"
+)
+
+// maxLoggedUnmappedElements limits the elements listed per unmapped field in the log,
+// image SBOMs may have thousands of files with the same unmapped field.
+const maxLoggedUnmappedElements = 5
+
+var SBOMConvertParamsConfig = map[string]common.Parameter{
+	"sbom": {
+		Name:       "sbom",
+		EnvVarName: "KBC_SBOM_CONVERT_SBOM",
+		TypeKind:   reflect.String,
+		Usage:      "Path to the SBOM to convert, SPDX 2.3 or CycloneDX 1.5/1.6 JSON.",
+		Required:   true,
+	},
+	"sbom-format": {
+		Name:       "sbom-format",
+		EnvVarName: "KBC_SBOM_CONVERT_SBOM_FORMAT",
+		TypeKind:   reflect.String,
+		Usage:      "Format of the converted SBOM (spdx or cyclonedx).",
+		Required:   true,
+	},
+	"cyclonedx-version": {
+		Name:         "cyclonedx-version",
+		EnvVarName:   "KBC_SBOM_CONVERT_CYCLONEDX_VERSION",
+		TypeKind:     reflect.String,
+		DefaultValue: "1.6",
+		Usage:        "CycloneDX specification version of the converted SBOM (1.5 or 1.6).",
+	},
+	"output": {
+		Name:       "output",
+		EnvVarName: "KBC_SBOM_CONVERT_OUTPUT",
+		TypeKind:   reflect.String,
+		Usage:      "File path where to write the converted SBOM.",
+		Required:   true,
+	},
+}
+
+type SBOMConvertParams struct {
+	SBOM             string `paramName:"sbom"`
+	SBOMFormat       string `paramName:"sbom-format"`
+	CycloneDXVersion string `paramName:"cyclonedx-version"`
+	Output           string `paramName:"output"`
+}
+
+type SBOMConvertResults struct {
+	SBOMPath       string               `json:"sbom_path"`
+	Format         string               `json:"format"`
+	UnmappedFields []sbom.UnmappedField `json:"unmapped_fields"`
+}
+
+type SBOMConvert struct {
+	Params        *SBOMConvertParams
+	Results       SBOMConvertResults
+	ResultsWriter common.ResultsWriterInterface
+}
+
+func NewSBOMConvert(cmd *cobra.Command) (*SBOMConvert, error) {
+	params := &SBOMConvertParams{}
+	if err := common.ParseParameters(cmd, SBOMConvertParamsConfig, params); err != nil {
+		return nil, err
+	}
+	return &SBOMConvert{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}, nil
+}
+
+func (c *SBOMConvert) Run() error {
+	common.LogParameters(SBOMConvertParamsConfig, c.Params)
+
+	var converted any
+	var unmapped []sbom.UnmappedField
+	switch c.Params.SBOMFormat {
+	case sbom.FormatSPDX:
+		doc, err := sbom.ReadCycloneDX(c.Params.SBOM)
+		if err != nil {
+			return err
+		}
+		if converted, unmapped, err = sbom.CycloneDXToSPDX(doc); err != nil {
+			return err
+		}
+	case sbom.FormatCycloneDX:
+		if !slices.Contains(sbom.CycloneDXSpecVersions, c.Params.CycloneDXVersion) {
+			return fmt.Errorf("cyclonedx-version must be one of %v, got '%s'", sbom.CycloneDXSpecVersions, c.Params.CycloneDXVersion)
+		}
+		doc, err := sbom.ReadSPDX(c.Params.SBOM)
+		if err != nil {
+			return err
+		}
+		if converted, unmapped, err = sbom.SPDXToCycloneDX(doc, c.Params.CycloneDXVersion); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("sbom-format must be 'cyclonedx' or 'spdx', got '%s'", c.Params.SBOMFormat)
+	}
+
+	for _, field := range unmapped {
+		elements := field.Elements
+		if len(elements) > maxLoggedUnmappedElements {
+			elements = append(elements[:maxLoggedUnmappedElements:maxLoggedUnmappedElements], "...")
+		}
+		if len(elements) == 0 {
+			l.Logger.Warnf("Field %s could not be mapped to %s", field.Field, c.Params.SBOMFormat)
+		} else {
+			l.Logger.Warnf("Field %s of %d elements could not be mapped to %s: %s",
+				field.Field, len(field.Elements), c.Params.SBOMFormat, strings.Join(elements, ", "))
+		}
+	}
+
+	if err := sbom.WriteDocument(c.Params.Output, converted); err != nil {
+		return err
+	}
+	l.Logger.Infof("Converted %s to %s in %s", c.Params.SBOM, c.Params.SBOMFormat, c.Params.Output)
+
+	c.Results = SBOMConvertResults{
+		SBOMPath:       c.Params.Output,
+		Format:         c.Params.SBOMFormat,
+		UnmappedFields: unmapped,
+	}
+	if c.Results.UnmappedFields == nil {
+		c.Results.UnmappedFields = []sbom.UnmappedField{}
+	}
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/sbom"
+)
+
+func Test_SBOMConvert_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	spdxDoc := testSPDXDocument("image", "SPDXRef-image", "pkg:pypi/requests@2.31.0")
+	spdxDoc.Packages[1].Annotations = []sbom.SPDXAnnotation{{Annotator: "Tool: syft", AnnotationType: "OTHER", Comment: "found"}}
+	spdxSBOM := writeTestSBOM(t, dir, "image.spdx.json", spdxDoc)
+
+	t.Run("should convert SPDX to CycloneDX", func(t *testing.T) {
+		output := filepath.Join(dir, "image.cdx.json")
+		c := &SBOMConvert{
+			Params:        &SBOMConvertParams{SBOM: spdxSBOM, SBOMFormat: "cyclonedx", CycloneDXVersion: "1.5", Output: output},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(Succeed())
+
+		converted, err := sbom.ReadCycloneDX(output)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(converted.SpecVersion).To(Equal("1.5"))
+		g.Expect(converted.Metadata.Component.BOMRef).To(Equal("SPDXRef-image"))
+		g.Expect(converted.Components[0].Purl).To(Equal("pkg:pypi/requests@2.31.0"))
+
+		g.Expect(c.Results.SBOMPath).To(Equal(output))
+		g.Expect(c.Results.Format).To(Equal("cyclonedx"))
+		g.Expect(c.Results.UnmappedFields).To(Equal([]sbom.UnmappedField{
+			{Field: "packages.annotations", Elements: []string{"SPDXRef-Package-requests"}},
+		}))
+	})
+
+	t.Run("should convert CycloneDX to SPDX", func(t *testing.T) {
+		cdxSBOM := writeTestSBOM(t, dir, "source.cdx.json", testCycloneDXDocument("source", "pkg:pypi/urllib3@2.0.0"))
+		output := filepath.Join(dir, "source.spdx.json")
+		c := &SBOMConvert{
+			Params:        &SBOMConvertParams{SBOM: cdxSBOM, SBOMFormat: "spdx", Output: output},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(Succeed())
+
+		converted, err := sbom.ReadSPDX(output)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(converted.Roots()).To(Equal([]string{"SPDXRef-source"}))
+		g.Expect(converted.Packages[1].Purls()).To(Equal([]string{"pkg:pypi/urllib3@2.0.0"}))
+		g.Expect(c.Results.UnmappedFields).To(BeEmpty())
+	})
+
+	t.Run("should fail if the SBOM has the requested format", func(t *testing.T) {
+		c := &SBOMConvert{
+			Params:        &SBOMConvertParams{SBOM: spdxSBOM, SBOMFormat: "spdx", Output: filepath.Join(dir, "out.json")},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("is spdx, expected cyclonedx")))
+	})
+
+	t.Run("should fail on invalid parameters", func(t *testing.T) {
+		c := &SBOMConvert{
+			Params:        &SBOMConvertParams{SBOM: spdxSBOM, SBOMFormat: "swid", Output: filepath.Join(dir, "out.json")},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("sbom-format must be 'cyclonedx' or 'spdx', got 'swid'")))
+
+		c.Params.SBOMFormat = "cyclonedx"
+		c.Params.CycloneDXVersion = "1.4"
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("cyclonedx-version must be one of [1.5 1.6], got '1.4'")))
+		_, err := os.Stat(c.Params.Output)
+		g.Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+}
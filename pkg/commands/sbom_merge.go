@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+	"github.com/konflux-ci/konflux-build-cli/pkg/sbom"
+)
+
+var SBOMMergeParamsConfig = map[string]common.Parameter{
+	"sbom": {
+		Name:       "sbom",
+		EnvVarName: "KBC_SBOM_MERGE_SBOM",
+		TypeKind:   reflect.Slice,
+		Usage: "Paths to the SBOMs to merge, e.g. the syft image SBOM, the syft source SBOM and the prefetch SBOM.\n" +
+			"The first SBOM describes the image, the other SBOMs are added as its content.",
+		Required: true,
+	},
+	"sbom-format": {
+		Name:         "sbom-format",
+		EnvVarName:   "KBC_SBOM_MERGE_SBOM_FORMAT",
+		TypeKind:     reflect.String,
+		DefaultValue: "spdx",
+		Usage:        "Format of the SBOMs and of the merged SBOM (spdx or cyclonedx).",
+	},
+	"resolved-base-images-file": {
+		Name:       "resolved-base-images-file",
+		EnvVarName: "KBC_SBOM_MERGE_RESOLVED_BASE_IMAGES_FILE",
+		TypeKind:   reflect.String,
+		Usage: "Path to the resolved base images file written by the build (--resolved-base-images-output). " +
+			"The digested images other than --base-image, e.g. of builder stages, are added as build tools of the image.",
+	},
+	"base-image": {
+		Name:       "base-image",
+		EnvVarName: "KBC_SBOM_MERGE_BASE_IMAGE",
+		TypeKind:   reflect.String,
+		Usage: "Digested reference of the base image of the final build stage, added as the ancestor of the image. " +
+			"Defaults to the first image of --resolved-base-images-file, the base image of the final stage.",
+	},
+	"output": {
+		Name:       "output",
+		EnvVarName: "KBC_SBOM_MERGE_OUTPUT",
+		TypeKind:   reflect.String,
+		Usage:      "File path where to write the merged SBOM.",
+		Required:   true,
+	},
+}
+
+type SBOMMergeParams struct {
+	SBOMs                  []string `paramName:"sbom"`
+	SBOMFormat             string   `paramName:"sbom-format"`
+	ResolvedBaseImagesFile string   `paramName:"resolved-base-images-file"`
+	BaseImage              string   `paramName:"base-image"`
+	Output                 string   `paramName:"output"`
+}
+
+type SBOMMergeResults struct {
+	SBOMPath    string   `json:"sbom_path"`
+	Format      string   `json:"format"`
+	Packages    int      `json:"packages"`
+	BaseImage   string   `json:"base_image,omitempty"`
+	BuildImages []string `json:"build_images,omitempty"`
+}
+
+type SBOMMerge struct {
+	Params        *SBOMMergeParams
+	Results       SBOMMergeResults
+	ResultsWriter common.ResultsWriterInterface
+}
+
+func NewSBOMMerge(cmd *cobra.Command) (*SBOMMerge, error) {
+	params := &SBOMMergeParams{}
+	if err := common.ParseParameters(cmd, SBOMMergeParamsConfig, params); err != nil {
+		return nil, err
+	}
+	return &SBOMMerge{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}, nil
+}
+
+func (c *SBOMMerge) Run() error {
+	common.LogParameters(SBOMMergeParamsConfig, c.Params)
+
+	if len(c.Params.SBOMs) == 0 {
+		return errors.New("no SBOM to merge")
+	}
+	if c.Params.SBOMFormat != sbom.FormatSPDX && c.Params.SBOMFormat != sbom.FormatCycloneDX {
+		return fmt.Errorf("sbom-format must be 'cyclonedx' or 'spdx', got '%s'", c.Params.SBOMFormat)
+	}
+
+	if c.Params.BaseImage != "" {
+		if _, err := reference.ParseNormalizedNamed(c.Params.BaseImage); err != nil || common.GetImageDigest(c.Params.BaseImage) == "" {
+			return fmt.Errorf("base-image must be a digested image reference, got '%s'", c.Params.BaseImage)
+		}
+	}
+
+	baseImage := c.Params.BaseImage
+	var buildImages []string
+	if c.Params.ResolvedBaseImagesFile != "" {
+		resolvedImages, err := readResolvedBaseImages(c.Params.ResolvedBaseImagesFile)
+		if err != nil {
+			return err
+		}
+		// The build writes the images of the final stage first, starting with its base image
+		if baseImage == "" && len(resolvedImages) > 0 {
+			baseImage = resolvedImages[0]
+			l.Logger.Infof("Using %s as the base image of the final stage", baseImage)
+		}
+		for _, image := range resolvedImages {
+			if !isSameImage(image, baseImage) {
+				buildImages = append(buildImages, image)
+			}
+		}
+	}
+
+	var merged any
+	var packages int
+	switch c.Params.SBOMFormat {
+	case sbom.FormatSPDX:
+		docs := make([]*sbom.SPDXDocument, 0, len(c.Params.SBOMs))
+		for _, path := range c.Params.SBOMs {
+			doc, err := sbom.ReadSPDX(path)
+			if err != nil {
+				return err
+			}
+			docs = append(docs, doc)
+		}
+		doc, err := sbom.MergeSPDX(docs, baseImage, buildImages)
+		if err != nil {
+			return err
+		}
+		merged, packages = doc, len(doc.Packages)
+	case sbom.FormatCycloneDX:
+		docs := make([]*sbom.CycloneDXDocument, 0, len(c.Params.SBOMs))
+		for _, path := range c.Params.SBOMs {
+			doc, err := sbom.ReadCycloneDX(path)
+			if err != nil {
+				return err
+			}
+			docs = append(docs, doc)
+		}
+		doc, err := sbom.MergeCycloneDX(docs, baseImage, buildImages)
+		if err != nil {
+			return err
+		}
+		merged, packages = doc, len(doc.Components)
+	}
+
+	if err := sbom.WriteDocument(c.Params.Output, merged); err != nil {
+		return err
+	}
+	l.Logger.Infof("Merged %d SBOMs into %s", len(c.Params.SBOMs), c.Params.Output)
+
+	c.Results = SBOMMergeResults{
+		SBOMPath:    c.Params.Output,
+		Format:      c.Params.SBOMFormat,
+		Packages:    packages,
+		BaseImage:   baseImage,
+		BuildImages: buildImages,
+	}
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	return nil
+}
+
+// isSameImage reports whether the digested references name the same image,
+// e.g. ubi9@sha256:<digest> and docker.io/library/ubi9:latest@sha256:<digest>.
+func isSameImage(imageRef, otherImageRef string) bool {
+	named, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return imageRef == otherImageRef
+	}
+	otherNamed, err := reference.ParseNormalizedNamed(otherImageRef)
+	if err != nil {
+		return false
+	}
+	return named.Name() == otherNamed.Name() && common.GetImageDigest(imageRef) == common.GetImageDigest(otherImageRef)
+}
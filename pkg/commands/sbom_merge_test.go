@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/sbom"
+)
+
+const sbomMergeTestDigest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+
+func writeTestSBOM(t *testing.T, dir, name string, doc any) string {
+	path := filepath.Join(dir, name)
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testSPDXDocument(name, root, purl string) *sbom.SPDXDocument {
+	return &sbom.SPDXDocument{
+		SPDXVersion:       sbom.SPDXVersion,
+		DataLicense:       sbom.SPDXDataLicense,
+		SPDXID:            sbom.SPDXDocumentID,
+		Name:              name,
+		DocumentNamespace: "https://example.com/" + name,
+		CreationInfo:      sbom.SPDXCreationInfo{Created: "2026-01-01T00:00:00Z", Creators: []string{"Tool: syft"}},
+		Packages: []sbom.SPDXPackage{
+			{SPDXID: root, Name: name, DownloadLocation: sbom.SPDXNoAssertion},
+			{SPDXID: "SPDXRef-Package-requests", Name: "requests", DownloadLocation: sbom.SPDXNoAssertion,
+				ExternalRefs: []sbom.SPDXExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl}}},
+		},
+		Relationships: []sbom.SPDXRelationship{
+			{SPDXElementID: sbom.SPDXDocumentID, RelationshipType: sbom.SPDXRelationshipDescribes, RelatedSPDXElement: root},
+			{SPDXElementID: root, RelationshipType: sbom.SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-Package-requests"},
+		},
+	}
+}
+
+func testCycloneDXDocument(name, purl string) *sbom.CycloneDXDocument {
+	return &sbom.CycloneDXDocument{
+		BOMFormat:    sbom.CycloneDXFormat,
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + name,
+		Version:      1,
+		Metadata: &sbom.CycloneDXMetadata{
+			Component: &sbom.CycloneDXComponent{BOMRef: name, Type: "application", Name: name},
+		},
+		Components: []sbom.CycloneDXComponent{
+			{BOMRef: purl, Type: "library", Name: "requests", Purl: purl},
+		},
+		Dependencies: []sbom.CycloneDXDependency{{Ref: name, DependsOn: []string{purl}}},
+	}
+}
+
+func Test_SBOMMerge_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	resolvedBaseImages := filepath.Join(dir, "resolved-base-images")
+	g.Expect(os.WriteFile(resolvedBaseImages,
+		[]byte("registry.io/golang:1.22 registry.io/golang:1.22@"+sbomMergeTestDigest+"\n"+
+			"registry.io/ubi9:latest registry.io/ubi9:latest@"+sbomMergeTestDigest+"\n"), 0644)).To(Succeed())
+
+	t.Run("should merge SPDX documents", func(t *testing.T) {
+		imageSBOM := writeTestSBOM(t, dir, "image.spdx.json", testSPDXDocument("image", "SPDXRef-image", "pkg:pypi/requests@2.31.0"))
+		prefetchSBOM := writeTestSBOM(t, dir, "prefetch.spdx.json", testSPDXDocument("prefetch", "SPDXRef-root", "pkg:pypi/requests@2.31.0"))
+		output := filepath.Join(dir, "merged.spdx.json")
+
+		resultsWriter := &mockResultsWriter{}
+		var results SBOMMergeResults
+		resultsWriter.CreateResultJsonFunc = func(result any) (string, error) {
+			results = result.(SBOMMergeResults)
+			return "", nil
+		}
+		c := &SBOMMerge{
+			Params: &SBOMMergeParams{
+				SBOMs:                  []string{imageSBOM, prefetchSBOM},
+				SBOMFormat:             "spdx",
+				ResolvedBaseImagesFile: resolvedBaseImages,
+				BaseImage:              "registry.io/ubi9:latest@" + sbomMergeTestDigest,
+				Output:                 output,
+			},
+			ResultsWriter: resultsWriter,
+		}
+		g.Expect(c.Run()).To(Succeed())
+
+		merged, err := sbom.ReadSPDX(output)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(merged.Roots()).To(Equal([]string{"SPDXRef-image"}))
+		// image, requests, prefetch root, base image and builder image
+		g.Expect(merged.Packages).To(HaveLen(5))
+		g.Expect(merged.Relationships).To(ContainElements(
+			sbom.SPDXRelationship{
+				SPDXElementID: "SPDXRef-image", RelationshipType: "DESCENDANT_OF", RelatedSPDXElement: "SPDXRef-BaseImage-registry.io-ubi9",
+			},
+			sbom.SPDXRelationship{
+				SPDXElementID: "SPDXRef-BaseImage-registry.io-golang", RelationshipType: "BUILD_TOOL_OF", RelatedSPDXElement: "SPDXRef-image",
+			},
+		))
+		g.Expect(merged.Relationships).ToNot(ContainElement(sbom.SPDXRelationship{
+			SPDXElementID: "SPDXRef-image", RelationshipType: "DESCENDANT_OF", RelatedSPDXElement: "SPDXRef-BaseImage-registry.io-golang",
+		}))
+
+		g.Expect(results).To(Equal(SBOMMergeResults{
+			SBOMPath:    output,
+			Format:      "spdx",
+			Packages:    5,
+			BaseImage:   "registry.io/ubi9:latest@" + sbomMergeTestDigest,
+			BuildImages: []string{"registry.io/golang:1.22@" + sbomMergeTestDigest},
+		}))
+	})
+
+	t.Run("should merge CycloneDX documents", func(t *testing.T) {
+		imageSBOM := writeTestSBOM(t, dir, "image.cdx.json", testCycloneDXDocument("image", "pkg:pypi/requests@2.31.0"))
+		sourceSBOM := writeTestSBOM(t, dir, "source.cdx.json", testCycloneDXDocument("source", "pkg:pypi/urllib3@2.0.0"))
+		output := filepath.Join(dir, "merged.cdx.json")
+
+		c := &SBOMMerge{
+			Params: &SBOMMergeParams{
+				SBOMs:      []string{imageSBOM, sourceSBOM},
+				SBOMFormat: "cyclonedx",
+				Output:     output,
+			},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(Succeed())
+
+		merged, err := sbom.ReadCycloneDX(output)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(merged.Metadata.Component.Name).To(Equal("image"))
+		g.Expect(merged.Components).To(HaveLen(3))
+		g.Expect(merged.Dependencies[0]).To(Equal(sbom.CycloneDXDependency{
+			Ref: "image", DependsOn: []string{"pkg:pypi/requests@2.31.0", "source"},
+		}))
+		g.Expect(c.Results.Packages).To(Equal(3))
+	})
+
+	t.Run("should take the base image of the final stage from resolved base images", func(t *testing.T) {
+		finalStageResolvedBaseImages := filepath.Join(dir, "final-stage-resolved-base-images")
+		g.Expect(os.WriteFile(finalStageResolvedBaseImages,
+			[]byte("registry.io/ubi9:latest registry.io/ubi9:latest@"+sbomMergeTestDigest+"\n"+
+				"registry.io/golang:1.22 registry.io/golang:1.22@"+sbomMergeTestDigest+"\n"), 0644)).To(Succeed())
+		imageSBOM := writeTestSBOM(t, dir, "final-stage.spdx.json", testSPDXDocument("image", "SPDXRef-image", "pkg:pypi/requests@2.31.0"))
+
+		c := &SBOMMerge{
+			Params: &SBOMMergeParams{
+				SBOMs:                  []string{imageSBOM},
+				SBOMFormat:             "spdx",
+				ResolvedBaseImagesFile: finalStageResolvedBaseImages,
+				Output:                 filepath.Join(dir, "final-stage-merged.spdx.json"),
+			},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(c.Results.BaseImage).To(Equal("registry.io/ubi9:latest@" + sbomMergeTestDigest))
+		g.Expect(c.Results.BuildImages).To(Equal([]string{"registry.io/golang:1.22@" + sbomMergeTestDigest}))
+	})
+
+	t.Run("should match the base image with normalized references", func(t *testing.T) {
+		normalizedResolvedBaseImages := filepath.Join(dir, "normalized-resolved-base-images")
+		g.Expect(os.WriteFile(normalizedResolvedBaseImages,
+			[]byte("golang:1.22 docker.io/library/golang:1.22@"+sbomMergeTestDigest+"\n"+
+				"ubi9 docker.io/library/ubi9:latest@"+sbomMergeTestDigest+"\n"), 0644)).To(Succeed())
+		imageSBOM := writeTestSBOM(t, dir, "normalized.spdx.json", testSPDXDocument("image", "SPDXRef-image", "pkg:pypi/requests@2.31.0"))
+
+		c := &SBOMMerge{
+			Params: &SBOMMergeParams{
+				SBOMs:                  []string{imageSBOM},
+				SBOMFormat:             "spdx",
+				ResolvedBaseImagesFile: normalizedResolvedBaseImages,
+				BaseImage:              "ubi9@" + sbomMergeTestDigest,
+				Output:                 filepath.Join(dir, "normalized-merged.spdx.json"),
+			},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(c.Results.BaseImage).To(Equal("ubi9@" + sbomMergeTestDigest))
+		g.Expect(c.Results.BuildImages).To(Equal([]string{"docker.io/library/golang:1.22@" + sbomMergeTestDigest}))
+	})
+
+	t.Run("should fail on format mismatch", func(t *testing.T) {
+		imageSBOM := writeTestSBOM(t, dir, "mismatch.cdx.json", testCycloneDXDocument("image", "pkg:pypi/requests@2.31.0"))
+		c := &SBOMMerge{
+			Params:        &SBOMMergeParams{SBOMs: []string{imageSBOM}, SBOMFormat: "spdx", Output: filepath.Join(dir, "out.json")},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("is cyclonedx, expected spdx")))
+	})
+
+	t.Run("should fail on base image without digest", func(t *testing.T) {
+		c := &SBOMMerge{
+			Params: &SBOMMergeParams{SBOMs: []string{"sbom.json"}, SBOMFormat: "spdx", BaseImage: "registry.io/ubi9:latest",
+				Output: filepath.Join(dir, "out.json")},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("base-image must be a digested image reference")))
+	})
+
+	t.Run("should fail on invalid format", func(t *testing.T) {
+		c := &SBOMMerge{
+			Params:        &SBOMMergeParams{SBOMs: []string{"sbom.json"}, SBOMFormat: "swid", Output: filepath.Join(dir, "out.json")},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("sbom-format must be 'cyclonedx' or 'spdx', got 'swid'")))
+	})
+}
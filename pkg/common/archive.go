@@ -0,0 +1,149 @@
+package common
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ListTreePaths returns the slash-separated paths of all entries under dir, relative to it
+// and sorted. .git directories and files (submodules have a .git file) are skipped.
+func ListTreePaths(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if d.Name() == ".git" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// TarGzOptions are the options of WriteReproducibleTarGz.
+type TarGzOptions struct {
+	// Prefix is a slash-separated directory the entries are placed in, e.g. src/app.
+	Prefix string
+	// ModTime is the mtime of all entries.
+	ModTime time.Time
+}
+
+// WriteReproducibleTarGz writes the entries of dir at the given paths, as returned by ListTreePaths,
+// as a gzipped tarball. The output depends only on the content, the paths and the options:
+// entries are owned by root, have the same mtime, and only the executable bit of file modes is kept.
+func WriteReproducibleTarGz(w io.Writer, dir string, paths []string, opts TarGzOptions) error {
+	// The zero gzip header has no name and no mtime
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	prefix := strings.Trim(opts.Prefix, "/")
+	if prefix != "" {
+		// Directory entries of the prefix itself
+		parts := strings.Split(prefix, "/")
+		for i := range parts {
+			header := archiveHeader(strings.Join(parts[:i+1], "/")+"/", opts.ModTime)
+			header.Typeflag = tar.TypeDir
+			header.Mode = 0755
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return err
+			}
+		}
+		prefix += "/"
+	}
+
+	for _, path := range paths {
+		if err := writeArchiveEntry(tarWriter, dir, path, prefix, opts.ModTime); err != nil {
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+func archiveHeader(name string, modTime time.Time) *tar.Header {
+	return &tar.Header{
+		Name:    name,
+		ModTime: modTime,
+		Uid:     0,
+		Gid:     0,
+		Uname:   "root",
+		Gname:   "root",
+	}
+}
+
+func writeArchiveEntry(tarWriter *tar.Writer, dir, path, prefix string, modTime time.Time) error {
+	fullPath := filepath.Join(dir, filepath.FromSlash(path))
+	info, err := os.Lstat(fullPath)
+	if err != nil {
+		return err
+	}
+
+	header := archiveHeader(prefix+path, modTime)
+	switch {
+	case info.Mode().IsDir():
+		header.Typeflag = tar.TypeDir
+		header.Name += "/"
+		header.Mode = 0755
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(fullPath)
+		if err != nil {
+			return err
+		}
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = target
+		header.Mode = 0777
+	case info.Mode().IsRegular():
+		header.Typeflag = tar.TypeReg
+		header.Size = info.Size()
+		header.Mode = 0644
+		if info.Mode()&0111 != 0 {
+			header.Mode = 0755
+		}
+	default:
+		return fmt.Errorf("unsupported file type of %s: %s", path, info.Mode().Type())
+	}
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	if header.Typeflag != tar.TypeReg {
+		return nil
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := io.Copy(tarWriter, file); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestWriteReproducibleTarGz(t *testing.T) {
+	g := NewWithT(t)
+
+	// .
+	// ├── .git/config
+	// ├── b.txt
+	// ├── a/run.sh (executable)
+	// └── a/link -> ../b.txt
+	dir := t.TempDir()
+	g.Expect(os.MkdirAll(filepath.Join(dir, ".git"), 0700)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dir, ".git", "config"), []byte("[core]\n"), 0600)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b\n"), 0600)).To(Succeed())
+	g.Expect(os.Mkdir(filepath.Join(dir, "a"), 0700)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dir, "a", "run.sh"), []byte("#!/bin/sh\n"), 0700)).To(Succeed())
+	g.Expect(os.Symlink("../b.txt", filepath.Join(dir, "a", "link"))).To(Succeed())
+
+	paths, err := ListTreePaths(dir)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(paths).To(Equal([]string{"a", "a/link", "a/run.sh", "b.txt"}))
+
+	modTime := time.Unix(1704067200, 0)
+	var archive bytes.Buffer
+	g.Expect(WriteReproducibleTarGz(&archive, dir, paths, TarGzOptions{Prefix: "src/app/", ModTime: modTime})).To(Succeed())
+
+	gzipReader, err := gzip.NewReader(&archive)
+	g.Expect(err).ToNot(HaveOccurred())
+	tarReader := tar.NewReader(gzipReader)
+	headers := map[string]*tar.Header{}
+	var names []string
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(header.ModTime.Equal(modTime)).To(BeTrue())
+		g.Expect(header.Uid).To(Equal(0))
+		g.Expect(header.Gname).To(Equal("root"))
+		names = append(names, header.Name)
+		headers[header.Name] = header
+	}
+
+	g.Expect(names).To(Equal([]string{"src/", "src/app/", "src/app/a/", "src/app/a/link", "src/app/a/run.sh", "src/app/b.txt"}))
+	g.Expect(headers["src/app/a/"].Mode).To(Equal(int64(0755)))
+	g.Expect(headers["src/app/a/run.sh"].Mode).To(Equal(int64(0755)))
+	g.Expect(headers["src/app/b.txt"].Mode).To(Equal(int64(0644)))
+	g.Expect(headers["src/app/a/link"].Linkname).To(Equal("../b.txt"))
+
+	// Same content with other file metadata gives the same archive
+	var first bytes.Buffer
+	g.Expect(WriteReproducibleTarGz(&first, dir, paths, TarGzOptions{ModTime: modTime})).To(Succeed())
+	g.Expect(os.Chmod(filepath.Join(dir, "b.txt"), 0664)).To(Succeed())
+	g.Expect(os.Chtimes(filepath.Join(dir, "b.txt"), time.Now(), time.Now())).To(Succeed())
+	var second bytes.Buffer
+	g.Expect(WriteReproducibleTarGz(&second, dir, paths, TarGzOptions{ModTime: modTime})).To(Succeed())
+	g.Expect(second.Bytes()).To(Equal(first.Bytes()))
+}
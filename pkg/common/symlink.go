@@ -17,17 +17,25 @@ import (
 // relative to dir; patterns must not start with '/'. Matching symlinks are skipped entirely.
 // Patterns use * and ? wildcards (* matches across '/').
 func CheckSymlinks(dir string, excludePatterns []string) error {
-	l.Logger.Debugf("Checking for symlinks pointing outside the directory %s", dir)
+	return CheckSymlinksWithin(dir, dir, excludePatterns)
+}
+
+// CheckSymlinksWithin walks the directory tree of dir and returns an error if any
+// symlink points to a target outside root, which must contain dir.
+// This allows checking a subtree, e.g. a submodule, against the whole checkout.
+// excludePatterns are relative to root, see CheckSymlinks.
+func CheckSymlinksWithin(dir, root string, excludePatterns []string) error {
+	l.Logger.Debugf("Checking for symlinks in %s pointing outside the directory %s", dir, root)
 
-	baseDir, err := ResolvePath(dir)
+	baseDir, err := ResolvePath(root)
 	if err != nil {
 		return fmt.Errorf("failed to resolve directory path: %w", err)
 	}
 
-	checkoutRoot := filepath.Clean(dir)
-	compiledExcludes, err := compileExcludePatterns(excludePatterns)
+	checkoutRoot := filepath.Clean(root)
+	compiledExcludes, err := CompilePathPatterns(excludePatterns)
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid symlink check exclusion: %w", err)
 	}
 
 	var invalidSymlinks []string
@@ -71,38 +79,12 @@ func CheckSymlinks(dir string, excludePatterns []string) error {
 	return nil
 }
 
-// compileExcludePatterns validates and pre-compiles exclusion patterns.
-func compileExcludePatterns(patterns []string) ([]*regexp.Regexp, error) {
-	var out []*regexp.Regexp
-	for _, pattern := range patterns {
-		pattern = strings.TrimSpace(pattern)
-		if pattern == "" {
-			continue
-		}
-		if strings.HasPrefix(pattern, "/") || filepath.IsAbs(pattern) {
-			return nil, fmt.Errorf("exclusion pattern %q must be relative to the checkout directory (must not start with '/')", pattern)
-		}
-		re, err := pathPatternToRegexp(pattern)
-		if err != nil {
-			return nil, fmt.Errorf("invalid exclusion pattern %q: %w", pattern, err)
-		}
-		out = append(out, re)
-	}
-	return out, nil
-}
-
-func symlinkPathExcluded(path, checkoutRoot string, patterns []*regexp.Regexp) bool {
+func symlinkPathExcluded(path, checkoutRoot string, patterns PathPatterns) bool {
 	rel, err := filepath.Rel(checkoutRoot, path)
 	if err != nil {
 		return false
 	}
-	rel = filepath.ToSlash(rel)
-	for _, re := range patterns {
-		if re.MatchString(rel) {
-			return true
-		}
-	}
-	return false
+	return patterns.Match(filepath.ToSlash(rel))
 }
 
 // pathPatternToRegexp converts a path pattern to an anchored regexp.
@@ -127,3 +109,38 @@ func pathPatternToRegexp(pattern string) (*regexp.Regexp, error) {
 	b.WriteString("$")
 	return regexp.Compile(b.String())
 }
+
+// PathPatterns is a compiled set of path patterns relative to a directory,
+// using the same syntax as the symlink check exclusion patterns.
+type PathPatterns []*regexp.Regexp
+
+// CompilePathPatterns validates and pre-compiles the given path patterns.
+// Blank patterns are ignored.
+func CompilePathPatterns(patterns []string) (PathPatterns, error) {
+	var out PathPatterns
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if strings.HasPrefix(pattern, "/") || filepath.IsAbs(pattern) {
+			return nil, fmt.Errorf("path pattern %q must be relative (must not start with '/')", pattern)
+		}
+		re, err := pathPatternToRegexp(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// Match reports whether the slash-separated relative path matches any of the patterns.
+func (p PathPatterns) Match(path string) bool {
+	for _, re := range p {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
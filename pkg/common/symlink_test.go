@@ -112,6 +112,78 @@ func Test_pathPatternToRegexp(t *testing.T) {
 	}
 }
 
+func TestCheckSymlinksWithin(t *testing.T) {
+	t.Run("allows symlink in subtree pointing elsewhere in root", func(t *testing.T) {
+		root := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(root, "sub"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "shared.txt"), []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink("../shared.txt", filepath.Join(root, "sub", "link")); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := CheckSymlinksWithin(filepath.Join(root, "sub"), root, nil); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("fails on symlink in subtree pointing outside root", func(t *testing.T) {
+		root := t.TempDir()
+		mustExternalSymlink(t, root, "sub/bad")
+
+		if err := CheckSymlinksWithin(filepath.Join(root, "sub"), root, nil); err == nil {
+			t.Fatal("expected error for symlink pointing outside root")
+		}
+	})
+
+	t.Run("matches exclude patterns relative to root", func(t *testing.T) {
+		root := t.TempDir()
+		mustExternalSymlink(t, root, "sub/testdata/bad")
+
+		if err := CheckSymlinksWithin(filepath.Join(root, "sub"), root, []string{"sub/testdata/*"}); err != nil {
+			t.Fatalf("expected excluded symlink to be skipped, got: %v", err)
+		}
+	})
+}
+
+func TestCompilePathPatterns(t *testing.T) {
+	t.Run("matches any pattern", func(t *testing.T) {
+		patterns, err := CompilePathPatterns([]string{"services/api/*", " ", "go.mod"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(patterns) != 2 {
+			t.Fatalf("expected blank pattern to be skipped, got %d patterns", len(patterns))
+		}
+		for _, path := range []string{"services/api/main.go", "services/api/internal/x.go", "go.mod"} {
+			if !patterns.Match(path) {
+				t.Errorf("expected %q to match", path)
+			}
+		}
+		for _, path := range []string{"services/web/main.go", "go.sum", "sub/go.mod"} {
+			if patterns.Match(path) {
+				t.Errorf("expected %q not to match", path)
+			}
+		}
+	})
+
+	t.Run("rejects absolute pattern", func(t *testing.T) {
+		if _, err := CompilePathPatterns([]string{"/etc/*"}); err == nil {
+			t.Fatal("expected error for absolute pattern")
+		}
+	})
+
+	t.Run("empty set matches nothing", func(t *testing.T) {
+		var patterns PathPatterns
+		if patterns.Match("anything") {
+			t.Fatal("expected empty pattern set not to match")
+		}
+	})
+}
+
 func TestCheckSymlinks(t *testing.T) {
 	t.Run("fails on external symlink without exclusions", func(t *testing.T) {
 		dir := t.TempDir()
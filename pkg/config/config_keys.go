@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	cacheProxySection       = "cache-proxy"
+	artifactRegistrySection = "artifact-registry"
+)
+
+// configKey describes a single Konflux config key, where it lives in config files
+// and how it maps to KonfluxRawConfig.
+type configKey struct {
+	// Name of the key, the same in the ConfigMap data and in the config file section.
+	Name string
+	// Section of the INI or YAML config file the key belongs to.
+	Section string
+	// Built-in default value, used when no config source sets the key.
+	Default string
+	// Whether tenants may override the key in the ConfigMap of their namespace.
+	TenantOverridable bool
+	// Whether the key is a switch tenants may only turn off, so they cannot enable what the platform disabled.
+	TenantMayOnlyDisable bool
+
+	set      func(rawConfig *KonfluxRawConfig, value string)
+	validate func(value string) error
+}
+
+// configKeys lists all known Konflux config keys in the order they are shown to the user.
+var configKeys = newConfigKeys()
+
+func newConfigKeys() []configKey {
+	keys := []configKey{
+		tenantMayDisable(stringFieldKey("allow-cache-proxy", cacheProxySection, "true", validateBool,
+			func(c *KonfluxRawConfig) *string { return &c.AllowCacheProxy })),
+		stringFieldKey("http-proxy", cacheProxySection, "", nil,
+			func(c *KonfluxRawConfig) *string { return &c.HttpProxy }),
+		stringFieldKey("no-proxy", cacheProxySection, "", nil,
+			func(c *KonfluxRawConfig) *string { return &c.NoProxy }),
+		stringFieldKey("allow-package-registry-proxy", artifactRegistrySection, "false", validateBool,
+			func(c *KonfluxRawConfig) *string { return &c.HermetoPackageRegistryProxyAllowed }),
+	}
+	for _, packageManager := range HermetoPackageManagers {
+		keys = append(keys,
+			tenantOverridable(mapFieldKey("package-registry-proxy-"+packageManager+"-url", artifactRegistrySection, ValidateProxyURL,
+				func(c *KonfluxRawConfig) *map[string]string { return &c.HermetoPackageManagerProxy }, packageManager)),
+			tenantMayDisable(mapFieldKey("allow-package-registry-proxy-"+packageManager, artifactRegistrySection, validateBool,
+				func(c *KonfluxRawConfig) *map[string]string { return &c.HermetoPackageManagerProxyAllowed }, packageManager)),
+		)
+	}
+	return keys
+}
+
+func stringFieldKey(name, section, defaultValue string, validate func(string) error, field func(*KonfluxRawConfig) *string) configKey {
+	return configKey{
+		Name:     name,
+		Section:  section,
+		Default:  defaultValue,
+		validate: validate,
+		set:      func(c *KonfluxRawConfig, value string) { *field(c) = value },
+	}
+}
+
+// mapFieldKey returns config key stored under mapKey in the map field. Empty values are not stored.
+func mapFieldKey(name, section string, validate func(string) error, field func(*KonfluxRawConfig) *map[string]string, mapKey string) configKey {
+	return configKey{
+		Name:     name,
+		Section:  section,
+		validate: validate,
+		set: func(c *KonfluxRawConfig, value string) {
+			values := field(c)
+			if value == "" {
+				delete(*values, mapKey)
+				return
+			}
+			if *values == nil {
+				*values = map[string]string{}
+			}
+			(*values)[mapKey] = value
+		},
+	}
+}
+
+func tenantOverridable(key configKey) configKey {
+	key.TenantOverridable = true
+	return key
+}
+
+func tenantMayDisable(key configKey) configKey {
+	key.TenantOverridable = true
+	key.TenantMayOnlyDisable = true
+	return key
+}
+
+// checkTenantOverride returns error if tenants may not set the known key to the value.
+// Switches may only be set to false by tenants, only the platform may enable them.
+func checkTenantOverride(name, value string) error {
+	key, known := findConfigKey(name)
+	if !known || !key.TenantMayOnlyDisable {
+		return nil
+	}
+	if enabled, err := strconv.ParseBool(value); err != nil || enabled {
+		return fmt.Errorf("tenants may only set it to false, got '%s'", value)
+	}
+	return nil
+}
+
+// TenantOverridableKeys returns the config keys tenants may override in the ConfigMap of their namespace.
+// The global allow-package-registry-proxy switch and the cache proxy address stay under platform control.
+func TenantOverridableKeys() []string {
+	var names []string
+	for _, key := range configKeys {
+		if key.TenantOverridable {
+			names = append(names, key.Name)
+		}
+	}
+	return names
+}
+
+func validateBool(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return fmt.Errorf("invalid boolean value '%s'", value)
+	}
+	return nil
+}
+
+// findConfigKey returns the known config key with the given name.
+func findConfigKey(name string) (configKey, bool) {
+	for _, key := range configKeys {
+		if key.Name == name {
+			return key, true
+		}
+	}
+	return configKey{}, false
+}
+
+// rawConfigFromValues maps config key/value pairs of a single config source to KonfluxRawConfig.
+// Unknown keys are ignored.
+func rawConfigFromValues(values map[string]string) *KonfluxRawConfig {
+	rawConfig := &KonfluxRawConfig{
+		HermetoPackageManagerProxy:        map[string]string{},
+		HermetoPackageManagerProxyAllowed: map[string]string{},
+	}
+	for _, key := range configKeys {
+		if value, found := values[key.Name]; found {
+			key.set(rawConfig, value)
+		}
+	}
+	return rawConfig
+}
@@ -3,33 +3,38 @@ package config
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 
-	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
+	"go.yaml.in/yaml/v3"
 	"gopkg.in/ini.v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
 var NewConfigReader func() (ConfigReader, error) = ConfigReaderFactory
 
-// KonfluxRawConfig holds unmodified data that was read from the config source.
+// KonfluxRawConfig holds unmodified data that was read from the config sources.
 type KonfluxRawConfig struct {
 	// Cache proxy config
 	AllowCacheProxy string
 	HttpProxy       string
 	NoProxy         string
 
-	// Package registry proxy URLs
-	HermetoBundlerProxy string
-	HermetoCargoProxy   string
-	HermetoGomodProxy   string
-	HermetoNpmProxy     string
-	HermetoPipProxy     string
-	HermetoPnpmProxy    string
-	HermetoYarnProxy    string
+	// Package registry proxy URLs, package manager name => URL. Only set values are present.
+	HermetoPackageManagerProxy map[string]string
 	// Global setting allowing of forbidding usage of package registry proxies on the cluster level.
 	HermetoPackageRegistryProxyAllowed string
+	// Per package manager settings allowing or forbidding usage of its package registry proxy,
+	// package manager name => unparsed value. Only set values are present.
+	HermetoPackageManagerProxyAllowed map[string]string
+
+	// Config key => name of the config layer the value comes from.
+	// Set only if the config was resolved from layered config sources.
+	Sources map[string]string
 }
 
 // ConfigReader defines the interface for reading config data.
@@ -37,18 +42,14 @@ type ConfigReader interface {
 	ReadConfigData() (*KonfluxRawConfig, error)
 }
 
-// ConfigReaderFactory returns config reader according to the configured config source.
+// ConfigSource defines the interface for reading raw key/value pairs of a single config layer.
+type ConfigSource interface {
+	ReadValues() (map[string]string, error)
+}
+
+// ConfigReaderFactory returns config reader according to the configured config sources.
 func ConfigReaderFactory() (ConfigReader, error) {
-	platformConfigFile := os.Getenv("PLATFORM_CONFIG_FILE")
-	if platformConfigFile != "" {
-		return &IniFileReader{FilePath: platformConfigFile}, nil
-	} else {
-		clientset, err := clients.NewKubeClientSet()
-		if err != nil {
-			return nil, err
-		}
-		return &K8sConfigMapReader{Name: "cluster-config", Namespace: "konflux-info", Clientset: clientset}, nil
-	}
+	return NewLayeredConfigReader()
 }
 
 // K8sConfigMapReader reads configuration from a Kubernetes cluster.
@@ -60,26 +61,73 @@ type K8sConfigMapReader struct {
 
 // ReadConfigData reads the config from the ConfigMap data of a Kubernetes cluster.
 func (k *K8sConfigMapReader) ReadConfigData() (*KonfluxRawConfig, error) {
-	ctx := context.Background()
-	configMap, err := k.Clientset.CoreV1().ConfigMaps(k.Namespace).Get(ctx, k.Name, metav1.GetOptions{})
+	values, err := k.ReadValues()
+	if err != nil {
+		return nil, err
+	}
+	return rawConfigFromValues(values), nil
+}
+
+// ReadValues returns the ConfigMap data.
+func (k *K8sConfigMapReader) ReadValues() (map[string]string, error) {
+	configMap, err := k.Clientset.CoreV1().ConfigMaps(k.Namespace).Get(context.Background(), k.Name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get configmap %s/%s: %w", k.Namespace, k.Name, err)
 	}
-	rawConfig := &KonfluxRawConfig{
-		AllowCacheProxy: configMap.Data["allow-cache-proxy"],
-		HttpProxy:       configMap.Data["http-proxy"],
-		NoProxy:         configMap.Data["no-proxy"],
-
-		HermetoBundlerProxy:                configMap.Data["package-registry-proxy-bundler-url"],
-		HermetoCargoProxy:                  configMap.Data["package-registry-proxy-cargo-url"],
-		HermetoGomodProxy:                  configMap.Data["package-registry-proxy-gomod-url"],
-		HermetoNpmProxy:                    configMap.Data["package-registry-proxy-npm-url"],
-		HermetoPipProxy:                    configMap.Data["package-registry-proxy-pip-url"],
-		HermetoPnpmProxy:                   configMap.Data["package-registry-proxy-pnpm-url"],
-		HermetoYarnProxy:                   configMap.Data["package-registry-proxy-yarn-url"],
-		HermetoPackageRegistryProxyAllowed: configMap.Data["allow-package-registry-proxy"],
+	values := maps.Clone(configMap.Data)
+	if values == nil {
+		values = map[string]string{}
 	}
-	return rawConfig, nil
+	return values, nil
+}
+
+// TenantConfigMapReader reads config overrides from the ConfigMap in the pipeline namespace.
+// A missing ConfigMap has no overrides. Keys not listed in AllowedKeys are rejected,
+// as are switches set to anything but false.
+type TenantConfigMapReader struct {
+	Name      string
+	Namespace string
+	Clientset kubernetes.Interface
+	// Keys tenants may override, overrides of other keys are rejected
+	AllowedKeys []string
+}
+
+// ReadConfigData reads the tenant config overrides.
+func (k *TenantConfigMapReader) ReadConfigData() (*KonfluxRawConfig, error) {
+	values, err := k.ReadValues()
+	if err != nil {
+		return nil, err
+	}
+	return rawConfigFromValues(values), nil
+}
+
+// ReadValues returns the allowed overrides of the tenant ConfigMap data.
+func (k *TenantConfigMapReader) ReadValues() (map[string]string, error) {
+	values := map[string]string{}
+	configMap, err := k.Clientset.CoreV1().ConfigMaps(k.Namespace).Get(context.Background(), k.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			configLog.Debugf("No tenant config overrides, configmap %s/%s not found", k.Namespace, k.Name)
+			return values, nil
+		}
+		return nil, fmt.Errorf("failed to get configmap %s/%s: %w", k.Namespace, k.Name, err)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(configMap.Data)) {
+		if !slices.Contains(k.AllowedKeys, key) {
+			configLog.Warnf("Rejected tenant config override of %s from configmap %s/%s: key may not be overridden by tenants",
+				key, k.Namespace, k.Name)
+			continue
+		}
+		if err := checkTenantOverride(key, configMap.Data[key]); err != nil {
+			configLog.Warnf("Rejected tenant config override of %s from configmap %s/%s: %s",
+				key, k.Namespace, k.Name, err.Error())
+			continue
+		}
+		values[key] = configMap.Data[key]
+		configLog.Infof("Applied tenant config override of %s from configmap %s/%s", key, k.Namespace, k.Name)
+	}
+	return values, nil
 }
 
 // INIFileReader reads configuration from a local INI file.
@@ -89,25 +137,121 @@ type IniFileReader struct {
 
 // ReadConfigData reads platform config from the INI file
 func (y *IniFileReader) ReadConfigData() (*KonfluxRawConfig, error) {
+	values, err := y.ReadValues()
+	if err != nil {
+		return nil, err
+	}
+	return rawConfigFromValues(values), nil
+}
+
+// ReadValues returns the keys of all INI file sections.
+func (y *IniFileReader) ReadValues() (map[string]string, error) {
 	cfg, err := ini.Load(y.FilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load ini file: %v", err)
 	}
 
-	rawConfig := &KonfluxRawConfig{
-		AllowCacheProxy: cfg.Section("cache-proxy").Key("allow-cache-proxy").String(),
-		HttpProxy:       cfg.Section("cache-proxy").Key("http-proxy").String(),
-		NoProxy:         cfg.Section("cache-proxy").Key("no-proxy").String(),
-
-		HermetoBundlerProxy:                cfg.Section("artifact-registry").Key("package-registry-proxy-bundler-url").String(),
-		HermetoCargoProxy:                  cfg.Section("artifact-registry").Key("package-registry-proxy-cargo-url").String(),
-		HermetoGomodProxy:                  cfg.Section("artifact-registry").Key("package-registry-proxy-gomod-url").String(),
-		HermetoNpmProxy:                    cfg.Section("artifact-registry").Key("package-registry-proxy-npm-url").String(),
-		HermetoPipProxy:                    cfg.Section("artifact-registry").Key("package-registry-proxy-pip-url").String(),
-		HermetoPnpmProxy:                   cfg.Section("artifact-registry").Key("package-registry-proxy-pnpm-url").String(),
-		HermetoYarnProxy:                   cfg.Section("artifact-registry").Key("package-registry-proxy-yarn-url").String(),
-		HermetoPackageRegistryProxyAllowed: cfg.Section("artifact-registry").Key("allow-package-registry-proxy").String(),
+	sections := map[string]map[string]string{}
+	for _, section := range cfg.Sections() {
+		for _, key := range section.Keys() {
+			if sections[section.Name()] == nil {
+				sections[section.Name()] = map[string]string{}
+			}
+			sections[section.Name()][key.Name()] = key.String()
+		}
 	}
+	return flattenSections(sections), nil
+}
+
+// YamlFileReader reads configuration from a local YAML file.
+// The file has the same sections and keys as the INI file, e.g.:
+//
+//	cache-proxy:
+//	  allow-cache-proxy: true
+type YamlFileReader struct {
+	FilePath string
+}
 
-	return rawConfig, nil
+// ReadConfigData reads platform config from the YAML file
+func (y *YamlFileReader) ReadConfigData() (*KonfluxRawConfig, error) {
+	values, err := y.ReadValues()
+	if err != nil {
+		return nil, err
+	}
+	return rawConfigFromValues(values), nil
+}
+
+// ReadValues returns the keys of all YAML file sections.
+func (y *YamlFileReader) ReadValues() (map[string]string, error) {
+	data, err := os.ReadFile(y.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read yaml file: %w", err)
+	}
+	var content map[string]map[string]any
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml file %s: %w", y.FilePath, err)
+	}
+
+	sections := map[string]map[string]string{}
+	for sectionName, section := range content {
+		sections[sectionName] = map[string]string{}
+		for key, value := range section {
+			if value == nil {
+				sections[sectionName][key] = ""
+			} else {
+				sections[sectionName][key] = fmt.Sprint(value)
+			}
+		}
+	}
+	return flattenSections(sections), nil
+}
+
+// flattenSections maps keys of config file sections to config key names.
+// Keys that are not in their expected section are named <section>.<key>, so they are reported as unknown.
+func flattenSections(sections map[string]map[string]string) map[string]string {
+	values := map[string]string{}
+	for sectionName, section := range sections {
+		for name, value := range section {
+			if key, known := findConfigKey(name); known && key.Section == sectionName {
+				values[name] = value
+			} else {
+				values[sectionName+"."+name] = value
+			}
+		}
+	}
+	return values
+}
+
+// EnvReader reads configuration from environment variables.
+// Config key names are derived from the variable names, e.g. KBC_CONFIG_ALLOW_CACHE_PROXY sets allow-cache-proxy.
+type EnvReader struct {
+	Prefix string
+	// Restricts the variables to the tenant overrides if set, like in TenantConfigMapReader.
+	// In cluster the variables are set by the tenant pipeline and must not override the platform config.
+	AllowedKeys []string
+}
+
+// ReadValues returns the values of all environment variables with the prefix.
+func (e *EnvReader) ReadValues() (map[string]string, error) {
+	values := map[string]string{}
+	for _, envVar := range os.Environ() {
+		name, value, _ := strings.Cut(envVar, "=")
+		keyName, found := strings.CutPrefix(name, e.Prefix)
+		if !found || keyName == "" {
+			continue
+		}
+		key := strings.ToLower(strings.ReplaceAll(keyName, "_", "-"))
+		if e.AllowedKeys != nil {
+			if !slices.Contains(e.AllowedKeys, key) {
+				configLog.Warnf("Rejected config override of %s from %s: key may not be overridden by tenants", key, name)
+				continue
+			}
+			if err := checkTenantOverride(key, value); err != nil {
+				configLog.Warnf("Rejected config override of %s from %s: %s", key, name, err.Error())
+				continue
+			}
+		}
+		values[key] = value
+	}
+	return values, nil
 }
@@ -2,21 +2,26 @@ package config_test
 
 import (
 	"context"
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/config"
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	fakeclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestConfigReaderFactory(t *testing.T) {
 	g := NewWithT(t)
 
-	t.Run("should create ini file reader", func(t *testing.T) {
+	t.Run("should create layered reader with ini file layer", func(t *testing.T) {
 		os.Setenv("PLATFORM_CONFIG_FILE", "/path/to/file.ini")
 		defer os.Unsetenv("PLATFORM_CONFIG_FILE")
 
@@ -24,8 +29,27 @@ func TestConfigReaderFactory(t *testing.T) {
 
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(configReader).ToNot(BeNil())
-		_, isIniConfigReader := configReader.(*config.IniFileReader)
-		g.Expect(isIniConfigReader).To(BeTrue())
+		layeredConfigReader, isLayeredConfigReader := configReader.(*config.LayeredConfigReader)
+		g.Expect(isLayeredConfigReader).To(BeTrue())
+
+		// The cluster layer is present only if a cluster is reachable
+		layers := layeredConfigReader.Layers
+		g.Expect(len(layers)).To(BeNumerically(">=", 2))
+		fileLayer := layers[len(layers)-2]
+		g.Expect(fileLayer.Name).To(Equal("file /path/to/file.ini"))
+		g.Expect(fileLayer.Source).To(BeAssignableToTypeOf(&config.IniFileReader{}))
+		g.Expect(layers[len(layers)-1].Source).To(BeAssignableToTypeOf(&config.EnvReader{}))
+	})
+
+	t.Run("should create layered reader with yaml file layer", func(t *testing.T) {
+		os.Setenv("PLATFORM_CONFIG_FILE", "/path/to/file.yaml")
+		defer os.Unsetenv("PLATFORM_CONFIG_FILE")
+
+		configReader, err := config.ConfigReaderFactory()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		layers := configReader.(*config.LayeredConfigReader).Layers
+		g.Expect(layers[len(layers)-2].Source).To(BeAssignableToTypeOf(&config.YamlFileReader{}))
 	})
 }
 
@@ -63,6 +87,33 @@ func TestReadConfigData(t *testing.T) {
 		g.Expect(konfluxInfo.NoProxy).Should(Equal("test.io"))
 	})
 
+	t.Run("successfully retrieves package registry proxy config data from cluster", func(t *testing.T) {
+		fakeClient := fakeclient.NewClientset()
+		newK8sConfigMapReader := config.K8sConfigMapReader{Name: testName, Namespace: testNamespace, Clientset: fakeClient}
+
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+			Data: map[string]string{
+				"allow-package-registry-proxy":         "true",
+				"allow-package-registry-proxy-rpm":     "false",
+				"package-registry-proxy-rpm-url":       "https://rpm-proxy.io",
+				"package-registry-proxy-generic-url":   "https://generic-proxy.io",
+				"allow-package-registry-proxy-unknown": "true",
+			},
+		}
+		ctx := context.Background()
+		_, err := fakeClient.CoreV1().ConfigMaps(testNamespace).Create(ctx, configMap, metav1.CreateOptions{})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		konfluxInfo, err := newK8sConfigMapReader.ReadConfigData()
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(konfluxInfo.HermetoPackageRegistryProxyAllowed).Should(Equal("true"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxy["rpm"]).Should(Equal("https://rpm-proxy.io"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxy["generic"]).Should(Equal("https://generic-proxy.io"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxyAllowed).Should(Equal(map[string]string{"rpm": "false"}))
+	})
+
 	t.Run("should fail to retrieve config data from cluster if config map doesn't exist", func(t *testing.T) {
 		fakeClient := fakeclient.NewClientset()
 		newK8sConfigMapReader := config.K8sConfigMapReader{Name: testName, Namespace: testNamespace, Clientset: fakeClient}
@@ -94,6 +145,59 @@ func TestReadConfigData(t *testing.T) {
 		g.Expect(konfluxInfo.NoProxy).Should(Equal("test.io"))
 	})
 
+	t.Run("successfully retrieves package registry proxy config data from platform config ini file", func(t *testing.T) {
+		tempFile, err := os.CreateTemp("", "*-platform-config.ini")
+		if err != nil {
+			t.Fatalf("failed to create temporary file: %v", err)
+		}
+
+		defer os.Remove(tempFile.Name())
+		defer tempFile.Close()
+
+		_, err = tempFile.Write([]byte("[artifact-registry]\nallow-package-registry-proxy=true\n" +
+			"allow-package-registry-proxy-generic=false\npackage-registry-proxy-rpm-url=https://rpm-proxy.io\n" +
+			"package-registry-proxy-generic-url=https://generic-proxy.io"))
+		g.Expect(err).ShouldNot(HaveOccurred())
+
+		newIniFileReader := config.IniFileReader{FilePath: tempFile.Name()}
+		konfluxInfo, err := newIniFileReader.ReadConfigData()
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(konfluxInfo.HermetoPackageRegistryProxyAllowed).Should(Equal("true"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxy["rpm"]).Should(Equal("https://rpm-proxy.io"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxy["generic"]).Should(Equal("https://generic-proxy.io"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxyAllowed).Should(Equal(map[string]string{"generic": "false"}))
+	})
+
+	t.Run("successfully retrieves config data from platform config yaml file", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "platform-config.yaml")
+		content := "cache-proxy:\n  allow-cache-proxy: false\n  http-proxy: testproxy.local:3128\n" +
+			"artifact-registry:\n  allow-package-registry-proxy: true\n  allow-package-registry-proxy-rpm: false\n" +
+			"  package-registry-proxy-rpm-url: https://rpm-proxy.io\n"
+		g.Expect(os.WriteFile(filePath, []byte(content), 0644)).To(Succeed())
+
+		newYamlFileReader := config.YamlFileReader{FilePath: filePath}
+		konfluxInfo, err := newYamlFileReader.ReadConfigData()
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(konfluxInfo.AllowCacheProxy).Should(Equal("false"))
+		g.Expect(konfluxInfo.HttpProxy).Should(Equal("testproxy.local:3128"))
+		g.Expect(konfluxInfo.NoProxy).Should(BeEmpty())
+		g.Expect(konfluxInfo.HermetoPackageRegistryProxyAllowed).Should(Equal("true"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxy["rpm"]).Should(Equal("https://rpm-proxy.io"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxyAllowed).Should(Equal(map[string]string{"rpm": "false"}))
+	})
+
+	t.Run("should fail if platform config yaml file is malformed", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "platform-config.yaml")
+		g.Expect(os.WriteFile(filePath, []byte("cache-proxy: [true"), 0644)).To(Succeed())
+
+		newYamlFileReader := config.YamlFileReader{FilePath: filePath}
+		konfluxInfo, err := newYamlFileReader.ReadConfigData()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(konfluxInfo).To(BeNil())
+	})
+
 	t.Run("should fail if specified platform config ini file doesn't exist", func(t *testing.T) {
 		newIniFileReader := config.IniFileReader{FilePath: "/path/non-existent.ini"}
 		konfluxInfo, err := newIniFileReader.ReadConfigData()
@@ -101,3 +205,170 @@ func TestReadConfigData(t *testing.T) {
 		g.Expect(konfluxInfo).To(BeNil())
 	})
 }
+
+func TestReadConfigDataWithTenantOverrides(t *testing.T) {
+	g := NewWithT(t)
+
+	const tenantNamespace = "tenant-ns"
+	const tenantName = "build-pipeline-config"
+
+	clusterConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-config", Namespace: "konflux-info"},
+		Data: map[string]string{
+			"allow-cache-proxy":              "true",
+			"http-proxy":                     "cluster-proxy.io:3128",
+			"allow-package-registry-proxy":   "true",
+			"package-registry-proxy-npm-url": "https://cluster-npm-proxy.io",
+		},
+	}
+	tenantConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: tenantName, Namespace: tenantNamespace},
+		Data: map[string]string{
+			"allow-cache-proxy":                "false",
+			"http-proxy":                       "tenant-proxy.io:3128",
+			"allow-package-registry-proxy":     "false",
+			"package-registry-proxy-npm-url":   "https://tenant-npm-proxy.io",
+			"allow-package-registry-proxy-pip": "false",
+		},
+	}
+
+	const clusterLayerName = "configmap konflux-info/cluster-config"
+	const tenantLayerName = "configmap " + tenantNamespace + "/" + tenantName
+
+	newTenantReader := func(clientset *fakeclient.Clientset) *config.TenantConfigMapReader {
+		return &config.TenantConfigMapReader{
+			Name:        tenantName,
+			Namespace:   tenantNamespace,
+			Clientset:   clientset,
+			AllowedKeys: config.TenantOverridableKeys(),
+		}
+	}
+	newReader := func(clientset *fakeclient.Clientset, tenantReader *config.TenantConfigMapReader) *config.LayeredConfigReader {
+		return &config.LayeredConfigReader{Layers: []config.ConfigLayer{
+			{
+				Name:   clusterLayerName,
+				Source: &config.K8sConfigMapReader{Name: "cluster-config", Namespace: "konflux-info", Clientset: clientset},
+			},
+			{Name: tenantLayerName, Source: tenantReader, Optional: true},
+		}}
+	}
+
+	t.Run("should apply allowed tenant overrides and reject others", func(t *testing.T) {
+		clientset := fakeclient.NewClientset(clusterConfigMap, tenantConfigMap)
+		reader := newReader(clientset, newTenantReader(clientset))
+
+		konfluxInfo, err := reader.ReadConfigData()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(konfluxInfo.AllowCacheProxy).To(Equal("false"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxy["npm"]).To(Equal("https://tenant-npm-proxy.io"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxyAllowed).To(Equal(map[string]string{"pip": "false"}))
+		// Not allowed to be overridden by tenants
+		g.Expect(konfluxInfo.HttpProxy).To(Equal("cluster-proxy.io:3128"))
+		g.Expect(konfluxInfo.HermetoPackageRegistryProxyAllowed).To(Equal("true"))
+
+		// Tenant overrides are reported as coming from the tenant config map
+		g.Expect(konfluxInfo.Sources["allow-cache-proxy"]).To(Equal(tenantLayerName))
+		g.Expect(konfluxInfo.Sources["package-registry-proxy-npm-url"]).To(Equal(tenantLayerName))
+		g.Expect(konfluxInfo.Sources["http-proxy"]).To(Equal(clusterLayerName))
+		g.Expect(konfluxInfo.Sources["allow-package-registry-proxy"]).To(Equal(clusterLayerName))
+	})
+
+	t.Run("should apply only explicitly allowed keys", func(t *testing.T) {
+		clientset := fakeclient.NewClientset(clusterConfigMap, tenantConfigMap)
+		tenantReader := newTenantReader(clientset)
+		tenantReader.AllowedKeys = []string{"allow-cache-proxy"}
+
+		values, err := tenantReader.ReadValues()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(values).To(Equal(map[string]string{"allow-cache-proxy": "false"}))
+	})
+
+	t.Run("should use cluster config if tenant config map doesn't exist", func(t *testing.T) {
+		clientset := fakeclient.NewClientset(clusterConfigMap)
+
+		values, err := newTenantReader(clientset).ReadValues()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(values).To(BeEmpty())
+
+		konfluxInfo, err := newReader(clientset, newTenantReader(clientset)).ReadConfigData()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(konfluxInfo.AllowCacheProxy).To(Equal("true"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxy["npm"]).To(Equal("https://cluster-npm-proxy.io"))
+	})
+
+	t.Run("should use cluster config if tenant config map cannot be read", func(t *testing.T) {
+		clientset := fakeclient.NewClientset(clusterConfigMap, tenantConfigMap)
+		clientset.PrependReactor("get", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetNamespace() == tenantNamespace {
+				return true, nil, apierrors.NewForbidden(corev1.Resource("configmaps"), tenantName, errors.New("access denied"))
+			}
+			return false, nil, nil
+		})
+
+		_, err := newTenantReader(clientset).ReadValues()
+		g.Expect(err).To(HaveOccurred())
+
+		konfluxInfo, err := newReader(clientset, newTenantReader(clientset)).ReadConfigData()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(konfluxInfo.AllowCacheProxy).To(Equal("true"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxy["npm"]).To(Equal("https://cluster-npm-proxy.io"))
+	})
+
+	t.Run("should accept tenant switch overrides that disable the proxies", func(t *testing.T) {
+		clusterConfigMap := clusterConfigMap.DeepCopy()
+		clusterConfigMap.Data["allow-package-registry-proxy-npm"] = "true"
+		tenantConfigMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: tenantName, Namespace: tenantNamespace},
+			Data: map[string]string{
+				"allow-cache-proxy":                "false",
+				"allow-package-registry-proxy-npm": "False",
+			},
+		}
+		clientset := fakeclient.NewClientset(clusterConfigMap, tenantConfigMap)
+
+		konfluxInfo, err := newReader(clientset, newTenantReader(clientset)).ReadConfigData()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(konfluxInfo.AllowCacheProxy).To(Equal("false"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxyAllowed).To(Equal(map[string]string{"npm": "False"}))
+	})
+
+	t.Run("should reject tenant switch overrides that enable the proxies", func(t *testing.T) {
+		clusterConfigMap := clusterConfigMap.DeepCopy()
+		clusterConfigMap.Data["allow-cache-proxy"] = "false"
+		clusterConfigMap.Data["allow-package-registry-proxy-npm"] = "false"
+		tenantConfigMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: tenantName, Namespace: tenantNamespace},
+			Data: map[string]string{
+				"allow-cache-proxy":                "true",
+				"allow-package-registry-proxy-npm": "1",
+				"allow-package-registry-proxy-pip": "yes",
+			},
+		}
+		clientset := fakeclient.NewClientset(clusterConfigMap, tenantConfigMap)
+
+		konfluxInfo, err := newReader(clientset, newTenantReader(clientset)).ReadConfigData()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(konfluxInfo.AllowCacheProxy).To(Equal("false"))
+		g.Expect(konfluxInfo.HermetoPackageManagerProxyAllowed).To(Equal(map[string]string{"npm": "false"}))
+		g.Expect(konfluxInfo.Sources["allow-cache-proxy"]).To(Equal(clusterLayerName))
+	})
+
+	t.Run("should fail if cluster config map doesn't exist even if tenant one does", func(t *testing.T) {
+		clientset := fakeclient.NewClientset(tenantConfigMap)
+
+		konfluxInfo, err := newReader(clientset, newTenantReader(clientset)).ReadConfigData()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(konfluxInfo).To(BeNil())
+	})
+
+	t.Run("should allow tenants to override only cache proxy and package manager proxy keys", func(t *testing.T) {
+		keys := config.TenantOverridableKeys()
+		g.Expect(keys).To(ContainElements("allow-cache-proxy", "package-registry-proxy-rpm-url", "allow-package-registry-proxy-rpm"))
+		g.Expect(keys).ToNot(ContainElements("http-proxy", "no-proxy", "allow-package-registry-proxy"))
+	})
+}
@@ -1,54 +1,99 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"net/url"
 	"strconv"
 )
 
+// HermetoPackageManagers are the package managers Hermeto can fetch through a package registry proxy.
+// Each one is configured by the package-registry-proxy-<name>-url and allow-package-registry-proxy-<name> keys.
+var HermetoPackageManagers = []string{"bundler", "cargo", "generic", "gomod", "npm", "pip", "pnpm", "rpm", "yarn"}
+
 type HermetoProxyConfig struct {
 	PackageRegistryProxyAllowed bool
-	BundlerProxy                string
-	CargoProxy                  string
-	GomodProxy                  string
-	NpmProxy                    string
-	PipProxy                    string
-	PnpmProxy                   string
-	YarnProxy                   string
+	// Proxy URL per package manager, package manager name => URL.
+	// Package managers without an entry have no proxy configured.
+	PackageManagerProxy map[string]string
+	// Per package manager switch, package manager name => allowed.
+	// Package managers without an entry are allowed if PackageRegistryProxyAllowed is true.
+	PackageManagerProxyAllowed map[string]bool
 }
 
 var _ KonfluxConfigPart[*HermetoProxyConfig] = (*HermetoProxyConfig)(nil)
 
 func NewHermetoProxyConfig(rawConfig KonfluxRawConfig) (*HermetoProxyConfig, error) {
 	hermetoProxyConfig := &HermetoProxyConfig{
-		BundlerProxy: rawConfig.HermetoBundlerProxy,
-		CargoProxy:   rawConfig.HermetoCargoProxy,
-		GomodProxy:   rawConfig.HermetoGomodProxy,
-		NpmProxy:     rawConfig.HermetoNpmProxy,
-		PipProxy:     rawConfig.HermetoPipProxy,
-		PnpmProxy:    rawConfig.HermetoPnpmProxy,
-		YarnProxy:    rawConfig.HermetoYarnProxy,
+		PackageManagerProxy: map[string]string{},
+	}
+	for _, packageManager := range HermetoPackageManagers {
+		if proxyURL := rawConfig.HermetoPackageManagerProxy[packageManager]; proxyURL != "" {
+			hermetoProxyConfig.PackageManagerProxy[packageManager] = proxyURL
+		}
+	}
+
+	var errs []error
+	for _, packageManager := range HermetoPackageManagers {
+		value := rawConfig.HermetoPackageManagerProxyAllowed[packageManager]
+		if value == "" {
+			continue
+		}
+		if hermetoProxyConfig.PackageManagerProxyAllowed == nil {
+			hermetoProxyConfig.PackageManagerProxyAllowed = map[string]bool{}
+		}
+		// An invalid value disables the proxy of the package manager
+		allowed, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("allow-package-registry-proxy-%s: %w", packageManager, err))
+		}
+		hermetoProxyConfig.PackageManagerProxyAllowed[packageManager] = allowed
 	}
 
 	if rawConfig.HermetoPackageRegistryProxyAllowed == "" {
 		hermetoProxyConfig.PackageRegistryProxyAllowed = false
-		return hermetoProxyConfig, nil
+		return hermetoProxyConfig, errors.Join(errs...)
 	}
 
 	isPackageRegistryProxyAllowed, err := strconv.ParseBool(rawConfig.HermetoPackageRegistryProxyAllowed)
 	hermetoProxyConfig.PackageRegistryProxyAllowed = isPackageRegistryProxyAllowed
-	return hermetoProxyConfig, err
+	if err != nil {
+		errs = append([]error{err}, errs...)
+	}
+	return hermetoProxyConfig, errors.Join(errs...)
+}
+
+// IsProxyAllowed returns true if the package registry proxy is allowed on the cluster level
+// and not disallowed for the package manager.
+func (c *HermetoProxyConfig) IsProxyAllowed(packageManager string) bool {
+	if !c.PackageRegistryProxyAllowed {
+		return false
+	}
+	allowed, found := c.PackageManagerProxyAllowed[packageManager]
+	return !found || allowed
+}
+
+// ValidateProxyURL checks that the proxy URL is an absolute http or https URL.
+func ValidateProxyURL(proxyURL string) error {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL '%s': %w", proxyURL, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("invalid proxy URL '%s': scheme must be http or https", proxyURL)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("invalid proxy URL '%s': host is missing", proxyURL)
+	}
+	return nil
 }
 
 func (c *HermetoProxyConfig) DeepCopy() *HermetoProxyConfig {
 	return &HermetoProxyConfig{
 		PackageRegistryProxyAllowed: c.PackageRegistryProxyAllowed,
-		BundlerProxy:                c.BundlerProxy,
-		CargoProxy:                  c.CargoProxy,
-		GomodProxy:                  c.GomodProxy,
-		NpmProxy:                    c.NpmProxy,
-		PipProxy:                    c.PipProxy,
-		PnpmProxy:                   c.PnpmProxy,
-		YarnProxy:                   c.YarnProxy,
+		PackageManagerProxy:         maps.Clone(c.PackageManagerProxy),
+		PackageManagerProxyAllowed:  maps.Clone(c.PackageManagerProxyAllowed),
 	}
 }
 
@@ -8,15 +8,14 @@ import (
 	. "github.com/onsi/gomega"
 )
 
-const (
-	testBundlerProxy = "test-bundler-proxy.io"
-	testCargoProxy   = "test-cargo-proxy.io"
-	testGomodProxy   = "test-gomod-proxy.io"
-	testNpmProxy     = "test-npm-proxy.io"
-	testPipProxy     = "test-pip-proxy.io"
-	testPnpmProxy    = "test-pnpm-proxy.io"
-	testYarnProxy    = "test-yarn-proxy.io"
-)
+// testProxies returns a proxy URL for each package manager Hermeto supports.
+func testProxies() map[string]string {
+	proxies := map[string]string{}
+	for _, packageManager := range config.HermetoPackageManagers {
+		proxies[packageManager] = "test-" + packageManager + "-proxy.io"
+	}
+	return proxies
+}
 
 func Test_NewHermetoProxyConfig(t *testing.T) {
 	g := NewWithT(t)
@@ -24,49 +23,76 @@ func Test_NewHermetoProxyConfig(t *testing.T) {
 	t.Run("should create hermeto proxy config", func(t *testing.T) {
 		rawConfig := config.KonfluxRawConfig{
 			HermetoPackageRegistryProxyAllowed: "true",
-			HermetoBundlerProxy:                testBundlerProxy,
-			HermetoCargoProxy:                  testCargoProxy,
-			HermetoGomodProxy:                  testGomodProxy,
-			HermetoNpmProxy:                    testNpmProxy,
-			HermetoPipProxy:                    testPipProxy,
-			HermetoPnpmProxy:                   testPnpmProxy,
-			HermetoYarnProxy:                   testYarnProxy,
+			HermetoPackageManagerProxy:         testProxies(),
 		}
 
 		hermetoProxyConfig, err := config.NewHermetoProxyConfig(rawConfig)
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(hermetoProxyConfig.PackageRegistryProxyAllowed).To(BeTrue())
-		g.Expect(hermetoProxyConfig.BundlerProxy).To(Equal(testBundlerProxy))
-		g.Expect(hermetoProxyConfig.CargoProxy).To(Equal(testCargoProxy))
-		g.Expect(hermetoProxyConfig.GomodProxy).To(Equal(testGomodProxy))
-		g.Expect(hermetoProxyConfig.NpmProxy).To(Equal(testNpmProxy))
-		g.Expect(hermetoProxyConfig.PipProxy).To(Equal(testPipProxy))
-		g.Expect(hermetoProxyConfig.PnpmProxy).To(Equal(testPnpmProxy))
-		g.Expect(hermetoProxyConfig.YarnProxy).To(Equal(testYarnProxy))
+		g.Expect(hermetoProxyConfig.PackageManagerProxy).To(Equal(testProxies()))
 	})
 
 	t.Run("should create hermeto proxy config if parse error happens", func(t *testing.T) {
 		rawConfig := config.KonfluxRawConfig{
 			HermetoPackageRegistryProxyAllowed: "abcd",
-			HermetoBundlerProxy:                testBundlerProxy,
-			HermetoCargoProxy:                  testCargoProxy,
-			HermetoGomodProxy:                  testGomodProxy,
-			HermetoNpmProxy:                    testNpmProxy,
-			HermetoPipProxy:                    testPipProxy,
-			HermetoPnpmProxy:                   testPnpmProxy,
-			HermetoYarnProxy:                   testYarnProxy,
+			HermetoPackageManagerProxy:         testProxies(),
 		}
 
 		hermetoProxyConfig, err := config.NewHermetoProxyConfig(rawConfig)
 		g.Expect(err).To(HaveOccurred())
 		g.Expect(hermetoProxyConfig.PackageRegistryProxyAllowed).To(BeFalse())
-		g.Expect(hermetoProxyConfig.BundlerProxy).To(Equal(testBundlerProxy))
-		g.Expect(hermetoProxyConfig.CargoProxy).To(Equal(testCargoProxy))
-		g.Expect(hermetoProxyConfig.GomodProxy).To(Equal(testGomodProxy))
-		g.Expect(hermetoProxyConfig.NpmProxy).To(Equal(testNpmProxy))
-		g.Expect(hermetoProxyConfig.PipProxy).To(Equal(testPipProxy))
-		g.Expect(hermetoProxyConfig.PnpmProxy).To(Equal(testPnpmProxy))
-		g.Expect(hermetoProxyConfig.YarnProxy).To(Equal(testYarnProxy))
+		g.Expect(hermetoProxyConfig.PackageManagerProxy).To(Equal(testProxies()))
+	})
+
+	t.Run("should create hermeto proxy config with rpm, generic and per package manager settings", func(t *testing.T) {
+		rawConfig := config.KonfluxRawConfig{
+			HermetoPackageRegistryProxyAllowed: "true",
+			HermetoPackageManagerProxy: map[string]string{
+				"rpm":     "test-rpm-proxy.io",
+				"generic": "test-generic-proxy.io",
+				// Not a package manager supported by Hermeto
+				"maven": "test-maven-proxy.io",
+			},
+			HermetoPackageManagerProxyAllowed: map[string]string{
+				"rpm":     "false",
+				"generic": "true",
+			},
+		}
+
+		hermetoProxyConfig, err := config.NewHermetoProxyConfig(rawConfig)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(hermetoProxyConfig.PackageManagerProxy).To(Equal(map[string]string{
+			"rpm":     "test-rpm-proxy.io",
+			"generic": "test-generic-proxy.io",
+		}))
+		g.Expect(hermetoProxyConfig.IsProxyAllowed("generic")).To(BeTrue())
+		g.Expect(hermetoProxyConfig.IsProxyAllowed("rpm")).To(BeFalse())
+		g.Expect(hermetoProxyConfig.IsProxyAllowed("npm")).To(BeTrue())
+	})
+
+	t.Run("should disallow package manager proxy if its setting is invalid", func(t *testing.T) {
+		rawConfig := config.KonfluxRawConfig{
+			HermetoPackageRegistryProxyAllowed: "true",
+			HermetoPackageManagerProxyAllowed:  map[string]string{"npm": "abcd"},
+		}
+
+		hermetoProxyConfig, err := config.NewHermetoProxyConfig(rawConfig)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("allow-package-registry-proxy-npm"))
+		g.Expect(hermetoProxyConfig.PackageRegistryProxyAllowed).To(BeTrue())
+		g.Expect(hermetoProxyConfig.IsProxyAllowed("npm")).To(BeFalse())
+		g.Expect(hermetoProxyConfig.IsProxyAllowed("pip")).To(BeTrue())
+	})
+
+	t.Run("should not allow package manager proxy if disallowed on the cluster level", func(t *testing.T) {
+		rawConfig := config.KonfluxRawConfig{
+			HermetoPackageRegistryProxyAllowed: "false",
+			HermetoPackageManagerProxyAllowed:  map[string]string{"npm": "true"},
+		}
+
+		hermetoProxyConfig, err := config.NewHermetoProxyConfig(rawConfig)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(hermetoProxyConfig.IsProxyAllowed("npm")).To(BeFalse())
 	})
 
 	t.Run("should create hermeto proxy config from empty values", func(t *testing.T) {
@@ -75,13 +101,8 @@ func Test_NewHermetoProxyConfig(t *testing.T) {
 		hermetoProxyConfig, err := config.NewHermetoProxyConfig(rawConfig)
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(hermetoProxyConfig.PackageRegistryProxyAllowed).To(BeFalse())
-		g.Expect(hermetoProxyConfig.BundlerProxy).To(BeEmpty())
-		g.Expect(hermetoProxyConfig.CargoProxy).To(BeEmpty())
-		g.Expect(hermetoProxyConfig.GomodProxy).To(BeEmpty())
-		g.Expect(hermetoProxyConfig.NpmProxy).To(BeEmpty())
-		g.Expect(hermetoProxyConfig.PipProxy).To(BeEmpty())
-		g.Expect(hermetoProxyConfig.PnpmProxy).To(BeEmpty())
-		g.Expect(hermetoProxyConfig.YarnProxy).To(BeEmpty())
+		g.Expect(hermetoProxyConfig.PackageManagerProxy).To(BeEmpty())
+		g.Expect(hermetoProxyConfig.PackageManagerProxyAllowed).To(BeEmpty())
 	})
 }
 
@@ -91,49 +112,39 @@ func Test_HermetoProxyConfig_DeepCopy(t *testing.T) {
 	t.Run("should deep copy hermeto proxy config", func(t *testing.T) {
 		hermetoProxyConfig := &config.HermetoProxyConfig{
 			PackageRegistryProxyAllowed: true,
-			BundlerProxy:                testBundlerProxy,
-			CargoProxy:                  testCargoProxy,
-			GomodProxy:                  testGomodProxy,
-			NpmProxy:                    testNpmProxy,
-			PipProxy:                    testPipProxy,
-			PnpmProxy:                   testPnpmProxy,
-			YarnProxy:                   testYarnProxy,
+			PackageManagerProxy:         testProxies(),
+			PackageManagerProxyAllowed:  map[string]bool{"rpm": true},
 		}
 
 		HermetoProxyConfigCopy := hermetoProxyConfig.DeepCopy()
 
 		hermetoProxyConfig.PackageRegistryProxyAllowed = false
-		hermetoProxyConfig.BundlerProxy = "bundler-proxy"
-		hermetoProxyConfig.CargoProxy = "cargo-proxy"
-		hermetoProxyConfig.GomodProxy = "gomod-proxy"
-		hermetoProxyConfig.NpmProxy = "npm-proxy"
-		hermetoProxyConfig.PipProxy = "pip-proxy"
-		hermetoProxyConfig.PnpmProxy = "pnpm-proxy"
-		hermetoProxyConfig.YarnProxy = "yarn-proxy"
+		hermetoProxyConfig.PackageManagerProxy["npm"] = "npm-proxy"
+		hermetoProxyConfig.PackageManagerProxyAllowed["rpm"] = false
 
 		g.Expect(HermetoProxyConfigCopy.PackageRegistryProxyAllowed).To(BeTrue())
-		g.Expect(HermetoProxyConfigCopy.BundlerProxy).To(Equal(testBundlerProxy))
-		g.Expect(HermetoProxyConfigCopy.CargoProxy).To(Equal(testCargoProxy))
-		g.Expect(HermetoProxyConfigCopy.GomodProxy).To(Equal(testGomodProxy))
-		g.Expect(HermetoProxyConfigCopy.NpmProxy).To(Equal(testNpmProxy))
-		g.Expect(HermetoProxyConfigCopy.PipProxy).To(Equal(testPipProxy))
-		g.Expect(HermetoProxyConfigCopy.PnpmProxy).To(Equal(testPnpmProxy))
-		g.Expect(HermetoProxyConfigCopy.YarnProxy).To(Equal(testYarnProxy))
+		g.Expect(HermetoProxyConfigCopy.PackageManagerProxy).To(Equal(testProxies()))
+		g.Expect(HermetoProxyConfigCopy.PackageManagerProxyAllowed).To(Equal(map[string]bool{"rpm": true}))
 	})
 }
 
+func Test_ValidateProxyURL(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(config.ValidateProxyURL("https://proxy.example.com/npm")).To(Succeed())
+	g.Expect(config.ValidateProxyURL("http://proxy.example.com:8080")).To(Succeed())
+	g.Expect(config.ValidateProxyURL("proxy.example.com")).ToNot(Succeed())
+	g.Expect(config.ValidateProxyURL("ftp://proxy.example.com")).ToNot(Succeed())
+	g.Expect(config.ValidateProxyURL("https://")).ToNot(Succeed())
+	g.Expect(config.ValidateProxyURL("https://proxy example.com")).ToNot(Succeed())
+}
+
 func Test_HermetoProxyConfig_ToString(t *testing.T) {
 	g := NewWithT(t)
 
 	hermetoProxyConfig := &config.HermetoProxyConfig{
 		PackageRegistryProxyAllowed: true,
-		BundlerProxy:                "test-bundler-proxy.io",
-		CargoProxy:                  "test-cargo-proxy.io",
-		GomodProxy:                  "test-gomod-proxy.io",
-		NpmProxy:                    "test-npm-proxy.io",
-		PipProxy:                    "test-pip-proxy.io",
-		PnpmProxy:                   "test-pnpm-proxy.io",
-		YarnProxy:                   "test-yarn-proxy.io",
+		PackageManagerProxy:         testProxies(),
 	}
 
 	str := hermetoProxyConfig.ToString()
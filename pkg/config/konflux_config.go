@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"maps"
 
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 	"github.com/sirupsen/logrus"
@@ -73,6 +74,9 @@ func newKonfluxConfig(rawConfig *KonfluxRawConfig) *KonfluxConfig {
 
 func (c *KonfluxConfig) DeepCopy() *KonfluxConfig {
 	copy := &KonfluxConfig{RawConfig: c.RawConfig}
+	copy.RawConfig.HermetoPackageManagerProxy = maps.Clone(c.RawConfig.HermetoPackageManagerProxy)
+	copy.RawConfig.HermetoPackageManagerProxyAllowed = maps.Clone(c.RawConfig.HermetoPackageManagerProxyAllowed)
+	copy.RawConfig.Sources = maps.Clone(c.RawConfig.Sources)
 
 	if c.CacheProxy != nil {
 		copy.CacheProxy = c.CacheProxy.DeepCopy()
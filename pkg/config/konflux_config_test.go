@@ -66,8 +66,7 @@ func TestKonfluxConfig(t *testing.T) {
 				NoProxy:         testNoProxy,
 
 				HermetoPackageRegistryProxyAllowed: "true",
-				HermetoNpmProxy:                    testNpmProxy,
-				HermetoYarnProxy:                   testYarnProxy,
+				HermetoPackageManagerProxy:         map[string]string{"npm": testNpmProxy, "yarn": testYarnProxy},
 			}, nil
 		}
 		config.NewConfigReader = NewMockConfigReader
@@ -83,8 +82,7 @@ func TestKonfluxConfig(t *testing.T) {
 
 		g.Expect(kofluxConfig.HermetoProxy).ToNot(BeNil())
 		g.Expect(kofluxConfig.HermetoProxy.PackageRegistryProxyAllowed).To(BeTrue())
-		g.Expect(kofluxConfig.HermetoProxy.NpmProxy).To(Equal(testNpmProxy))
-		g.Expect(kofluxConfig.HermetoProxy.YarnProxy).To(Equal(testYarnProxy))
+		g.Expect(kofluxConfig.HermetoProxy.PackageManagerProxy).To(Equal(map[string]string{"npm": testNpmProxy, "yarn": testYarnProxy}))
 
 		// Test that it can log config without errors
 		kofluxConfig.LogConfig(logrus.InfoLevel)
@@ -109,8 +107,7 @@ func TestKonfluxConfig(t *testing.T) {
 				NoProxy:         testNoProxy,
 
 				HermetoPackageRegistryProxyAllowed: "invalid",
-				HermetoNpmProxy:                    testNpmProxy,
-				HermetoYarnProxy:                   testYarnProxy,
+				HermetoPackageManagerProxy:         map[string]string{"npm": testNpmProxy, "yarn": testYarnProxy},
 			}, nil
 		}
 		config.NewConfigReader = NewMockConfigReader
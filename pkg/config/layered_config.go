@@ -0,0 +1,227 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
+)
+
+const (
+	// DefaultConfigSource is the provenance of built-in default values.
+	DefaultConfigSource = "default"
+	// ConfigEnvVarPrefix is the prefix of environment variables overriding config keys.
+	ConfigEnvVarPrefix = "KBC_CONFIG_"
+
+	clusterConfigName      = "cluster-config"
+	clusterConfigNamespace = "konflux-info"
+	// Name of the optional ConfigMap with config overrides in the pipeline namespace
+	tenantConfigName = "build-pipeline-config"
+)
+
+// ConfigLayer is a single config source in the layered config resolution.
+type ConfigLayer struct {
+	// Name identifies the layer in the provenance of config values.
+	Name   string
+	Source ConfigSource
+	// Optional layers are skipped with a warning if they cannot be read.
+	Optional bool
+}
+
+// ConfigValue is the effective value of a config key and the layer it comes from.
+type ConfigValue struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// ConfigIssue describes an unknown or invalid key found in a config layer.
+type ConfigIssue struct {
+	Key     string `json:"key"`
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+// ResolvedConfig is the result of the layered config resolution.
+type ResolvedConfig struct {
+	// Effective values of all known keys.
+	Values      []ConfigValue `json:"values"`
+	UnknownKeys []ConfigIssue `json:"unknown_keys"`
+	InvalidKeys []ConfigIssue `json:"invalid_keys"`
+}
+
+// LayeredConfigReader resolves config from built-in defaults overridden by the layers in the given order.
+type LayeredConfigReader struct {
+	Layers []ConfigLayer
+}
+
+var _ ConfigReader = (*LayeredConfigReader)(nil)
+
+// NewLayeredConfigReader returns config reader with the layers in the order:
+// cluster ConfigMap, optional tenant ConfigMap in the pipeline namespace,
+// PLATFORM_CONFIG_FILE INI or YAML file, KBC_CONFIG_* environment variables.
+// The cluster ConfigMap is optional if the config file is set.
+// In the pipeline namespace, the environment variables are restricted to the tenant overrides.
+func NewLayeredConfigReader() (*LayeredConfigReader, error) {
+	platformConfigFile := os.Getenv("PLATFORM_CONFIG_FILE")
+	reader := &LayeredConfigReader{}
+	envReader := &EnvReader{Prefix: ConfigEnvVarPrefix}
+
+	clientset, err := clients.NewKubeClientSet()
+	if err != nil {
+		if platformConfigFile == "" {
+			return nil, err
+		}
+		configLog.Debugf("Not reading cluster config: %s", err.Error())
+	} else {
+		reader.Layers = append(reader.Layers, ConfigLayer{
+			Name: fmt.Sprintf("configmap %s/%s", clusterConfigNamespace, clusterConfigName),
+			Source: &K8sConfigMapReader{
+				Name:      clusterConfigName,
+				Namespace: clusterConfigNamespace,
+				Clientset: clientset,
+			},
+			Optional: platformConfigFile != "",
+		})
+
+		tenantNamespace := clients.CurrentNamespace()
+		if tenantNamespace != "" && tenantNamespace != clusterConfigNamespace {
+			reader.Layers = append(reader.Layers, ConfigLayer{
+				Name: fmt.Sprintf("configmap %s/%s", tenantNamespace, tenantConfigName),
+				Source: &TenantConfigMapReader{
+					Name:        tenantConfigName,
+					Namespace:   tenantNamespace,
+					Clientset:   clientset,
+					AllowedKeys: TenantOverridableKeys(),
+				},
+				// The tenant overrides must not fail the build
+				Optional: true,
+			})
+			// The environment of the pipeline is under tenant control too
+			envReader.AllowedKeys = TenantOverridableKeys()
+		}
+	}
+
+	if platformConfigFile != "" {
+		reader.Layers = append(reader.Layers, ConfigLayer{
+			Name:   "file " + platformConfigFile,
+			Source: newFileConfigSource(platformConfigFile),
+		})
+	}
+
+	reader.Layers = append(reader.Layers, ConfigLayer{
+		Name:   "env " + ConfigEnvVarPrefix + "*",
+		Source: envReader,
+	})
+
+	return reader, nil
+}
+
+// newFileConfigSource returns YAML reader for .yaml and .yml files and INI reader otherwise.
+func newFileConfigSource(filePath string) ConfigSource {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		return &YamlFileReader{FilePath: filePath}
+	default:
+		return &IniFileReader{FilePath: filePath}
+	}
+}
+
+// Resolve reads all layers and merges them on top of the built-in defaults.
+// Keys set by a layer override the previous value, even if the new value is empty.
+func (r *LayeredConfigReader) Resolve() (*ResolvedConfig, error) {
+	resolved := &ResolvedConfig{
+		UnknownKeys: []ConfigIssue{},
+		InvalidKeys: []ConfigIssue{},
+	}
+
+	values := map[string]ConfigValue{}
+	for _, key := range configKeys {
+		values[key.Name] = ConfigValue{Key: key.Name, Value: key.Default, Source: DefaultConfigSource}
+	}
+
+	for _, layer := range r.Layers {
+		layerValues, err := layer.Source.ReadValues()
+		if err != nil {
+			if layer.Optional {
+				configLog.Warnf("Skipping %s config: %s", layer.Name, err.Error())
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s config: %w", layer.Name, err)
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(layerValues)) {
+			if _, known := findConfigKey(name); !known {
+				resolved.UnknownKeys = append(resolved.UnknownKeys, ConfigIssue{
+					Key:     name,
+					Source:  layer.Name,
+					Message: "unknown config key",
+				})
+				continue
+			}
+			values[name] = ConfigValue{Key: name, Value: layerValues[name], Source: layer.Name}
+		}
+	}
+
+	for _, key := range configKeys {
+		value := values[key.Name]
+		resolved.Values = append(resolved.Values, value)
+		if value.Value == "" || key.validate == nil {
+			continue
+		}
+		if err := key.validate(value.Value); err != nil {
+			resolved.InvalidKeys = append(resolved.InvalidKeys, ConfigIssue{
+				Key:     key.Name,
+				Source:  value.Source,
+				Message: err.Error(),
+			})
+		}
+	}
+
+	return resolved, nil
+}
+
+// ReadConfigData resolves the layered config.
+// Unknown keys are only logged, as the cluster ConfigMap is shared with other components.
+func (r *LayeredConfigReader) ReadConfigData() (*KonfluxRawConfig, error) {
+	resolved, err := r.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range resolved.UnknownKeys {
+		configLog.Debugf("Ignoring unknown config key %s from %s", issue.Key, issue.Source)
+	}
+	for _, issue := range resolved.InvalidKeys {
+		configLog.Warnf("Invalid config key %s from %s: %s", issue.Key, issue.Source, issue.Message)
+	}
+	return resolved.RawConfig(), nil
+}
+
+// RawConfig returns the effective values with their provenance.
+func (c *ResolvedConfig) RawConfig() *KonfluxRawConfig {
+	rawConfig := &KonfluxRawConfig{
+		HermetoPackageManagerProxy:        map[string]string{},
+		HermetoPackageManagerProxyAllowed: map[string]string{},
+		Sources:                           map[string]string{},
+	}
+	for _, value := range c.Values {
+		key, known := findConfigKey(value.Key)
+		if !known {
+			continue
+		}
+		key.set(rawConfig, value.Value)
+		rawConfig.Sources[value.Key] = value.Source
+	}
+	return rawConfig
+}
+
+// ConfigResolver defines the interface for resolving config with provenance.
+type ConfigResolver interface {
+	Resolve() (*ResolvedConfig, error)
+}
+
+var _ ConfigResolver = (*LayeredConfigReader)(nil)
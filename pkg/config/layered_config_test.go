@@ -0,0 +1,185 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/config"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+type failingConfigSource struct{}
+
+func (f *failingConfigSource) ReadValues() (map[string]string, error) {
+	return nil, errors.New("source is not available")
+}
+
+func findConfigValue(resolved *config.ResolvedConfig, key string) config.ConfigValue {
+	for _, value := range resolved.Values {
+		if value.Key == key {
+			return value
+		}
+	}
+	return config.ConfigValue{}
+}
+
+func TestLayeredConfigReader(t *testing.T) {
+	g := NewWithT(t)
+
+	fakeClient := fakeclient.NewClientset()
+	_, err := fakeClient.CoreV1().ConfigMaps("konflux-info").Create(context.Background(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-config", Namespace: "konflux-info"},
+		Data: map[string]string{
+			"http-proxy":                     "cluster-proxy.io:3128",
+			"no-proxy":                       "cluster.io",
+			"allow-package-registry-proxy":   "true",
+			"package-registry-proxy-npm-url": "https://npm-proxy.io",
+			"some-other-component-key":       "value",
+		},
+	}, metav1.CreateOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+	clusterLayer := config.ConfigLayer{
+		Name:   "configmap konflux-info/cluster-config",
+		Source: &config.K8sConfigMapReader{Name: "cluster-config", Namespace: "konflux-info", Clientset: fakeClient},
+	}
+
+	iniFilePath := filepath.Join(t.TempDir(), "platform-config.ini")
+	iniContent := "[cache-proxy]\nhttp-proxy=file-proxy.io:3128\nallow-package-registry-proxy=false\n" +
+		"[artifact-registry]\npackage-registry-proxy-rpm-url=rpm-proxy.io\n"
+	g.Expect(os.WriteFile(iniFilePath, []byte(iniContent), 0644)).To(Succeed())
+	fileLayer := config.ConfigLayer{Name: "file " + iniFilePath, Source: &config.IniFileReader{FilePath: iniFilePath}}
+
+	envLayer := config.ConfigLayer{Name: "env", Source: &config.EnvReader{Prefix: config.ConfigEnvVarPrefix}}
+
+	t.Run("should resolve values from defaults and layers in order", func(t *testing.T) {
+		t.Setenv("KBC_CONFIG_NO_PROXY", "env.io")
+		t.Setenv("KBC_CONFIG_ALLOW_PACKAGE_REGISTRY_PROXY_NPM", "maybe")
+		t.Setenv("KBC_CONFIG_UNKNOWN_KEY", "value")
+
+		reader := &config.LayeredConfigReader{Layers: []config.ConfigLayer{clusterLayer, fileLayer, envLayer}}
+		resolved, err := reader.Resolve()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(findConfigValue(resolved, "allow-cache-proxy")).To(Equal(config.ConfigValue{
+			Key: "allow-cache-proxy", Value: "true", Source: config.DefaultConfigSource,
+		}))
+		g.Expect(findConfigValue(resolved, "allow-package-registry-proxy").Source).To(Equal(clusterLayer.Name))
+		g.Expect(findConfigValue(resolved, "package-registry-proxy-npm-url").Source).To(Equal(clusterLayer.Name))
+		g.Expect(findConfigValue(resolved, "http-proxy")).To(Equal(config.ConfigValue{
+			Key: "http-proxy", Value: "file-proxy.io:3128", Source: fileLayer.Name,
+		}))
+		g.Expect(findConfigValue(resolved, "no-proxy")).To(Equal(config.ConfigValue{
+			Key: "no-proxy", Value: "env.io", Source: "env",
+		}))
+		g.Expect(findConfigValue(resolved, "package-registry-proxy-pip-url")).To(Equal(config.ConfigValue{
+			Key: "package-registry-proxy-pip-url", Value: "", Source: config.DefaultConfigSource,
+		}))
+
+		g.Expect(resolved.UnknownKeys).To(ConsistOf(
+			config.ConfigIssue{Key: "some-other-component-key", Source: clusterLayer.Name, Message: "unknown config key"},
+			config.ConfigIssue{Key: "cache-proxy.allow-package-registry-proxy", Source: fileLayer.Name, Message: "unknown config key"},
+			config.ConfigIssue{Key: "unknown-key", Source: "env", Message: "unknown config key"},
+		))
+		g.Expect(resolved.InvalidKeys).To(HaveLen(2))
+		g.Expect(resolved.InvalidKeys[0].Key).To(Equal("allow-package-registry-proxy-npm"))
+		g.Expect(resolved.InvalidKeys[0].Source).To(Equal("env"))
+		g.Expect(resolved.InvalidKeys[1].Key).To(Equal("package-registry-proxy-rpm-url"))
+		g.Expect(resolved.InvalidKeys[1].Source).To(Equal(fileLayer.Name))
+	})
+
+	t.Run("should read raw config with provenance", func(t *testing.T) {
+		t.Setenv("KBC_CONFIG_ALLOW_PACKAGE_REGISTRY_PROXY_RPM", "false")
+
+		reader := &config.LayeredConfigReader{Layers: []config.ConfigLayer{clusterLayer, fileLayer, envLayer}}
+		rawConfig, err := reader.ReadConfigData()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(rawConfig.AllowCacheProxy).To(Equal("true"))
+		g.Expect(rawConfig.HttpProxy).To(Equal("file-proxy.io:3128"))
+		g.Expect(rawConfig.NoProxy).To(Equal("cluster.io"))
+		g.Expect(rawConfig.HermetoPackageManagerProxy["npm"]).To(Equal("https://npm-proxy.io"))
+		g.Expect(rawConfig.HermetoPackageManagerProxyAllowed).To(Equal(map[string]string{"rpm": "false"}))
+		g.Expect(rawConfig.Sources).To(HaveKeyWithValue("allow-cache-proxy", config.DefaultConfigSource))
+		g.Expect(rawConfig.Sources).To(HaveKeyWithValue("no-proxy", clusterLayer.Name))
+		g.Expect(rawConfig.Sources).To(HaveKeyWithValue("http-proxy", fileLayer.Name))
+		g.Expect(rawConfig.Sources).To(HaveKeyWithValue("allow-package-registry-proxy-rpm", "env"))
+	})
+
+	t.Run("should let later layer unset value with empty value", func(t *testing.T) {
+		t.Setenv("KBC_CONFIG_HTTP_PROXY", "")
+
+		reader := &config.LayeredConfigReader{Layers: []config.ConfigLayer{clusterLayer, envLayer}}
+		rawConfig, err := reader.ReadConfigData()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(rawConfig.HttpProxy).To(BeEmpty())
+		g.Expect(rawConfig.Sources).To(HaveKeyWithValue("http-proxy", "env"))
+	})
+
+	t.Run("should apply only tenant overrides from restricted env layer", func(t *testing.T) {
+		t.Setenv("KBC_CONFIG_HTTP_PROXY", "env-proxy.io:3128")
+		t.Setenv("KBC_CONFIG_ALLOW_PACKAGE_REGISTRY_PROXY", "true")
+		t.Setenv("KBC_CONFIG_ALLOW_CACHE_PROXY", "true")
+		t.Setenv("KBC_CONFIG_ALLOW_PACKAGE_REGISTRY_PROXY_NPM", "false")
+		t.Setenv("KBC_CONFIG_PACKAGE_REGISTRY_PROXY_NPM_URL", "https://env-npm-proxy.io")
+
+		restrictedEnvLayer := config.ConfigLayer{Name: "env", Source: &config.EnvReader{
+			Prefix:      config.ConfigEnvVarPrefix,
+			AllowedKeys: config.TenantOverridableKeys(),
+		}}
+		reader := &config.LayeredConfigReader{Layers: []config.ConfigLayer{clusterLayer, restrictedEnvLayer}}
+		rawConfig, err := reader.ReadConfigData()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(rawConfig.HermetoPackageManagerProxyAllowed).To(Equal(map[string]string{"npm": "false"}))
+		g.Expect(rawConfig.HermetoPackageManagerProxy["npm"]).To(Equal("https://env-npm-proxy.io"))
+		// Platform controlled keys and enabled switches are rejected
+		g.Expect(rawConfig.HttpProxy).To(Equal("cluster-proxy.io:3128"))
+		g.Expect(rawConfig.Sources).To(HaveKeyWithValue("http-proxy", clusterLayer.Name))
+		g.Expect(rawConfig.Sources).To(HaveKeyWithValue("allow-package-registry-proxy", clusterLayer.Name))
+		g.Expect(rawConfig.AllowCacheProxy).To(Equal("true"))
+		g.Expect(rawConfig.Sources).To(HaveKeyWithValue("allow-cache-proxy", config.DefaultConfigSource))
+	})
+
+	t.Run("should skip optional layer that cannot be read", func(t *testing.T) {
+		reader := &config.LayeredConfigReader{Layers: []config.ConfigLayer{
+			{Name: "unavailable", Source: &failingConfigSource{}, Optional: true},
+			fileLayer,
+		}}
+		rawConfig, err := reader.ReadConfigData()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(rawConfig.HttpProxy).To(Equal("file-proxy.io:3128"))
+	})
+
+	t.Run("should fail if required layer cannot be read", func(t *testing.T) {
+		reader := &config.LayeredConfigReader{Layers: []config.ConfigLayer{
+			{Name: "unavailable", Source: &failingConfigSource{}},
+			fileLayer,
+		}}
+		rawConfig, err := reader.ReadConfigData()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("unavailable"))
+		g.Expect(rawConfig).To(BeNil())
+	})
+
+	t.Run("should resolve defaults without layers", func(t *testing.T) {
+		reader := &config.LayeredConfigReader{}
+		resolved, err := reader.Resolve()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(resolved.UnknownKeys).To(BeEmpty())
+		g.Expect(resolved.InvalidKeys).To(BeEmpty())
+		for _, value := range resolved.Values {
+			g.Expect(value.Source).To(Equal(config.DefaultConfigSource))
+		}
+
+		konfluxConfig, err := config.NewHermetoProxyConfig(*resolved.RawConfig())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(konfluxConfig.PackageRegistryProxyAllowed).To(BeFalse())
+	})
+}
@@ -0,0 +1,851 @@
+package sbom
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Mapping between SPDX 2.3 and CycloneDX documents.
+//
+//	| SPDX                                     | CycloneDX                                                      |
+//	|------------------------------------------|----------------------------------------------------------------|
+//	| package described by the document        | metadata component                                             |
+//	| other packages and files                 | components, files have type file                               |
+//	| SPDXID                                   | bom-ref                                                        |
+//	| primaryPackagePurpose                    | type                                                           |
+//	| supplier / originator                    | supplier / author                                              |
+//	| checksums                                | hashes                                                         |
+//	| licenseDeclared / licenseConcluded       | license expression, with acknowledgement since 1.6             |
+//	| downloadLocation / homepage              | externalReferences of type distribution / website              |
+//	| purl and cpe externalRefs                | purl and cpe                                                   |
+//	| SECURITY advisory externalRefs           | externalReferences of type advisories                          |
+//	| OTHER externalRefs of a CycloneDX type   | externalReferences of that type                                |
+//	| any other externalRefs                   | properties spdx:externalRef:<category>:<type> = <locator>      |
+//	| described package CONTAINS X             | X is a top-level component                                     |
+//	| P CONTAINS X, hasFiles                   | X is nested in the components of P                             |
+//	| A DEPENDS_ON B, B DEPENDENCY_OF A        | dependency of A on B                                           |
+//	| A DESCENDANT_OF B, B ANCESTOR_OF A       | B is in the pedigree ancestors of A                            |
+//	| creators Tool / Person / Organization    | metadata tools / authors / manufacturer (1.6)                  |
+//
+// Anything else, including unmodeled fields kept in Extra, is reported as unmapped.
+
+// spdxExternalRefProperty prefixes the names of the CycloneDX properties holding SPDX external
+// references without a CycloneDX counterpart.
+const spdxExternalRefProperty = "spdx:externalRef:"
+
+var spdxToCycloneDXHashAlgorithms = map[string]string{
+	"MD5":         "MD5",
+	"SHA1":        "SHA-1",
+	"SHA256":      "SHA-256",
+	"SHA384":      "SHA-384",
+	"SHA512":      "SHA-512",
+	"SHA3-256":    "SHA3-256",
+	"SHA3-384":    "SHA3-384",
+	"SHA3-512":    "SHA3-512",
+	"BLAKE2b-256": "BLAKE2b-256",
+	"BLAKE2b-384": "BLAKE2b-384",
+	"BLAKE2b-512": "BLAKE2b-512",
+	"BLAKE3":      "BLAKE3",
+}
+
+var spdxToCycloneDXComponentTypes = map[string]string{
+	"APPLICATION":      CycloneDXComponentTypeApplication,
+	"FRAMEWORK":        "framework",
+	"LIBRARY":          CycloneDXComponentTypeLibrary,
+	"CONTAINER":        CycloneDXComponentTypeContainer,
+	"OPERATING-SYSTEM": "operating-system",
+	"DEVICE":           "device",
+	"FIRMWARE":         "firmware",
+	"FILE":             CycloneDXComponentTypeFile,
+}
+
+// cycloneDXExternalReferenceTypes are the external reference types of CycloneDX 1.5.
+var cycloneDXExternalReferenceTypes = []string{
+	"vcs", "issue-tracker", "website", "advisories", "bom", "mailing-list", "social", "chat",
+	"documentation", "support", "distribution", "distribution-intake", "license", "build-meta",
+	"build-system", "release-notes", "security-contact", "model-card", "log", "configuration",
+	"evidence", "formulation", "attestation", "threat-model", "adversary-model", "risk-assessment",
+	"vulnerability-assertion", "exploitability-statement", "pentest-report", "static-analysis-report",
+	"dynamic-analysis-report", "runtime-analysis-report", "component-analysis-report",
+	"maturity-report", "certification-report", "codified-infrastructure", "quality-metrics", "poam",
+	"other",
+}
+
+// UnmappedField is a field that could not be mapped to the other format.
+type UnmappedField struct {
+	// Field is the path of the field, e.g. packages.sourceInfo or relationships.GENERATED_FROM
+	Field string `json:"field"`
+	// Elements are the SPDXIDs or bom-refs of the elements that have the field
+	Elements []string `json:"elements,omitempty"`
+}
+
+func (f UnmappedField) String() string {
+	if len(f.Elements) == 0 {
+		return f.Field
+	}
+	return fmt.Sprintf("%s (%d elements)", f.Field, len(f.Elements))
+}
+
+// unmappedFields collects unmapped fields in the order they are found.
+type unmappedFields struct {
+	fields []UnmappedField
+	index  map[string]int
+}
+
+func (u *unmappedFields) add(field, element string) {
+	if u.index == nil {
+		u.index = map[string]int{}
+	}
+	i, ok := u.index[field]
+	if !ok {
+		u.fields = append(u.fields, UnmappedField{Field: field})
+		i = len(u.fields) - 1
+		u.index[field] = i
+	}
+	if element != "" && !slices.Contains(u.fields[i].Elements, element) {
+		u.fields[i].Elements = append(u.fields[i].Elements, element)
+	}
+}
+
+func (u *unmappedFields) addExtra(prefix string, extra Extra, element string) {
+	for _, key := range extra.Keys() {
+		u.add(prefix+key, element)
+	}
+}
+
+// SPDXToCycloneDX converts the SPDX document to a CycloneDX document of the spec version.
+func SPDXToCycloneDX(doc *SPDXDocument, specVersion string) (*CycloneDXDocument, []UnmappedField, error) {
+	if !slices.Contains(CycloneDXSpecVersions, specVersion) {
+		return nil, nil, fmt.Errorf("unsupported CycloneDX version '%s', expected one of %v", specVersion, CycloneDXSpecVersions)
+	}
+	c := &spdxToCycloneDX{
+		doc:         doc,
+		specVersion: specVersion,
+		components:  map[string]*CycloneDXComponent{},
+		children:    map[string][]string{},
+		parent:      map[string]string{},
+		ancestors:   map[string][]string{},
+		isAncestor:  map[string]bool{},
+	}
+	return c.convert()
+}
+
+type spdxToCycloneDX struct {
+	doc         *SPDXDocument
+	specVersion string
+	unmapped    unmappedFields
+
+	// order lists the SPDXIDs of packages and files in document order
+	order      []string
+	components map[string]*CycloneDXComponent
+	root       string
+	children   map[string][]string
+	parent     map[string]string
+	ancestors  map[string][]string
+	isAncestor map[string]bool
+}
+
+func (c *spdxToCycloneDX) convert() (*CycloneDXDocument, []UnmappedField, error) {
+	doc := c.doc
+	c.unmapped.addExtra("document.", doc.Extra, "")
+	c.unmapped.addExtra("creationInfo.", doc.CreationInfo.Extra, "")
+	if doc.CreationInfo.Comment != "" {
+		c.unmapped.add("creationInfo.comment", "")
+	}
+	if doc.CreationInfo.LicenseListVersion != "" {
+		c.unmapped.add("creationInfo.licenseListVersion", "")
+	}
+	for _, license := range doc.HasExtractedLicensingInfos {
+		c.unmapped.add("hasExtractedLicensingInfos", license.LicenseID)
+	}
+
+	for i := range doc.Packages {
+		c.addPackage(&doc.Packages[i])
+	}
+	for i := range doc.Files {
+		c.addFile(&doc.Files[i])
+	}
+
+	roots := doc.Roots()
+	if len(roots) > 0 {
+		c.root = roots[0]
+		if c.components[c.root] == nil {
+			return nil, nil, fmt.Errorf("the document describes unknown element '%s'", c.root)
+		}
+		if len(roots) > 1 {
+			c.unmapped.add("relationships.DESCRIBES", strings.Join(roots[1:], ", "))
+		}
+		if doc.Name != c.components[c.root].Name {
+			c.unmapped.add("document.name", "")
+		}
+	} else {
+		c.unmapped.add("document.name", "")
+	}
+
+	dependencies := c.convertRelationships()
+
+	result := &CycloneDXDocument{
+		Schema:       "http://cyclonedx.org/schema/bom-" + c.specVersion + ".schema.json",
+		BOMFormat:    CycloneDXFormat,
+		SpecVersion:  c.specVersion,
+		SerialNumber: "urn:uuid:" + uuid.NewSHA1(uuid.NameSpaceURL, []byte(doc.DocumentNamespace)).String(),
+		Version:      1,
+		Metadata:     c.metadata(),
+		Dependencies: dependencies,
+	}
+	for _, id := range c.order {
+		if id != c.root && c.parent[id] == "" && !c.isAncestor[id] {
+			result.Components = append(result.Components, *c.build(id))
+		}
+	}
+	if err := result.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("converted CycloneDX document is invalid: %w", err)
+	}
+	return result, c.unmapped.fields, nil
+}
+
+func (c *spdxToCycloneDX) metadata() *CycloneDXMetadata {
+	metadata := &CycloneDXMetadata{Timestamp: c.doc.CreationInfo.Created}
+	for _, creator := range c.doc.CreationInfo.Creators {
+		kind, name, _ := strings.Cut(creator, ": ")
+		switch kind {
+		case "Tool":
+			if metadata.Tools == nil {
+				metadata.Tools = &CycloneDXTools{}
+			}
+			metadata.Tools.Components = append(metadata.Tools.Components, CycloneDXComponent{Type: CycloneDXComponentTypeApplication, Name: name})
+		case "Person":
+			metadata.Authors = append(metadata.Authors, CycloneDXContact{Name: name})
+		case "Organization":
+			if c.specVersion == "1.5" || metadata.Manufacturer != nil {
+				c.unmapped.add("creationInfo.creators", creator)
+				continue
+			}
+			metadata.Manufacturer = &CycloneDXOrganization{Name: name}
+		default:
+			c.unmapped.add("creationInfo.creators", creator)
+		}
+	}
+	if c.root != "" {
+		metadata.Component = c.build(c.root)
+	}
+	return metadata
+}
+
+// build returns the component with its nested components and ancestors.
+func (c *spdxToCycloneDX) build(id string) *CycloneDXComponent {
+	component := *c.components[id]
+	for _, child := range c.children[id] {
+		component.Components = append(component.Components, *c.build(child))
+	}
+	if len(c.ancestors[id]) > 0 {
+		component.Pedigree = &CycloneDXPedigree{}
+		for _, ancestor := range c.ancestors[id] {
+			component.Pedigree.Ancestors = append(component.Pedigree.Ancestors, *c.build(ancestor))
+		}
+	}
+	return &component
+}
+
+func (c *spdxToCycloneDX) addPackage(pkg *SPDXPackage) {
+	id := pkg.SPDXID
+	component := &CycloneDXComponent{
+		BOMRef:      id,
+		Type:        CycloneDXComponentTypeLibrary,
+		Name:        pkg.Name,
+		Version:     pkg.VersionInfo,
+		Description: pkg.Description,
+	}
+	if pkg.PrimaryPackagePurpose != "" {
+		if componentType, ok := spdxToCycloneDXComponentTypes[pkg.PrimaryPackagePurpose]; ok {
+			component.Type = componentType
+		} else {
+			c.unmapped.add("packages.primaryPackagePurpose", id)
+		}
+	}
+	if supplier := spdxActor(pkg.Supplier); supplier != "" {
+		component.Supplier = &CycloneDXOrganization{Name: supplier}
+	}
+	component.Author = spdxActor(pkg.Originator)
+	if pkg.Summary != "" {
+		if component.Description == "" {
+			component.Description = pkg.Summary
+		} else {
+			c.unmapped.add("packages.summary", id)
+		}
+	}
+	if pkg.CopyrightText != SPDXNoAssertion && pkg.CopyrightText != "NONE" {
+		component.Copyright = pkg.CopyrightText
+	}
+	component.Hashes = c.hashes(pkg.Checksums, "packages.checksums", id)
+	component.Licenses = c.licenses(pkg, id)
+
+	if isSPDXLocation(pkg.DownloadLocation) {
+		component.ExternalReferences = append(component.ExternalReferences, CycloneDXExternalReference{Type: "distribution", URL: pkg.DownloadLocation})
+	}
+	if isSPDXLocation(pkg.Homepage) {
+		component.ExternalReferences = append(component.ExternalReferences, CycloneDXExternalReference{Type: "website", URL: pkg.Homepage})
+	}
+	for _, ref := range pkg.ExternalRefs {
+		c.addExternalRef(component, ref)
+	}
+
+	if len(pkg.Annotations) > 0 {
+		c.unmapped.add("packages.annotations", id)
+	}
+	c.unmapped.addExtra("packages.", pkg.Extra, id)
+
+	c.order = append(c.order, id)
+	c.components[id] = component
+}
+
+func (c *spdxToCycloneDX) addExternalRef(component *CycloneDXComponent, ref SPDXExternalRef) {
+	switch {
+	case ref.ReferenceType == spdxPurlReferenceType && component.Purl == "":
+		component.Purl = ref.ReferenceLocator
+	case (ref.ReferenceType == "cpe23Type" || ref.ReferenceType == "cpe22Type") && component.CPE == "":
+		component.CPE = ref.ReferenceLocator
+	case ref.ReferenceCategory == "SECURITY" && ref.ReferenceType == "advisory":
+		component.ExternalReferences = append(component.ExternalReferences,
+			CycloneDXExternalReference{Type: "advisories", URL: ref.ReferenceLocator, Comment: ref.Comment})
+		return
+	case ref.ReferenceCategory == "OTHER" && slices.Contains(cycloneDXExternalReferenceTypes, ref.ReferenceType):
+		component.ExternalReferences = append(component.ExternalReferences,
+			CycloneDXExternalReference{Type: ref.ReferenceType, URL: ref.ReferenceLocator, Comment: ref.Comment})
+		return
+	default:
+		component.Properties = append(component.Properties, CycloneDXProperty{
+			Name:  spdxExternalRefProperty + ref.ReferenceCategory + ":" + ref.ReferenceType,
+			Value: ref.ReferenceLocator,
+		})
+	}
+	if ref.Comment != "" {
+		c.unmapped.add("packages.externalRefs.comment", component.BOMRef)
+	}
+}
+
+func (c *spdxToCycloneDX) hashes(checksums []SPDXChecksum, field, id string) []CycloneDXHash {
+	var hashes []CycloneDXHash
+	for _, checksum := range checksums {
+		algorithm, ok := spdxToCycloneDXHashAlgorithms[checksum.Algorithm]
+		if !ok {
+			c.unmapped.add(field+"."+checksum.Algorithm, id)
+			continue
+		}
+		hashes = append(hashes, CycloneDXHash{Algorithm: algorithm, Content: checksum.ChecksumValue})
+	}
+	return hashes
+}
+
+// licenses returns the declared license, or the concluded one if no license is declared.
+// A CycloneDX component has a single license expression, so a different concluded license is unmapped.
+func (c *spdxToCycloneDX) licenses(pkg *SPDXPackage, id string) []CycloneDXLicenseChoice {
+	for _, license := range []string{pkg.LicenseDeclared, pkg.LicenseConcluded} {
+		if license == "NONE" {
+			c.unmapped.add("packages.license.NONE", id)
+		}
+	}
+	declared, concluded := spdxLicense(pkg.LicenseDeclared), spdxLicense(pkg.LicenseConcluded)
+	choice := CycloneDXLicenseChoice{Expression: declared, Acknowledgement: "declared"}
+	switch {
+	case declared == "" && concluded == "":
+		return nil
+	case declared == "":
+		choice = CycloneDXLicenseChoice{Expression: concluded, Acknowledgement: "concluded"}
+	case concluded != "" && concluded != declared:
+		c.unmapped.add("packages.licenseConcluded", id)
+	}
+	if c.specVersion == "1.5" {
+		choice.Acknowledgement = ""
+	}
+	return []CycloneDXLicenseChoice{choice}
+}
+
+func (c *spdxToCycloneDX) addFile(file *SPDXFile) {
+	c.unmapped.addExtra("files.", file.Extra, file.SPDXID)
+	c.order = append(c.order, file.SPDXID)
+	c.components[file.SPDXID] = &CycloneDXComponent{
+		BOMRef: file.SPDXID,
+		Type:   CycloneDXComponentTypeFile,
+		Name:   file.FileName,
+		Hashes: c.hashes(file.Checksums, "files.checksums", file.SPDXID),
+	}
+}
+
+// convertRelationships records the containment and ancestors of the elements
+// and returns the dependencies.
+func (c *spdxToCycloneDX) convertRelationships() []CycloneDXDependency {
+	type edge struct{ from, to string }
+	var contains, descends []edge
+	var dependencies []CycloneDXDependency
+	dependencyIndex := map[string]int{}
+	dependsOn := func(from, to string) {
+		index, ok := dependencyIndex[from]
+		if !ok {
+			dependencies = append(dependencies, CycloneDXDependency{Ref: from})
+			index = len(dependencies) - 1
+			dependencyIndex[from] = index
+		}
+		if !slices.Contains(dependencies[index].DependsOn, to) {
+			dependencies[index].DependsOn = append(dependencies[index].DependsOn, to)
+		}
+	}
+	inDependencies := map[string]bool{}
+
+	for _, pkg := range c.doc.Packages {
+		for _, file := range pkg.HasFiles {
+			contains = append(contains, edge{pkg.SPDXID, file})
+		}
+	}
+	for _, rel := range c.doc.Relationships {
+		from, to := rel.SPDXElementID, rel.RelatedSPDXElement
+		if from == c.doc.SPDXID || to == c.doc.SPDXID {
+			if rel.RelationshipType != SPDXRelationshipDescribes && rel.RelationshipType != SPDXRelationshipDescribedBy {
+				c.unmapped.add("relationships."+rel.RelationshipType, from+" -> "+to)
+			}
+			continue
+		}
+		if c.components[from] == nil || c.components[to] == nil {
+			c.unmapped.add("relationships."+rel.RelationshipType, from+" -> "+to)
+			continue
+		}
+		switch rel.RelationshipType {
+		case SPDXRelationshipContains:
+			contains = append(contains, edge{from, to})
+		case SPDXRelationshipContainedBy:
+			contains = append(contains, edge{to, from})
+		case SPDXRelationshipDependsOn:
+			dependsOn(from, to)
+			inDependencies[from], inDependencies[to] = true, true
+		case SPDXRelationshipDependencyOf:
+			dependsOn(to, from)
+			inDependencies[from], inDependencies[to] = true, true
+		case SPDXRelationshipDescendantOf:
+			descends = append(descends, edge{from, to})
+		case SPDXRelationshipAncestorOf:
+			descends = append(descends, edge{to, from})
+		default:
+			c.unmapped.add("relationships."+rel.RelationshipType, from+" -> "+to)
+		}
+	}
+
+	// Elements contained by the root are top-level components, others are nested in their first container
+	containedByRoot := map[string]bool{}
+	for _, e := range contains {
+		switch {
+		case e.from == c.root:
+			containedByRoot[e.to] = true
+		case e.to != c.root && c.parent[e.to] == "" && !c.isInside(e.from, e.to):
+			c.parent[e.to] = e.from
+			c.children[e.from] = append(c.children[e.from], e.to)
+		case c.parent[e.to] != e.from:
+			c.unmapped.add("relationships.CONTAINS", e.from+" -> "+e.to)
+		}
+	}
+	for _, e := range contains {
+		if e.from == c.root && c.parent[e.to] != "" {
+			c.unmapped.add("relationships.CONTAINS", e.from+" -> "+e.to)
+		}
+	}
+
+	// Ancestors are moved into the pedigree, so they must not be referenced elsewhere
+	for _, e := range descends {
+		ancestor := e.to
+		if ancestor == c.root || c.parent[ancestor] != "" || containedByRoot[ancestor] || inDependencies[ancestor] ||
+			c.isAncestor[ancestor] || c.isInside(e.from, ancestor) {
+			c.unmapped.add("relationships.DESCENDANT_OF", e.from+" -> "+ancestor)
+			continue
+		}
+		c.isAncestor[ancestor] = true
+		c.ancestors[e.from] = append(c.ancestors[e.from], ancestor)
+	}
+
+	return dependencies
+}
+
+// isInside reports whether the element is the container or nested in the container.
+func (c *spdxToCycloneDX) isInside(element, container string) bool {
+	for id := element; id != ""; id = c.parent[id] {
+		if id == container {
+			return true
+		}
+	}
+	return false
+}
+
+// spdxActor returns the name of the SPDX supplier or originator, e.g. Organization: Red Hat.
+func spdxActor(actor string) string {
+	if actor == "" || actor == SPDXNoAssertion {
+		return ""
+	}
+	if _, name, found := strings.Cut(actor, ": "); found {
+		return name
+	}
+	return actor
+}
+
+func spdxLicense(license string) string {
+	if license == SPDXNoAssertion || license == "NONE" {
+		return ""
+	}
+	return license
+}
+
+func isSPDXLocation(location string) bool {
+	return location != "" && location != SPDXNoAssertion && location != "NONE"
+}
+
+// CycloneDXToSPDX converts the CycloneDX document to an SPDX 2.3 document.
+func CycloneDXToSPDX(doc *CycloneDXDocument) (*SPDXDocument, []UnmappedField, error) {
+	c := &cycloneDXToSPDX{
+		doc:          doc,
+		ids:          map[string]string{},
+		unreferenced: map[*CycloneDXComponent]string{},
+		usedIDs:      map[string]bool{SPDXDocumentID: true},
+	}
+	return c.convert()
+}
+
+type cycloneDXToSPDX struct {
+	doc      *CycloneDXDocument
+	result   *SPDXDocument
+	unmapped unmappedFields
+	// ids maps bom-refs to SPDXIDs, unreferenced has the SPDXIDs of components without bom-ref
+	ids          map[string]string
+	unreferenced map[*CycloneDXComponent]string
+	usedIDs      map[string]bool
+	licenses     []string
+}
+
+func (c *cycloneDXToSPDX) convert() (*SPDXDocument, []UnmappedField, error) {
+	doc := c.doc
+	name := doc.SerialNumber
+	if doc.Metadata != nil && doc.Metadata.Component != nil {
+		name = doc.Metadata.Component.Name
+	}
+	if name == "" {
+		name = "sbom"
+	}
+	namespaceID := uuid.NewSHA1(uuid.NameSpaceURL, []byte(doc.SerialNumber+"\n"+name))
+
+	c.result = &SPDXDocument{
+		SPDXVersion:       SPDXVersion,
+		DataLicense:       SPDXDataLicense,
+		SPDXID:            SPDXDocumentID,
+		Name:              name,
+		DocumentNamespace: spdxNamespaceBase + "/" + spdxIDSuffix(name) + "-" + namespaceID.String(),
+		CreationInfo: SPDXCreationInfo{
+			Created:  now().Format(time.RFC3339),
+			Creators: []string{"Tool: " + toolName},
+		},
+	}
+	c.unmapped.addExtra("document.", doc.Extra, "")
+	if len(doc.Formulation) > 0 {
+		c.unmapped.add("document.formulation", "")
+	}
+
+	// Assign the SPDXIDs first, dependencies may refer to any component
+	if doc.Metadata != nil && doc.Metadata.Component != nil {
+		c.assignID(doc.Metadata.Component)
+	}
+	for i := range doc.Components {
+		c.assignID(&doc.Components[i])
+	}
+
+	root := ""
+	if metadata := doc.Metadata; metadata != nil {
+		c.convertMetadata(metadata)
+		if metadata.Component != nil {
+			root = c.addComponent(metadata.Component)
+			c.relate(SPDXDocumentID, SPDXRelationshipDescribes, root)
+			for i := range metadata.Component.Components {
+				c.relate(root, SPDXRelationshipContains, c.addComponent(&metadata.Component.Components[i]))
+			}
+		}
+	}
+	for i := range doc.Components {
+		id := c.addComponent(&doc.Components[i])
+		if root != "" {
+			c.relate(root, SPDXRelationshipContains, id)
+		} else {
+			c.relate(SPDXDocumentID, SPDXRelationshipDescribes, id)
+		}
+	}
+
+	for _, dependency := range doc.Dependencies {
+		for _, ref := range dependency.DependsOn {
+			c.relate(c.ids[dependency.Ref], SPDXRelationshipDependsOn, c.ids[ref])
+		}
+	}
+
+	if err := c.result.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("converted SPDX document is invalid: %w", err)
+	}
+	return c.result, c.unmapped.fields, nil
+}
+
+func (c *cycloneDXToSPDX) convertMetadata(metadata *CycloneDXMetadata) {
+	if metadata.Timestamp != "" {
+		c.result.CreationInfo.Created = metadata.Timestamp
+	}
+	if metadata.Tools != nil {
+		for _, tool := range metadata.Tools.Components {
+			name := tool.Name
+			if tool.Version != "" {
+				name += "-" + tool.Version
+			}
+			c.addCreator("Tool: " + name)
+		}
+		c.unmapped.addExtra("metadata.tools.", metadata.Tools.Extra, "")
+	}
+	for _, author := range metadata.Authors {
+		c.addCreator("Person: " + author.Name)
+	}
+	if metadata.Manufacturer != nil {
+		c.addCreator("Organization: " + metadata.Manufacturer.Name)
+	}
+	c.unmapped.addExtra("metadata.", metadata.Extra, "")
+}
+
+func (c *cycloneDXToSPDX) addCreator(creator string) {
+	if !slices.Contains(c.result.CreationInfo.Creators, creator) {
+		c.result.CreationInfo.Creators = append(c.result.CreationInfo.Creators, creator)
+	}
+}
+
+// assignID assigns SPDXIDs to the component, its nested components and ancestors.
+// bom-refs that are valid SPDXIDs are kept.
+func (c *cycloneDXToSPDX) assignID(component *CycloneDXComponent) {
+	id := component.BOMRef
+	if !isValidSPDXID(id) {
+		suffix := component.BOMRef
+		if suffix == "" {
+			suffix = component.Type + "-" + component.Name
+		}
+		id = "SPDXRef-" + spdxIDSuffix(suffix)
+	}
+	id = uniqueID(id, c.usedIDs)
+	if component.BOMRef != "" {
+		c.ids[component.BOMRef] = id
+	} else {
+		c.unreferenced[component] = id
+	}
+
+	for i := range component.Components {
+		c.assignID(&component.Components[i])
+	}
+	if component.Pedigree != nil {
+		for i := range component.Pedigree.Ancestors {
+			c.assignID(&component.Pedigree.Ancestors[i])
+		}
+	}
+}
+
+func (c *cycloneDXToSPDX) id(component *CycloneDXComponent) string {
+	if component.BOMRef != "" {
+		return c.ids[component.BOMRef]
+	}
+	return c.unreferenced[component]
+}
+
+// addComponent adds the component as a package or a file, with its nested components and ancestors,
+// and returns its SPDXID.
+func (c *cycloneDXToSPDX) addComponent(component *CycloneDXComponent) string {
+	id := c.id(component)
+	if component.Type == CycloneDXComponentTypeFile && component.Purl == "" {
+		c.addFile(id, component)
+	} else {
+		c.addPackage(id, component)
+	}
+
+	for i := range component.Components {
+		c.relate(id, SPDXRelationshipContains, c.addComponent(&component.Components[i]))
+	}
+	if component.Pedigree != nil {
+		for i := range component.Pedigree.Ancestors {
+			c.relate(id, SPDXRelationshipDescendantOf, c.addComponent(&component.Pedigree.Ancestors[i]))
+		}
+		c.unmapped.addExtra("components.pedigree.", component.Pedigree.Extra, component.BOMRef)
+	}
+	return id
+}
+
+func (c *cycloneDXToSPDX) addFile(id string, component *CycloneDXComponent) {
+	file := SPDXFile{
+		SPDXID:    id,
+		FileName:  component.Name,
+		Checksums: c.checksums(component),
+	}
+	fields := []struct {
+		name string
+		set  bool
+	}{
+		{"version", component.Version != ""},
+		{"licenses", len(component.Licenses) > 0},
+		{"copyright", component.Copyright != ""},
+		{"externalReferences", len(component.ExternalReferences) > 0},
+		{"properties", len(component.Properties) > 0},
+	}
+	for _, field := range fields {
+		if field.set {
+			c.unmapped.add("components.file."+field.name, component.BOMRef)
+		}
+	}
+	c.unmapped.addExtra("components.", component.Extra, component.BOMRef)
+	c.result.Files = append(c.result.Files, file)
+}
+
+func (c *cycloneDXToSPDX) addPackage(id string, component *CycloneDXComponent) {
+	filesAnalyzed := false
+	pkg := SPDXPackage{
+		SPDXID:           id,
+		Name:             component.Name,
+		VersionInfo:      component.Version,
+		DownloadLocation: SPDXNoAssertion,
+		FilesAnalyzed:    &filesAnalyzed,
+		Checksums:        c.checksums(component),
+		Description:      component.Description,
+		CopyrightText:    component.Copyright,
+	}
+	for purpose, componentType := range spdxToCycloneDXComponentTypes {
+		if componentType == component.Type {
+			pkg.PrimaryPackagePurpose = purpose
+		}
+	}
+	if pkg.PrimaryPackagePurpose == "" {
+		pkg.PrimaryPackagePurpose = "OTHER"
+	}
+	if component.Supplier != nil && component.Supplier.Name != "" {
+		pkg.Supplier = "Organization: " + component.Supplier.Name
+	}
+	if component.Author != "" {
+		pkg.Originator = "Person: " + component.Author
+	}
+	if component.Group != "" {
+		c.unmapped.add("components.group", component.BOMRef)
+	}
+	c.convertLicenses(&pkg, component)
+
+	if component.Purl != "" {
+		pkg.ExternalRefs = append(pkg.ExternalRefs, SPDXExternalRef{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: spdxPurlReferenceType, ReferenceLocator: component.Purl})
+	}
+	if component.CPE != "" {
+		cpeType := "cpe23Type"
+		if strings.HasPrefix(component.CPE, "cpe:/") {
+			cpeType = "cpe22Type"
+		}
+		pkg.ExternalRefs = append(pkg.ExternalRefs, SPDXExternalRef{ReferenceCategory: "SECURITY", ReferenceType: cpeType, ReferenceLocator: component.CPE})
+	}
+	for _, ref := range component.ExternalReferences {
+		switch {
+		case ref.Type == "distribution" && pkg.DownloadLocation == SPDXNoAssertion:
+			pkg.DownloadLocation = ref.URL
+		case ref.Type == "website" && pkg.Homepage == "":
+			pkg.Homepage = ref.URL
+		case ref.Type == "advisories":
+			pkg.ExternalRefs = append(pkg.ExternalRefs, SPDXExternalRef{ReferenceCategory: "SECURITY", ReferenceType: "advisory", ReferenceLocator: ref.URL, Comment: ref.Comment})
+		default:
+			pkg.ExternalRefs = append(pkg.ExternalRefs, SPDXExternalRef{ReferenceCategory: "OTHER", ReferenceType: ref.Type, ReferenceLocator: ref.URL, Comment: ref.Comment})
+		}
+		c.unmapped.addExtra("components.externalReferences.", ref.Extra, component.BOMRef)
+	}
+	for _, property := range component.Properties {
+		category, refType, found := strings.Cut(strings.TrimPrefix(property.Name, spdxExternalRefProperty), ":")
+		if !strings.HasPrefix(property.Name, spdxExternalRefProperty) || !found {
+			c.unmapped.add("components.properties."+property.Name, component.BOMRef)
+			continue
+		}
+		pkg.ExternalRefs = append(pkg.ExternalRefs, SPDXExternalRef{ReferenceCategory: category, ReferenceType: refType, ReferenceLocator: property.Value})
+	}
+
+	c.unmapped.addExtra("components.", component.Extra, component.BOMRef)
+	c.result.Packages = append(c.result.Packages, pkg)
+}
+
+func (c *cycloneDXToSPDX) checksums(component *CycloneDXComponent) []SPDXChecksum {
+	var checksums []SPDXChecksum
+	for _, hash := range component.Hashes {
+		found := false
+		for algorithm, cycloneDXAlgorithm := range spdxToCycloneDXHashAlgorithms {
+			if cycloneDXAlgorithm == hash.Algorithm {
+				checksums = append(checksums, SPDXChecksum{Algorithm: algorithm, ChecksumValue: hash.Content})
+				found = true
+			}
+		}
+		if !found {
+			c.unmapped.add("components.hashes."+hash.Algorithm, component.BOMRef)
+		}
+	}
+	return checksums
+}
+
+// convertLicenses sets the declared and concluded licenses of the package.
+// Licenses without acknowledgement are declared, multiple licenses are combined with AND.
+func (c *cycloneDXToSPDX) convertLicenses(pkg *SPDXPackage, component *CycloneDXComponent) {
+	var declared, concluded []string
+	for _, choice := range component.Licenses {
+		license, acknowledgement := choice.Expression, choice.Acknowledgement
+		if choice.License != nil {
+			acknowledgement = choice.License.Acknowledgement
+			switch {
+			case choice.License.ID != "":
+				license = choice.License.ID
+			case choice.License.Name != "":
+				license = c.extractedLicense(choice.License.Name)
+			}
+			c.unmapped.addExtra("components.licenses.", choice.License.Extra, component.BOMRef)
+		}
+		if license == "" {
+			continue
+		}
+		if acknowledgement == "concluded" {
+			concluded = append(concluded, license)
+		} else {
+			declared = append(declared, license)
+		}
+	}
+	pkg.LicenseDeclared = joinLicenses(declared)
+	pkg.LicenseConcluded = joinLicenses(concluded)
+}
+
+// extractedLicense adds a license that is not on the SPDX license list and returns its LicenseRef.
+func (c *cycloneDXToSPDX) extractedLicense(name string) string {
+	id := "LicenseRef-" + spdxIDSuffix(name)
+	if !slices.Contains(c.licenses, id) {
+		c.licenses = append(c.licenses, id)
+		c.result.HasExtractedLicensingInfos = append(c.result.HasExtractedLicensingInfos,
+			SPDXExtractedLicense{LicenseID: id, Name: name, ExtractedText: name})
+	}
+	return id
+}
+
+func joinLicenses(licenses []string) string {
+	if len(licenses) < 2 {
+		return strings.Join(licenses, "")
+	}
+	parts := make([]string, 0, len(licenses))
+	for _, license := range licenses {
+		if strings.Contains(license, " ") {
+			license = "(" + license + ")"
+		}
+		parts = append(parts, license)
+	}
+	return strings.Join(parts, " AND ")
+}
+
+func (c *cycloneDXToSPDX) relate(from, relationshipType, to string) {
+	rel := SPDXRelationship{SPDXElementID: from, RelationshipType: relationshipType, RelatedSPDXElement: to}
+	if !slices.Contains(c.result.Relationships, rel) {
+		c.result.Relationships = append(c.result.Relationships, rel)
+	}
+}
+
+// isValidSPDXID reports whether the id is SPDXRef- followed by letters, numbers, . and -.
+func isValidSPDXID(id string) bool {
+	suffix, found := strings.CutPrefix(id, "SPDXRef-")
+	return found && suffix != "" && spdxIDSuffix(suffix) == suffix
+}
@@ -0,0 +1,315 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func conversionSPDX() *SPDXDocument {
+	return &SPDXDocument{
+		SPDXVersion:       SPDXVersion,
+		DataLicense:       SPDXDataLicense,
+		SPDXID:            SPDXDocumentID,
+		Name:              "quay.io/org/app",
+		DocumentNamespace: "https://example.com/app",
+		CreationInfo: SPDXCreationInfo{
+			Created:  "2026-01-01T00:00:00Z",
+			Creators: []string{"Tool: syft-1.0.0", "Organization: Example", "Person: Jane Doe"},
+		},
+		Packages: []SPDXPackage{
+			{
+				SPDXID:                "SPDXRef-image",
+				Name:                  "quay.io/org/app",
+				VersionInfo:           "v1",
+				DownloadLocation:      SPDXNoAssertion,
+				Supplier:              "Organization: Example",
+				PrimaryPackagePurpose: "CONTAINER",
+				LicenseDeclared:       "MIT",
+				LicenseConcluded:      "Apache-2.0",
+				Checksums: []SPDXChecksum{
+					{Algorithm: "SHA256", ChecksumValue: "aaaa"},
+					{Algorithm: "SHA224", ChecksumValue: "bbbb"},
+				},
+			},
+			{
+				SPDXID:           "SPDXRef-openssl",
+				Name:             "openssl",
+				VersionInfo:      "3.0.7",
+				DownloadLocation: "https://www.openssl.org/source/openssl-3.0.7.tar.gz",
+				Homepage:         "https://www.openssl.org",
+				Originator:       "Person: Maintainer",
+				LicenseConcluded: "Apache-2.0",
+				CopyrightText:    SPDXNoAssertion,
+				HasFiles:         []string{"SPDXRef-File-license"},
+				ExternalRefs: []SPDXExternalRef{
+					{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:rpm/redhat/openssl@3.0.7?arch=x86_64"},
+					{ReferenceCategory: "SECURITY", ReferenceType: "cpe23Type", ReferenceLocator: "cpe:2.3:a:openssl:openssl:3.0.7:*:*:*:*:*:*:*"},
+					{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:generic/openssl@3.0.7"},
+					{ReferenceCategory: "SECURITY", ReferenceType: "advisory", ReferenceLocator: "https://access.redhat.com/errata/RHSA-2023:0001"},
+					{ReferenceCategory: "OTHER", ReferenceType: "vcs", ReferenceLocator: "https://github.com/openssl/openssl"},
+					{ReferenceCategory: "PERSISTENT-ID", ReferenceType: "swh", ReferenceLocator: "swh:1:cnt:94a9ed024d3859793618152ea559a168bbcbb5e2"},
+				},
+				Extra: Extra{"sourceInfo": json.RawMessage(`"built from source"`)},
+			},
+			{
+				SPDXID:           "SPDXRef-requests",
+				Name:             "requests",
+				DownloadLocation: SPDXNoAssertion,
+				LicenseDeclared:  "Apache-2.0",
+				Checksums:        []SPDXChecksum{{Algorithm: "SHA1", ChecksumValue: "cccc"}},
+				ExternalRefs:     purlRef("pkg:pypi/requests@2.31.0"),
+			},
+			{
+				SPDXID:           "SPDXRef-urllib3",
+				Name:             "urllib3",
+				DownloadLocation: SPDXNoAssertion,
+				ExternalRefs:     purlRef("pkg:pypi/urllib3@2.0.0"),
+			},
+			{
+				SPDXID:                "SPDXRef-BaseImage-ubi9",
+				Name:                  "registry.io/ubi9",
+				DownloadLocation:      SPDXNoAssertion,
+				PrimaryPackagePurpose: "CONTAINER",
+				ExternalRefs:          purlRef("pkg:oci/ubi9@" + testDigest),
+			},
+		},
+		Files: []SPDXFile{
+			{SPDXID: "SPDXRef-File-license", FileName: "/usr/share/licenses/openssl/LICENSE",
+				Checksums: []SPDXChecksum{{Algorithm: "SHA1", ChecksumValue: "dddd"}}},
+		},
+		HasExtractedLicensingInfos: []SPDXExtractedLicense{{LicenseID: "LicenseRef-custom", ExtractedText: "custom"}},
+		Relationships: []SPDXRelationship{
+			{SPDXElementID: SPDXDocumentID, RelationshipType: SPDXRelationshipDescribes, RelatedSPDXElement: "SPDXRef-image"},
+			{SPDXElementID: "SPDXRef-image", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-openssl"},
+			{SPDXElementID: "SPDXRef-image", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-requests"},
+			{SPDXElementID: "SPDXRef-image", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-urllib3"},
+			{SPDXElementID: "SPDXRef-requests", RelationshipType: SPDXRelationshipDependsOn, RelatedSPDXElement: "SPDXRef-urllib3"},
+			{SPDXElementID: "SPDXRef-openssl", RelationshipType: SPDXRelationshipDependencyOf, RelatedSPDXElement: "SPDXRef-requests"},
+			{SPDXElementID: "SPDXRef-image", RelationshipType: SPDXRelationshipDescendantOf, RelatedSPDXElement: "SPDXRef-BaseImage-ubi9"},
+			{SPDXElementID: "SPDXRef-requests", RelationshipType: "GENERATED_FROM", RelatedSPDXElement: "SPDXRef-urllib3"},
+		},
+	}
+}
+
+func TestSPDXToCycloneDX(t *testing.T) {
+	g := NewWithT(t)
+
+	doc, unmapped, err := SPDXToCycloneDX(conversionSPDX(), "1.6")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(doc.SpecVersion).To(Equal("1.6"))
+	g.Expect(doc.Schema).To(Equal("http://cyclonedx.org/schema/bom-1.6.schema.json"))
+	g.Expect(doc.SerialNumber).To(HavePrefix("urn:uuid:"))
+	g.Expect(doc.Metadata.Timestamp).To(Equal("2026-01-01T00:00:00Z"))
+	g.Expect(doc.Metadata.Tools.Components).To(Equal([]CycloneDXComponent{{Type: "application", Name: "syft-1.0.0"}}))
+	g.Expect(doc.Metadata.Manufacturer).To(Equal(&CycloneDXOrganization{Name: "Example"}))
+	g.Expect(doc.Metadata.Authors).To(Equal([]CycloneDXContact{{Name: "Jane Doe"}}))
+
+	root := doc.Metadata.Component
+	g.Expect(root.BOMRef).To(Equal("SPDXRef-image"))
+	g.Expect(root.Type).To(Equal("container"))
+	g.Expect(root.Supplier).To(Equal(&CycloneDXOrganization{Name: "Example"}))
+	g.Expect(root.Hashes).To(Equal([]CycloneDXHash{{Algorithm: "SHA-256", Content: "aaaa"}}))
+	g.Expect(root.Licenses).To(Equal([]CycloneDXLicenseChoice{{Expression: "MIT", Acknowledgement: "declared"}}))
+	g.Expect(root.Pedigree.Ancestors).To(HaveLen(1))
+	g.Expect(root.Pedigree.Ancestors[0].BOMRef).To(Equal("SPDXRef-BaseImage-ubi9"))
+	g.Expect(root.Pedigree.Ancestors[0].Purl).To(Equal("pkg:oci/ubi9@" + testDigest))
+
+	g.Expect(doc.Components).To(HaveLen(3))
+	openssl := doc.Components[0]
+	g.Expect(openssl.BOMRef).To(Equal("SPDXRef-openssl"))
+	g.Expect(openssl.Type).To(Equal("library"))
+	g.Expect(openssl.Author).To(Equal("Maintainer"))
+	g.Expect(openssl.Copyright).To(BeEmpty())
+	g.Expect(openssl.Purl).To(Equal("pkg:rpm/redhat/openssl@3.0.7?arch=x86_64"))
+	g.Expect(openssl.CPE).To(Equal("cpe:2.3:a:openssl:openssl:3.0.7:*:*:*:*:*:*:*"))
+	g.Expect(openssl.Licenses).To(Equal([]CycloneDXLicenseChoice{{Expression: "Apache-2.0", Acknowledgement: "concluded"}}))
+	g.Expect(openssl.ExternalReferences).To(Equal([]CycloneDXExternalReference{
+		{Type: "distribution", URL: "https://www.openssl.org/source/openssl-3.0.7.tar.gz"},
+		{Type: "website", URL: "https://www.openssl.org"},
+		{Type: "advisories", URL: "https://access.redhat.com/errata/RHSA-2023:0001"},
+		{Type: "vcs", URL: "https://github.com/openssl/openssl"},
+	}))
+	g.Expect(openssl.Properties).To(Equal([]CycloneDXProperty{
+		{Name: "spdx:externalRef:PACKAGE-MANAGER:purl", Value: "pkg:generic/openssl@3.0.7"},
+		{Name: "spdx:externalRef:PERSISTENT-ID:swh", Value: "swh:1:cnt:94a9ed024d3859793618152ea559a168bbcbb5e2"},
+	}))
+	g.Expect(openssl.Components).To(Equal([]CycloneDXComponent{{
+		BOMRef: "SPDXRef-File-license",
+		Type:   "file",
+		Name:   "/usr/share/licenses/openssl/LICENSE",
+		Hashes: []CycloneDXHash{{Algorithm: "SHA-1", Content: "dddd"}},
+	}}))
+	g.Expect(doc.Components[1].BOMRef).To(Equal("SPDXRef-requests"))
+	g.Expect(doc.Components[2].BOMRef).To(Equal("SPDXRef-urllib3"))
+
+	g.Expect(doc.Dependencies).To(Equal([]CycloneDXDependency{
+		{Ref: "SPDXRef-requests", DependsOn: []string{"SPDXRef-urllib3", "SPDXRef-openssl"}},
+	}))
+
+	g.Expect(unmapped).To(ConsistOf(
+		UnmappedField{Field: "hasExtractedLicensingInfos", Elements: []string{"LicenseRef-custom"}},
+		UnmappedField{Field: "packages.checksums.SHA224", Elements: []string{"SPDXRef-image"}},
+		UnmappedField{Field: "packages.licenseConcluded", Elements: []string{"SPDXRef-image"}},
+		UnmappedField{Field: "packages.sourceInfo", Elements: []string{"SPDXRef-openssl"}},
+		UnmappedField{Field: "relationships.GENERATED_FROM", Elements: []string{"SPDXRef-requests -> SPDXRef-urllib3"}},
+	))
+}
+
+func TestSPDXToCycloneDX_version15(t *testing.T) {
+	g := NewWithT(t)
+
+	doc, unmapped, err := SPDXToCycloneDX(conversionSPDX(), "1.5")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(doc.Metadata.Manufacturer).To(BeNil())
+	g.Expect(doc.Metadata.Component.Licenses).To(Equal([]CycloneDXLicenseChoice{{Expression: "MIT"}}))
+	g.Expect(unmapped).To(ContainElement(UnmappedField{Field: "creationInfo.creators", Elements: []string{"Organization: Example"}}))
+
+	_, _, err = SPDXToCycloneDX(conversionSPDX(), "1.4")
+	g.Expect(err).To(MatchError(ContainSubstring("unsupported CycloneDX version '1.4'")))
+}
+
+func TestSPDXToCycloneDX_containment(t *testing.T) {
+	g := NewWithT(t)
+
+	doc := conversionSPDX()
+	doc.Relationships = append(doc.Relationships,
+		// urllib3 is already contained by the image
+		SPDXRelationship{SPDXElementID: "SPDXRef-requests", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-urllib3"},
+		// a cycle
+		SPDXRelationship{SPDXElementID: "SPDXRef-urllib3", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-requests"},
+	)
+
+	converted, unmapped, err := SPDXToCycloneDX(doc, "1.6")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(converted.Components).To(HaveLen(2))
+	g.Expect(converted.Components[1].BOMRef).To(Equal("SPDXRef-requests"))
+	g.Expect(converted.Components[1].Components[0].BOMRef).To(Equal("SPDXRef-urllib3"))
+	g.Expect(unmapped).To(ContainElement(UnmappedField{Field: "relationships.CONTAINS", Elements: []string{
+		"SPDXRef-urllib3 -> SPDXRef-requests", "SPDXRef-image -> SPDXRef-urllib3",
+	}}))
+}
+
+func TestSPDXCycloneDXRoundTrip(t *testing.T) {
+	g := NewWithT(t)
+
+	original := conversionSPDX()
+	cdx, _, err := SPDXToCycloneDX(original, "1.6")
+	g.Expect(err).ToNot(HaveOccurred())
+	spdx, unmapped, err := CycloneDXToSPDX(cdx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(unmapped).To(BeEmpty())
+
+	g.Expect(spdx.Roots()).To(Equal([]string{"SPDXRef-image"}))
+	g.Expect(spdx.CreationInfo.Creators).To(Equal([]string{
+		"Tool: konflux-build-cli", "Tool: syft-1.0.0", "Person: Jane Doe", "Organization: Example",
+	}))
+	g.Expect(spdx.Files).To(Equal(original.Files))
+
+	for _, pkg := range original.Packages {
+		converted := findSPDXPackage(spdx, pkg.SPDXID)
+		g.Expect(converted).ToNot(BeNil(), pkg.SPDXID)
+		g.Expect(converted.Name).To(Equal(pkg.Name))
+		g.Expect(converted.VersionInfo).To(Equal(pkg.VersionInfo))
+		g.Expect(converted.DownloadLocation).To(Equal(pkg.DownloadLocation))
+		g.Expect(converted.Homepage).To(Equal(pkg.Homepage))
+		g.Expect(converted.Supplier).To(Equal(pkg.Supplier))
+		g.Expect(converted.Originator).To(Equal(pkg.Originator))
+		g.Expect(converted.ExternalRefs).To(ConsistOf(pkg.ExternalRefs))
+		g.Expect(converted.LicenseDeclared).To(Equal(pkg.LicenseDeclared))
+	}
+	g.Expect(findSPDXPackage(spdx, "SPDXRef-openssl").LicenseConcluded).To(Equal("Apache-2.0"))
+	g.Expect(findSPDXPackage(spdx, "SPDXRef-requests").Checksums).To(Equal([]SPDXChecksum{{Algorithm: "SHA1", ChecksumValue: "cccc"}}))
+
+	// All relationships but the unmapped GENERATED_FROM are kept, DEPENDENCY_OF as DEPENDS_ON
+	g.Expect(spdx.Relationships).To(ConsistOf(
+		SPDXRelationship{SPDXElementID: SPDXDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-image"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-openssl"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-requests"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-urllib3"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-openssl", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-File-license"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-requests", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-urllib3"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-requests", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-openssl"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-image", RelationshipType: "DESCENDANT_OF", RelatedSPDXElement: "SPDXRef-BaseImage-ubi9"},
+	))
+}
+
+func TestCycloneDXToSPDX(t *testing.T) {
+	g := NewWithT(t)
+
+	input := `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "serialNumber": "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+  "version": 1,
+  "metadata": {
+    "timestamp": "2026-01-01T00:00:00Z",
+    "tools": [{"vendor": "anchore", "name": "syft", "version": "1.0.0"}],
+    "component": {"bom-ref": "image-ref", "type": "container", "name": "quay.io/org/app", "version": "v1"}
+  },
+  "components": [
+    {
+      "bom-ref": "pkg:npm/%40scope/lib@1.0.0?package-id=1",
+      "type": "library",
+      "group": "@scope",
+      "name": "lib",
+      "version": "1.0.0",
+      "purl": "pkg:npm/%40scope/lib@1.0.0",
+      "scope": "required",
+      "hashes": [{"alg": "SHA-512", "content": "eeee"}, {"alg": "MD5", "content": "ffff"}],
+      "licenses": [{"license": {"id": "MIT"}}, {"license": {"name": "Custom License"}}],
+      "properties": [{"name": "syft:package:foundBy", "value": "javascript-lock-cataloger"}],
+      "components": [{"type": "file", "name": "package.json"}]
+    },
+    {"type": "library", "name": "unreferenced"}
+  ],
+  "dependencies": [
+    {"ref": "image-ref", "dependsOn": ["pkg:npm/%40scope/lib@1.0.0?package-id=1"]}
+  ]
+}`
+	var doc CycloneDXDocument
+	g.Expect(json.Unmarshal([]byte(input), &doc)).To(Succeed())
+	g.Expect(doc.Validate()).To(Succeed())
+
+	spdx, unmapped, err := CycloneDXToSPDX(&doc)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(spdx.Name).To(Equal("quay.io/org/app"))
+	g.Expect(spdx.DocumentNamespace).To(HavePrefix("https://konflux-ci.dev/spdxdocs/quay.io-org-app-"))
+	g.Expect(spdx.CreationInfo.Created).To(Equal("2026-01-01T00:00:00Z"))
+	g.Expect(spdx.CreationInfo.Creators).To(Equal([]string{"Tool: konflux-build-cli", "Tool: syft-1.0.0"}))
+
+	var ids []string
+	for _, pkg := range spdx.Packages {
+		ids = append(ids, pkg.SPDXID)
+	}
+	g.Expect(ids).To(Equal([]string{"SPDXRef-image-ref", "SPDXRef-pkg-npm--40scope-lib-1.0.0-package-id-1", "SPDXRef-library-unreferenced"}))
+	g.Expect(spdx.Packages[0].PrimaryPackagePurpose).To(Equal("CONTAINER"))
+
+	lib := spdx.Packages[1]
+	g.Expect(lib.Name).To(Equal("lib"))
+	g.Expect(lib.Checksums).To(Equal([]SPDXChecksum{{Algorithm: "SHA512", ChecksumValue: "eeee"}, {Algorithm: "MD5", ChecksumValue: "ffff"}}))
+	g.Expect(lib.LicenseDeclared).To(Equal("MIT AND LicenseRef-Custom-License"))
+	g.Expect(lib.ExternalRefs).To(Equal(purlRef("pkg:npm/%40scope/lib@1.0.0")))
+	g.Expect(spdx.HasExtractedLicensingInfos).To(Equal([]SPDXExtractedLicense{
+		{LicenseID: "LicenseRef-Custom-License", Name: "Custom License", ExtractedText: "Custom License"},
+	}))
+
+	g.Expect(spdx.Files).To(Equal([]SPDXFile{{SPDXID: "SPDXRef-file-package.json", FileName: "package.json"}}))
+
+	g.Expect(spdx.Relationships).To(Equal([]SPDXRelationship{
+		{SPDXElementID: SPDXDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-image-ref"},
+		{SPDXElementID: "SPDXRef-pkg-npm--40scope-lib-1.0.0-package-id-1", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-file-package.json"},
+		{SPDXElementID: "SPDXRef-image-ref", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-pkg-npm--40scope-lib-1.0.0-package-id-1"},
+		{SPDXElementID: "SPDXRef-image-ref", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-library-unreferenced"},
+		{SPDXElementID: "SPDXRef-image-ref", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-pkg-npm--40scope-lib-1.0.0-package-id-1"},
+	}))
+
+	g.Expect(unmapped).To(ConsistOf(
+		UnmappedField{Field: "components.group", Elements: []string{"pkg:npm/%40scope/lib@1.0.0?package-id=1"}},
+		UnmappedField{Field: "components.properties.syft:package:foundBy", Elements: []string{"pkg:npm/%40scope/lib@1.0.0?package-id=1"}},
+		UnmappedField{Field: "components.scope", Elements: []string{"pkg:npm/%40scope/lib@1.0.0?package-id=1"}},
+	))
+}
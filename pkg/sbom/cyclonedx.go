@@ -0,0 +1,299 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+const (
+	CycloneDXFormat = "CycloneDX"
+
+	CycloneDXComponentTypeApplication = "application"
+	CycloneDXComponentTypeContainer   = "container"
+	CycloneDXComponentTypeFile        = "file"
+	CycloneDXComponentTypeLibrary     = "library"
+)
+
+// CycloneDXSpecVersions are the supported CycloneDX specification versions.
+var CycloneDXSpecVersions = []string{"1.5", "1.6"}
+
+// CycloneDXDocument is a CycloneDX 1.5 or 1.6 JSON document.
+// Fields that are not modeled are kept in Extra of the document and its elements.
+type CycloneDXDocument struct {
+	Schema       string                `json:"$schema,omitempty"`
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber,omitempty"`
+	Version      int                   `json:"version"`
+	Metadata     *CycloneDXMetadata    `json:"metadata,omitempty"`
+	Components   []CycloneDXComponent  `json:"components,omitempty"`
+	Dependencies []CycloneDXDependency `json:"dependencies,omitempty"`
+	Formulation  []CycloneDXFormula    `json:"formulation,omitempty"`
+	Extra        Extra                 `json:"-"`
+}
+
+type CycloneDXMetadata struct {
+	Timestamp string              `json:"timestamp,omitempty"`
+	Tools     *CycloneDXTools     `json:"tools,omitempty"`
+	Authors   []CycloneDXContact  `json:"authors,omitempty"`
+	Component *CycloneDXComponent `json:"component,omitempty"`
+	// Manufacturer is the organization that created the BOM, since CycloneDX 1.6
+	Manufacturer *CycloneDXOrganization `json:"manufacturer,omitempty"`
+	Extra        Extra                  `json:"-"`
+}
+
+// CycloneDXTools are the tools that created the BOM. The legacy array of tools is read
+// as components, the tools are always written in the object form.
+type CycloneDXTools struct {
+	Components []CycloneDXComponent `json:"components,omitempty"`
+	Extra      Extra                `json:"-"`
+}
+
+type CycloneDXContact struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+type CycloneDXComponent struct {
+	BOMRef             string                       `json:"bom-ref,omitempty"`
+	Type               string                       `json:"type"`
+	Supplier           *CycloneDXOrganization       `json:"supplier,omitempty"`
+	Author             string                       `json:"author,omitempty"`
+	Group              string                       `json:"group,omitempty"`
+	Name               string                       `json:"name"`
+	Version            string                       `json:"version,omitempty"`
+	Description        string                       `json:"description,omitempty"`
+	Hashes             []CycloneDXHash              `json:"hashes,omitempty"`
+	Licenses           []CycloneDXLicenseChoice     `json:"licenses,omitempty"`
+	Copyright          string                       `json:"copyright,omitempty"`
+	CPE                string                       `json:"cpe,omitempty"`
+	Purl               string                       `json:"purl,omitempty"`
+	ExternalReferences []CycloneDXExternalReference `json:"externalReferences,omitempty"`
+	Properties         []CycloneDXProperty          `json:"properties,omitempty"`
+	Components         []CycloneDXComponent         `json:"components,omitempty"`
+	Pedigree           *CycloneDXPedigree           `json:"pedigree,omitempty"`
+	Extra              Extra                        `json:"-"`
+}
+
+type CycloneDXOrganization struct {
+	Name  string   `json:"name,omitempty"`
+	URL   []string `json:"url,omitempty"`
+	Extra Extra    `json:"-"`
+}
+
+type CycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+// CycloneDXLicenseChoice is either a license or an SPDX license expression.
+type CycloneDXLicenseChoice struct {
+	License    *CycloneDXLicense `json:"license,omitempty"`
+	Expression string            `json:"expression,omitempty"`
+	// Acknowledgement of the expression, declared or concluded, since CycloneDX 1.6
+	Acknowledgement string `json:"acknowledgement,omitempty"`
+}
+
+type CycloneDXLicense struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+	// Acknowledgement of the license, declared or concluded, since CycloneDX 1.6
+	Acknowledgement string `json:"acknowledgement,omitempty"`
+	Extra           Extra  `json:"-"`
+}
+
+type CycloneDXExternalReference struct {
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Comment string `json:"comment,omitempty"`
+	Extra   Extra  `json:"-"`
+}
+
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+type CycloneDXPedigree struct {
+	Ancestors []CycloneDXComponent `json:"ancestors,omitempty"`
+	Extra     Extra                `json:"-"`
+}
+
+// CycloneDXFormula describes how the components of the BOM were built.
+// Workflows and other fields of the formula are kept in Extra.
+type CycloneDXFormula struct {
+	BOMRef     string               `json:"bom-ref,omitempty"`
+	Components []CycloneDXComponent `json:"components,omitempty"`
+	Extra      Extra                `json:"-"`
+}
+
+type CycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+func (d *CycloneDXDocument) UnmarshalJSON(data []byte) error {
+	type plain CycloneDXDocument
+	return unmarshalWithExtra(data, (*plain)(d), &d.Extra)
+}
+
+func (d CycloneDXDocument) MarshalJSON() ([]byte, error) {
+	type plain CycloneDXDocument
+	return marshalWithExtra(plain(d), d.Extra)
+}
+
+func (m *CycloneDXMetadata) UnmarshalJSON(data []byte) error {
+	type plain CycloneDXMetadata
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m CycloneDXMetadata) MarshalJSON() ([]byte, error) {
+	type plain CycloneDXMetadata
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (t *CycloneDXTools) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var legacy []struct {
+			Vendor  string          `json:"vendor"`
+			Name    string          `json:"name"`
+			Version string          `json:"version"`
+			Hashes  []CycloneDXHash `json:"hashes"`
+		}
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return err
+		}
+		*t = CycloneDXTools{}
+		for _, tool := range legacy {
+			component := CycloneDXComponent{Type: CycloneDXComponentTypeApplication, Name: tool.Name, Version: tool.Version, Hashes: tool.Hashes}
+			if tool.Vendor != "" {
+				component.Supplier = &CycloneDXOrganization{Name: tool.Vendor}
+			}
+			t.Components = append(t.Components, component)
+		}
+		return nil
+	}
+	type plain CycloneDXTools
+	return unmarshalWithExtra(data, (*plain)(t), &t.Extra)
+}
+
+func (t CycloneDXTools) MarshalJSON() ([]byte, error) {
+	type plain CycloneDXTools
+	return marshalWithExtra(plain(t), t.Extra)
+}
+
+func (c *CycloneDXComponent) UnmarshalJSON(data []byte) error {
+	type plain CycloneDXComponent
+	return unmarshalWithExtra(data, (*plain)(c), &c.Extra)
+}
+
+func (c CycloneDXComponent) MarshalJSON() ([]byte, error) {
+	type plain CycloneDXComponent
+	return marshalWithExtra(plain(c), c.Extra)
+}
+
+func (o *CycloneDXOrganization) UnmarshalJSON(data []byte) error {
+	type plain CycloneDXOrganization
+	return unmarshalWithExtra(data, (*plain)(o), &o.Extra)
+}
+
+func (o CycloneDXOrganization) MarshalJSON() ([]byte, error) {
+	type plain CycloneDXOrganization
+	return marshalWithExtra(plain(o), o.Extra)
+}
+
+func (l *CycloneDXLicense) UnmarshalJSON(data []byte) error {
+	type plain CycloneDXLicense
+	return unmarshalWithExtra(data, (*plain)(l), &l.Extra)
+}
+
+func (l CycloneDXLicense) MarshalJSON() ([]byte, error) {
+	type plain CycloneDXLicense
+	return marshalWithExtra(plain(l), l.Extra)
+}
+
+func (r *CycloneDXExternalReference) UnmarshalJSON(data []byte) error {
+	type plain CycloneDXExternalReference
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r CycloneDXExternalReference) MarshalJSON() ([]byte, error) {
+	type plain CycloneDXExternalReference
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+func (p *CycloneDXPedigree) UnmarshalJSON(data []byte) error {
+	type plain CycloneDXPedigree
+	return unmarshalWithExtra(data, (*plain)(p), &p.Extra)
+}
+
+func (p CycloneDXPedigree) MarshalJSON() ([]byte, error) {
+	type plain CycloneDXPedigree
+	return marshalWithExtra(plain(p), p.Extra)
+}
+
+func (f *CycloneDXFormula) UnmarshalJSON(data []byte) error {
+	type plain CycloneDXFormula
+	return unmarshalWithExtra(data, (*plain)(f), &f.Extra)
+}
+
+func (f CycloneDXFormula) MarshalJSON() ([]byte, error) {
+	type plain CycloneDXFormula
+	return marshalWithExtra(plain(f), f.Extra)
+}
+
+// walkComponents calls fn for each component, including nested ones, depth first.
+func walkComponents(components []CycloneDXComponent, fn func(component *CycloneDXComponent)) {
+	for i := range components {
+		fn(&components[i])
+		walkComponents(components[i].Components, fn)
+	}
+}
+
+// Validate checks the format and version of the document and that dependencies refer to components of the document.
+func (d *CycloneDXDocument) Validate() error {
+	if d.BOMFormat != CycloneDXFormat {
+		return fmt.Errorf("bomFormat is '%s', expected %s", d.BOMFormat, CycloneDXFormat)
+	}
+	if !slices.Contains(CycloneDXSpecVersions, d.SpecVersion) {
+		return fmt.Errorf("unsupported CycloneDX version '%s', expected one of %v", d.SpecVersion, CycloneDXSpecVersions)
+	}
+
+	refs := map[string]bool{}
+	var duplicate string
+	addRef := func(component *CycloneDXComponent) {
+		if component.BOMRef == "" {
+			return
+		}
+		if refs[component.BOMRef] && duplicate == "" {
+			duplicate = component.BOMRef
+		}
+		refs[component.BOMRef] = true
+	}
+	if d.Metadata != nil && d.Metadata.Component != nil {
+		addRef(d.Metadata.Component)
+		walkComponents(d.Metadata.Component.Components, addRef)
+	}
+	walkComponents(d.Components, addRef)
+	for i := range d.Formulation {
+		walkComponents(d.Formulation[i].Components, addRef)
+	}
+	if duplicate != "" {
+		return fmt.Errorf("duplicate bom-ref '%s'", duplicate)
+	}
+
+	for _, dependency := range d.Dependencies {
+		if !refs[dependency.Ref] {
+			return fmt.Errorf("dependency refers to unknown component '%s'", dependency.Ref)
+		}
+		for _, ref := range dependency.DependsOn {
+			if !refs[ref] {
+				return fmt.Errorf("dependency of '%s' refers to unknown component '%s'", dependency.Ref, ref)
+			}
+		}
+	}
+	return nil
+}
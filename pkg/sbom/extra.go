@@ -0,0 +1,103 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Extra holds the JSON fields of an object that are not modeled by its Go type,
+// so they are kept when a document is read and written again.
+type Extra map[string]json.RawMessage
+
+// Keys returns the sorted names of the fields.
+func (e Extra) Keys() []string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+var jsonFieldNamesCache sync.Map
+
+// jsonFieldNames returns the JSON names of the fields of the struct type.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	if names, ok := jsonFieldNamesCache.Load(t); ok {
+		return names.(map[string]bool)
+	}
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+	jsonFieldNamesCache.Store(t, names)
+	return names
+}
+
+// unmarshalWithExtra decodes data into v, a pointer to a struct, and the unknown fields into extra.
+func unmarshalWithExtra(data []byte, v any, extra *Extra) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	known := jsonFieldNames(reflect.TypeOf(v).Elem())
+	for name := range fields {
+		if known[name] {
+			delete(fields, name)
+		}
+	}
+	*extra = nil
+	if len(fields) > 0 {
+		*extra = fields
+	}
+	return nil
+}
+
+// marshalWithExtra encodes v, a struct, followed by the extra fields in sorted order.
+// HTML characters are not escaped, as SBOM text fields often contain them.
+func marshalWithExtra(v any, extra Extra) ([]byte, error) {
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	data := bytes.TrimSuffix(encoded.Bytes(), []byte("\n"))
+	if len(extra) == 0 {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	separator := len(data) > 2
+	for _, key := range extra.Keys() {
+		if separator {
+			buf.WriteByte(',')
+		}
+		separator = true
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(extra[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,528 @@
+package sbom
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// MergeSPDX merges SPDX documents into a new document.
+//
+// The new document describes the roots of the first document. The roots of the other documents
+// are contained by the first root, so all their elements are kept with their relationships.
+// Packages with the same purl are merged into the first one, other colliding SPDXIDs get a suffix.
+// The base image, a digested image reference of the final build stage, is added as a package the first
+// root is DESCENDANT_OF. The other build images, e.g. of builder stages, are added as packages that are
+// BUILD_TOOL_OF the first root.
+func MergeSPDX(docs []*SPDXDocument, baseImage string, buildImages []string) (*SPDXDocument, error) {
+	if len(docs) == 0 {
+		return nil, errors.New("no SPDX documents to merge")
+	}
+
+	merged := &SPDXDocument{
+		SPDXVersion: SPDXVersion,
+		DataLicense: SPDXDataLicense,
+		SPDXID:      SPDXDocumentID,
+		Name:        docs[0].Name,
+		CreationInfo: SPDXCreationInfo{
+			Created:  now().Format(time.RFC3339),
+			Creators: []string{"Tool: " + toolName},
+		},
+	}
+	m := &spdxMerger{
+		doc:      merged,
+		usedIDs:  map[string]bool{SPDXDocumentID: true},
+		byPurl:   map[string]int{},
+		licenses: map[string]bool{},
+		relSeen:  map[SPDXRelationship]bool{},
+	}
+
+	var root string
+	var namespaces []string
+	for i, doc := range docs {
+		namespaces = append(namespaces, doc.DocumentNamespace)
+		for _, creator := range doc.CreationInfo.Creators {
+			if !slices.Contains(merged.CreationInfo.Creators, creator) {
+				merged.CreationInfo.Creators = append(merged.CreationInfo.Creators, creator)
+			}
+		}
+
+		roots := m.add(doc)
+		if i == 0 {
+			if len(roots) == 0 {
+				return nil, errors.New("the first SPDX document describes no element")
+			}
+			root = roots[0]
+			for _, docRoot := range roots {
+				m.relate(SPDXDocumentID, SPDXRelationshipDescribes, docRoot)
+			}
+			continue
+		}
+		for _, docRoot := range roots {
+			m.relate(root, SPDXRelationshipContains, docRoot)
+		}
+		if len(doc.Extra) > 0 {
+			l.Logger.Warnf("Dropping document fields %s of SPDX document %s", strings.Join(doc.Extra.Keys(), ", "), doc.Name)
+		}
+	}
+	merged.Extra = docs[0].Extra
+
+	baseImageID := ""
+	if baseImage != "" {
+		baseImageID = m.addBaseImage(baseImage)
+		m.relate(root, SPDXRelationshipDescendantOf, baseImageID)
+	}
+	for _, buildImage := range buildImages {
+		if id := m.addBaseImage(buildImage); id != baseImageID {
+			m.relate(id, SPDXRelationshipBuildToolOf, root)
+		}
+	}
+
+	merged.DocumentNamespace = mergedNamespace(docs[0].DocumentNamespace, namespaces)
+	if err := merged.Validate(); err != nil {
+		return nil, fmt.Errorf("merged SPDX document is invalid: %w", err)
+	}
+	return merged, nil
+}
+
+type spdxMerger struct {
+	doc      *SPDXDocument
+	usedIDs  map[string]bool
+	byPurl   map[string]int
+	licenses map[string]bool
+	relSeen  map[SPDXRelationship]bool
+}
+
+// add adds the elements and relationships of the document, except the ones describing its roots,
+// and returns its roots with the IDs in the merged document.
+func (m *spdxMerger) add(doc *SPDXDocument) []string {
+	ids := map[string]string{doc.SPDXID: SPDXDocumentID}
+	mapID := func(id string) string {
+		if mapped, ok := ids[id]; ok {
+			return mapped
+		}
+		return id
+	}
+
+	firstPackage := len(m.doc.Packages)
+	for _, pkg := range doc.Packages {
+		if existing := m.findPackage(pkg.Purls()); existing >= 0 {
+			ids[pkg.SPDXID] = m.doc.Packages[existing].SPDXID
+			mergeSPDXPackage(&m.doc.Packages[existing], pkg)
+			continue
+		}
+		id := uniqueID(pkg.SPDXID, m.usedIDs)
+		ids[pkg.SPDXID] = id
+		pkg.SPDXID = id
+		m.doc.Packages = append(m.doc.Packages, pkg)
+		for _, purl := range pkg.Purls() {
+			m.byPurl[normalizePurl(purl)] = len(m.doc.Packages) - 1
+		}
+	}
+
+	for _, file := range doc.Files {
+		id := uniqueID(file.SPDXID, m.usedIDs)
+		ids[file.SPDXID] = id
+		file.SPDXID = id
+		m.doc.Files = append(m.doc.Files, file)
+	}
+
+	for i := firstPackage; i < len(m.doc.Packages); i++ {
+		pkg := &m.doc.Packages[i]
+		hasFiles := make([]string, 0, len(pkg.HasFiles))
+		for _, id := range pkg.HasFiles {
+			hasFiles = append(hasFiles, mapID(id))
+		}
+		if len(pkg.HasFiles) > 0 {
+			pkg.HasFiles = hasFiles
+		}
+	}
+
+	for _, license := range doc.HasExtractedLicensingInfos {
+		if m.licenses[license.LicenseID] {
+			continue
+		}
+		m.licenses[license.LicenseID] = true
+		m.doc.HasExtractedLicensingInfos = append(m.doc.HasExtractedLicensingInfos, license)
+	}
+
+	for _, rel := range doc.Relationships {
+		if rel.SPDXElementID == doc.SPDXID && rel.RelationshipType == SPDXRelationshipDescribes ||
+			rel.RelatedSPDXElement == doc.SPDXID && rel.RelationshipType == SPDXRelationshipDescribedBy {
+			continue
+		}
+		rel.SPDXElementID = mapID(rel.SPDXElementID)
+		rel.RelatedSPDXElement = mapID(rel.RelatedSPDXElement)
+		m.addRelationship(rel)
+	}
+
+	var roots []string
+	for _, root := range doc.Roots() {
+		if mapped := mapID(root); !slices.Contains(roots, mapped) {
+			roots = append(roots, mapped)
+		}
+	}
+	return roots
+}
+
+// findPackage returns the index of the package with any of the purls, or -1.
+func (m *spdxMerger) findPackage(purls []string) int {
+	for _, purl := range purls {
+		if index, ok := m.byPurl[normalizePurl(purl)]; ok {
+			return index
+		}
+	}
+	return -1
+}
+
+func (m *spdxMerger) addBaseImage(imageRef string) string {
+	purl := baseImagePurl(imageRef)
+	if existing := m.findPackage([]string{purl}); existing >= 0 {
+		return m.doc.Packages[existing].SPDXID
+	}
+	pkg := SPDXPackage{
+		SPDXID:           uniqueID("SPDXRef-BaseImage-"+spdxIDSuffix(common.GetImageName(imageRef)), m.usedIDs),
+		Name:             common.GetImageName(imageRef),
+		VersionInfo:      common.GetImageTag(imageRef),
+		DownloadLocation: SPDXNoAssertion,
+		ExternalRefs: []SPDXExternalRef{{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     spdxPurlReferenceType,
+			ReferenceLocator:  purl,
+		}},
+		PrimaryPackagePurpose: "CONTAINER",
+	}
+	m.doc.Packages = append(m.doc.Packages, pkg)
+	m.byPurl[normalizePurl(purl)] = len(m.doc.Packages) - 1
+	return pkg.SPDXID
+}
+
+func (m *spdxMerger) relate(from, relationshipType, to string) {
+	m.addRelationship(SPDXRelationship{SPDXElementID: from, RelationshipType: relationshipType, RelatedSPDXElement: to})
+}
+
+// addRelationship adds the relationship unless it is a duplicate, or relates a package to itself
+// after merging packages.
+func (m *spdxMerger) addRelationship(rel SPDXRelationship) {
+	if rel.SPDXElementID == rel.RelatedSPDXElement || m.relSeen[rel] {
+		return
+	}
+	m.relSeen[rel] = true
+	m.doc.Relationships = append(m.doc.Relationships, rel)
+}
+
+// mergeSPDXPackage adds the checksums and external references of the duplicate package.
+func mergeSPDXPackage(pkg *SPDXPackage, duplicate SPDXPackage) {
+	for _, checksum := range duplicate.Checksums {
+		if !slices.Contains(pkg.Checksums, checksum) {
+			pkg.Checksums = append(pkg.Checksums, checksum)
+		}
+	}
+	for _, ref := range duplicate.ExternalRefs {
+		if !slices.Contains(pkg.ExternalRefs, ref) {
+			pkg.ExternalRefs = append(pkg.ExternalRefs, ref)
+		}
+	}
+}
+
+// spdxIDSuffix replaces the characters not allowed in SPDXIDs.
+func spdxIDSuffix(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, s)
+}
+
+// mergedNamespace derives a unique namespace of the merged document from the namespaces of the merged documents.
+func mergedNamespace(base string, namespaces []string) string {
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(strings.Join(namespaces, "\n")))
+	if base == "" {
+		base = spdxNamespaceBase + "/merged"
+	}
+	return strings.TrimSuffix(base, "/") + "/merged-" + id.String()
+}
+
+// MergeCycloneDX merges CycloneDX documents into a new document.
+//
+// The metadata component of the first document is the component of the new document. The metadata
+// components of the other documents are added as components it depends on. If a document has no metadata
+// component, the component of the new document depends on its top-level components. Components with the same
+// purl are merged into the first one, other colliding bom-refs get a suffix. The base image, a digested
+// image reference of the final build stage, is added to the pedigree ancestors of the metadata component.
+// The other build images, e.g. of builder stages, are added as components of a new formula.
+// Nested components of the documents may be modified.
+func MergeCycloneDX(docs []*CycloneDXDocument, baseImage string, buildImages []string) (*CycloneDXDocument, error) {
+	if len(docs) == 0 {
+		return nil, errors.New("no CycloneDX documents to merge")
+	}
+	if docs[0].Metadata == nil || docs[0].Metadata.Component == nil {
+		return nil, errors.New("the first CycloneDX document has no metadata component")
+	}
+
+	specVersion := docs[0].SpecVersion
+	var serialNumbers []string
+	for _, doc := range docs {
+		serialNumbers = append(serialNumbers, doc.SerialNumber)
+		if doc.SpecVersion > specVersion {
+			specVersion = doc.SpecVersion
+		}
+	}
+
+	metadata := *docs[0].Metadata
+	metadata.Timestamp = now().Format(time.RFC3339)
+	root := *metadata.Component
+	metadata.Component = &root
+
+	merged := &CycloneDXDocument{
+		BOMFormat:    CycloneDXFormat,
+		SpecVersion:  specVersion,
+		SerialNumber: "urn:uuid:" + uuid.NewSHA1(uuid.NameSpaceURL, []byte(strings.Join(serialNumbers, "\n"))).String(),
+		Version:      1,
+		Metadata:     &metadata,
+		Formulation:  slices.Clone(docs[0].Formulation),
+		Extra:        docs[0].Extra,
+	}
+	if docs[0].Schema != "" {
+		merged.Schema = strings.Replace(docs[0].Schema, docs[0].SpecVersion, specVersion, 1)
+	}
+
+	m := &cycloneDXMerger{
+		doc:       merged,
+		usedRefs:  map[string]bool{},
+		byPurl:    map[string]int{},
+		depByRef:  map[string]int{},
+		rootRef:   root.BOMRef,
+		rootPurls: map[string]bool{},
+	}
+	m.reserveRefs(&root)
+	if root.Purl != "" {
+		m.rootPurls[normalizePurl(root.Purl)] = true
+	}
+
+	for i, doc := range docs {
+		refs := map[string]string{}
+		if i == 0 {
+			// The root keeps its bom-ref
+			m.reserveNestedRefs(root.Components)
+			for _, formula := range merged.Formulation {
+				m.reserveNestedRefs(formula.Components)
+			}
+		} else {
+			if doc.Metadata != nil && doc.Metadata.Component != nil {
+				m.addRootDependency(m.addComponent(*doc.Metadata.Component, refs))
+			}
+			dropped := doc.Extra.Keys()
+			if len(doc.Formulation) > 0 {
+				dropped = append(dropped, "formulation")
+			}
+			if len(dropped) > 0 {
+				l.Logger.Warnf("Dropping document fields %s of CycloneDX document %s", strings.Join(dropped, ", "), doc.SerialNumber)
+			}
+		}
+		// Without a metadata component, e.g. in Hermeto SBOMs, the root depends on the components directly
+		rootDependsOnComponents := i > 0 && (doc.Metadata == nil || doc.Metadata.Component == nil)
+		for _, component := range doc.Components {
+			if rootDependsOnComponents && component.BOMRef == "" {
+				component.BOMRef = componentRef(component)
+			}
+			ref := m.addComponent(component, refs)
+			if rootDependsOnComponents {
+				m.addRootDependency(ref)
+			}
+		}
+		for _, dependency := range doc.Dependencies {
+			dependency.Ref = mapRef(refs, dependency.Ref)
+			dependsOn := make([]string, 0, len(dependency.DependsOn))
+			for _, ref := range dependency.DependsOn {
+				dependsOn = append(dependsOn, mapRef(refs, ref))
+			}
+			dependency.DependsOn = dependsOn
+			m.addDependency(dependency)
+		}
+	}
+
+	if baseImage != "" {
+		pedigree := CycloneDXPedigree{}
+		if root.Pedigree != nil {
+			pedigree = *root.Pedigree
+		}
+		root.Pedigree = &pedigree
+		m.addAncestor(baseImage)
+	}
+	var formula CycloneDXFormula
+	seen := map[string]bool{}
+	if baseImage != "" {
+		seen[normalizePurl(baseImagePurl(baseImage))] = true
+	}
+	for _, buildImage := range buildImages {
+		if purl := normalizePurl(baseImagePurl(buildImage)); !seen[purl] {
+			seen[purl] = true
+			formula.Components = append(formula.Components, m.imageComponent(buildImage))
+		}
+	}
+	if len(formula.Components) > 0 {
+		merged.Formulation = append(merged.Formulation, formula)
+	}
+
+	if err := merged.Validate(); err != nil {
+		return nil, fmt.Errorf("merged CycloneDX document is invalid: %w", err)
+	}
+	return merged, nil
+}
+
+type cycloneDXMerger struct {
+	doc       *CycloneDXDocument
+	usedRefs  map[string]bool
+	byPurl    map[string]int
+	depByRef  map[string]int
+	rootRef   string
+	rootPurls map[string]bool
+}
+
+func mapRef(refs map[string]string, ref string) string {
+	if mapped, ok := refs[ref]; ok {
+		return mapped
+	}
+	return ref
+}
+
+func (m *cycloneDXMerger) reserveRefs(component *CycloneDXComponent) {
+	if component.BOMRef != "" {
+		m.usedRefs[component.BOMRef] = true
+	}
+}
+
+func (m *cycloneDXMerger) reserveNestedRefs(components []CycloneDXComponent) {
+	walkComponents(components, m.reserveRefs)
+}
+
+// addComponent adds the top-level component, or merges it into the component with the same purl,
+// records the bom-ref mapping in refs and returns the bom-ref of the component in the merged document.
+func (m *cycloneDXMerger) addComponent(component CycloneDXComponent, refs map[string]string) string {
+	if component.Purl != "" {
+		purl := normalizePurl(component.Purl)
+		if m.rootPurls[purl] {
+			refs[component.BOMRef] = m.rootRef
+			return m.rootRef
+		}
+		if existing, ok := m.byPurl[purl]; ok {
+			merged := &m.doc.Components[existing]
+			refs[component.BOMRef] = merged.BOMRef
+			mergeCycloneDXComponent(merged, component)
+			// Nested components are kept, so dependencies on them stay valid
+			merged.Components = append(merged.Components, m.renameRefs(component.Components, refs)...)
+			return merged.BOMRef
+		}
+	}
+
+	component = m.renameRefs([]CycloneDXComponent{component}, refs)[0]
+	m.doc.Components = append(m.doc.Components, component)
+	if component.Purl != "" {
+		m.byPurl[normalizePurl(component.Purl)] = len(m.doc.Components) - 1
+	}
+	return component.BOMRef
+}
+
+// renameRefs gives the components and their nested components bom-refs that are not used yet
+// and records the mapping in refs.
+func (m *cycloneDXMerger) renameRefs(components []CycloneDXComponent, refs map[string]string) []CycloneDXComponent {
+	walkComponents(components, func(c *CycloneDXComponent) {
+		if c.BOMRef == "" {
+			return
+		}
+		ref := uniqueID(c.BOMRef, m.usedRefs)
+		refs[c.BOMRef] = ref
+		c.BOMRef = ref
+	})
+	return components
+}
+
+// addRootDependency makes the metadata component of the merged document depend on the component.
+func (m *cycloneDXMerger) addRootDependency(ref string) {
+	if ref != "" && m.rootRef != "" && ref != m.rootRef {
+		m.addDependency(CycloneDXDependency{Ref: m.rootRef, DependsOn: []string{ref}})
+	}
+}
+
+// componentRef returns bom-ref for a component without one, its purl or name and version.
+func componentRef(component CycloneDXComponent) string {
+	if component.Purl != "" {
+		return component.Purl
+	}
+	if component.Version != "" {
+		return component.Name + "@" + component.Version
+	}
+	return component.Name
+}
+
+func (m *cycloneDXMerger) addDependency(dependency CycloneDXDependency) {
+	index, ok := m.depByRef[dependency.Ref]
+	if !ok {
+		m.doc.Dependencies = append(m.doc.Dependencies, CycloneDXDependency{Ref: dependency.Ref})
+		index = len(m.doc.Dependencies) - 1
+		m.depByRef[dependency.Ref] = index
+	}
+	existing := &m.doc.Dependencies[index]
+	for _, ref := range dependency.DependsOn {
+		if ref != dependency.Ref && !slices.Contains(existing.DependsOn, ref) {
+			existing.DependsOn = append(existing.DependsOn, ref)
+		}
+	}
+}
+
+func (m *cycloneDXMerger) addAncestor(imageRef string) {
+	pedigree := m.doc.Metadata.Component.Pedigree
+	purl := baseImagePurl(imageRef)
+	for _, ancestor := range pedigree.Ancestors {
+		if ancestor.Purl != "" && normalizePurl(ancestor.Purl) == normalizePurl(purl) {
+			return
+		}
+	}
+	pedigree.Ancestors = append(pedigree.Ancestors, m.imageComponent(imageRef))
+}
+
+// imageComponent returns container component of the digested image reference with a unique bom-ref.
+func (m *cycloneDXMerger) imageComponent(imageRef string) CycloneDXComponent {
+	purl := baseImagePurl(imageRef)
+	return CycloneDXComponent{
+		BOMRef:  uniqueID(purl, m.usedRefs),
+		Type:    CycloneDXComponentTypeContainer,
+		Name:    common.GetImageName(imageRef),
+		Version: common.GetImageTag(imageRef),
+		Purl:    purl,
+		Hashes: []CycloneDXHash{{
+			Algorithm: "SHA-256",
+			Content:   strings.TrimPrefix(common.GetImageDigest(imageRef), "sha256:"),
+		}},
+	}
+}
+
+// mergeCycloneDXComponent adds the hashes, external references and properties of the duplicate component.
+func mergeCycloneDXComponent(component *CycloneDXComponent, duplicate CycloneDXComponent) {
+	for _, hash := range duplicate.Hashes {
+		if !slices.Contains(component.Hashes, hash) {
+			component.Hashes = append(component.Hashes, hash)
+		}
+	}
+	for _, ref := range duplicate.ExternalReferences {
+		if !slices.ContainsFunc(component.ExternalReferences, func(r CycloneDXExternalReference) bool {
+			return r.Type == ref.Type && r.URL == ref.URL
+		}) {
+			component.ExternalReferences = append(component.ExternalReferences, ref)
+		}
+	}
+	for _, property := range duplicate.Properties {
+		if !slices.Contains(component.Properties, property) {
+			component.Properties = append(component.Properties, property)
+		}
+	}
+}
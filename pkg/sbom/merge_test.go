@@ -0,0 +1,342 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+const (
+	testDigest    = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	testBaseImage = "registry.io/org/ubi9:latest@" + testDigest
+	// Image of a builder stage
+	testBuildImage = "registry.io/org/go-toolset:1.22@sha256:0000000000000000000000000000000000000000000000000000000000000001"
+)
+
+func init() {
+	now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+}
+
+func purlRef(purl string) []SPDXExternalRef {
+	return []SPDXExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl}}
+}
+
+func imageSPDX() *SPDXDocument {
+	return &SPDXDocument{
+		SPDXVersion:       SPDXVersion,
+		DataLicense:       SPDXDataLicense,
+		SPDXID:            SPDXDocumentID,
+		Name:              "quay.io/org/app",
+		DocumentNamespace: "https://anchore.com/syft/image/app-1",
+		CreationInfo:      SPDXCreationInfo{Created: "2026-01-01T00:00:00Z", Creators: []string{"Tool: syft-1.0.0"}},
+		Packages: []SPDXPackage{
+			{SPDXID: "SPDXRef-image", Name: "app", DownloadLocation: SPDXNoAssertion},
+			{SPDXID: "SPDXRef-Package-1", Name: "openssl", DownloadLocation: SPDXNoAssertion,
+				ExternalRefs: purlRef("pkg:rpm/redhat/openssl@3.0.7?arch=x86_64&distro=rhel-9")},
+			{SPDXID: "SPDXRef-Package-2", Name: "requests", DownloadLocation: SPDXNoAssertion,
+				ExternalRefs: purlRef("pkg:pypi/requests@2.31.0"),
+				HasFiles:     []string{"SPDXRef-File-1"}},
+		},
+		Files: []SPDXFile{{SPDXID: "SPDXRef-File-1", FileName: "/app/requirements.txt"}},
+		HasExtractedLicensingInfos: []SPDXExtractedLicense{
+			{LicenseID: "LicenseRef-custom", ExtractedText: "custom"},
+		},
+		Relationships: []SPDXRelationship{
+			{SPDXElementID: SPDXDocumentID, RelationshipType: SPDXRelationshipDescribes, RelatedSPDXElement: "SPDXRef-image"},
+			{SPDXElementID: "SPDXRef-image", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-Package-1"},
+			{SPDXElementID: "SPDXRef-image", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-Package-2"},
+		},
+		Extra: Extra{"comment": json.RawMessage(`"image SBOM"`)},
+	}
+}
+
+func prefetchSPDX() *SPDXDocument {
+	return &SPDXDocument{
+		SPDXVersion:       SPDXVersion,
+		DataLicense:       SPDXDataLicense,
+		SPDXID:            SPDXDocumentID,
+		Name:              "hermeto",
+		DocumentNamespace: "https://hermeto/prefetch",
+		CreationInfo:      SPDXCreationInfo{Created: "2026-01-01T00:00:00Z", Creators: []string{"Tool: hermeto", "Tool: syft-1.0.0"}},
+		DocumentDescribes: []string{"SPDXRef-DocumentRoot"},
+		Packages: []SPDXPackage{
+			{SPDXID: "SPDXRef-DocumentRoot", Name: "", DownloadLocation: SPDXNoAssertion},
+			// Same purl as SPDXRef-Package-2 of the image SBOM
+			{SPDXID: "SPDXRef-Package-a", Name: "requests", DownloadLocation: SPDXNoAssertion,
+				ExternalRefs: purlRef("pkg:pypi/requests@2.31.0"),
+				Checksums:    []SPDXChecksum{{Algorithm: "SHA256", ChecksumValue: "abc"}}},
+			// Colliding SPDXID
+			{SPDXID: "SPDXRef-Package-1", Name: "urllib3", DownloadLocation: SPDXNoAssertion,
+				ExternalRefs: purlRef("pkg:pypi/urllib3@2.0.0")},
+			// Same purl as SPDXRef-Package-1 of the image SBOM, with other qualifier order
+			{SPDXID: "SPDXRef-Package-c", Name: "openssl", DownloadLocation: SPDXNoAssertion,
+				ExternalRefs: purlRef("pkg:rpm/redhat/openssl@3.0.7?distro=rhel-9&arch=x86_64")},
+		},
+		HasExtractedLicensingInfos: []SPDXExtractedLicense{
+			{LicenseID: "LicenseRef-custom", ExtractedText: "custom"},
+		},
+		Relationships: []SPDXRelationship{
+			{SPDXElementID: "SPDXRef-DocumentRoot", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-Package-a"},
+			{SPDXElementID: "SPDXRef-DocumentRoot", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-Package-1"},
+			{SPDXElementID: "SPDXRef-Package-a", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-1"},
+			{SPDXElementID: "SPDXRef-DocumentRoot", RelationshipType: SPDXRelationshipContains, RelatedSPDXElement: "SPDXRef-Package-c"},
+		},
+		Extra: Extra{"comment": json.RawMessage(`"dropped"`)},
+	}
+}
+
+func findSPDXPackage(doc *SPDXDocument, id string) *SPDXPackage {
+	for i := range doc.Packages {
+		if doc.Packages[i].SPDXID == id {
+			return &doc.Packages[i]
+		}
+	}
+	return nil
+}
+
+func TestMergeSPDX(t *testing.T) {
+	g := NewWithT(t)
+
+	merged, err := MergeSPDX([]*SPDXDocument{imageSPDX(), prefetchSPDX()}, testBaseImage, []string{testBaseImage, testBuildImage})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(merged.Name).To(Equal("quay.io/org/app"))
+	g.Expect(merged.CreationInfo.Created).To(Equal("2026-01-02T03:04:05Z"))
+	g.Expect(merged.CreationInfo.Creators).To(Equal([]string{"Tool: konflux-build-cli", "Tool: syft-1.0.0", "Tool: hermeto"}))
+	g.Expect(merged.DocumentNamespace).To(HavePrefix("https://anchore.com/syft/image/app-1/merged-"))
+	g.Expect(merged.Extra).To(HaveKey("comment"))
+
+	var ids []string
+	for _, pkg := range merged.Packages {
+		ids = append(ids, pkg.SPDXID)
+	}
+	g.Expect(ids).To(Equal([]string{
+		"SPDXRef-image", "SPDXRef-Package-1", "SPDXRef-Package-2",
+		"SPDXRef-DocumentRoot", "SPDXRef-Package-1-2",
+		"SPDXRef-BaseImage-registry.io-org-ubi9", "SPDXRef-BaseImage-registry.io-org-go-toolset",
+	}))
+
+	// Duplicates are merged by purl
+	requests := findSPDXPackage(merged, "SPDXRef-Package-2")
+	g.Expect(requests.Checksums).To(Equal([]SPDXChecksum{{Algorithm: "SHA256", ChecksumValue: "abc"}}))
+	g.Expect(requests.HasFiles).To(Equal([]string{"SPDXRef-File-1"}))
+	g.Expect(findSPDXPackage(merged, "SPDXRef-Package-1").ExternalRefs).To(HaveLen(2))
+	g.Expect(merged.HasExtractedLicensingInfos).To(HaveLen(1))
+
+	baseImage := findSPDXPackage(merged, "SPDXRef-BaseImage-registry.io-org-ubi9")
+	g.Expect(baseImage.Purls()).To(Equal([]string{
+		"pkg:oci/ubi9@sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b?repository_url=registry.io%2Forg%2Fubi9&tag=latest",
+	}))
+	g.Expect(baseImage.PrimaryPackagePurpose).To(Equal("CONTAINER"))
+
+	g.Expect(merged.Relationships).To(ConsistOf(
+		SPDXRelationship{SPDXElementID: "SPDXRef-image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Package-1"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Package-2"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-image"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-DocumentRoot", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Package-2"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-DocumentRoot", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Package-1-2"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-Package-2", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-1-2"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-DocumentRoot", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Package-1"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-DocumentRoot"},
+		SPDXRelationship{SPDXElementID: "SPDXRef-image", RelationshipType: "DESCENDANT_OF", RelatedSPDXElement: "SPDXRef-BaseImage-registry.io-org-ubi9"},
+		// Only the base image of the final stage is an ancestor
+		SPDXRelationship{SPDXElementID: "SPDXRef-BaseImage-registry.io-org-go-toolset", RelationshipType: "BUILD_TOOL_OF", RelatedSPDXElement: "SPDXRef-image"},
+	))
+
+	// The merge is deterministic
+	again, err := MergeSPDX([]*SPDXDocument{imageSPDX(), prefetchSPDX()}, testBaseImage, []string{testBaseImage, testBuildImage})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(again).To(Equal(merged))
+}
+
+func TestMergeSPDX_errors(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := MergeSPDX(nil, "", nil)
+	g.Expect(err).To(MatchError(ContainSubstring("no SPDX documents")))
+
+	doc := imageSPDX()
+	doc.Relationships = nil
+	_, err = MergeSPDX([]*SPDXDocument{doc}, "", nil)
+	g.Expect(err).To(MatchError(ContainSubstring("describes no element")))
+}
+
+func imageCycloneDX() *CycloneDXDocument {
+	return &CycloneDXDocument{
+		Schema:       "http://cyclonedx.org/schema/bom-1.5.schema.json",
+		BOMFormat:    CycloneDXFormat,
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:1",
+		Version:      1,
+		Metadata: &CycloneDXMetadata{
+			Component: &CycloneDXComponent{BOMRef: "image", Type: "container", Name: "quay.io/org/app"},
+		},
+		Components: []CycloneDXComponent{
+			{BOMRef: "pkg:pypi/requests@2.31.0", Type: "library", Name: "requests", Purl: "pkg:pypi/requests@2.31.0"},
+			{BOMRef: "os", Type: "operating-system", Name: "rhel"},
+		},
+		Dependencies: []CycloneDXDependency{
+			{Ref: "image", DependsOn: []string{"pkg:pypi/requests@2.31.0", "os"}},
+		},
+	}
+}
+
+func sourceCycloneDX() *CycloneDXDocument {
+	return &CycloneDXDocument{
+		BOMFormat:    CycloneDXFormat,
+		SpecVersion:  "1.6",
+		SerialNumber: "urn:uuid:2",
+		Version:      1,
+		Metadata: &CycloneDXMetadata{
+			Component: &CycloneDXComponent{BOMRef: "source", Type: "application", Name: "source"},
+		},
+		Components: []CycloneDXComponent{
+			{BOMRef: "requests", Type: "library", Name: "requests", Purl: "pkg:pypi/requests@2.31.0",
+				Hashes: []CycloneDXHash{{Algorithm: "SHA-256", Content: "abc"}},
+				Components: []CycloneDXComponent{
+					{BOMRef: "requests-vendored", Type: "library", Name: "chardet"},
+				}},
+			// Colliding bom-ref
+			{BOMRef: "os", Type: "library", Name: "urllib3", Purl: "pkg:pypi/urllib3@2.0.0"},
+		},
+		Dependencies: []CycloneDXDependency{
+			{Ref: "source", DependsOn: []string{"requests", "os"}},
+			{Ref: "requests", DependsOn: []string{"os", "requests-vendored"}},
+		},
+		Extra: Extra{"annotations": json.RawMessage(`[]`)},
+	}
+}
+
+// prefetchCycloneDX returns SBOM in the format written by Hermeto, with no metadata component,
+// serial number, bom-refs or dependencies.
+func prefetchCycloneDX() *CycloneDXDocument {
+	doc := &CycloneDXDocument{}
+	err := json.Unmarshal([]byte(`{
+  "bomFormat": "CycloneDX",
+  "components": [
+    {
+      "name": "requests",
+      "properties": [{"name": "hermeto:found_by", "value": "hermeto"}],
+      "purl": "pkg:pypi/requests@2.31.0",
+      "type": "library",
+      "version": "2.31.0"
+    },
+    {
+      "name": "certifi",
+      "properties": [{"name": "hermeto:found_by", "value": "hermeto"}],
+      "purl": "pkg:pypi/certifi@2024.2.2",
+      "type": "library",
+      "version": "2024.2.2"
+    }
+  ],
+  "metadata": {
+    "tools": [{"vendor": "red hat", "name": "hermeto"}]
+  },
+  "specVersion": "1.6",
+  "version": 1
+}`), doc)
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+func TestMergeCycloneDX(t *testing.T) {
+	g := NewWithT(t)
+
+	image := imageCycloneDX()
+	image.Metadata.Component.Pedigree = &CycloneDXPedigree{Ancestors: []CycloneDXComponent{{Type: "container", Name: "existing"}}}
+	merged, err := MergeCycloneDX([]*CycloneDXDocument{image, sourceCycloneDX()}, testBaseImage, []string{testBaseImage, testBuildImage, testBuildImage})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(merged.SpecVersion).To(Equal("1.6"))
+	g.Expect(merged.Schema).To(Equal("http://cyclonedx.org/schema/bom-1.6.schema.json"))
+	g.Expect(merged.SerialNumber).To(HavePrefix("urn:uuid:"))
+	g.Expect(merged.SerialNumber).ToNot(Equal(image.SerialNumber))
+	g.Expect(merged.Metadata.Timestamp).To(Equal("2026-01-02T03:04:05Z"))
+	g.Expect(merged.Extra).To(BeEmpty())
+
+	var refs []string
+	for _, component := range merged.Components {
+		refs = append(refs, component.BOMRef)
+	}
+	g.Expect(refs).To(Equal([]string{"pkg:pypi/requests@2.31.0", "os", "source", "os-2"}))
+
+	// Duplicates are merged by purl with their nested components
+	requests := merged.Components[0]
+	g.Expect(requests.Hashes).To(Equal([]CycloneDXHash{{Algorithm: "SHA-256", Content: "abc"}}))
+	g.Expect(requests.Components).To(HaveLen(1))
+	g.Expect(requests.Components[0].BOMRef).To(Equal("requests-vendored"))
+
+	g.Expect(merged.Dependencies).To(Equal([]CycloneDXDependency{
+		{Ref: "image", DependsOn: []string{"pkg:pypi/requests@2.31.0", "os", "source"}},
+		{Ref: "source", DependsOn: []string{"pkg:pypi/requests@2.31.0", "os-2"}},
+		{Ref: "pkg:pypi/requests@2.31.0", DependsOn: []string{"os-2", "requests-vendored"}},
+	}))
+
+	// Base images are added once to the ancestors of the image, the input is not modified
+	ancestors := merged.Metadata.Component.Pedigree.Ancestors
+	g.Expect(ancestors).To(HaveLen(2))
+	g.Expect(ancestors[1].Type).To(Equal("container"))
+	g.Expect(ancestors[1].Name).To(Equal("registry.io/org/ubi9"))
+	g.Expect(ancestors[1].Version).To(Equal("latest"))
+	g.Expect(ancestors[1].Purl).To(HavePrefix("pkg:oci/ubi9@sha256:"))
+	g.Expect(ancestors[1].Hashes).To(Equal([]CycloneDXHash{{Algorithm: "SHA-256", Content: testDigest[len("sha256:"):]}}))
+	g.Expect(image.Metadata.Component.Pedigree.Ancestors).To(HaveLen(1))
+
+	// Other build images are added once to a formula
+	g.Expect(merged.Formulation).To(HaveLen(1))
+	g.Expect(merged.Formulation[0].Components).To(HaveLen(1))
+	g.Expect(merged.Formulation[0].Components[0].Name).To(Equal("registry.io/org/go-toolset"))
+	g.Expect(merged.Formulation[0].Components[0].Purl).To(HavePrefix("pkg:oci/go-toolset@sha256:"))
+	g.Expect(merged.Validate()).To(Succeed())
+}
+
+func TestMergeCycloneDX_rootPurl(t *testing.T) {
+	g := NewWithT(t)
+
+	image := imageCycloneDX()
+	image.Metadata.Component.Purl = "pkg:oci/app@" + testDigest
+	source := sourceCycloneDX()
+	source.Metadata.Component.Purl = "pkg:oci/app@" + testDigest
+
+	merged, err := MergeCycloneDX([]*CycloneDXDocument{image, source}, "", nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(merged.Dependencies[0]).To(Equal(CycloneDXDependency{
+		Ref: "image", DependsOn: []string{"pkg:pypi/requests@2.31.0", "os", "os-2"},
+	}))
+	g.Expect(merged.Metadata.Component.Pedigree).To(BeNil())
+}
+
+func TestMergeCycloneDX_noMetadataComponent(t *testing.T) {
+	g := NewWithT(t)
+
+	merged, err := MergeCycloneDX([]*CycloneDXDocument{imageCycloneDX(), prefetchCycloneDX()}, "", nil)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	var refs []string
+	for _, component := range merged.Components {
+		refs = append(refs, component.BOMRef)
+	}
+	g.Expect(refs).To(Equal([]string{"pkg:pypi/requests@2.31.0", "os", "pkg:pypi/certifi@2024.2.2"}))
+	g.Expect(merged.Components[0].Properties).To(Equal([]CycloneDXProperty{{Name: "hermeto:found_by", Value: "hermeto"}}))
+
+	// The image depends on the top-level components of the document directly
+	g.Expect(merged.Dependencies).To(Equal([]CycloneDXDependency{
+		{Ref: "image", DependsOn: []string{"pkg:pypi/requests@2.31.0", "os", "pkg:pypi/certifi@2024.2.2"}},
+	}))
+}
+
+func TestMergeCycloneDX_errors(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := MergeCycloneDX(nil, "", nil)
+	g.Expect(err).To(MatchError(ContainSubstring("no CycloneDX documents")))
+
+	doc := imageCycloneDX()
+	doc.Metadata = nil
+	_, err = MergeCycloneDX([]*CycloneDXDocument{doc}, "", nil)
+	g.Expect(err).To(MatchError(ContainSubstring("has no metadata component")))
+}
@@ -0,0 +1,138 @@
+// Package sbom reads, writes, merges and converts SPDX 2.3 and CycloneDX 1.5/1.6 JSON documents.
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+)
+
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// toolName is the creator of the documents written by this package.
+const toolName = "konflux-build-cli"
+
+// now returns the creation time of documents, replaced in tests.
+var now = func() time.Time { return time.Now().UTC() }
+
+// DetectFormat returns FormatSPDX or FormatCycloneDX for the JSON document.
+func DetectFormat(data []byte) (string, error) {
+	var probe struct {
+		SPDXVersion string `json:"spdxVersion"`
+		BOMFormat   string `json:"bomFormat"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return "", fmt.Errorf("not a JSON document: %w", err)
+	}
+	switch {
+	case probe.SPDXVersion != "":
+		return FormatSPDX, nil
+	case probe.BOMFormat == CycloneDXFormat:
+		return FormatCycloneDX, nil
+	}
+	return "", fmt.Errorf("neither an SPDX nor a CycloneDX document")
+}
+
+// ReadSPDX reads and validates an SPDX document.
+func ReadSPDX(path string) (*SPDXDocument, error) {
+	doc := &SPDXDocument{}
+	if err := readDocument(path, FormatSPDX, doc); err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid SPDX document %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// ReadCycloneDX reads and validates a CycloneDX document.
+func ReadCycloneDX(path string) (*CycloneDXDocument, error) {
+	doc := &CycloneDXDocument{}
+	if err := readDocument(path, FormatCycloneDX, doc); err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid CycloneDX document %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+func readDocument(path, format string, doc any) error {
+	data, err := os.ReadFile(path) //nolint:gosec // SBOM path from command arguments
+	if err != nil {
+		return fmt.Errorf("failed to read SBOM: %w", err)
+	}
+	detected, err := DetectFormat(data)
+	if err != nil {
+		return fmt.Errorf("failed to read SBOM %s: %w", path, err)
+	}
+	if detected != format {
+		return fmt.Errorf("SBOM %s is %s, expected %s", path, detected, format)
+	}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return fmt.Errorf("failed to parse SBOM %s: %w", path, err)
+	}
+	return nil
+}
+
+// WriteDocument writes the document as indented JSON.
+func WriteDocument(path string, doc any) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode SBOM: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write SBOM: %w", err)
+	}
+	return nil
+}
+
+// normalizePurl returns the canonical form of the purl, with sorted qualifiers,
+// so the same package is matched across documents. Invalid purls are returned as is.
+func normalizePurl(purl string) string {
+	parsed, err := packageurl.FromString(purl)
+	if err != nil {
+		return purl
+	}
+	slices.SortFunc(parsed.Qualifiers, func(a, b packageurl.Qualifier) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return parsed.ToString()
+}
+
+// baseImagePurl returns the oci purl of the digested image reference,
+// e.g. pkg:oci/ubi9@sha256:...?repository_url=registry.io%2Forg%2Fubi9&tag=latest
+func baseImagePurl(imageRef string) string {
+	repository := common.GetImageName(imageRef)
+	qualifiers := packageurl.Qualifiers{{Key: "repository_url", Value: repository}}
+	if tag := common.GetImageTag(imageRef); tag != "" {
+		qualifiers = append(qualifiers, packageurl.Qualifier{Key: "tag", Value: tag})
+	}
+	name := repository[strings.LastIndex(repository, "/")+1:]
+	return packageurl.NewPackageURL(packageurl.TypeOCI, "", name, common.GetImageDigest(imageRef), qualifiers, "").ToString()
+}
+
+// uniqueID returns id, or id with a numeric suffix if id is already used, and marks it as used.
+func uniqueID(id string, used map[string]bool) string {
+	unique := id
+	for n := 2; used[unique]; n++ {
+		unique = id + "-" + strconv.Itoa(n)
+	}
+	used[unique] = true
+	return unique
+}
@@ -0,0 +1,122 @@
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDetectFormat(t *testing.T) {
+	g := NewWithT(t)
+
+	format, err := DetectFormat([]byte(`{"spdxVersion": "SPDX-2.3"}`))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(format).To(Equal(FormatSPDX))
+
+	format, err = DetectFormat([]byte(`{"bomFormat": "CycloneDX"}`))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(format).To(Equal(FormatCycloneDX))
+
+	_, err = DetectFormat([]byte(`{"name": "other"}`))
+	g.Expect(err).To(MatchError(ContainSubstring("neither an SPDX nor a CycloneDX document")))
+
+	_, err = DetectFormat([]byte(`not json`))
+	g.Expect(err).To(MatchError(ContainSubstring("not a JSON document")))
+}
+
+func TestReadWriteDocument(t *testing.T) {
+	g := NewWithT(t)
+
+	input := `{
+  "spdxVersion": "SPDX-2.3",
+  "dataLicense": "CC0-1.0",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "app",
+  "documentNamespace": "https://example.com/app",
+  "creationInfo": {
+    "created": "2026-01-01T00:00:00Z",
+    "creators": [
+      "Tool: syft"
+    ],
+    "x-creation": true
+  },
+  "packages": [
+    {
+      "SPDXID": "SPDXRef-app",
+      "name": "app",
+      "downloadLocation": "NOASSERTION",
+      "sourceInfo": "acquired <from> source"
+    }
+  ],
+  "relationships": [
+    {
+      "spdxElementId": "SPDXRef-DOCUMENT",
+      "relationshipType": "DESCRIBES",
+      "relatedSpdxElement": "SPDXRef-app"
+    }
+  ],
+  "comment": "kept"
+}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sbom.json")
+	g.Expect(os.WriteFile(path, []byte(input), 0644)).To(Succeed())
+
+	doc, err := ReadSPDX(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(doc.Extra.Keys()).To(Equal([]string{"comment"}))
+	g.Expect(doc.CreationInfo.Extra.Keys()).To(Equal([]string{"x-creation"}))
+	g.Expect(doc.Packages[0].Extra.Keys()).To(Equal([]string{"sourceInfo"}))
+	g.Expect(doc.Roots()).To(Equal([]string{"SPDXRef-app"}))
+
+	// Unknown fields are written back
+	output := filepath.Join(dir, "output.json")
+	g.Expect(WriteDocument(output, doc)).To(Succeed())
+	written, err := os.ReadFile(output)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(written)).To(Equal(input))
+
+	_, err = ReadCycloneDX(path)
+	g.Expect(err).To(MatchError(ContainSubstring("is spdx, expected cyclonedx")))
+}
+
+func TestReadDocument_invalid(t *testing.T) {
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	spdxPath := filepath.Join(dir, "spdx.json")
+	g.Expect(os.WriteFile(spdxPath, []byte(`{"spdxVersion": "SPDX-2.2", "SPDXID": "SPDXRef-DOCUMENT"}`), 0644)).To(Succeed())
+	_, err := ReadSPDX(spdxPath)
+	g.Expect(err).To(MatchError(ContainSubstring("unsupported SPDX version 'SPDX-2.2'")))
+
+	g.Expect(os.WriteFile(spdxPath, []byte(`{"spdxVersion": "SPDX-2.3", "SPDXID": "SPDXRef-DOCUMENT",
+		"relationships": [{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-missing"}]}`), 0644)).To(Succeed())
+	_, err = ReadSPDX(spdxPath)
+	g.Expect(err).To(MatchError(ContainSubstring("unknown element 'SPDXRef-missing'")))
+
+	cdxPath := filepath.Join(dir, "cdx.json")
+	g.Expect(os.WriteFile(cdxPath, []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.4"}`), 0644)).To(Succeed())
+	_, err = ReadCycloneDX(cdxPath)
+	g.Expect(err).To(MatchError(ContainSubstring("unsupported CycloneDX version '1.4'")))
+
+	g.Expect(os.WriteFile(cdxPath, []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5",
+		"components": [{"bom-ref": "a", "type": "library", "name": "a"}, {"bom-ref": "a", "type": "library", "name": "b"}]}`), 0644)).To(Succeed())
+	_, err = ReadCycloneDX(cdxPath)
+	g.Expect(err).To(MatchError(ContainSubstring("duplicate bom-ref 'a'")))
+
+	g.Expect(os.WriteFile(cdxPath, []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5",
+		"components": [{"bom-ref": "a", "type": "library", "name": "a"}],
+		"formulation": [{"components": [{"bom-ref": "a", "type": "container", "name": "builder"}]}]}`), 0644)).To(Succeed())
+	_, err = ReadCycloneDX(cdxPath)
+	g.Expect(err).To(MatchError(ContainSubstring("duplicate bom-ref 'a'")))
+}
+
+func TestNormalizePurl(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(normalizePurl("pkg:rpm/redhat/openssl@3.0.7?distro=rhel-9&arch=x86_64")).
+		To(Equal(normalizePurl("pkg:rpm/redhat/openssl@3.0.7?arch=x86_64&distro=rhel-9")))
+	g.Expect(normalizePurl("not a purl")).To(Equal("not a purl"))
+}
@@ -0,0 +1,235 @@
+package sbom
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	SPDXVersion     = "SPDX-2.3"
+	SPDXDocumentID  = "SPDXRef-DOCUMENT"
+	SPDXDataLicense = "CC0-1.0"
+	SPDXNoAssertion = "NOASSERTION"
+
+	SPDXRelationshipDescribes    = "DESCRIBES"
+	SPDXRelationshipDescribedBy  = "DESCRIBED_BY"
+	SPDXRelationshipContains     = "CONTAINS"
+	SPDXRelationshipContainedBy  = "CONTAINED_BY"
+	SPDXRelationshipDependsOn    = "DEPENDS_ON"
+	SPDXRelationshipDependencyOf = "DEPENDENCY_OF"
+	SPDXRelationshipDescendantOf = "DESCENDANT_OF"
+	SPDXRelationshipAncestorOf   = "ANCESTOR_OF"
+	SPDXRelationshipBuildToolOf  = "BUILD_TOOL_OF"
+
+	spdxPurlReferenceType = "purl"
+
+	// spdxNamespaceBase is the base of the namespaces of the documents created by this package
+	spdxNamespaceBase = "https://konflux-ci.dev/spdxdocs"
+)
+
+// SPDXDocument is an SPDX 2.3 JSON document.
+// Fields that are not modeled are kept in Extra of the document and its elements.
+type SPDXDocument struct {
+	SPDXVersion                string                 `json:"spdxVersion"`
+	DataLicense                string                 `json:"dataLicense"`
+	SPDXID                     string                 `json:"SPDXID"`
+	Name                       string                 `json:"name"`
+	DocumentNamespace          string                 `json:"documentNamespace"`
+	CreationInfo               SPDXCreationInfo       `json:"creationInfo"`
+	DocumentDescribes          []string               `json:"documentDescribes,omitempty"`
+	Packages                   []SPDXPackage          `json:"packages,omitempty"`
+	Files                      []SPDXFile             `json:"files,omitempty"`
+	HasExtractedLicensingInfos []SPDXExtractedLicense `json:"hasExtractedLicensingInfos,omitempty"`
+	Relationships              []SPDXRelationship     `json:"relationships,omitempty"`
+	Extra                      Extra                  `json:"-"`
+}
+
+type SPDXCreationInfo struct {
+	Created            string   `json:"created"`
+	Creators           []string `json:"creators"`
+	LicenseListVersion string   `json:"licenseListVersion,omitempty"`
+	Comment            string   `json:"comment,omitempty"`
+	Extra              Extra    `json:"-"`
+}
+
+type SPDXPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	Supplier              string            `json:"supplier,omitempty"`
+	Originator            string            `json:"originator,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         *bool             `json:"filesAnalyzed,omitempty"`
+	Checksums             []SPDXChecksum    `json:"checksums,omitempty"`
+	Homepage              string            `json:"homepage,omitempty"`
+	LicenseConcluded      string            `json:"licenseConcluded,omitempty"`
+	LicenseDeclared       string            `json:"licenseDeclared,omitempty"`
+	CopyrightText         string            `json:"copyrightText,omitempty"`
+	Summary               string            `json:"summary,omitempty"`
+	Description           string            `json:"description,omitempty"`
+	ExternalRefs          []SPDXExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	HasFiles              []string          `json:"hasFiles,omitempty"`
+	Annotations           []SPDXAnnotation  `json:"annotations,omitempty"`
+	Extra                 Extra             `json:"-"`
+}
+
+type SPDXFile struct {
+	SPDXID    string         `json:"SPDXID"`
+	FileName  string         `json:"fileName"`
+	Checksums []SPDXChecksum `json:"checksums,omitempty"`
+	Extra     Extra          `json:"-"`
+}
+
+type SPDXChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+	Comment           string `json:"comment,omitempty"`
+}
+
+type SPDXAnnotation struct {
+	Annotator      string `json:"annotator"`
+	AnnotationDate string `json:"annotationDate"`
+	AnnotationType string `json:"annotationType"`
+	Comment        string `json:"comment"`
+}
+
+type SPDXExtractedLicense struct {
+	LicenseID     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name,omitempty"`
+	Extra         Extra  `json:"-"`
+}
+
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+	Comment            string `json:"comment,omitempty"`
+}
+
+func (d *SPDXDocument) UnmarshalJSON(data []byte) error {
+	type plain SPDXDocument
+	return unmarshalWithExtra(data, (*plain)(d), &d.Extra)
+}
+
+func (d SPDXDocument) MarshalJSON() ([]byte, error) {
+	type plain SPDXDocument
+	return marshalWithExtra(plain(d), d.Extra)
+}
+
+func (c *SPDXCreationInfo) UnmarshalJSON(data []byte) error {
+	type plain SPDXCreationInfo
+	return unmarshalWithExtra(data, (*plain)(c), &c.Extra)
+}
+
+func (c SPDXCreationInfo) MarshalJSON() ([]byte, error) {
+	type plain SPDXCreationInfo
+	return marshalWithExtra(plain(c), c.Extra)
+}
+
+func (p *SPDXPackage) UnmarshalJSON(data []byte) error {
+	type plain SPDXPackage
+	return unmarshalWithExtra(data, (*plain)(p), &p.Extra)
+}
+
+func (p SPDXPackage) MarshalJSON() ([]byte, error) {
+	type plain SPDXPackage
+	return marshalWithExtra(plain(p), p.Extra)
+}
+
+func (f *SPDXFile) UnmarshalJSON(data []byte) error {
+	type plain SPDXFile
+	return unmarshalWithExtra(data, (*plain)(f), &f.Extra)
+}
+
+func (f SPDXFile) MarshalJSON() ([]byte, error) {
+	type plain SPDXFile
+	return marshalWithExtra(plain(f), f.Extra)
+}
+
+func (l *SPDXExtractedLicense) UnmarshalJSON(data []byte) error {
+	type plain SPDXExtractedLicense
+	return unmarshalWithExtra(data, (*plain)(l), &l.Extra)
+}
+
+func (l SPDXExtractedLicense) MarshalJSON() ([]byte, error) {
+	type plain SPDXExtractedLicense
+	return marshalWithExtra(plain(l), l.Extra)
+}
+
+// Purls returns the purls of the package external references.
+func (p *SPDXPackage) Purls() []string {
+	var purls []string
+	for _, ref := range p.ExternalRefs {
+		if ref.ReferenceType == spdxPurlReferenceType {
+			purls = append(purls, ref.ReferenceLocator)
+		}
+	}
+	return purls
+}
+
+// Roots returns the IDs of the elements the document describes.
+func (d *SPDXDocument) Roots() []string {
+	roots := append([]string{}, d.DocumentDescribes...)
+	for _, rel := range d.Relationships {
+		root := ""
+		switch {
+		case rel.SPDXElementID == d.SPDXID && rel.RelationshipType == SPDXRelationshipDescribes:
+			root = rel.RelatedSPDXElement
+		case rel.RelatedSPDXElement == d.SPDXID && rel.RelationshipType == SPDXRelationshipDescribedBy:
+			root = rel.SPDXElementID
+		}
+		if root != "" && !slices.Contains(roots, root) {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// Validate checks the document version and that relationships refer to elements of the document.
+func (d *SPDXDocument) Validate() error {
+	if d.SPDXVersion != SPDXVersion {
+		return fmt.Errorf("unsupported SPDX version '%s', expected %s", d.SPDXVersion, SPDXVersion)
+	}
+	if d.SPDXID != SPDXDocumentID {
+		return fmt.Errorf("document SPDXID is '%s', expected %s", d.SPDXID, SPDXDocumentID)
+	}
+	ids := map[string]bool{d.SPDXID: true}
+	for _, pkg := range d.Packages {
+		if ids[pkg.SPDXID] {
+			return fmt.Errorf("duplicate SPDXID '%s'", pkg.SPDXID)
+		}
+		ids[pkg.SPDXID] = true
+	}
+	for _, file := range d.Files {
+		if ids[file.SPDXID] {
+			return fmt.Errorf("duplicate SPDXID '%s'", file.SPDXID)
+		}
+		ids[file.SPDXID] = true
+	}
+	isKnown := func(id string) bool {
+		return ids[id] || id == SPDXNoAssertion || id == "NONE" || isExternalSPDXID(id)
+	}
+	for _, rel := range d.Relationships {
+		if !isKnown(rel.SPDXElementID) {
+			return fmt.Errorf("relationship %s refers to unknown element '%s'", rel.RelationshipType, rel.SPDXElementID)
+		}
+		if !isKnown(rel.RelatedSPDXElement) {
+			return fmt.Errorf("relationship %s refers to unknown element '%s'", rel.RelationshipType, rel.RelatedSPDXElement)
+		}
+	}
+	return nil
+}
+
+// isExternalSPDXID reports whether the ID refers to an element of another document, DocumentRef-<doc>:SPDXRef-<id>.
+func isExternalSPDXID(id string) bool {
+	return strings.HasPrefix(id, "DocumentRef-")
+}